@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/prescribe/internal/controller"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type CacheClearCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &CacheClearCommand{}
+
+type CacheClearSettings struct {
+	Expired bool `glazed.parameter:"expired"`
+}
+
+func NewCacheClearCommand() (*CacheClearCommand, error) {
+	repoLayer, err := prescribe_layers.NewRepositoryLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository layer")
+	}
+	repoLayerExisting, err := prescribe_layers.WrapAsExistingCobraFlagsLayer(repoLayer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
+	}
+
+	expiredFlag := parameters.NewParameterDefinition(
+		"expired",
+		parameters.ParameterTypeBool,
+		parameters.WithHelp("Only evict entries beyond the configured age/size limits (cache.max_age, cache.max_size_mb)"),
+		parameters.WithDefault(false),
+	)
+
+	cmdDesc := cmds.NewCommandDescription(
+		"clear",
+		cmds.WithShort("Clear the generation cache"),
+		cmds.WithLong("Remove all cached generations of the repository, or with --expired only those beyond the eviction limits."),
+		cmds.WithFlags(expiredFlag),
+		cmds.WithLayersList(
+			repoLayerExisting,
+		),
+	)
+
+	return &CacheClearCommand{CommandDescription: cmdDesc}, nil
+}
+
+func (c *CacheClearCommand) Run(ctx context.Context, parsedLayers *glazed_layers.ParsedLayers) error {
+	_ = ctx
+
+	settings := &CacheClearSettings{}
+	if err := parsedLayers.InitializeStruct(glazed_layers.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "failed to decode cache clear settings")
+	}
+
+	repoSettings, err := prescribe_layers.GetRepositorySettings(parsedLayers)
+	if err != nil {
+		return err
+	}
+	ctrl, err := controller.NewController(repoSettings.RepoPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to create controller")
+	}
+	store := ctrl.CacheStore()
+
+	if settings.Expired {
+		policy, err := ctrl.CachePolicy()
+		if err != nil {
+			return err
+		}
+		n, err := store.Evict(policy, time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Evicted %d cache entr%s from %s\n", n, plural(n), store.Dir())
+		return nil
+	}
+
+	n, err := store.Clear()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d cache entr%s from %s\n", n, plural(n), store.Dir())
+	return nil
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return "y"
+	}
+	return "ies"
+}
+
+func NewClearCobraCommand() (*cobra.Command, error) {
+	glazedCmd, err := NewCacheClearCommand()
+	if err != nil {
+		return nil, err
+	}
+	cobraCmd, err := cli.BuildCobraCommand(
+		glazedCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{
+			MiddlewaresFunc: cli.CobraCommandDefaultMiddlewares,
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return cobraCmd, nil
+}
@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	pcache "github.com/go-go-golems/prescribe/internal/cache"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type CacheLsCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.GlazeCommand = &CacheLsCommand{}
+
+func NewCacheLsCommand() (*CacheLsCommand, error) {
+	repoLayer, err := prescribe_layers.NewRepositoryLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository layer")
+	}
+	repoLayerExisting, err := prescribe_layers.WrapAsExistingCobraFlagsLayer(repoLayer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
+	}
+
+	cmdDesc := cmds.NewCommandDescription(
+		"ls",
+		cmds.WithShort("List cached generations"),
+		cmds.WithLong("List the cached generations of the repository, newest first. Each entry is .pr-builder/cache/<key>.yaml (plus <key>.turn.yaml with the conversation)."),
+		cmds.WithLayersList(
+			repoLayerExisting,
+		),
+	)
+
+	return &CacheLsCommand{CommandDescription: cmdDesc}, nil
+}
+
+func (c *CacheLsCommand) RunIntoGlazeProcessor(
+	ctx context.Context,
+	parsedLayers *glazed_layers.ParsedLayers,
+	gp middlewares.Processor,
+) error {
+	repoSettings, err := prescribe_layers.GetRepositorySettings(parsedLayers)
+	if err != nil {
+		return err
+	}
+
+	store := pcache.NewStore(pcache.Dir(repoSettings.RepoPath))
+	infos, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, info := range infos {
+		row := types.NewRow(
+			types.MRP("key", info.Key),
+			types.MRP("created_at", info.CreatedAt.Format(time.RFC3339)),
+			types.MRP("age", now.Sub(info.CreatedAt).Round(time.Second).String()),
+			types.MRP("model", info.Model),
+			types.MRP("title", info.Title),
+			types.MRP("size_bytes", info.Size),
+			types.MRP("path", filepath.Join(store.Dir(), info.Key+".yaml")),
+		)
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func NewLsCobraCommand() (*cobra.Command, error) {
+	glazedCmd, err := NewCacheLsCommand()
+	if err != nil {
+		return nil, err
+	}
+
+	cobraCmd, err := cli.BuildCobraCommand(
+		glazedCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{
+			MiddlewaresFunc: cli.CobraCommandDefaultMiddlewares,
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	cobraCmd.Aliases = []string{"list"}
+
+	return cobraCmd, nil
+}
@@ -0,0 +1,27 @@
+package cache
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// NewCacheCmd groups the generation cache subcommands.
+func NewCacheCmd() (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the generation cache",
+		Long:  "Inspect and clear cached generations in .pr-builder/cache/ (keyed by a hash of the compiled prompt and model settings).",
+	}
+
+	lsCmd, err := NewLsCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build cache ls command")
+	}
+	clearCmd, err := NewClearCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build cache clear command")
+	}
+
+	cmd.AddCommand(lsCmd, clearCmd)
+	return cmd, nil
+}
@@ -107,6 +107,9 @@ func (c *CommitPatchCommand) Run(ctx context.Context, parsedLayers *glazed_layer
 		return err
 	}
 	helpers.LoadDefaultSessionIfExists(ctrl)
+	if err := ctrl.CheckAllowed(settings.Paths...); err != nil {
+		return err
+	}
 
 	data := ctrl.GetData()
 	data.GitContext = append(data.GitContext, domain.GitContextItem{
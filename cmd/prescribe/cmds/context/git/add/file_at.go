@@ -89,6 +89,9 @@ func (c *FileAtCommand) Run(ctx context.Context, parsedLayers *glazed_layers.Par
 		return err
 	}
 	helpers.LoadDefaultSessionIfExists(ctrl)
+	if err := ctrl.CheckAllowed(path); err != nil {
+		return err
+	}
 
 	data := ctrl.GetData()
 	data.GitContext = append(data.GitContext, domain.GitContextItem{
@@ -99,6 +99,9 @@ func (c *FileDiffCommand) Run(ctx context.Context, parsedLayers *glazed_layers.P
 		return err
 	}
 	helpers.LoadDefaultSessionIfExists(ctrl)
+	if err := ctrl.CheckAllowed(path); err != nil {
+		return err
+	}
 
 	data := ctrl.GetData()
 	data.GitContext = append(data.GitContext, domain.GitContextItem{
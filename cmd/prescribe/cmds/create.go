@@ -130,7 +130,7 @@ func (c *CreateCommand) Run(ctx context.Context, parsedLayers *glazed_layers.Par
 		}
 		sourceDesc = "yaml-file:" + extra.YAMLFile
 		title = p.Title
-		body = p.Body
+		body = renderedPRBody(extra.YAMLFile, p)
 	} else if extra.UseLast {
 		path := prdata.LastGeneratedPRDataPath(repoSettings.RepoPath)
 		p, err := prdata.LoadGeneratedPRDataFromYAMLFile(path)
@@ -139,7 +139,7 @@ func (c *CreateCommand) Run(ctx context.Context, parsedLayers *glazed_layers.Par
 		}
 		sourceDesc = "use-last:" + path
 		title = p.Title
-		body = p.Body
+		body = renderedPRBody(path, p)
 	} else {
 		sourceDesc = "flags"
 	}
@@ -189,7 +189,7 @@ func (c *CreateCommand) Run(ctx context.Context, parsedLayers *glazed_layers.Par
 	pushStart := time.Now()
 	if err := gitSvc.PushCurrentBranch(ctx); err != nil {
 		failPath := prdata.FailurePRDataPath(repoSettings.RepoPath, time.Now())
-		if werr := prdata.WriteGeneratedPRDataToYAMLFile(failPath, &domain.GeneratedPRData{Title: opts.Title, Body: opts.Body}); werr != nil {
+		if werr := prdata.WriteGeneratedPRDataToYAMLFile(failPath, &domain.GeneratedPRData{Title: opts.Title, Body: opts.Body}, nil); werr != nil {
 			fmt.Fprintf(os.Stderr, "prescribe create: git push failed after %s; also failed to save PR data to %s: %v\n", time.Since(pushStart), failPath, werr)
 		} else {
 			fmt.Fprintf(os.Stderr, "prescribe create: git push failed after %s; saved PR data to %s\n", time.Since(pushStart), failPath)
@@ -203,7 +203,7 @@ func (c *CreateCommand) Run(ctx context.Context, parsedLayers *glazed_layers.Par
 	out, err := svc.CreatePR(ctx, opts)
 	if err != nil {
 		failPath := prdata.FailurePRDataPath(repoSettings.RepoPath, time.Now())
-		if werr := prdata.WriteGeneratedPRDataToYAMLFile(failPath, &domain.GeneratedPRData{Title: opts.Title, Body: opts.Body}); werr != nil {
+		if werr := prdata.WriteGeneratedPRDataToYAMLFile(failPath, &domain.GeneratedPRData{Title: opts.Title, Body: opts.Body}, nil); werr != nil {
 			fmt.Fprintf(os.Stderr, "prescribe create: gh pr create failed after %s; also failed to save PR data to %s: %v\n", time.Since(ghStart), failPath, werr)
 		} else {
 			fmt.Fprintf(os.Stderr, "prescribe create: gh pr create failed after %s; saved PR data to %s\n", time.Since(ghStart), failPath)
@@ -244,3 +244,13 @@ func NewCreateCobraCommand() (*cobra.Command, error) {
 
 	return cobraCmd, nil
 }
+
+// renderedPRBody is the PR body with custom output schema fields (risk level, rollout, ...) appended,
+// using the schema recorded in the file's generation metadata when there is one.
+func renderedPRBody(path string, p *domain.GeneratedPRData) string {
+	var schema *domain.OutputSchema
+	if meta, err := prdata.LoadGenerationMetadataFromYAMLFile(path); err == nil && meta != nil {
+		schema = meta.OutputSchema
+	}
+	return p.RenderBody(schema)
+}
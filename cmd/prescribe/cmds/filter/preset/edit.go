@@ -0,0 +1,129 @@
+package preset
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
+	"github.com/go-go-golems/prescribe/internal/controller"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type EditSettings struct {
+	PresetID string `glazed.parameter:"preset-id"`
+	Project  bool   `glazed.parameter:"project"`
+	Global   bool   `glazed.parameter:"global"`
+}
+
+type EditCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &EditCommand{}
+
+func NewEditCommand() (*EditCommand, error) {
+	repoLayer, err := prescribe_layers.NewRepositoryLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository layer")
+	}
+	repoLayerExisting, err := prescribe_layers.WrapAsExistingCobraFlagsLayer(repoLayer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
+	}
+
+	defaultLayer, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithFields(scopeFields()...),
+		schema.WithArguments(
+			fields.New(
+				"preset-id",
+				fields.TypeString,
+				fields.WithHelp("Preset ID (filename)"),
+				fields.WithRequired(true),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdDesc := cmds.NewCommandDescription(
+		"edit",
+		cmds.WithShort("Edit a filter preset in $EDITOR"),
+		cmds.WithLong("Open a filter preset file in $VISUAL/$EDITOR (default: vi) and validate it after the editor exits."),
+		cmds.WithLayersList(repoLayerExisting, defaultLayer),
+	)
+
+	return &EditCommand{CommandDescription: cmdDesc}, nil
+}
+
+func (c *EditCommand) Run(ctx context.Context, parsedLayers *glazed_layers.ParsedLayers) error {
+	settings := &EditSettings{}
+	if err := parsedLayers.InitializeStruct(schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "failed to initialize preset edit settings")
+	}
+	location, err := resolveScope(settings.Project, settings.Global)
+	if err != nil {
+		return err
+	}
+
+	ctrl, err := helpers.NewInitializedControllerFromParsedLayers(parsedLayers)
+	if err != nil {
+		return err
+	}
+
+	// Resolve by file existence rather than by parsing, so broken presets can be fixed here.
+	candidates := []domain.PresetLocation{location}
+	if location == "" {
+		candidates = []domain.PresetLocation{domain.PresetLocationProject, domain.PresetLocationGlobal}
+	}
+	path := ""
+	for _, loc := range candidates {
+		p, err := ctrl.FilterPresetPath(settings.PresetID, loc)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(p); err == nil {
+			path = p
+			break
+		}
+	}
+	if path == "" {
+		return errors.Errorf("filter preset not found: %s", settings.PresetID)
+	}
+
+	if err := helpers.RunEditor(ctx, path); err != nil {
+		return err
+	}
+
+	p, err := controller.ValidateFilterPresetFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "edited preset %s is invalid", path)
+	}
+
+	fmt.Printf("Filter preset %q saved (%d rules)\n", p.Name, len(p.Rules))
+	return nil
+}
+
+func NewEditCobraCommand() (*cobra.Command, error) {
+	glazedCmd, err := NewEditCommand()
+	if err != nil {
+		return nil, err
+	}
+	return cli.BuildCobraCommand(
+		glazedCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{
+			MiddlewaresFunc: cli.CobraCommandDefaultMiddlewares,
+		}),
+	)
+}
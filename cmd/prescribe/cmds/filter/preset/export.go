@@ -0,0 +1,177 @@
+package preset
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
+	"github.com/go-go-golems/prescribe/internal/controller"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type ExportSettings struct {
+	PresetIDs []string `glazed.parameter:"preset-ids"`
+	Output    string   `glazed.parameter:"output"`
+	Format    string   `glazed.parameter:"format"`
+	Project   bool     `glazed.parameter:"project"`
+	Global    bool     `glazed.parameter:"global"`
+}
+
+type ExportCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &ExportCommand{}
+
+func NewExportCommand() (*ExportCommand, error) {
+	repoLayer, err := prescribe_layers.NewRepositoryLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository layer")
+	}
+	repoLayerExisting, err := prescribe_layers.WrapAsExistingCobraFlagsLayer(repoLayer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
+	}
+
+	defaultLayer, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithFields(append(scopeFields(),
+			fields.New(
+				"output",
+				fields.TypeString,
+				fields.WithDefault(""),
+				fields.WithHelp("Bundle file to write (default: stdout)"),
+				fields.WithShortFlag("o"),
+			),
+			fields.New(
+				"format",
+				fields.TypeChoice,
+				fields.WithChoices("", "yaml", "tar"),
+				fields.WithDefault(""),
+				fields.WithHelp("Bundle format: yaml or tar (default: derived from --output extension, else yaml)"),
+			),
+		)...),
+		schema.WithArguments(
+			fields.New(
+				"preset-ids",
+				fields.TypeStringList,
+				fields.WithHelp("Preset IDs to export (default: all presets in scope)"),
+				fields.WithRequired(false),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdDesc := cmds.NewCommandDescription(
+		"export",
+		cmds.WithShort("Export filter presets to a bundle"),
+		cmds.WithLong("Export filter presets into a single YAML or tar bundle for sharing across repos. Without --project/--global, both locations are exported (project wins on ID clashes)."),
+		cmds.WithLayersList(repoLayerExisting, defaultLayer),
+	)
+
+	return &ExportCommand{CommandDescription: cmdDesc}, nil
+}
+
+func (c *ExportCommand) Run(ctx context.Context, parsedLayers *glazed_layers.ParsedLayers) error {
+	_ = ctx
+
+	settings := &ExportSettings{}
+	if err := parsedLayers.InitializeStruct(schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "failed to initialize preset export settings")
+	}
+	location, err := resolveScope(settings.Project, settings.Global)
+	if err != nil {
+		return err
+	}
+
+	ctrl, err := helpers.NewInitializedControllerFromParsedLayers(parsedLayers)
+	if err != nil {
+		return err
+	}
+
+	locations := []domain.PresetLocation{location}
+	if location == "" {
+		locations = []domain.PresetLocation{domain.PresetLocationProject, domain.PresetLocationGlobal}
+	}
+
+	wanted := map[string]bool{}
+	for _, id := range settings.PresetIDs {
+		wanted[id] = true
+	}
+
+	seen := map[string]bool{}
+	selected := make([]domain.FilterPreset, 0)
+	for _, loc := range locations {
+		presets, fileErrs, err := ctrl.ScanFilterPresets(loc)
+		if err != nil {
+			return errors.Wrapf(err, "failed to scan %s filter presets", loc)
+		}
+		for _, fe := range fileErrs {
+			fmt.Fprintf(os.Stderr, "Warning: skipping invalid filter preset %s\n", fe.Error())
+		}
+		for _, p := range presets {
+			if seen[p.ID] {
+				continue
+			}
+			if len(wanted) > 0 && !wanted[p.ID] {
+				continue
+			}
+			seen[p.ID] = true
+			selected = append(selected, p)
+		}
+	}
+	for id := range wanted {
+		if !seen[id] {
+			return errors.Errorf("filter preset not found: %s", id)
+		}
+	}
+	if len(selected) == 0 {
+		return errors.New("no filter presets to export")
+	}
+
+	format := controller.FilterPresetBundleFormat(settings.Format)
+	if format == "" {
+		format = controller.FilterPresetBundleFormatForPath(settings.Output)
+	}
+
+	var buf bytes.Buffer
+	if err := controller.ExportFilterPresets(&buf, selected, format); err != nil {
+		return errors.Wrap(err, "failed to export filter presets")
+	}
+
+	if settings.Output == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	if err := os.WriteFile(settings.Output, buf.Bytes(), 0644); err != nil {
+		return errors.Wrap(err, "failed to write preset bundle")
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d filter preset(s) to %s (%s)\n", len(selected), settings.Output, format)
+	return nil
+}
+
+func NewExportCobraCommand() (*cobra.Command, error) {
+	glazedCmd, err := NewExportCommand()
+	if err != nil {
+		return nil, err
+	}
+	return cli.BuildCobraCommand(
+		glazedCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{
+			MiddlewaresFunc: cli.CobraCommandDefaultMiddlewares,
+		}),
+	)
+}
@@ -0,0 +1,40 @@
+package preset
+
+import (
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/pkg/errors"
+)
+
+// scopeFields returns the optional --project/--global flags used to disambiguate a preset ID.
+func scopeFields() []*fields.Definition {
+	return []*fields.Definition{
+		fields.New(
+			"project",
+			fields.TypeBool,
+			fields.WithDefault(false),
+			fields.WithHelp("Only look at project presets (<repo>/.pr-builder/filters)"),
+		),
+		fields.New(
+			"global",
+			fields.TypeBool,
+			fields.WithDefault(false),
+			fields.WithHelp("Only look at global presets (~/.pr-builder/filters)"),
+		),
+	}
+}
+
+// resolveScope maps --project/--global to a preset location.
+// An empty location means "search project first, then global".
+func resolveScope(project, global bool) (domain.PresetLocation, error) {
+	if project && global {
+		return "", errors.New("choose at most one location: --project or --global")
+	}
+	if project {
+		return domain.PresetLocationProject, nil
+	}
+	if global {
+		return domain.PresetLocationGlobal, nil
+	}
+	return "", nil
+}
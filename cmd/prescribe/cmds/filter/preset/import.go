@@ -0,0 +1,160 @@
+package preset
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
+	"github.com/go-go-golems/prescribe/internal/controller"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type ImportSettings struct {
+	File      string `glazed.parameter:"file"`
+	Format    string `glazed.parameter:"format"`
+	Overwrite bool   `glazed.parameter:"overwrite"`
+	Project   bool   `glazed.parameter:"project"`
+	Global    bool   `glazed.parameter:"global"`
+}
+
+type ImportCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &ImportCommand{}
+
+func NewImportCommand() (*ImportCommand, error) {
+	repoLayer, err := prescribe_layers.NewRepositoryLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository layer")
+	}
+	repoLayerExisting, err := prescribe_layers.WrapAsExistingCobraFlagsLayer(repoLayer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
+	}
+
+	defaultLayer, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithFields(
+			fields.New(
+				"project",
+				fields.TypeBool,
+				fields.WithDefault(false),
+				fields.WithHelp("Import into project presets (<repo>/.pr-builder/filters)"),
+			),
+			fields.New(
+				"global",
+				fields.TypeBool,
+				fields.WithDefault(false),
+				fields.WithHelp("Import into global presets (~/.pr-builder/filters)"),
+			),
+			fields.New(
+				"format",
+				fields.TypeChoice,
+				fields.WithChoices("", "yaml", "tar"),
+				fields.WithDefault(""),
+				fields.WithHelp("Bundle format: yaml or tar (default: derived from the file extension)"),
+			),
+			fields.New(
+				"overwrite",
+				fields.TypeBool,
+				fields.WithDefault(false),
+				fields.WithHelp("Overwrite presets that already exist at the destination"),
+			),
+		),
+		schema.WithArguments(
+			fields.New(
+				"file",
+				fields.TypeString,
+				fields.WithHelp("Bundle file produced by `prescribe filter preset export`"),
+				fields.WithRequired(true),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdDesc := cmds.NewCommandDescription(
+		"import",
+		cmds.WithShort("Import filter presets from a bundle"),
+		cmds.WithLong("Import filter presets from a YAML or tar bundle into project or global presets. Every preset is validated before anything is written."),
+		cmds.WithLayersList(repoLayerExisting, defaultLayer),
+	)
+
+	return &ImportCommand{CommandDescription: cmdDesc}, nil
+}
+
+func (c *ImportCommand) Run(ctx context.Context, parsedLayers *glazed_layers.ParsedLayers) error {
+	_ = ctx
+
+	settings := &ImportSettings{}
+	if err := parsedLayers.InitializeStruct(schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "failed to initialize preset import settings")
+	}
+	location, err := resolveScope(settings.Project, settings.Global)
+	if err != nil {
+		return err
+	}
+	if location == "" {
+		return errors.New("missing location: choose --project or --global")
+	}
+
+	ctrl, err := helpers.NewInitializedControllerFromParsedLayers(parsedLayers)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(settings.File)
+	if err != nil {
+		return errors.Wrap(err, "failed to open preset bundle")
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	format := controller.FilterPresetBundleFormat(settings.Format)
+	if format == "" {
+		format = controller.FilterPresetBundleFormatForPath(settings.File)
+	}
+
+	presets, err := controller.ReadFilterPresetBundle(f, format)
+	if err != nil {
+		return errors.Wrap(err, "failed to read preset bundle")
+	}
+
+	imported, skipped, err := ctrl.ImportFilterPresets(presets, location, settings.Overwrite)
+	if err != nil {
+		return errors.Wrap(err, "failed to import filter presets")
+	}
+
+	for _, id := range imported {
+		fmt.Printf("Imported %s (%s)\n", id, location)
+	}
+	for _, id := range skipped {
+		fmt.Printf("Skipped %s (already exists; use --overwrite)\n", id)
+	}
+	return nil
+}
+
+func NewImportCobraCommand() (*cobra.Command, error) {
+	glazedCmd, err := NewImportCommand()
+	if err != nil {
+		return nil, err
+	}
+	return cli.BuildCobraCommand(
+		glazedCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{
+			MiddlewaresFunc: cli.CobraCommandDefaultMiddlewares,
+		}),
+	)
+}
@@ -2,6 +2,8 @@ package preset
 
 import (
 	"context"
+	"fmt"
+	"os"
 
 	"github.com/go-go-golems/glazed/pkg/cli"
 	"github.com/go-go-golems/glazed/pkg/cmds"
@@ -11,6 +13,8 @@ import (
 	"github.com/go-go-golems/glazed/pkg/middlewares"
 	"github.com/go-go-golems/glazed/pkg/types"
 	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
+	"github.com/go-go-golems/prescribe/internal/controller"
+	"github.com/go-go-golems/prescribe/internal/domain"
 	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -21,6 +25,7 @@ const listSlug = "filter-preset-list"
 type ListSettings struct {
 	Project bool `glazed.parameter:"project"`
 	Global  bool `glazed.parameter:"global"`
+	Builtin bool `glazed.parameter:"builtin"`
 	All     bool `glazed.parameter:"all"`
 }
 
@@ -56,11 +61,17 @@ func NewListCommand() (*ListCommand, error) {
 				fields.WithDefault(false),
 				fields.WithHelp("List global presets (~/.pr-builder/filters)"),
 			),
+			fields.New(
+				"builtin",
+				fields.TypeBool,
+				fields.WithDefault(false),
+				fields.WithHelp("List builtin presets shipped with prescribe"),
+			),
 			fields.New(
 				"all",
 				fields.TypeBool,
 				fields.WithDefault(false),
-				fields.WithHelp("List both project and global presets (default when no scope flags are set)"),
+				fields.WithHelp("List project, global and builtin presets (default when no scope flags are set)"),
 			),
 		),
 	)
@@ -71,7 +82,7 @@ func NewListCommand() (*ListCommand, error) {
 	cmdDesc := cmds.NewCommandDescription(
 		"list",
 		cmds.WithShort("List filter presets"),
-		cmds.WithLong("List named filter presets from project/global locations."),
+		cmds.WithLong("List named filter presets from project/global locations and the builtin library."),
 		cmds.WithLayersList(
 			repoLayerExisting,
 			listLayer,
@@ -98,92 +109,96 @@ func (c *ListCommand) RunIntoGlazeProcessor(
 
 	wantProject := settings.Project
 	wantGlobal := settings.Global
+	wantBuiltin := settings.Builtin
 	wantAll := settings.All
 
-	if !wantProject && !wantGlobal && !wantAll {
+	if !wantProject && !wantGlobal && !wantBuiltin && !wantAll {
 		wantAll = true
 	}
 	if wantAll {
 		wantProject = true
 		wantGlobal = true
+		wantBuiltin = true
 	}
 
 	if wantProject {
-		ps, err := ctrl.LoadProjectFilterPresets()
+		ps, fileErrs, err := ctrl.ScanFilterPresets(domain.PresetLocationProject)
 		if err != nil {
 			return errors.Wrap(err, "failed to load project filter presets")
 		}
-		for _, p := range ps {
-			if len(p.Rules) == 0 {
-				row := types.NewRow(
-					types.MRP("preset_id", p.ID),
-					types.MRP("preset_name", p.Name),
-					types.MRP("preset_description", p.Description),
-					types.MRP("preset_location", p.Location),
-					types.MRP("rule_index", nil),
-					types.MRP("rule_type", nil),
-					types.MRP("rule_pattern", nil),
-				)
-				if err := gp.AddRow(ctx, row); err != nil {
-					return err
-				}
-				continue
-			}
-			for i, r := range p.Rules {
-				row := types.NewRow(
-					types.MRP("preset_id", p.ID),
-					types.MRP("preset_name", p.Name),
-					types.MRP("preset_description", p.Description),
-					types.MRP("preset_location", p.Location),
-					types.MRP("rule_index", i),
-					types.MRP("rule_type", r.Type),
-					types.MRP("rule_pattern", r.Pattern),
-				)
-				if err := gp.AddRow(ctx, row); err != nil {
-					return err
-				}
-			}
+		printFileErrors(fileErrs)
+		if err := addPresetRows(ctx, gp, ps); err != nil {
+			return err
 		}
 	}
 
 	if wantGlobal {
-		ps, err := ctrl.LoadGlobalFilterPresets()
+		ps, fileErrs, err := ctrl.ScanFilterPresets(domain.PresetLocationGlobal)
 		if err != nil {
 			return errors.Wrap(err, "failed to load global filter presets")
 		}
-		for _, p := range ps {
-			if len(p.Rules) == 0 {
-				row := types.NewRow(
-					types.MRP("preset_id", p.ID),
-					types.MRP("preset_name", p.Name),
-					types.MRP("preset_description", p.Description),
-					types.MRP("preset_location", p.Location),
-					types.MRP("rule_index", nil),
-					types.MRP("rule_type", nil),
-					types.MRP("rule_pattern", nil),
-				)
-				if err := gp.AddRow(ctx, row); err != nil {
-					return err
-				}
-				continue
+		printFileErrors(fileErrs)
+		if err := addPresetRows(ctx, gp, ps); err != nil {
+			return err
+		}
+	}
+
+	if wantBuiltin {
+		ps, err := controller.LoadBuiltinFilterPresets()
+		if err != nil {
+			return errors.Wrap(err, "failed to load builtin filter presets")
+		}
+		if err := addPresetRows(ctx, gp, ps); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printFileErrors reports the preset files that were skipped on stderr, so the listing stays
+// machine-readable.
+func printFileErrors(fileErrs []controller.FilterPresetFileError) {
+	for _, fe := range fileErrs {
+		fmt.Fprintf(os.Stderr, "Warning: skipping invalid %s filter preset %s: %v\n", fe.Location, fe.Path, fe.Err)
+	}
+	if len(fileErrs) > 0 {
+		fmt.Fprintf(os.Stderr, "Run 'prescribe filter preset validate' for details.\n")
+	}
+}
+
+func addPresetRows(ctx context.Context, gp middlewares.Processor, ps []domain.FilterPreset) error {
+	for _, p := range ps {
+		if len(p.Rules) == 0 {
+			row := types.NewRow(
+				types.MRP("preset_id", p.ID),
+				types.MRP("preset_name", p.Name),
+				types.MRP("preset_description", p.Description),
+				types.MRP("preset_location", p.Location),
+				types.MRP("rule_index", nil),
+				types.MRP("rule_type", nil),
+				types.MRP("rule_pattern", nil),
+			)
+			if err := gp.AddRow(ctx, row); err != nil {
+				return err
 			}
-			for i, r := range p.Rules {
-				row := types.NewRow(
-					types.MRP("preset_id", p.ID),
-					types.MRP("preset_name", p.Name),
-					types.MRP("preset_description", p.Description),
-					types.MRP("preset_location", p.Location),
-					types.MRP("rule_index", i),
-					types.MRP("rule_type", r.Type),
-					types.MRP("rule_pattern", r.Pattern),
-				)
-				if err := gp.AddRow(ctx, row); err != nil {
-					return err
-				}
+			continue
+		}
+		for i, r := range p.Rules {
+			row := types.NewRow(
+				types.MRP("preset_id", p.ID),
+				types.MRP("preset_name", p.Name),
+				types.MRP("preset_description", p.Description),
+				types.MRP("preset_location", p.Location),
+				types.MRP("rule_index", i),
+				types.MRP("rule_type", r.Type),
+				types.MRP("rule_pattern", r.Pattern),
+			)
+			if err := gp.AddRow(ctx, row); err != nil {
+				return err
 			}
 		}
 	}
-
 	return nil
 }
 
@@ -0,0 +1,111 @@
+package preset
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type RenameSettings struct {
+	PresetID string `glazed.parameter:"preset-id"`
+	NewName  string `glazed.parameter:"new-name"`
+	Project  bool   `glazed.parameter:"project"`
+	Global   bool   `glazed.parameter:"global"`
+}
+
+type RenameCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &RenameCommand{}
+
+func NewRenameCommand() (*RenameCommand, error) {
+	repoLayer, err := prescribe_layers.NewRepositoryLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository layer")
+	}
+	repoLayerExisting, err := prescribe_layers.WrapAsExistingCobraFlagsLayer(repoLayer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
+	}
+
+	defaultLayer, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithFields(scopeFields()...),
+		schema.WithArguments(
+			fields.New(
+				"preset-id",
+				fields.TypeString,
+				fields.WithHelp("Preset ID (filename)"),
+				fields.WithRequired(true),
+			),
+			fields.New(
+				"new-name",
+				fields.TypeString,
+				fields.WithHelp("New preset name (the filename is derived from it)"),
+				fields.WithRequired(true),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdDesc := cmds.NewCommandDescription(
+		"rename",
+		cmds.WithShort("Rename a filter preset"),
+		cmds.WithLong("Change a filter preset's name and move it to the matching filename. Refuses to overwrite another preset."),
+		cmds.WithLayersList(repoLayerExisting, defaultLayer),
+	)
+
+	return &RenameCommand{CommandDescription: cmdDesc}, nil
+}
+
+func (c *RenameCommand) Run(ctx context.Context, parsedLayers *glazed_layers.ParsedLayers) error {
+	_ = ctx
+
+	settings := &RenameSettings{}
+	if err := parsedLayers.InitializeStruct(schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "failed to initialize preset rename settings")
+	}
+	location, err := resolveScope(settings.Project, settings.Global)
+	if err != nil {
+		return err
+	}
+
+	ctrl, err := helpers.NewInitializedControllerFromParsedLayers(parsedLayers)
+	if err != nil {
+		return err
+	}
+
+	p, err := ctrl.RenameFilterPreset(settings.PresetID, settings.NewName, location)
+	if err != nil {
+		return errors.Wrap(err, "failed to rename filter preset")
+	}
+
+	fmt.Printf("Renamed filter preset %q to %q (%s, %s)\n", settings.PresetID, p.Name, p.ID, p.Location)
+	return nil
+}
+
+func NewRenameCobraCommand() (*cobra.Command, error) {
+	glazedCmd, err := NewRenameCommand()
+	if err != nil {
+		return nil, err
+	}
+	return cli.BuildCobraCommand(
+		glazedCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{
+			MiddlewaresFunc: cli.CobraCommandDefaultMiddlewares,
+		}),
+	)
+}
@@ -10,7 +10,7 @@ func NewPresetCmd() (*cobra.Command, error) {
 	cmd := &cobra.Command{
 		Use:   "preset",
 		Short: "Manage filter presets",
-		Long:  "List, save, and apply named filter presets.",
+		Long:  "List, show, save, apply, edit, rename, delete, and validate named filter presets, and share them as export/import bundles.",
 	}
 
 	listCmd, err := NewListCobraCommand()
@@ -26,6 +26,35 @@ func NewPresetCmd() (*cobra.Command, error) {
 		return nil, errors.Wrap(err, "failed to build filter preset apply command")
 	}
 
-	cmd.AddCommand(listCmd, saveCmd, applyCmd)
+	showCmd, err := NewShowCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build filter preset show command")
+	}
+	deleteCmd, err := NewDeleteCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build filter preset delete command")
+	}
+	renameCmd, err := NewRenameCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build filter preset rename command")
+	}
+	editCmd, err := NewEditCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build filter preset edit command")
+	}
+	validateCmd, err := NewValidateCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build filter preset validate command")
+	}
+	exportCmd, err := NewExportCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build filter preset export command")
+	}
+	importCmd, err := NewImportCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build filter preset import command")
+	}
+
+	cmd.AddCommand(listCmd, showCmd, saveCmd, applyCmd, editCmd, renameCmd, deleteCmd, validateCmd, exportCmd, importCmd)
 	return cmd, nil
 }
@@ -167,11 +167,12 @@ func (c *SaveCommand) Run(ctx context.Context, parsedLayers *glazed_layers.Parse
 		}
 	}
 
-	if err := ctrl.SaveFilterPreset(settings.Name, settings.Description, rules, location); err != nil {
+	presetID, err := ctrl.SaveFilterPreset(settings.Name, settings.Description, rules, location)
+	if err != nil {
 		return errors.Wrap(err, "failed to save filter preset")
 	}
 
-	fmt.Printf("Filter preset '%s' saved (%s)\n", settings.Name, location)
+	fmt.Printf("Filter preset '%s' saved as %s (%s)\n", settings.Name, presetID, location)
 	return nil
 }
 
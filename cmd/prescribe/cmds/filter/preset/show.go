@@ -0,0 +1,126 @@
+package preset
+
+import (
+	"context"
+
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type ShowSettings struct {
+	PresetID string `glazed.parameter:"preset-id"`
+	Project  bool   `glazed.parameter:"project"`
+	Global   bool   `glazed.parameter:"global"`
+}
+
+type ShowCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.GlazeCommand = &ShowCommand{}
+
+func NewShowCommand() (*ShowCommand, error) {
+	repoLayer, err := prescribe_layers.NewRepositoryLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository layer")
+	}
+	repoLayerExisting, err := prescribe_layers.WrapAsExistingCobraFlagsLayer(repoLayer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
+	}
+
+	defaultLayer, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithFields(scopeFields()...),
+		schema.WithArguments(
+			fields.New(
+				"preset-id",
+				fields.TypeString,
+				fields.WithHelp("Preset ID (filename)"),
+				fields.WithRequired(true),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdDesc := cmds.NewCommandDescription(
+		"show",
+		cmds.WithShort("Show a filter preset"),
+		cmds.WithLong("Show the rules, location and backing file of a single filter preset."),
+		cmds.WithLayersList(repoLayerExisting, defaultLayer),
+	)
+
+	return &ShowCommand{CommandDescription: cmdDesc}, nil
+}
+
+func (c *ShowCommand) RunIntoGlazeProcessor(
+	ctx context.Context,
+	parsedLayers *glazed_layers.ParsedLayers,
+	gp middlewares.Processor,
+) error {
+	settings := &ShowSettings{}
+	if err := parsedLayers.InitializeStruct(schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "failed to initialize preset show settings")
+	}
+	location, err := resolveScope(settings.Project, settings.Global)
+	if err != nil {
+		return err
+	}
+
+	ctrl, err := helpers.NewInitializedControllerFromParsedLayers(parsedLayers)
+	if err != nil {
+		return err
+	}
+
+	p, err := ctrl.ResolveFilterPreset(settings.PresetID, location)
+	if err != nil {
+		return errors.Wrap(err, "failed to load filter preset")
+	}
+	path, err := ctrl.FilterPresetPath(p.ID, p.Location)
+	if err != nil {
+		path = ""
+	}
+
+	for i, r := range p.Rules {
+		row := types.NewRow(
+			types.MRP("preset_id", p.ID),
+			types.MRP("preset_name", p.Name),
+			types.MRP("preset_description", p.Description),
+			types.MRP("preset_location", p.Location),
+			types.MRP("preset_path", path),
+			types.MRP("rule_index", i),
+			types.MRP("rule_type", r.Type),
+			types.MRP("rule_pattern", r.Pattern),
+		)
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func NewShowCobraCommand() (*cobra.Command, error) {
+	glazedCmd, err := NewShowCommand()
+	if err != nil {
+		return nil, err
+	}
+	return cli.BuildCobraCommand(
+		glazedCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{
+			MiddlewaresFunc: cli.CobraCommandDefaultMiddlewares,
+		}),
+	)
+}
@@ -0,0 +1,168 @@
+package preset
+
+import (
+	"context"
+
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type ValidateSettings struct {
+	PresetID string `glazed.parameter:"preset-id"`
+	Project  bool   `glazed.parameter:"project"`
+	Global   bool   `glazed.parameter:"global"`
+}
+
+type ValidateCommand struct {
+	*cmds.CommandDescription
+	// invalid is the number of invalid presets found by the last run. It is reported after the
+	// table is printed (an error returned from RunIntoGlazeProcessor would suppress the table).
+	invalid int
+}
+
+var _ cmds.GlazeCommand = &ValidateCommand{}
+
+func NewValidateCommand() (*ValidateCommand, error) {
+	repoLayer, err := prescribe_layers.NewRepositoryLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository layer")
+	}
+	repoLayerExisting, err := prescribe_layers.WrapAsExistingCobraFlagsLayer(repoLayer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
+	}
+
+	defaultLayer, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithFields(scopeFields()...),
+		schema.WithArguments(
+			fields.New(
+				"preset-id",
+				fields.TypeString,
+				fields.WithHelp("Only validate this preset ID (default: all presets)"),
+				fields.WithRequired(false),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdDesc := cmds.NewCommandDescription(
+		"validate",
+		cmds.WithShort("Validate filter preset files"),
+		cmds.WithLong("Parse and validate filter preset files, reporting malformed YAML, unknown keys, bad rule types and invalid glob patterns. Exits non-zero if any preset is invalid."),
+		cmds.WithLayersList(repoLayerExisting, defaultLayer),
+	)
+
+	return &ValidateCommand{CommandDescription: cmdDesc}, nil
+}
+
+func (c *ValidateCommand) RunIntoGlazeProcessor(
+	ctx context.Context,
+	parsedLayers *glazed_layers.ParsedLayers,
+	gp middlewares.Processor,
+) error {
+	settings := &ValidateSettings{}
+	if err := parsedLayers.InitializeStruct(schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "failed to initialize preset validate settings")
+	}
+	location, err := resolveScope(settings.Project, settings.Global)
+	if err != nil {
+		return err
+	}
+
+	ctrl, err := helpers.NewInitializedControllerFromParsedLayers(parsedLayers)
+	if err != nil {
+		return err
+	}
+
+	locations := []domain.PresetLocation{location}
+	if location == "" {
+		locations = []domain.PresetLocation{domain.PresetLocationProject, domain.PresetLocationGlobal}
+	}
+
+	seen := 0
+	invalid := 0
+	for _, loc := range locations {
+		presets, fileErrs, err := ctrl.ScanFilterPresets(loc)
+		if err != nil {
+			return errors.Wrapf(err, "failed to scan %s filter presets", loc)
+		}
+		for _, p := range presets {
+			if settings.PresetID != "" && p.ID != settings.PresetID {
+				continue
+			}
+			seen++
+			path, _ := ctrl.FilterPresetPath(p.ID, loc)
+			row := types.NewRow(
+				types.MRP("preset_id", p.ID),
+				types.MRP("preset_location", loc),
+				types.MRP("preset_path", path),
+				types.MRP("valid", true),
+				types.MRP("error", nil),
+			)
+			if err := gp.AddRow(ctx, row); err != nil {
+				return err
+			}
+		}
+		for _, fe := range fileErrs {
+			if settings.PresetID != "" && fe.ID != settings.PresetID {
+				continue
+			}
+			seen++
+			invalid++
+			row := types.NewRow(
+				types.MRP("preset_id", fe.ID),
+				types.MRP("preset_location", fe.Location),
+				types.MRP("preset_path", fe.Path),
+				types.MRP("valid", false),
+				types.MRP("error", fe.Err.Error()),
+			)
+			if err := gp.AddRow(ctx, row); err != nil {
+				return err
+			}
+		}
+	}
+
+	if settings.PresetID != "" && seen == 0 {
+		return errors.Errorf("filter preset not found: %s", settings.PresetID)
+	}
+	c.invalid = invalid
+	return nil
+}
+
+func NewValidateCobraCommand() (*cobra.Command, error) {
+	glazedCmd, err := NewValidateCommand()
+	if err != nil {
+		return nil, err
+	}
+	cobraCmd, err := cli.BuildCobraCommand(
+		glazedCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{
+			MiddlewaresFunc: cli.CobraCommandDefaultMiddlewares,
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	run := cobraCmd.Run
+	cobraCmd.Run = func(cmd *cobra.Command, args []string) {
+		run(cmd, args)
+		if glazedCmd.invalid > 0 {
+			cobra.CheckErr(errors.Errorf("%d invalid filter preset(s)", glazedCmd.invalid))
+		}
+	}
+	return cobraCmd, nil
+}
@@ -17,13 +17,17 @@ import (
 	cmd_middlewares "github.com/go-go-golems/glazed/pkg/cmds/middlewares"
 	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
 	glazed_config "github.com/go-go-golems/glazed/pkg/config"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/settings"
+	"github.com/go-go-golems/glazed/pkg/types"
 	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
 	papi "github.com/go-go-golems/prescribe/internal/api"
+	"github.com/go-go-golems/prescribe/internal/controller"
+	"github.com/go-go-golems/prescribe/internal/domain"
 	pexport "github.com/go-go-golems/prescribe/internal/export"
 	"github.com/go-go-golems/prescribe/internal/git"
 	"github.com/go-go-golems/prescribe/internal/github"
 	"github.com/go-go-golems/prescribe/internal/prdata"
-	"github.com/go-go-golems/prescribe/internal/tokens"
 	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -35,17 +39,30 @@ type GenerateCommand struct {
 }
 
 var _ cmds.BareCommand = &GenerateCommand{}
+var _ cmds.GlazeCommand = &GenerateCommand{}
 
 type GenerateExtraSettings struct {
-	ExportContext           bool   `glazed.parameter:"export-context"`
-	ExportRendered          bool   `glazed.parameter:"export-rendered"`
-	PrintRenderedTokenCount bool   `glazed.parameter:"print-rendered-token-count"`
-	Stream                  bool   `glazed.parameter:"stream"`
-	Separator               string `glazed.parameter:"separator"`
-	Create                  bool   `glazed.parameter:"create"`
-	CreateDryRun            bool   `glazed.parameter:"create-dry-run"`
-	CreateDraft             bool   `glazed.parameter:"create-draft"`
-	CreateBase              string `glazed.parameter:"create-base"`
+	ExportContext           bool      `glazed.parameter:"export-context"`
+	ExportRendered          bool      `glazed.parameter:"export-rendered"`
+	PrintRenderedTokenCount bool      `glazed.parameter:"print-rendered-token-count"`
+	Stream                  bool      `glazed.parameter:"stream"`
+	Separator               string    `glazed.parameter:"separator"`
+	Create                  bool      `glazed.parameter:"create"`
+	CreateDryRun            bool      `glazed.parameter:"create-dry-run"`
+	CreateDraft             bool      `glazed.parameter:"create-draft"`
+	CreateBase              string    `glazed.parameter:"create-base"`
+	FitBudget               bool      `glazed.parameter:"fit-budget"`
+	TokenBudget             int       `glazed.parameter:"token-budget"`
+	Candidates              int       `glazed.parameter:"candidates"`
+	CandidatePresets        []string  `glazed.parameter:"candidate-presets"`
+	CandidateTemperatures   []float64 `glazed.parameter:"candidate-temperatures"`
+	Pick                    string    `glazed.parameter:"pick"`
+	Section                 string    `glazed.parameter:"section"`
+	Translate               bool      `glazed.parameter:"translate"`
+	NoCache                 bool      `glazed.parameter:"no-cache"`
+	RecordFixture           string    `glazed.parameter:"record-fixture"`
+	LintRepair              bool      `glazed.parameter:"lint-repair"`
+	Redaction               string    `glazed.parameter:"redaction"`
 }
 
 func NewGenerateCommand() (*GenerateCommand, error) {
@@ -63,6 +80,16 @@ func NewGenerateCommand() (*GenerateCommand, error) {
 		return nil, errors.Wrap(err, "failed to create generation layer")
 	}
 
+	promptVarsLayer, err := prescribe_layers.NewPromptVarsLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create prompt variables layer")
+	}
+
+	inferenceLayer, err := prescribe_layers.NewInferenceLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create inference layer")
+	}
+
 	geppettoLayers, err := geppettolayers.CreateGeppettoLayers()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create geppetto parameter layers")
@@ -122,10 +149,102 @@ func NewGenerateCommand() (*GenerateCommand, error) {
 		parameters.WithHelp("With --create: base branch for the PR (defaults to the session/--target branch)"),
 		parameters.WithDefault(""),
 	)
+	fitBudgetFlag := parameters.NewParameterDefinition(
+		"fit-budget",
+		parameters.ParameterTypeBool,
+		parameters.WithHelp("Degrade low-priority files (full -> diff -> reduced diff -> numstat -> excluded) until the context fits the token budget"),
+		parameters.WithDefault(false),
+	)
+	tokenBudgetFlag := parameters.NewParameterDefinition(
+		"token-budget",
+		parameters.ParameterTypeInteger,
+		parameters.WithHelp("With --fit-budget: token budget for files + context (default: session budget.max_tokens, else derived from the model's context window)"),
+		parameters.WithDefault(0),
+	)
+
+	// Structured output (--with-glaze-output). This command already owns --output-file (where the
+	// description is written) and --stream (inference events), so glazed's flags of the same name are dropped.
+	glazedLayer, err := settings.NewGlazedParameterLayers(
+		settings.WithOutputParameterLayerOptions(func(l *glazed_layers.ParameterLayerImpl) error {
+			l.ParameterDefinitions.Delete("output-file")
+			l.ParameterDefinitions.Delete("stream")
+			return nil
+		}),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create glazed parameter layer")
+	}
+
+	candidatesFlag := parameters.NewParameterDefinition(
+		"candidates",
+		parameters.ParameterTypeInteger,
+		parameters.WithHelp("Generate N candidates concurrently and pick (or merge) the result (see --pick)"),
+		parameters.WithDefault(0),
+	)
+	candidatePresetsFlag := parameters.NewParameterDefinition(
+		"candidate-presets",
+		parameters.ParameterTypeStringList,
+		parameters.WithHelp("With --candidates: prompt presets to cycle through (default: session candidates.presets, else the session prompt)"),
+	)
+	candidateTemperaturesFlag := parameters.NewParameterDefinition(
+		"candidate-temperatures",
+		parameters.ParameterTypeFloatList,
+		parameters.WithHelp("With --candidates: temperatures to cycle through (default: session candidates.temperatures, else the configured temperature)"),
+	)
+	pickFlag := parameters.NewParameterDefinition(
+		"pick",
+		parameters.ParameterTypeString,
+		parameters.WithHelp("With --candidates: 1-based candidate to keep (\"2\") or per-section picks (\"title=2,body=1\"); default: first valid candidate"),
+		parameters.WithDefault(""),
+	)
+
+	sectionFlag := parameters.NewParameterDefinition(
+		"section",
+		parameters.ParameterTypeChoice,
+		parameters.WithHelp("Regenerate only this section of the last generated PR data; the other sections stay untouched"),
+		parameters.WithChoices("title", "body", "changelog", "release_notes"),
+	)
+	translateFlag := parameters.NewParameterDefinition(
+		"translate",
+		parameters.ParameterTypeBool,
+		parameters.WithHelp("Translate the last generated PR data (including text custom fields) into --language (or the session's language), keeping code blocks and identifiers intact"),
+		parameters.WithDefault(false),
+	)
+
+	noCacheFlag := parameters.NewParameterDefinition(
+		"no-cache",
+		parameters.ParameterTypeBool,
+		parameters.WithHelp("Always run inference, even if an identical request is cached in .pr-builder/cache/ (the fresh result is still cached)"),
+		parameters.WithDefault(false),
+	)
+
+	recordFixtureFlag := parameters.NewParameterDefinition(
+		"record-fixture",
+		parameters.ParameterTypeString,
+		parameters.WithHelp("Append every inference result to this mock engine fixture, for offline replay with --ai-engine replay:<file> (implies --no-cache)"),
+		parameters.WithDefault(""),
+	)
+
+	lintRepairFlag := parameters.NewParameterDefinition(
+		"lint-repair",
+		parameters.ParameterTypeBool,
+		parameters.WithHelp("If the lint finds problems in the generated description (unknown files/identifiers, placeholders, ...), ask the model once to fix them (also enabled by lint.repair in the preset)"),
+		parameters.WithDefault(false),
+	)
+
+	redactionFlag := parameters.NewParameterDefinition(
+		"redaction",
+		parameters.ParameterTypeString,
+		parameters.WithHelp("Secret scan of diffs, files and context before they are sent: off, warn, redact or block (default: redaction.mode in .pr-builder/config.yaml, else redact)"),
+		parameters.WithDefault(""),
+	)
 
 	layersList := []glazed_layers.ParameterLayer{
 		repoLayerExisting,
 		generationLayer,
+		promptVarsLayer,
+		inferenceLayer,
+		glazedLayer,
 	}
 	layersList = append(layersList, geppettoLayers...)
 
@@ -133,7 +252,7 @@ func NewGenerateCommand() (*GenerateCommand, error) {
 		"generate",
 		cmds.WithShort("Generate PR description"),
 		cmds.WithLong("Generate a PR description using AI based on the current session."),
-		cmds.WithFlags(extraFlags, exportRenderedFlag, printRenderedTokenCountFlag, streamFlag, separatorFlag, createFlag, createDryRunFlag, createDraftFlag, createBaseFlag),
+		cmds.WithFlags(extraFlags, exportRenderedFlag, printRenderedTokenCountFlag, streamFlag, separatorFlag, createFlag, createDryRunFlag, createDraftFlag, createBaseFlag, fitBudgetFlag, tokenBudgetFlag, candidatesFlag, candidatePresetsFlag, candidateTemperaturesFlag, pickFlag, sectionFlag, translateFlag, noCacheFlag, recordFixtureFlag, lintRepairFlag, redactionFlag),
 		cmds.WithLayersList(
 			layersList...,
 		),
@@ -143,6 +262,21 @@ func NewGenerateCommand() (*GenerateCommand, error) {
 }
 
 func (c *GenerateCommand) Run(ctx context.Context, parsedLayers *glazed_layers.ParsedLayers) error {
+	return c.run(ctx, parsedLayers, nil)
+}
+
+// RunIntoGlazeProcessor emits the generated description and its usage metadata as one row
+// (enabled with --with-glaze-output).
+func (c *GenerateCommand) RunIntoGlazeProcessor(
+	ctx context.Context,
+	parsedLayers *glazed_layers.ParsedLayers,
+	gp middlewares.Processor,
+) error {
+	return c.run(ctx, parsedLayers, gp)
+}
+
+// run implements both output modes; gp is nil in classic (human-readable) mode.
+func (c *GenerateCommand) run(ctx context.Context, parsedLayers *glazed_layers.ParsedLayers, gp middlewares.Processor) error {
 	genSettings, err := prescribe_layers.GetGenerationSettings(parsedLayers)
 	if err != nil {
 		return err
@@ -177,6 +311,13 @@ func (c *GenerateCommand) Run(ctx context.Context, parsedLayers *glazed_layers.P
 			return errors.Wrap(err, "failed to load preset")
 		}
 	}
+	if genSettings.OutputMode != "" {
+		mode, err := domain.ParseOutputMode(genSettings.OutputMode)
+		if err != nil {
+			return err
+		}
+		ctrl.GetData().OutputMode = mode
+	}
 
 	// Override PR title/description if specified (takes precedence over session.yaml).
 	if strings.TrimSpace(genSettings.Title) != "" {
@@ -185,16 +326,60 @@ func (c *GenerateCommand) Run(ctx context.Context, parsedLayers *glazed_layers.P
 	if strings.TrimSpace(genSettings.Description) != "" {
 		ctrl.GetData().Description = genSettings.Description
 	}
+	if _, err := prescribe_layers.ApplyPromptVars(parsedLayers, &ctrl.GetData().PromptVars); err != nil {
+		return err
+	}
+	presetVars, err := prescribe_layers.PresetVarPairs(parsedLayers)
+	if err != nil {
+		return err
+	}
+	if err := ctrl.SetPresetVars(presetVars); err != nil {
+		return err
+	}
+	if shadowed := ctrl.GetData().PromptVars.ShadowedVars(); len(shadowed) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring session vars that shadow built-in template variables: %s\n", strings.Join(shadowed, ", "))
+	}
+
+	// StepSettings parsing happens here (higher up), then injected into API service.
+	// This also selects the tokenizer for token counts and the model context window for --fit-budget.
+	stepSettings, err := gepsettings.NewStepSettingsFromParsedLayers(parsedLayers)
+	if err != nil {
+		return errors.Wrap(err, "failed to build AI step settings from parsed layers")
+	}
+	ctrl.SetStepSettings(stepSettings)
+	if err := helpers.ConfigureInference(ctrl, parsedLayers, stepSettings); err != nil {
+		return err
+	}
+	ctrl.SetNoCache(extra.NoCache || extra.RecordFixture != "")
+	ctrl.SetRecordFixture(extra.RecordFixture)
+	ctrl.SetLintRepair(extra.LintRepair)
+	if err := helpers.ConfigureRedaction(ctrl, extra.Redaction); err != nil {
+		return err
+	}
+	counter := ctrl.TokenCounter()
+
+	// Fit the session into the token budget before anything is rendered or sent.
+	if extra.FitBudget {
+		report, err := ctrl.FitResolvedTokenBudget(extra.TokenBudget)
+		if err != nil {
+			return errors.Wrap(err, "failed to fit token budget")
+		}
+		helpers.PrintBudgetReport(os.Stderr, report)
+	}
 
 	// Export-only path (no inference).
 	if extra.ExportContext && extra.ExportRendered {
 		return errors.New("flags --export-context and --export-rendered are mutually exclusive")
 	}
+	if (extra.ExportContext || extra.ExportRendered) && gp != nil {
+		return errors.New("flags --export-context and --export-rendered cannot be combined with structured (glaze) output")
+	}
 	if extra.ExportContext || extra.ExportRendered {
 		req, err := ctrl.BuildGenerateDescriptionRequest()
 		if err != nil {
 			return err
 		}
+		helpers.PrintRedactionReport(os.Stderr, ctrl)
 		sep := pexport.SeparatorType(extra.Separator)
 
 		if extra.PrintRenderedTokenCount {
@@ -202,14 +387,15 @@ func (c *GenerateCommand) Run(ctx context.Context, parsedLayers *glazed_layers.P
 			if err != nil {
 				return err
 			}
-			sysTokens := tokens.Count(sys)
-			userTokens := tokens.Count(user)
-			fmt.Fprintf(os.Stderr, "Rendered payload token counts (encoding=%s): system=%d user=%d total=%d\n", tokens.EncodingName(), sysTokens, userTokens, sysTokens+userTokens)
+			sysTokens := counter.Count(sys)
+			userTokens := counter.Count(user)
+			fmt.Fprintf(os.Stderr, "Rendered payload token counts (tokenizer=%s): system=%d user=%d total=%d\n", counter.Label(), sysTokens, userTokens, sysTokens+userTokens)
 
 			renderedExport, err := pexport.BuildRenderedLLMPayload(req, sep)
 			if err == nil {
-				fmt.Fprintf(os.Stderr, "Rendered payload export token count (separator=%s): %d\n", extra.Separator, tokens.Count(renderedExport))
+				fmt.Fprintf(os.Stderr, "Rendered payload export token count (separator=%s): %d\n", extra.Separator, counter.Count(renderedExport))
 			}
+			printCostEstimate(ctrl)
 		}
 
 		text := ""
@@ -248,34 +434,76 @@ func (c *GenerateCommand) Run(ctx context.Context, parsedLayers *glazed_layers.P
 		if err != nil {
 			return err
 		}
-		sysTokens := tokens.Count(sys)
-		userTokens := tokens.Count(user)
-		fmt.Fprintf(os.Stderr, "Rendered payload token counts (encoding=%s): system=%d user=%d total=%d\n", tokens.EncodingName(), sysTokens, userTokens, sysTokens+userTokens)
+		sysTokens := counter.Count(sys)
+		userTokens := counter.Count(user)
+		fmt.Fprintf(os.Stderr, "Rendered payload token counts (tokenizer=%s): system=%d user=%d total=%d\n", counter.Label(), sysTokens, userTokens, sysTokens+userTokens)
 
 		renderedExport, err := pexport.BuildRenderedLLMPayload(req, sep)
 		if err == nil {
-			fmt.Fprintf(os.Stderr, "Rendered payload export token count (separator=%s): %d\n", extra.Separator, tokens.Count(renderedExport))
+			fmt.Fprintf(os.Stderr, "Rendered payload export token count (separator=%s): %d\n", extra.Separator, counter.Count(renderedExport))
 		}
+		printCostEstimate(ctrl)
 	}
 
-	// StepSettings parsing happens here (higher up), then injected into API service.
-	stepSettings, err := gepsettings.NewStepSettingsFromParsedLayers(parsedLayers)
-	if err != nil {
-		return errors.Wrap(err, "failed to build AI step settings from parsed layers")
-	}
-	ctrl.SetStepSettings(stepSettings)
-
 	// Generate description
-	fmt.Fprintf(os.Stderr, "Generating PR description...\n")
 	description := ""
-	if extra.Stream {
+	if extra.Translate {
+		if extra.Section != "" || extra.Candidates > 1 || extra.Stream {
+			return errors.New("flag --translate cannot be combined with --section, --candidates or --stream")
+		}
+		language := ctrl.GetData().PromptVars.Language
+		if strings.TrimSpace(language) == "" {
+			return errors.New("flag --translate needs a target language: pass --language or set it with 'prescribe session set --language'")
+		}
+		fmt.Fprintf(os.Stderr, "Translating the last generated PR data to %s...\n", domain.LanguageName(language))
+		description, err = ctrl.Translate(ctx, language)
+		if err != nil {
+			return errors.Wrap(err, "failed to translate")
+		}
+	} else if extra.Section != "" {
+		if extra.Candidates > 1 || extra.Stream {
+			return errors.New("flag --section cannot be combined with --candidates or --stream")
+		}
+		section, err := domain.ParsePRDataSection(extra.Section)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Regenerating %s...\n", section)
+		description, err = ctrl.RegenerateSection(ctx, section)
+		if err != nil {
+			return errors.Wrapf(err, "failed to regenerate %s", section)
+		}
+	} else if extra.Candidates > 1 {
+		if extra.Stream {
+			return errors.New("flags --stream and --candidates cannot be combined")
+		}
+		description, err = generateCandidates(ctx, ctrl, extra)
+	} else if extra.Stream {
+		fmt.Fprintf(os.Stderr, "Generating PR description...\n")
 		description, err = ctrl.GenerateDescriptionStreaming(ctx, os.Stderr)
 	} else {
+		fmt.Fprintf(os.Stderr, "Generating PR description...\n")
 		description, err = ctrl.GenerateDescription(ctx)
 	}
 	if err != nil {
 		return errors.Wrap(err, "failed to generate description")
 	}
+	helpers.PrintRedactionReport(os.Stderr, ctrl)
+
+	// Report usage, stop reason, latency and the actual cost from provider-reported usage (best-effort).
+	var actualCost *controller.CostEstimate
+	if last := ctrl.LastGeneration(); last != nil {
+		meta := last.Metadata()
+		helpers.PrintGenerationSummary(os.Stderr, &meta)
+		if last.Usage != nil {
+			if est, err := ctrl.ActualCost(last.Model, *last.Usage); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to compute cost: %v\n", err)
+			} else {
+				helpers.PrintActualCost(os.Stderr, est)
+				actualCost = &est
+			}
+		}
+	}
 
 	if extra.Stream {
 		// Print a deterministic end-of-run summary to stderr so users can see the parsed structure
@@ -295,16 +523,12 @@ func (c *GenerateCommand) Run(ctx context.Context, parsedLayers *glazed_layers.P
 		}
 	}
 
-	// Persist last generated structured PR data (for `prescribe create --use-last`).
-	data := ctrl.GetData()
-	if data != nil && data.GeneratedPRData != nil {
-		if repoSettings, err := prescribe_layers.GetRepositorySettings(parsedLayers); err == nil {
-			path := prdata.LastGeneratedPRDataPath(repoSettings.RepoPath)
-			if err := prdata.WriteGeneratedPRDataToYAMLFile(path, data.GeneratedPRData); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to write last generated PR data: %v\n", err)
-			} else {
-				fmt.Fprintf(os.Stderr, "Parsed PR data written to %s\n", path)
-			}
+	// Persist last generated structured PR data (for `prescribe create --use-last` and `prescribe refine`).
+	if data := ctrl.GetData(); data != nil && data.GeneratedPRData != nil {
+		if path, err := ctrl.WriteLastGeneratedPRData(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write last generated PR data: %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Parsed PR data written to %s\n", path)
 		}
 	}
 
@@ -314,9 +538,14 @@ func (c *GenerateCommand) Run(ctx context.Context, parsedLayers *glazed_layers.P
 			return errors.Wrap(err, "failed to write output file")
 		}
 		fmt.Fprintf(os.Stderr, "Description written to %s\n", genSettings.OutputFile)
-	} else {
+	} else if gp == nil {
 		fmt.Println(description)
 	}
+	if gp != nil {
+		if err := gp.AddRow(ctx, generationRow(description, ctrl, actualCost)); err != nil {
+			return err
+		}
+	}
 
 	// Optional: create PR from parsed structured data.
 	if extra.Create {
@@ -331,9 +560,13 @@ func (c *GenerateCommand) Run(ctx context.Context, parsedLayers *glazed_layers.P
 
 		base := resolveCreateBase(extra.CreateBase, ctrl.GetData().TargetBranch)
 
+		var outputSchema *domain.OutputSchema
+		if data.GenerationMetadata != nil {
+			outputSchema = data.GenerationMetadata.OutputSchema
+		}
 		opts := github.CreatePROptions{
 			Title: data.GeneratedPRData.Title,
-			Body:  data.GeneratedPRData.Body,
+			Body:  data.GeneratedPRData.RenderBody(outputSchema),
 			Base:  base,
 			Draft: extra.CreateDraft,
 		}
@@ -364,7 +597,7 @@ func (c *GenerateCommand) Run(ctx context.Context, parsedLayers *glazed_layers.P
 		if err != nil {
 			// Save PR data for manual retry.
 			failPath := prdata.FailurePRDataPath(repoSettings.RepoPath, time.Now())
-			if werr := prdata.WriteGeneratedPRDataToYAMLFile(failPath, data.GeneratedPRData); werr == nil {
+			if werr := prdata.WriteGeneratedPRDataToYAMLFile(failPath, data.GeneratedPRData, data.GenerationMetadata); werr == nil {
 				fmt.Fprintf(os.Stderr, "generate --create: saved PR data to %s\n", failPath)
 			}
 			return err
@@ -375,6 +608,103 @@ func (c *GenerateCommand) Run(ctx context.Context, parsedLayers *glazed_layers.P
 	return nil
 }
 
+// printCostEstimate prints the pre-generation cost estimate to stderr (best-effort).
+func printCostEstimate(ctrl *controller.Controller) {
+	est, err := ctrl.EstimateCost()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to estimate cost: %v\n", err)
+		return
+	}
+	helpers.PrintCostEstimate(os.Stderr, est)
+}
+
+// generateCandidates runs --candidates generations concurrently, prints them to stderr and
+// keeps the sections selected with --pick.
+func generateCandidates(ctx context.Context, ctrl *controller.Controller, extra *GenerateExtraSettings) (string, error) {
+	cfg := ctrl.CandidatesConfig()
+	cfg.Count = extra.Candidates
+	if len(extra.CandidatePresets) > 0 {
+		cfg.Presets = extra.CandidatePresets
+	}
+	if len(extra.CandidateTemperatures) > 0 {
+		cfg.Temperatures = extra.CandidateTemperatures
+	}
+
+	fmt.Fprintf(os.Stderr, "Generating %d PR description candidates...\n", cfg.Count)
+	candidates, err := ctrl.GenerateCandidates(ctx, controller.CandidateSpecs(cfg))
+	if err != nil {
+		return "", err
+	}
+	for i, cand := range candidates {
+		fmt.Fprintf(os.Stderr, "\n--- Candidate %d/%d (%s) ---\n", i+1, len(candidates), cand.Spec.Label())
+		switch {
+		case cand.Err != nil:
+			fmt.Fprintf(os.Stderr, "failed: %v\n", cand.Err)
+		case cand.Response.ParseError != "":
+			fmt.Fprintf(os.Stderr, "%s\n(parse error: %s)\n", cand.Response.Description, cand.Response.ParseError)
+		default:
+			fmt.Fprintf(os.Stderr, "%s\n", cand.Response.Description)
+		}
+	}
+
+	picks, err := controller.ParseCandidatePicks(extra.Pick, candidates)
+	if err != nil {
+		return "", err
+	}
+	if err := ctrl.SelectCandidates(candidates, picks); err != nil {
+		return "", err
+	}
+	fmt.Fprintf(os.Stderr, "\nSelected candidates: %s\n", picks.String())
+	return ctrl.GetData().GeneratedDescription, nil
+}
+
+// generationRow builds the structured output row for --with-glaze-output.
+func generationRow(description string, ctrl *controller.Controller, cost *controller.CostEstimate) types.Row {
+	row := types.NewRow(
+		types.MRP("description", description),
+	)
+	data := ctrl.GetData()
+	if data.GeneratedPRData != nil {
+		row.Set("title", data.GeneratedPRData.Title)
+	}
+	if data.GeneratedPRDataParseError != "" {
+		row.Set("parse_error", data.GeneratedPRDataParseError)
+	}
+	if meta := data.GenerationMetadata; meta != nil {
+		row.Set("model", meta.Model)
+		if meta.Usage != nil {
+			row.Set("input_tokens", meta.Usage.InputTokens)
+			row.Set("output_tokens", meta.Usage.OutputTokens)
+			row.Set("cached_tokens", meta.Usage.CachedTokens)
+			row.Set("cache_read_tokens", meta.Usage.CacheReadTokens)
+			row.Set("cache_write_tokens", meta.Usage.CacheWriteTokens)
+		}
+		row.Set("stop_reason", meta.StopReason)
+		row.Set("latency_ms", meta.LatencyMs)
+		row.Set("attempts", meta.Attempts)
+		failed := 0
+		for _, a := range meta.AttemptLog {
+			if a.Error != "" {
+				failed++
+			}
+		}
+		row.Set("failed_attempts", failed)
+		row.Set("output_mode", string(meta.OutputMode))
+		row.Set("cached", meta.Cached)
+		row.Set("lint_findings", len(meta.LintFindings))
+		if meta.CacheKey != "" {
+			row.Set("cache_key", meta.CacheKey)
+		}
+	}
+	if cost != nil && cost.Priced {
+		row.Set("cost_usd", cost.Cost.Total())
+	}
+	if cands := ctrl.Candidates(); len(cands) > 0 {
+		row.Set("candidates", len(cands))
+	}
+	return row
+}
+
 func resolveCreateBase(explicitBase, sessionTarget string) string {
 	base := strings.TrimSpace(explicitBase)
 	if base != "" {
@@ -389,162 +719,13 @@ func NewGenerateCobraCommand() (*cobra.Command, error) {
 		return nil, err
 	}
 
-	// Build a middleware chain that supports:
-	// - config files (prescribe config) for parameter defaults / overrides
-	// - PINOCCHIO profiles.yaml (bootstrap parse of profile selection + profile loading)
-	// - env overrides (PRESCRIBE_* and PINOCCHIO_*)
-	//
-	// The precedence is:
-	// defaults < profiles < config < env < args < flags
-	generateMiddlewares := func(parsedCommandLayers *glazed_layers.ParsedLayers, cmd *cobra.Command, args []string) ([]cmd_middlewares.Middleware, error) {
-		// 1) Resolve config files (low -> high precedence).
-		commandSettings := &cli.CommandSettings{}
-		if parsedCommandLayers != nil {
-			_ = parsedCommandLayers.InitializeStruct(cli.CommandSettingsSlug, commandSettings)
-		}
-
-		var configFiles []string
-		// Base config discovery (if present).
-		if p, err := glazed_config.ResolveAppConfigPath("prescribe", ""); err == nil && p != "" {
-			configFiles = append(configFiles, p)
-		}
-		// Optional explicit config overlay.
-		if commandSettings.ConfigFile != "" {
-			configFiles = append(configFiles, commandSettings.ConfigFile)
-		}
-		// Optional "load parameters from file" overlay (legacy).
-		if commandSettings.LoadParametersFromFile != "" {
-			configFiles = append(configFiles, commandSettings.LoadParametersFromFile)
-		}
-
-		// Optional: Load Pinocchio config as a *defaults overlay* (lower precedence than profiles).
-		//
-		// This is useful because many users keep common AI defaults (like ai-max-response-tokens)
-		// in `~/.pinocchio/config.yaml`, but `prescribe` is a separate app name and therefore
-		// won't discover it via `ResolveAppConfigPath("prescribe", "")`.
-		//
-		// We intentionally apply this AFTER profiles (lower precedence) so profiles can still
-		// select provider/model without being overridden by global defaults.
-		pinocchioConfigFile := ""
-		if p, err := glazed_config.ResolveAppConfigPath("pinocchio", ""); err == nil && p != "" {
-			pinocchioConfigFile = p
-		}
-
-		// 2) Bootstrap-parse profile selection using appconfig (circularity-safe).
-		// This allows `profile-settings.profile` and `profile-settings.profile-file` to be set by:
-		// - cobra flags (--profile/--profile-file)
-		// - env vars (PINOCCHIO_PROFILE / PINOCCHIO_PROFILE_FILE)
-		// - config files under `profile-settings:`
-		type bootstrap struct {
-			Profile cli.ProfileSettings
-		}
-		profileSettingsLayer, err := cli.NewProfileSettingsLayer()
-		if err != nil {
-			return nil, err
-		}
-		bootstrapParser, err := appconfig.NewParser[bootstrap](
-			appconfig.WithDefaults(),
-			appconfig.WithConfigFiles(configFiles...),
-			appconfig.WithEnv("PINOCCHIO"),
-			appconfig.WithCobra(cmd, args),
-		)
-		if err != nil {
-			return nil, err
-		}
-		if err := bootstrapParser.Register(appconfig.LayerSlug(cli.ProfileSettingsSlug), profileSettingsLayer, func(t *bootstrap) any {
-			return &t.Profile
-		}); err != nil {
-			return nil, err
-		}
-		boot, err := bootstrapParser.Parse()
-		if err != nil {
-			return nil, err
-		}
-
-		xdgConfigPath, err := os.UserConfigDir()
-		if err != nil {
-			return nil, err
-		}
-		defaultProfileFile := filepath.Join(xdgConfigPath, "pinocchio", "profiles.yaml")
-		profileName := boot.Profile.Profile
-		if profileName == "" {
-			profileName = "default"
-		}
-		profileFile := boot.Profile.ProfileFile
-		if profileFile == "" {
-			profileFile = defaultProfileFile
-		}
-
-		profileMiddleware := cmd_middlewares.GatherFlagsFromProfiles(
-			defaultProfileFile,
-			profileFile,
-			profileName,
-			"default",
-			parameters.WithParseStepSource("profiles"),
-			parameters.WithParseStepMetadata(map[string]interface{}{
-				"profileFile": profileFile,
-				"profile":     profileName,
-			}),
-		)
-
-		// 3) Main chain (highest -> lowest precedence in slice order).
-		middlewares_ := []cmd_middlewares.Middleware{
-			cmd_middlewares.ParseFromCobraCommand(cmd, parameters.WithParseStepSource("cobra")),
-			cmd_middlewares.GatherArguments(args, parameters.WithParseStepSource("arguments")),
-
-			// Environment overrides
-			cmd_middlewares.UpdateFromEnv("PRESCRIBE", parameters.WithParseStepSource("env")),
-			cmd_middlewares.UpdateFromEnv("PINOCCHIO", parameters.WithParseStepSource("env")),
-
-			// Config files: low -> high precedence
-			cmd_middlewares.LoadParametersFromFiles(configFiles),
-
-			// Profiles: apply after defaults but before config/env/flags
-			profileMiddleware,
-		}
-		if pinocchioConfigFile != "" {
-			middlewares_ = append(middlewares_,
-				cmd_middlewares.LoadParametersFromFile(
-					pinocchioConfigFile,
-					// Pinocchio config often includes non-layer top-level keys like `repositories: [...]`.
-					// The default loader expects every top-level key to be a layer map, so we filter here.
-					cmd_middlewares.WithConfigFileMapper(func(raw interface{}) (map[string]map[string]interface{}, error) {
-						out := map[string]map[string]interface{}{}
-						rm, ok := raw.(map[string]interface{})
-						if !ok {
-							return out, nil
-						}
-						for k, v := range rm {
-							vm, ok := v.(map[string]interface{})
-							if !ok {
-								continue
-							}
-							out[k] = vm
-						}
-						return out, nil
-					}),
-					cmd_middlewares.WithParseOptions(
-						parameters.WithParseStepSource("pinocchio-config"),
-						parameters.WithParseStepMetadata(map[string]interface{}{
-							"config_file": pinocchioConfigFile,
-						}),
-					),
-				),
-			)
-		}
-		// Defaults (lowest precedence)
-		middlewares_ = append(middlewares_,
-			cmd_middlewares.SetFromDefaults(parameters.WithParseStepSource(parameters.SourceDefaults)),
-		)
-		return middlewares_, nil
-	}
-
 	cobraCmd, err := cli.BuildCobraCommand(
 		glazedCmd,
 		cli.WithParserConfig(cli.CobraParserConfig{
 			EnableProfileSettingsLayer: true,
-			MiddlewaresFunc:            generateMiddlewares,
+			MiddlewaresFunc:            aiCommandMiddlewares,
 		}),
+		cli.WithDualMode(true),
 	)
 	if err != nil {
 		return nil, err
@@ -552,3 +733,153 @@ func NewGenerateCobraCommand() (*cobra.Command, error) {
 
 	return cobraCmd, nil
 }
+
+// aiCommandMiddlewares builds the middleware chain for commands that run inference. It supports:
+// - config files (prescribe config) for parameter defaults / overrides
+// - PINOCCHIO profiles.yaml (bootstrap parse of profile selection + profile loading)
+// - env overrides (PRESCRIBE_* and PINOCCHIO_*)
+//
+// The precedence is:
+// defaults < profiles < config < env < args < flags
+func aiCommandMiddlewares(parsedCommandLayers *glazed_layers.ParsedLayers, cmd *cobra.Command, args []string) ([]cmd_middlewares.Middleware, error) {
+	// 1) Resolve config files (low -> high precedence).
+	commandSettings := &cli.CommandSettings{}
+	if parsedCommandLayers != nil {
+		_ = parsedCommandLayers.InitializeStruct(cli.CommandSettingsSlug, commandSettings)
+	}
+
+	var configFiles []string
+	// Base config discovery (if present).
+	if p, err := glazed_config.ResolveAppConfigPath("prescribe", ""); err == nil && p != "" {
+		configFiles = append(configFiles, p)
+	}
+	// Optional explicit config overlay.
+	if commandSettings.ConfigFile != "" {
+		configFiles = append(configFiles, commandSettings.ConfigFile)
+	}
+	// Optional "load parameters from file" overlay (legacy).
+	if commandSettings.LoadParametersFromFile != "" {
+		configFiles = append(configFiles, commandSettings.LoadParametersFromFile)
+	}
+
+	// Optional: Load Pinocchio config as a *defaults overlay* (lower precedence than profiles).
+	//
+	// This is useful because many users keep common AI defaults (like ai-max-response-tokens)
+	// in `~/.pinocchio/config.yaml`, but `prescribe` is a separate app name and therefore
+	// won't discover it via `ResolveAppConfigPath("prescribe", "")`.
+	//
+	// We intentionally apply this AFTER profiles (lower precedence) so profiles can still
+	// select provider/model without being overridden by global defaults.
+	pinocchioConfigFile := ""
+	if p, err := glazed_config.ResolveAppConfigPath("pinocchio", ""); err == nil && p != "" {
+		pinocchioConfigFile = p
+	}
+
+	// 2) Bootstrap-parse profile selection using appconfig (circularity-safe).
+	// This allows `profile-settings.profile` and `profile-settings.profile-file` to be set by:
+	// - cobra flags (--profile/--profile-file)
+	// - env vars (PINOCCHIO_PROFILE / PINOCCHIO_PROFILE_FILE)
+	// - config files under `profile-settings:`
+	type bootstrap struct {
+		Profile cli.ProfileSettings
+	}
+	profileSettingsLayer, err := cli.NewProfileSettingsLayer()
+	if err != nil {
+		return nil, err
+	}
+	bootstrapParser, err := appconfig.NewParser[bootstrap](
+		appconfig.WithDefaults(),
+		appconfig.WithConfigFiles(configFiles...),
+		appconfig.WithEnv("PINOCCHIO"),
+		appconfig.WithCobra(cmd, args),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := bootstrapParser.Register(appconfig.LayerSlug(cli.ProfileSettingsSlug), profileSettingsLayer, func(t *bootstrap) any {
+		return &t.Profile
+	}); err != nil {
+		return nil, err
+	}
+	boot, err := bootstrapParser.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	xdgConfigPath, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	defaultProfileFile := filepath.Join(xdgConfigPath, "pinocchio", "profiles.yaml")
+	profileName := boot.Profile.Profile
+	if profileName == "" {
+		profileName = "default"
+	}
+	profileFile := boot.Profile.ProfileFile
+	if profileFile == "" {
+		profileFile = defaultProfileFile
+	}
+
+	profileMiddleware := cmd_middlewares.GatherFlagsFromProfiles(
+		defaultProfileFile,
+		profileFile,
+		profileName,
+		"default",
+		parameters.WithParseStepSource("profiles"),
+		parameters.WithParseStepMetadata(map[string]interface{}{
+			"profileFile": profileFile,
+			"profile":     profileName,
+		}),
+	)
+
+	// 3) Main chain (highest -> lowest precedence in slice order).
+	middlewares_ := []cmd_middlewares.Middleware{
+		cmd_middlewares.ParseFromCobraCommand(cmd, parameters.WithParseStepSource("cobra")),
+		cmd_middlewares.GatherArguments(args, parameters.WithParseStepSource("arguments")),
+
+		// Environment overrides
+		cmd_middlewares.UpdateFromEnv("PRESCRIBE", parameters.WithParseStepSource("env")),
+		cmd_middlewares.UpdateFromEnv("PINOCCHIO", parameters.WithParseStepSource("env")),
+
+		// Config files: low -> high precedence
+		cmd_middlewares.LoadParametersFromFiles(configFiles),
+
+		// Profiles: apply after defaults but before config/env/flags
+		profileMiddleware,
+	}
+	if pinocchioConfigFile != "" {
+		middlewares_ = append(middlewares_,
+			cmd_middlewares.LoadParametersFromFile(
+				pinocchioConfigFile,
+				// Pinocchio config often includes non-layer top-level keys like `repositories: [...]`.
+				// The default loader expects every top-level key to be a layer map, so we filter here.
+				cmd_middlewares.WithConfigFileMapper(func(raw interface{}) (map[string]map[string]interface{}, error) {
+					out := map[string]map[string]interface{}{}
+					rm, ok := raw.(map[string]interface{})
+					if !ok {
+						return out, nil
+					}
+					for k, v := range rm {
+						vm, ok := v.(map[string]interface{})
+						if !ok {
+							continue
+						}
+						out[k] = vm
+					}
+					return out, nil
+				}),
+				cmd_middlewares.WithParseOptions(
+					parameters.WithParseStepSource("pinocchio-config"),
+					parameters.WithParseStepMetadata(map[string]interface{}{
+						"config_file": pinocchioConfigFile,
+					}),
+				),
+			),
+		)
+	}
+	// Defaults (lowest precedence)
+	middlewares_ = append(middlewares_,
+		cmd_middlewares.SetFromDefaults(parameters.WithParseStepSource(parameters.SourceDefaults)),
+	)
+	return middlewares_, nil
+}
@@ -0,0 +1,20 @@
+package helpers
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-go-golems/prescribe/internal/controller"
+)
+
+// PrintBudgetReport writes a human-readable summary of a token budget fit.
+func PrintBudgetReport(w io.Writer, r controller.BudgetReport) {
+	status := "fits"
+	if !r.Fits {
+		status = "DOES NOT FIT"
+	}
+	fmt.Fprintf(w, "Token budget %d (%s): %d -> %d tokens, %s\n", r.MaxTokens, r.Source, r.TokensBefore, r.TokensAfter, status)
+	for _, ch := range r.Changes {
+		fmt.Fprintf(w, "  %-12s -> %-12s %6d -> %6d  %s\n", ch.From, ch.To, ch.TokensBefore, ch.TokensAfter, ch.Path)
+	}
+}
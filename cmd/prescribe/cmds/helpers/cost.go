@@ -0,0 +1,46 @@
+package helpers
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-go-golems/prescribe/internal/controller"
+	"github.com/go-go-golems/prescribe/internal/pricing"
+)
+
+// CostModelLabel renders "provider/model" for cost output.
+func CostModelLabel(est controller.CostEstimate) string {
+	if est.Provider == "" {
+		return est.Model
+	}
+	return est.Provider + "/" + est.Model
+}
+
+// PrintCostEstimate writes the pre-generation estimate; the output side is an upper bound.
+func PrintCostEstimate(w io.Writer, est controller.CostEstimate) {
+	if !est.Priced {
+		fmt.Fprintf(w, "Estimated cost: unknown (no pricing for %q; add it under `pricing:` in .pr-builder/config.yaml)\n", CostModelLabel(est))
+		return
+	}
+	fmt.Fprintf(w, "Estimated cost (%s): input %d tokens = %s, output <= %d tokens = %s, total <= %s\n",
+		CostModelLabel(est),
+		est.InputTokens, pricing.FormatUSD(est.Cost.Input),
+		est.OutputTokens, pricing.FormatUSD(est.Cost.Output),
+		pricing.FormatUSD(est.Cost.Total()),
+	)
+}
+
+// PrintActualCost writes the cost of a finished generation from provider-reported usage.
+func PrintActualCost(w io.Writer, est controller.CostEstimate) {
+	if !est.Priced {
+		fmt.Fprintf(w, "Usage: input %d tokens (%s), output %d tokens; cost unknown (no pricing for %q)\n",
+			est.InputTokens, est.Usage().CacheText(), est.OutputTokens, CostModelLabel(est))
+		return
+	}
+	fmt.Fprintf(w, "Actual cost (%s): input %d tokens (%s) = %s, output %d tokens = %s, total %s\n",
+		CostModelLabel(est),
+		est.InputTokens, est.Usage().CacheText(), pricing.FormatUSD(est.Cost.Input),
+		est.OutputTokens, pricing.FormatUSD(est.Cost.Output),
+		pricing.FormatUSD(est.Cost.Total()),
+	)
+}
@@ -0,0 +1,33 @@
+package helpers
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RunEditor opens path in $VISUAL/$EDITOR (default: vi) and waits for it to exit.
+func RunEditor(ctx context.Context, path string) error {
+	editor := strings.TrimSpace(os.Getenv("VISUAL"))
+	if editor == "" {
+		editor = strings.TrimSpace(os.Getenv("EDITOR"))
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// Support editors configured with arguments, e.g. EDITOR="code --wait".
+	parts := strings.Fields(editor)
+	args := append(parts[1:], path)
+	cmd := exec.CommandContext(ctx, parts[0], args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "editor %q failed", editor)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package helpers
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-go-golems/prescribe/internal/domain"
+)
+
+// PrintGenerationSummary writes the stop reason, latency and attempt count of a finished generation.
+func PrintGenerationSummary(w io.Writer, meta *domain.GenerationMetadata) {
+	if meta == nil {
+		return
+	}
+	if meta.Cached {
+		fmt.Fprintf(w, "Generation (%s): served from cache (key %s), no inference; use --no-cache to regenerate\n",
+			meta.Model, meta.CacheKey)
+		PrintLintFindings(w, meta)
+		return
+	}
+	stop := meta.StopReason
+	if stop == "" {
+		stop = "unknown"
+	}
+	mode := meta.OutputMode
+	if mode == "" {
+		mode = domain.OutputModeYAML
+	}
+	fmt.Fprintf(w, "Generation (%s): stop reason %s, latency %s, attempts %d, output mode %s\n",
+		meta.Model, stop, FormatLatency(meta.LatencyMs), meta.Attempts, mode)
+	for i, a := range meta.AttemptLog {
+		if a.Error == "" {
+			continue
+		}
+		fmt.Fprintf(w, "  attempt %d (%s) failed after %s: %s\n", i+1, a.Model, FormatLatency(a.LatencyMs), a.Error)
+	}
+	if n := len(meta.AttemptLog); n > 0 && meta.AttemptLog[n-1].Fallback && meta.AttemptLog[n-1].Error == "" {
+		fmt.Fprintf(w, "  answered by fallback model %s\n", meta.AttemptLog[n-1].Model)
+	}
+	PrintLintFindings(w, meta)
+}
+
+// PrintLintFindings prints the lint findings of the generated PR data, if any.
+func PrintLintFindings(w io.Writer, meta *domain.GenerationMetadata) {
+	if meta == nil {
+		return
+	}
+	if meta.LintRepaired {
+		fmt.Fprintf(w, "Lint: the first answer had problems; kept the repaired answer\n")
+	}
+	if len(meta.LintFindings) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "Lint: %d finding(s)\n", len(meta.LintFindings))
+	for _, f := range meta.LintFindings {
+		fmt.Fprintf(w, "  %s\n", f)
+	}
+}
+
+// FormatLatency renders a millisecond latency for humans (e.g. "1.5s").
+func FormatLatency(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).Round(100 * time.Millisecond).String()
+}
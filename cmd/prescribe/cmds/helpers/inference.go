@@ -0,0 +1,176 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	geppettolayers "github.com/go-go-golems/geppetto/pkg/layers"
+	gepsettings "github.com/go-go-golems/geppetto/pkg/steps/ai/settings"
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+	cmd_middlewares "github.com/go-go-golems/glazed/pkg/cmds/middlewares"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
+	"github.com/go-go-golems/prescribe/internal/api"
+	"github.com/go-go-golems/prescribe/internal/controller"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigureInference applies the inference layer (--retries, --attempt-timeout, --fallback, ...)
+// to ctrl. Fallbacks are resolved against base, the step settings of the configured model.
+func ConfigureInference(ctrl *controller.Controller, parsedLayers *layers.ParsedLayers, base *gepsettings.StepSettings) error {
+	s, err := prescribe_layers.GetInferenceSettings(parsedLayers)
+	if err != nil {
+		return err
+	}
+	policy, err := RetryPolicyFromSettings(s)
+	if err != nil {
+		return err
+	}
+	fallbacks, err := ResolveFallbacks(base, s.Fallbacks, profileFileFromLayers(parsedLayers))
+	if err != nil {
+		return err
+	}
+	ctrl.SetRetryPolicy(policy)
+	ctrl.SetFallbacks(fallbacks...)
+	return nil
+}
+
+// RetryPolicyFromSettings converts the inference layer settings into a retry policy.
+func RetryPolicyFromSettings(s *prescribe_layers.InferenceSettings) (api.RetryPolicy, error) {
+	policy := api.DefaultRetryPolicy()
+	if s == nil {
+		return policy, nil
+	}
+	if s.Retries < 0 {
+		return policy, errors.Errorf("invalid --retries %d (must be >= 0)", s.Retries)
+	}
+	policy.MaxRetries = s.Retries
+
+	for _, d := range []struct {
+		flag  string
+		value string
+		dst   *time.Duration
+	}{
+		{"retry-backoff", s.RetryBackoff, &policy.InitialBackoff},
+		{"retry-max-backoff", s.RetryMaxBackoff, &policy.MaxBackoff},
+		{"attempt-timeout", s.AttemptTimeout, &policy.AttemptTimeout},
+	} {
+		v := strings.TrimSpace(d.value)
+		if v == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(v)
+		if err != nil || parsed < 0 {
+			return policy, errors.Errorf("invalid --%s %q (expected a duration like 2s or 1m)", d.flag, d.value)
+		}
+		*d.dst = parsed
+	}
+	return policy, nil
+}
+
+// ResolveFallbacks turns --fallback entries into step settings. An entry naming a profile in
+// profileFile overlays that profile on base; any other entry is taken as a model name for the
+// provider of base.
+func ResolveFallbacks(base *gepsettings.StepSettings, names []string, profileFile string) ([]*gepsettings.StepSettings, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	if base == nil || base.Chat == nil {
+		return nil, errors.New("fallbacks require AI step settings")
+	}
+	profiles, err := loadProfileNames(profileFile)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []*gepsettings.StepSettings{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if profiles[name] {
+			ss, err := stepSettingsFromProfile(base, profileFile, name)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to load fallback profile %q", name)
+			}
+			out = append(out, ss)
+			continue
+		}
+		ss := base.Clone()
+		model := name
+		ss.Chat.Engine = &model
+		out = append(out, ss)
+	}
+	return out, nil
+}
+
+// stepSettingsFromProfile returns base with the values of profile applied on top.
+func stepSettingsFromProfile(base *gepsettings.StepSettings, profileFile, profile string) (*gepsettings.StepSettings, error) {
+	geppettoLayers, err := geppettolayers.CreateGeppettoLayers()
+	if err != nil {
+		return nil, err
+	}
+	parameterLayers := layers.NewParameterLayers(layers.WithLayers(geppettoLayers...))
+	parsed := layers.NewParsedLayers()
+	if err := cmd_middlewares.ExecuteMiddlewares(parameterLayers, parsed,
+		cmd_middlewares.GatherFlagsFromProfiles(profileFile, profileFile, profile, "default",
+			parameters.WithParseStepSource("profiles")),
+	); err != nil {
+		return nil, err
+	}
+	// Layers the profile does not mention must exist (empty) so the overlay leaves base untouched.
+	for _, l := range geppettoLayers {
+		parsed.GetOrCreate(l)
+	}
+
+	ss := base.Clone()
+	if err := ss.UpdateFromParsedLayers(parsed); err != nil {
+		return nil, err
+	}
+	return ss, nil
+}
+
+// loadProfileNames returns the profiles defined in profileFile (none if it does not exist).
+func loadProfileNames(profileFile string) (map[string]bool, error) {
+	names := map[string]bool{}
+	if profileFile == "" {
+		return names, nil
+	}
+	b, err := os.ReadFile(profileFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return names, nil
+		}
+		return nil, errors.Wrap(err, "failed to read profiles")
+	}
+	var profiles map[string]any
+	if err := yaml.Unmarshal(b, &profiles); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse profiles %s", profileFile)
+	}
+	for name := range profiles {
+		names[name] = true
+	}
+	return names, nil
+}
+
+// profileFileFromLayers returns the --profile-file of the command, or the default pinocchio profiles.
+func profileFileFromLayers(parsedLayers *layers.ParsedLayers) string {
+	if parsedLayers != nil {
+		if _, ok := parsedLayers.Get(cli.ProfileSettingsSlug); ok {
+			ps := &cli.ProfileSettings{}
+			if err := parsedLayers.InitializeStruct(cli.ProfileSettingsSlug, ps); err == nil && ps.ProfileFile != "" {
+				return ps.ProfileFile
+			}
+		}
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pinocchio", "profiles.yaml")
+}
@@ -0,0 +1,76 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gepsettings "github.com/go-go-golems/geppetto/pkg/steps/ai/settings"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+)
+
+func TestResolveFallbacks(t *testing.T) {
+	profileFile := filepath.Join(t.TempDir(), "profiles.yaml")
+	if err := os.WriteFile(profileFile, []byte(`cheap:
+  ai-chat:
+    ai-engine: claude-3-5-haiku-latest
+    ai-api-type: claude
+`), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	base, err := gepsettings.NewStepSettings()
+	if err != nil {
+		t.Fatalf("NewStepSettings: %v", err)
+	}
+	engine := "gpt-4o"
+	temperature := 0.3
+	base.Chat.Engine = &engine
+	base.Chat.Temperature = &temperature
+
+	got, err := ResolveFallbacks(base, []string{"cheap", " gpt-4o-mini ", ""}, profileFile)
+	if err != nil {
+		t.Fatalf("ResolveFallbacks: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 fallbacks, got %d", len(got))
+	}
+
+	profile := got[0].Chat
+	if *profile.Engine != "claude-3-5-haiku-latest" || profile.ApiType == nil || string(*profile.ApiType) != "claude" {
+		t.Fatalf("expected the profile's model and provider, got %v / %v", *profile.Engine, profile.ApiType)
+	}
+	if profile.Temperature == nil || *profile.Temperature != temperature {
+		t.Fatalf("expected settings the profile does not set to be kept, got %v", profile.Temperature)
+	}
+
+	if *got[1].Chat.Engine != "gpt-4o-mini" {
+		t.Fatalf("expected a model fallback, got %q", *got[1].Chat.Engine)
+	}
+	if *base.Chat.Engine != "gpt-4o" {
+		t.Fatalf("base settings were modified: %q", *base.Chat.Engine)
+	}
+}
+
+func TestRetryPolicyFromSettings(t *testing.T) {
+	p, err := RetryPolicyFromSettings(&prescribe_layers.InferenceSettings{
+		Retries:         4,
+		RetryBackoff:    "250ms",
+		RetryMaxBackoff: "10s",
+		AttemptTimeout:  "2m",
+	})
+	if err != nil {
+		t.Fatalf("RetryPolicyFromSettings: %v", err)
+	}
+	if p.MaxRetries != 4 || p.InitialBackoff != 250*time.Millisecond || p.MaxBackoff != 10*time.Second || p.AttemptTimeout != 2*time.Minute {
+		t.Fatalf("unexpected policy: %+v", p)
+	}
+
+	if _, err := RetryPolicyFromSettings(&prescribe_layers.InferenceSettings{AttemptTimeout: "soon"}); err == nil {
+		t.Fatalf("expected an error for an invalid duration")
+	}
+	if _, err := RetryPolicyFromSettings(&prescribe_layers.InferenceSettings{Retries: -1}); err == nil {
+		t.Fatalf("expected an error for negative retries")
+	}
+}
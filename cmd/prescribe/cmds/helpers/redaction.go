@@ -0,0 +1,44 @@
+package helpers
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-go-golems/prescribe/internal/controller"
+	"github.com/go-go-golems/prescribe/internal/redact"
+)
+
+// ConfigureRedaction applies a --redaction override ("" keeps redaction.mode from the config).
+func ConfigureRedaction(ctrl *controller.Controller, mode string) error {
+	if mode == "" {
+		return nil
+	}
+	m, err := redact.ParseMode(mode)
+	if err != nil {
+		return err
+	}
+	ctrl.SetRedactionMode(m)
+	return nil
+}
+
+// PrintRedactionReport prints the secrets found in the last request built by ctrl, if any.
+// Only a short prefix of each secret is shown.
+func PrintRedactionReport(w io.Writer, ctrl *controller.Controller) {
+	report := ctrl.LastRedaction()
+	findings := report.Findings
+	if len(findings) == 0 {
+		return
+	}
+	switch report.Mode {
+	case redact.ModeWarn:
+		fmt.Fprintf(w, "Warning: %d possible secret(s) sent unredacted (redaction mode warn):\n", len(findings))
+	case redact.ModeRedact:
+		fmt.Fprintf(w, "Redacted %d possible secret(s) before sending:\n", len(findings))
+	default:
+		// Block mode fails the request with the findings in the error.
+		return
+	}
+	for _, f := range findings {
+		fmt.Fprintf(w, "  %s\n", f)
+	}
+}
@@ -0,0 +1,21 @@
+package helpers
+
+import (
+	gepsettings "github.com/go-go-golems/geppetto/pkg/steps/ai/settings"
+	"github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/prescribe/internal/api"
+	"github.com/go-go-golems/prescribe/internal/tokens"
+	"github.com/pkg/errors"
+)
+
+// TokenCounterFromParsedLayers selects the tokenizer for the provider/model configured in the
+// geppetto layers (tokens.Default if no model is configured).
+func TokenCounterFromParsedLayers(parsedLayers *layers.ParsedLayers) (*tokens.Counter, error) {
+	stepSettings, err := gepsettings.NewStepSettingsFromParsedLayers(parsedLayers)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build AI step settings from parsed layers")
+	}
+	svc := api.NewService()
+	svc.SetStepSettings(stepSettings)
+	return svc.TokenCounter(), nil
+}
@@ -0,0 +1,143 @@
+package history
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	phistory "github.com/go-go-golems/prescribe/internal/history"
+	"github.com/go-go-golems/prescribe/internal/prdata"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type HistoryDiffSettings struct {
+	From string `glazed.parameter:"from"`
+	To   string `glazed.parameter:"to"`
+}
+
+type HistoryDiffCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &HistoryDiffCommand{}
+
+func NewHistoryDiffCommand() (*HistoryDiffCommand, error) {
+	repoLayer, err := prescribe_layers.NewRepositoryLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository layer")
+	}
+	repoLayerExisting, err := prescribe_layers.WrapAsExistingCobraFlagsLayer(repoLayer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
+	}
+
+	defaultLayer, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithArguments(
+			fields.New(
+				"from",
+				fields.TypeString,
+				fields.WithHelp("History record to diff from: ID, unique ID prefix, latest or latest~N"),
+				fields.WithRequired(true),
+			),
+			fields.New(
+				"to",
+				fields.TypeString,
+				fields.WithHelp("History record to diff to (default: the current .pr-builder/last-generated-pr.yaml)"),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdDesc := cmds.NewCommandDescription(
+		"diff",
+		cmds.WithShort("Diff two recorded generations"),
+		cmds.WithLong(`Print a unified diff of the parsed PR data (as YAML; the raw text for records that did not parse) of two history records.
+
+Without a second record, the first one is compared with the current .pr-builder/last-generated-pr.yaml, i.e. the diff shows what 'prescribe history restore <from>' would undo.`),
+		cmds.WithLayersList(
+			repoLayerExisting,
+			defaultLayer,
+		),
+	)
+
+	return &HistoryDiffCommand{CommandDescription: cmdDesc}, nil
+}
+
+func (c *HistoryDiffCommand) Run(ctx context.Context, parsedLayers *glazed_layers.ParsedLayers) error {
+	_ = ctx
+
+	settings := &HistoryDiffSettings{}
+	if err := parsedLayers.InitializeStruct(schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "failed to decode history diff settings")
+	}
+	repoSettings, err := prescribe_layers.GetRepositorySettings(parsedLayers)
+	if err != nil {
+		return err
+	}
+	store := phistory.NewStore(phistory.Dir(repoSettings.RepoPath))
+
+	from, err := loadRecord(store, settings.From)
+	if err != nil {
+		return err
+	}
+	fromText, err := from.DiffText()
+	if err != nil {
+		return err
+	}
+
+	var toName, toText string
+	if settings.To != "" {
+		to, err := loadRecord(store, settings.To)
+		if err != nil {
+			return err
+		}
+		toName = "history/" + to.ID
+		if toText, err = to.DiffText(); err != nil {
+			return err
+		}
+	} else {
+		path := prdata.LastGeneratedPRDataPath(repoSettings.RepoPath)
+		data, err := prdata.LoadGeneratedPRDataFromYAMLFile(path)
+		if err != nil {
+			return err
+		}
+		toName = "last-generated-pr.yaml"
+		if toText, err = phistory.PRDataText(data, ""); err != nil {
+			return err
+		}
+	}
+
+	out, err := phistory.Diff("history/"+from.ID, fromText, toName, toText)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		fmt.Println("No differences.")
+		return nil
+	}
+	fmt.Print(out)
+	return nil
+}
+
+func NewDiffCobraCommand() (*cobra.Command, error) {
+	glazedCmd, err := NewHistoryDiffCommand()
+	if err != nil {
+		return nil, err
+	}
+	return cli.BuildCobraCommand(
+		glazedCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{
+			MiddlewaresFunc: cli.CobraCommandDefaultMiddlewares,
+		}),
+	)
+}
@@ -0,0 +1,114 @@
+package history
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	phistory "github.com/go-go-golems/prescribe/internal/history"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type HistoryListCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.GlazeCommand = &HistoryListCommand{}
+
+func NewHistoryListCommand() (*HistoryListCommand, error) {
+	repoLayer, err := prescribe_layers.NewRepositoryLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository layer")
+	}
+	repoLayerExisting, err := prescribe_layers.WrapAsExistingCobraFlagsLayer(repoLayer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
+	}
+
+	cmdDesc := cmds.NewCommandDescription(
+		"list",
+		cmds.WithShort("List recorded generations"),
+		cmds.WithLong("List the generation history of the repository, newest first. Each record is .pr-builder/history/<id>.yaml (plus <id>.turn.yaml with the conversation)."),
+		cmds.WithLayersList(
+			repoLayerExisting,
+		),
+	)
+
+	return &HistoryListCommand{CommandDescription: cmdDesc}, nil
+}
+
+func (c *HistoryListCommand) RunIntoGlazeProcessor(
+	ctx context.Context,
+	parsedLayers *glazed_layers.ParsedLayers,
+	gp middlewares.Processor,
+) error {
+	repoSettings, err := prescribe_layers.GetRepositorySettings(parsedLayers)
+	if err != nil {
+		return err
+	}
+
+	store := phistory.NewStore(phistory.Dir(repoSettings.RepoPath))
+	records, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		var inTokens, outTokens int
+		if r.Usage != nil {
+			inTokens, outTokens = r.Usage.InputTokens, r.Usage.OutputTokens
+		}
+		row := types.NewRow(
+			types.MRP("id", r.ID),
+			types.MRP("created_at", r.CreatedAt.Format(time.RFC3339)),
+			types.MRP("kind", string(r.Kind)),
+			types.MRP("model", r.Model),
+			types.MRP("preset", r.Preset),
+			types.MRP("source_branch", r.SourceBranch),
+			types.MRP("source_commit", shortSHA(r.SourceCommit)),
+			types.MRP("input_tokens", inTokens),
+			types.MRP("output_tokens", outTokens),
+			types.MRP("title", r.Title()),
+			types.MRP("parse_error", r.ParseError),
+			types.MRP("path", filepath.Join(store.Dir(), r.ID+".yaml")),
+		)
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
+
+func NewListCobraCommand() (*cobra.Command, error) {
+	glazedCmd, err := NewHistoryListCommand()
+	if err != nil {
+		return nil, err
+	}
+
+	cobraCmd, err := cli.BuildCobraCommand(
+		glazedCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{
+			MiddlewaresFunc: cli.CobraCommandDefaultMiddlewares,
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	cobraCmd.Aliases = []string{"ls"}
+
+	return cobraCmd, nil
+}
@@ -0,0 +1,102 @@
+package history
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/prescribe/internal/controller"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type HistoryRestoreSettings struct {
+	Ref string `glazed.parameter:"ref"`
+}
+
+type HistoryRestoreCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &HistoryRestoreCommand{}
+
+func NewHistoryRestoreCommand() (*HistoryRestoreCommand, error) {
+	repoLayer, err := prescribe_layers.NewRepositoryLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository layer")
+	}
+	repoLayerExisting, err := prescribe_layers.WrapAsExistingCobraFlagsLayer(repoLayer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
+	}
+
+	defaultLayer, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithArguments(
+			fields.New(
+				"ref",
+				fields.TypeString,
+				fields.WithHelp("History record to restore: ID, unique ID prefix, latest or latest~N"),
+				fields.WithRequired(true),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdDesc := cmds.NewCommandDescription(
+		"restore",
+		cmds.WithShort("Restore a recorded generation"),
+		cmds.WithLong("Make a history record the current generation: its PR data and metadata are written to .pr-builder/last-generated-pr.yaml and its conversation to last-generated-turn.yaml, so it can be refined ('prescribe refine') or used with 'prescribe create --use-last'."),
+		cmds.WithLayersList(
+			repoLayerExisting,
+			defaultLayer,
+		),
+	)
+
+	return &HistoryRestoreCommand{CommandDescription: cmdDesc}, nil
+}
+
+func (c *HistoryRestoreCommand) Run(ctx context.Context, parsedLayers *glazed_layers.ParsedLayers) error {
+	_ = ctx
+
+	settings := &HistoryRestoreSettings{}
+	if err := parsedLayers.InitializeStruct(schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "failed to decode history restore settings")
+	}
+	repoSettings, err := prescribe_layers.GetRepositorySettings(parsedLayers)
+	if err != nil {
+		return err
+	}
+	ctrl, err := controller.NewController(repoSettings.RepoPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to create controller")
+	}
+
+	r, path, err := ctrl.RestoreHistory(settings.Ref)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Restored %s (%q) to %s\n", r.ID, r.Title(), path)
+	return nil
+}
+
+func NewRestoreCobraCommand() (*cobra.Command, error) {
+	glazedCmd, err := NewHistoryRestoreCommand()
+	if err != nil {
+		return nil, err
+	}
+	return cli.BuildCobraCommand(
+		glazedCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{
+			MiddlewaresFunc: cli.CobraCommandDefaultMiddlewares,
+		}),
+	)
+}
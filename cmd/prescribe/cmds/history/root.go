@@ -0,0 +1,37 @@
+package history
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// NewHistoryCmd groups the generation history subcommands.
+func NewHistoryCmd() (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Browse, diff and restore earlier generations",
+		Long: `Every generation (including refinements, section regenerations and candidates) is recorded in .pr-builder/history/, so an earlier draft can be recovered after last-generated-pr.yaml was overwritten.
+
+Records are referenced by ID, a unique ID prefix, "latest" or "latest~N" (the N-th record before the newest).`,
+	}
+
+	listCmd, err := NewListCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build history list command")
+	}
+	showCmd, err := NewShowCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build history show command")
+	}
+	diffCmd, err := NewDiffCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build history diff command")
+	}
+	restoreCmd, err := NewRestoreCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build history restore command")
+	}
+
+	cmd.AddCommand(listCmd, showCmd, diffCmd, restoreCmd)
+	return cmd, nil
+}
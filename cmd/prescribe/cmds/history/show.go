@@ -0,0 +1,126 @@
+package history
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	phistory "github.com/go-go-golems/prescribe/internal/history"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+type HistoryShowSettings struct {
+	Ref string `glazed.parameter:"ref"`
+	Raw bool   `glazed.parameter:"raw"`
+}
+
+type HistoryShowCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &HistoryShowCommand{}
+
+func NewHistoryShowCommand() (*HistoryShowCommand, error) {
+	repoLayer, err := prescribe_layers.NewRepositoryLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository layer")
+	}
+	repoLayerExisting, err := prescribe_layers.WrapAsExistingCobraFlagsLayer(repoLayer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
+	}
+
+	defaultLayer, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithFields(
+			fields.New(
+				"raw",
+				fields.TypeBool,
+				fields.WithHelp("Print only the raw assistant text"),
+				fields.WithDefault(false),
+			),
+		),
+		schema.WithArguments(
+			fields.New(
+				"ref",
+				fields.TypeString,
+				fields.WithHelp("History record: ID, unique ID prefix, latest or latest~N"),
+				fields.WithDefault("latest"),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdDesc := cmds.NewCommandDescription(
+		"show",
+		cmds.WithShort("Show a recorded generation"),
+		cmds.WithLong("Print a history record as YAML: branches and commits, preset, prompt hash, model, usage, the raw assistant text and the parsed PR data."),
+		cmds.WithLayersList(
+			repoLayerExisting,
+			defaultLayer,
+		),
+	)
+
+	return &HistoryShowCommand{CommandDescription: cmdDesc}, nil
+}
+
+func (c *HistoryShowCommand) Run(ctx context.Context, parsedLayers *glazed_layers.ParsedLayers) error {
+	_ = ctx
+
+	settings := &HistoryShowSettings{}
+	if err := parsedLayers.InitializeStruct(schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "failed to decode history show settings")
+	}
+	repoSettings, err := prescribe_layers.GetRepositorySettings(parsedLayers)
+	if err != nil {
+		return err
+	}
+
+	r, err := loadRecord(phistory.NewStore(phistory.Dir(repoSettings.RepoPath)), settings.Ref)
+	if err != nil {
+		return err
+	}
+	if settings.Raw {
+		fmt.Print(r.Raw)
+		return nil
+	}
+	b, err := yaml.Marshal(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal history record")
+	}
+	fmt.Print(string(b))
+	return nil
+}
+
+// loadRecord resolves ref (see history.Store.Resolve) and loads the record.
+func loadRecord(store *phistory.Store, ref string) (*phistory.Record, error) {
+	id, err := store.Resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	r, _, err := store.Get(id)
+	return r, err
+}
+
+func NewShowCobraCommand() (*cobra.Command, error) {
+	glazedCmd, err := NewHistoryShowCommand()
+	if err != nil {
+		return nil, err
+	}
+	return cli.BuildCobraCommand(
+		glazedCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{
+			MiddlewaresFunc: cli.CobraCommandDefaultMiddlewares,
+		}),
+	)
+}
@@ -0,0 +1,113 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type DeleteSettings struct {
+	PresetID string `glazed.parameter:"preset-id"`
+	Project  bool   `glazed.parameter:"project"`
+	Global   bool   `glazed.parameter:"global"`
+}
+
+type DeleteCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &DeleteCommand{}
+
+func NewDeleteCommand() (*DeleteCommand, error) {
+	repoLayer, err := prescribe_layers.NewRepositoryLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository layer")
+	}
+	repoLayerExisting, err := prescribe_layers.WrapAsExistingCobraFlagsLayer(repoLayer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
+	}
+
+	defaultLayer, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithFields(scopeFields()...),
+		schema.WithArguments(
+			fields.New(
+				"preset-id",
+				fields.TypeString,
+				fields.WithHelp("Preset ID (filename)"),
+				fields.WithRequired(true),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdDesc := cmds.NewCommandDescription(
+		"delete",
+		cmds.WithShort("Delete a prompt preset"),
+		cmds.WithLong("Delete a prompt preset file. Without --project/--global, the project preset is preferred over the global one. Builtin presets cannot be deleted."),
+		cmds.WithLayersList(repoLayerExisting, defaultLayer),
+	)
+
+	return &DeleteCommand{CommandDescription: cmdDesc}, nil
+}
+
+func (c *DeleteCommand) Run(ctx context.Context, parsedLayers *glazed_layers.ParsedLayers) error {
+	_ = ctx
+
+	settings := &DeleteSettings{}
+	if err := parsedLayers.InitializeStruct(schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "failed to initialize prompt delete settings")
+	}
+	location, err := resolveScope(settings.Project, settings.Global)
+	if err != nil {
+		return err
+	}
+
+	ctrl, err := helpers.NewInitializedControllerFromParsedLayers(parsedLayers)
+	if err != nil {
+		return err
+	}
+
+	candidates := []domain.PresetLocation{location}
+	if location == "" {
+		candidates = []domain.PresetLocation{domain.PresetLocationProject, domain.PresetLocationGlobal}
+	}
+
+	var lastErr error
+	for _, loc := range candidates {
+		if err := ctrl.DeletePromptPreset(settings.PresetID, loc); err != nil {
+			lastErr = err
+			continue
+		}
+		fmt.Printf("Deleted prompt preset %q (%s)\n", settings.PresetID, loc)
+		return nil
+	}
+	return errors.Wrap(lastErr, "failed to delete prompt preset")
+}
+
+func NewDeleteCobraCommand() (*cobra.Command, error) {
+	glazedCmd, err := NewDeleteCommand()
+	if err != nil {
+		return nil, err
+	}
+	return cli.BuildCobraCommand(
+		glazedCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{
+			MiddlewaresFunc: cli.CobraCommandDefaultMiddlewares,
+		}),
+	)
+}
@@ -0,0 +1,67 @@
+package prompt
+
+import (
+	"strings"
+
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
+	"github.com/go-go-golems/prescribe/internal/controller"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/pkg/errors"
+)
+
+// scopeFields returns the optional --project/--global flags used to disambiguate a preset ID.
+func scopeFields() []*fields.Definition {
+	return []*fields.Definition{
+		fields.New(
+			"project",
+			fields.TypeBool,
+			fields.WithDefault(false),
+			fields.WithHelp("Only look at project presets (<repo>/.pr-builder/prompts)"),
+		),
+		fields.New(
+			"global",
+			fields.TypeBool,
+			fields.WithDefault(false),
+			fields.WithHelp("Only look at global presets (~/.pr-builder/prompts)"),
+		),
+	}
+}
+
+// resolveScope maps --project/--global to a preset location.
+// An empty location means "search builtin, project, then global".
+func resolveScope(project, global bool) (domain.PresetLocation, error) {
+	if project && global {
+		return "", errors.New("choose at most one location: --project or --global")
+	}
+	if project {
+		return domain.PresetLocationProject, nil
+	}
+	if global {
+		return domain.PresetLocationGlobal, nil
+	}
+	return "", nil
+}
+
+// useSessionPrompt loads the default session and, if presetID is set, switches its prompt to that
+// preset (in memory only).
+func useSessionPrompt(ctrl *controller.Controller, presetID string) error {
+	helpers.LoadDefaultSessionIfExists(ctrl)
+	if presetID == "" {
+		return nil
+	}
+	return ctrl.LoadPromptPreset(presetID)
+}
+
+// parameterNames lists the declared parameters of p, e.g. "audience, ticket*" (* = required).
+func parameterNames(p domain.PromptPreset) string {
+	names := make([]string, 0, len(p.Parameters))
+	for _, param := range p.Parameters {
+		name := param.Name
+		if param.Required {
+			name += "*"
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ", ")
+}
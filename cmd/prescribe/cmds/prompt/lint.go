@@ -0,0 +1,109 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
+	"github.com/go-go-golems/prescribe/internal/api"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type LintSettings struct {
+	PresetID string `glazed.parameter:"preset-id"`
+}
+
+type LintCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &LintCommand{}
+
+func NewLintCommand() (*LintCommand, error) {
+	repoLayer, err := prescribe_layers.NewRepositoryLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository layer")
+	}
+	repoLayerExisting, err := prescribe_layers.WrapAsExistingCobraFlagsLayer(repoLayer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
+	}
+
+	defaultLayer, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithArguments(
+			fields.New(
+				"preset-id",
+				fields.TypeString,
+				fields.WithHelp("Preset ID to lint instead of the session's prompt"),
+				fields.WithRequired(false),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdDesc := cmds.NewCommandDescription(
+		"lint",
+		cmds.WithShort("Check the prompt templates"),
+		cmds.WithLong("Check the session's prompt (or the given preset) for template parse errors, calls of undefined templates, references to unknown variables, and variables set in the session (custom vars, preset parameters, issue, instructions, style switches) that the prompt never uses. Exits non-zero if there are errors."),
+		cmds.WithLayersList(repoLayerExisting, defaultLayer),
+	)
+
+	return &LintCommand{CommandDescription: cmdDesc}, nil
+}
+
+func (c *LintCommand) Run(ctx context.Context, parsedLayers *glazed_layers.ParsedLayers) error {
+	_ = ctx
+
+	settings := &LintSettings{}
+	if err := parsedLayers.InitializeStruct(schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "failed to initialize prompt lint settings")
+	}
+
+	ctrl, err := helpers.NewInitializedControllerFromParsedLayers(parsedLayers)
+	if err != nil {
+		return err
+	}
+	if err := useSessionPrompt(ctrl, settings.PresetID); err != nil {
+		return err
+	}
+
+	findings := ctrl.LintPrompt()
+	errorCount := 0
+	for _, f := range findings {
+		if f.Severity == api.PromptLintError {
+			errorCount++
+		}
+		fmt.Println(f.String())
+	}
+	if len(findings) == 0 {
+		fmt.Println("No problems found")
+	}
+	if errorCount > 0 {
+		return errors.Errorf("%d error(s) in the prompt", errorCount)
+	}
+	return nil
+}
+
+func NewLintCobraCommand() (*cobra.Command, error) {
+	glazedCmd, err := NewLintCommand()
+	if err != nil {
+		return nil, err
+	}
+	return cli.BuildCobraCommand(
+		glazedCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{
+			MiddlewaresFunc: cli.CobraCommandDefaultMiddlewares,
+		}),
+	)
+}
@@ -0,0 +1,168 @@
+package prompt
+
+import (
+	"context"
+
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const listSlug = "prompt-list"
+
+type ListSettings struct {
+	Project bool `glazed.parameter:"project"`
+	Global  bool `glazed.parameter:"global"`
+	Builtin bool `glazed.parameter:"builtin"`
+	All     bool `glazed.parameter:"all"`
+}
+
+type ListCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.GlazeCommand = &ListCommand{}
+
+func NewListCommand() (*ListCommand, error) {
+	repoLayer, err := prescribe_layers.NewRepositoryLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository layer")
+	}
+	repoLayerExisting, err := prescribe_layers.WrapAsExistingCobraFlagsLayer(repoLayer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
+	}
+
+	listLayer, err := schema.NewSection(
+		listSlug,
+		"Prompt Preset List",
+		schema.WithFields(
+			fields.New(
+				"project",
+				fields.TypeBool,
+				fields.WithDefault(false),
+				fields.WithHelp("List project presets (<repo>/.pr-builder/prompts)"),
+			),
+			fields.New(
+				"global",
+				fields.TypeBool,
+				fields.WithDefault(false),
+				fields.WithHelp("List global presets (~/.pr-builder/prompts)"),
+			),
+			fields.New(
+				"builtin",
+				fields.TypeBool,
+				fields.WithDefault(false),
+				fields.WithHelp("List builtin presets shipped with prescribe"),
+			),
+			fields.New(
+				"all",
+				fields.TypeBool,
+				fields.WithDefault(false),
+				fields.WithHelp("List builtin, project and global presets (default when no scope flags are set)"),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdDesc := cmds.NewCommandDescription(
+		"list",
+		cmds.WithShort("List prompt presets"),
+		cmds.WithLong("List the builtin prompt presets and the ones in the project and global preset directories, with their format and declared parameters."),
+		cmds.WithLayersList(
+			repoLayerExisting,
+			listLayer,
+		),
+	)
+
+	return &ListCommand{CommandDescription: cmdDesc}, nil
+}
+
+func (c *ListCommand) RunIntoGlazeProcessor(
+	ctx context.Context,
+	parsedLayers *glazed_layers.ParsedLayers,
+	gp middlewares.Processor,
+) error {
+	settings := &ListSettings{}
+	if err := parsedLayers.InitializeStruct(listSlug, settings); err != nil {
+		return errors.Wrap(err, "failed to initialize prompt list settings")
+	}
+
+	ctrl, err := helpers.NewInitializedControllerFromParsedLayers(parsedLayers)
+	if err != nil {
+		return err
+	}
+
+	wantProject := settings.Project
+	wantGlobal := settings.Global
+	wantBuiltin := settings.Builtin
+	if settings.All || (!wantProject && !wantGlobal && !wantBuiltin) {
+		wantProject, wantGlobal, wantBuiltin = true, true, true
+	}
+
+	if wantBuiltin {
+		if err := addPresetRows(ctx, gp, domain.GetBuiltinPresets()); err != nil {
+			return err
+		}
+	}
+	if wantProject {
+		ps, err := ctrl.LoadProjectPresets()
+		if err != nil {
+			return errors.Wrap(err, "failed to load project prompt presets")
+		}
+		if err := addPresetRows(ctx, gp, ps); err != nil {
+			return err
+		}
+	}
+	if wantGlobal {
+		ps, err := ctrl.LoadGlobalPresets()
+		if err != nil {
+			return errors.Wrap(err, "failed to load global prompt presets")
+		}
+		if err := addPresetRows(ctx, gp, ps); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addPresetRows(ctx context.Context, gp middlewares.Processor, ps []domain.PromptPreset) error {
+	for _, p := range ps {
+		row := types.NewRow(
+			types.MRP("preset_id", p.ID),
+			types.MRP("preset_name", p.Name),
+			types.MRP("preset_description", p.Description),
+			types.MRP("preset_location", p.Location),
+			types.MRP("prompt_format", string(p.Format())),
+			types.MRP("parameters", parameterNames(p)),
+		)
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func NewListCobraCommand() (*cobra.Command, error) {
+	glazedCmd, err := NewListCommand()
+	if err != nil {
+		return nil, err
+	}
+	return cli.BuildCobraCommand(
+		glazedCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{
+			MiddlewaresFunc: cli.CobraCommandDefaultMiddlewares,
+		}),
+	)
+}
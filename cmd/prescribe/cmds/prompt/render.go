@@ -0,0 +1,123 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
+	"github.com/go-go-golems/prescribe/internal/api"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type RenderSettings struct {
+	PresetID string `glazed.parameter:"preset-id"`
+}
+
+type RenderCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &RenderCommand{}
+
+func NewRenderCommand() (*RenderCommand, error) {
+	repoLayer, err := prescribe_layers.NewRepositoryLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository layer")
+	}
+	repoLayerExisting, err := prescribe_layers.WrapAsExistingCobraFlagsLayer(repoLayer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
+	}
+	promptVarsLayer, err := prescribe_layers.NewPromptVarsLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create prompt variables layer")
+	}
+
+	defaultLayer, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithArguments(
+			fields.New(
+				"preset-id",
+				fields.TypeString,
+				fields.WithHelp("Preset ID to render instead of the session's prompt (not saved)"),
+				fields.WithRequired(false),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdDesc := cmds.NewCommandDescription(
+		"render",
+		cmds.WithShort("Render the prompt against the session"),
+		cmds.WithLong("Render the session's prompt (or the given preset) with the session's files, context and variables, exactly as generate would send it, and print the system and user prompts separately. Prompt variable flags apply to this run only."),
+		cmds.WithLayersList(repoLayerExisting, defaultLayer, promptVarsLayer),
+	)
+
+	return &RenderCommand{CommandDescription: cmdDesc}, nil
+}
+
+func (c *RenderCommand) Run(ctx context.Context, parsedLayers *glazed_layers.ParsedLayers) error {
+	_ = ctx
+
+	settings := &RenderSettings{}
+	if err := parsedLayers.InitializeStruct(schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "failed to initialize prompt render settings")
+	}
+
+	ctrl, err := helpers.NewInitializedControllerFromParsedLayers(parsedLayers)
+	if err != nil {
+		return err
+	}
+	if err := useSessionPrompt(ctrl, settings.PresetID); err != nil {
+		return err
+	}
+	if _, err := prescribe_layers.ApplyPromptVars(parsedLayers, &ctrl.GetData().PromptVars); err != nil {
+		return err
+	}
+	presetVars, err := prescribe_layers.PresetVarPairs(parsedLayers)
+	if err != nil {
+		return err
+	}
+	if err := ctrl.SetPresetVars(presetVars); err != nil {
+		return err
+	}
+
+	req, err := ctrl.BuildGenerateDescriptionRequest()
+	if err != nil {
+		return err
+	}
+	helpers.PrintRedactionReport(os.Stderr, ctrl)
+	sys, user, err := api.CompilePrompt(req)
+	if err != nil {
+		return err
+	}
+
+	counter := ctrl.TokenCounter()
+	fmt.Printf("=== System prompt (%d tokens) ===\n%s\n\n", counter.Count(sys), sys)
+	fmt.Printf("=== User prompt (%d tokens) ===\n%s\n", counter.Count(user), user)
+	return nil
+}
+
+func NewRenderCobraCommand() (*cobra.Command, error) {
+	glazedCmd, err := NewRenderCommand()
+	if err != nil {
+		return nil, err
+	}
+	return cli.BuildCobraCommand(
+		glazedCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{
+			MiddlewaresFunc: cli.CobraCommandDefaultMiddlewares,
+		}),
+	)
+}
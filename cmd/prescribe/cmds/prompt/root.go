@@ -0,0 +1,47 @@
+package prompt
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// NewPromptCmd groups the prompt preset subcommands.
+func NewPromptCmd() (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:   "prompt",
+		Short: "Manage, preview and lint prompt presets",
+		Long:  "List, show, save, edit and delete prompt presets, render the current prompt against the session, and lint its templates.",
+	}
+
+	listCmd, err := NewListCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build prompt list command")
+	}
+	showCmd, err := NewShowCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build prompt show command")
+	}
+	saveCmd, err := NewSaveCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build prompt save command")
+	}
+	editCmd, err := NewEditCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build prompt edit command")
+	}
+	deleteCmd, err := NewDeleteCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build prompt delete command")
+	}
+	renderCmd, err := NewRenderCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build prompt render command")
+	}
+	lintCmd, err := NewLintCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build prompt lint command")
+	}
+
+	cmd.AddCommand(listCmd, showCmd, saveCmd, editCmd, deleteCmd, renderCmd, lintCmd)
+	return cmd, nil
+}
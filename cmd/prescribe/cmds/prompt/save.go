@@ -0,0 +1,176 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const saveSlug = "prompt-save"
+
+type SaveSettings struct {
+	Name         string `glazed.parameter:"name"`
+	Description  string `glazed.parameter:"description"`
+	Project      bool   `glazed.parameter:"project"`
+	Global       bool   `glazed.parameter:"global"`
+	Template     string `glazed.parameter:"template"`
+	TemplateFile string `glazed.parameter:"template-file"`
+}
+
+type SaveCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &SaveCommand{}
+
+func NewSaveCommand() (*SaveCommand, error) {
+	repoLayer, err := prescribe_layers.NewRepositoryLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository layer")
+	}
+	repoLayerExisting, err := prescribe_layers.WrapAsExistingCobraFlagsLayer(repoLayer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
+	}
+
+	saveLayer, err := schema.NewSection(
+		saveSlug,
+		"Prompt Preset Save",
+		schema.WithFields(
+			fields.New(
+				"name",
+				fields.TypeString,
+				fields.WithDefault(""),
+				fields.WithHelp("Preset name (required)"),
+				fields.WithShortFlag("n"),
+				fields.WithRequired(true),
+			),
+			fields.New(
+				"description",
+				fields.TypeString,
+				fields.WithDefault(""),
+				fields.WithHelp("Preset description"),
+				fields.WithShortFlag("d"),
+			),
+			fields.New(
+				"project",
+				fields.TypeBool,
+				fields.WithDefault(false),
+				fields.WithHelp("Save preset to project presets (<repo>/.pr-builder/prompts)"),
+			),
+			fields.New(
+				"global",
+				fields.TypeBool,
+				fields.WithDefault(false),
+				fields.WithHelp("Save preset to global presets (~/.pr-builder/prompts)"),
+			),
+			fields.New(
+				"template",
+				fields.TypeString,
+				fields.WithDefault(""),
+				fields.WithHelp("Prompt template text (default: the session's prompt)"),
+			),
+			fields.New(
+				"template-file",
+				fields.TypeString,
+				fields.WithDefault(""),
+				fields.WithHelp("Read the prompt template from a file (default: the session's prompt)"),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdDesc := cmds.NewCommandDescription(
+		"save",
+		cmds.WithShort("Save a prompt preset"),
+		cmds.WithLong("Save a named prompt preset to project or global presets. Without --template/--template-file, the session's prompt is saved; a session preset is copied with its sections, parameters and output settings."),
+		cmds.WithLayersList(
+			repoLayerExisting,
+			saveLayer,
+		),
+	)
+
+	return &SaveCommand{CommandDescription: cmdDesc}, nil
+}
+
+func (c *SaveCommand) Run(ctx context.Context, parsedLayers *glazed_layers.ParsedLayers) error {
+	_ = ctx
+
+	settings := &SaveSettings{}
+	if err := parsedLayers.InitializeStruct(saveSlug, settings); err != nil {
+		return errors.Wrap(err, "failed to initialize prompt save settings")
+	}
+
+	if settings.Project && settings.Global {
+		return errors.New("choose exactly one location: --project or --global")
+	}
+	if !settings.Project && !settings.Global {
+		return errors.New("missing location: choose --project or --global")
+	}
+	if settings.Template != "" && settings.TemplateFile != "" {
+		return errors.New("choose at most one of --template and --template-file")
+	}
+	location := domain.PresetLocationProject
+	if settings.Global {
+		location = domain.PresetLocationGlobal
+	}
+
+	ctrl, err := helpers.NewInitializedControllerFromParsedLayers(parsedLayers)
+	if err != nil {
+		return err
+	}
+
+	preset := domain.PromptPreset{Template: settings.Template}
+	switch {
+	case settings.TemplateFile != "":
+		data, err := os.ReadFile(settings.TemplateFile)
+		if err != nil {
+			return errors.Wrap(err, "failed to read template file")
+		}
+		preset.Template = string(data)
+	case settings.Template == "":
+		helpers.LoadDefaultSessionIfExists(ctrl)
+		data := ctrl.GetData()
+		if data.CurrentPreset != nil {
+			preset = *data.CurrentPreset
+		} else {
+			preset.Template = data.CurrentPrompt
+		}
+	}
+	preset.Name = settings.Name
+	preset.Description = settings.Description
+
+	presetID, err := ctrl.SavePromptPreset(preset, location)
+	if err != nil {
+		return errors.Wrap(err, "failed to save prompt preset")
+	}
+
+	fmt.Printf("Prompt preset '%s' saved as %s (%s)\n", settings.Name, presetID, location)
+	return nil
+}
+
+func NewSaveCobraCommand() (*cobra.Command, error) {
+	glazedCmd, err := NewSaveCommand()
+	if err != nil {
+		return nil, err
+	}
+	return cli.BuildCobraCommand(
+		glazedCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{
+			MiddlewaresFunc: cli.CobraCommandDefaultMiddlewares,
+		}),
+	)
+}
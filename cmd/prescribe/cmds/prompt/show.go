@@ -0,0 +1,125 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/go-go-golems/prescribe/internal/presets"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type ShowSettings struct {
+	PresetID string `glazed.parameter:"preset-id"`
+	Project  bool   `glazed.parameter:"project"`
+	Global   bool   `glazed.parameter:"global"`
+}
+
+type ShowCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &ShowCommand{}
+
+func NewShowCommand() (*ShowCommand, error) {
+	repoLayer, err := prescribe_layers.NewRepositoryLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository layer")
+	}
+	repoLayerExisting, err := prescribe_layers.WrapAsExistingCobraFlagsLayer(repoLayer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
+	}
+
+	defaultLayer, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithFields(scopeFields()...),
+		schema.WithArguments(
+			fields.New(
+				"preset-id",
+				fields.TypeString,
+				fields.WithHelp("Preset ID (filename; default: the session's preset)"),
+				fields.WithRequired(false),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdDesc := cmds.NewCommandDescription(
+		"show",
+		cmds.WithShort("Show a prompt preset"),
+		cmds.WithLong("Print a prompt preset in its file format, preceded by its ID, location, format and backing file. Without a preset ID, the session's preset is shown."),
+		cmds.WithLayersList(repoLayerExisting, defaultLayer),
+	)
+
+	return &ShowCommand{CommandDescription: cmdDesc}, nil
+}
+
+func (c *ShowCommand) Run(ctx context.Context, parsedLayers *glazed_layers.ParsedLayers) error {
+	_ = ctx
+
+	settings := &ShowSettings{}
+	if err := parsedLayers.InitializeStruct(schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "failed to initialize prompt show settings")
+	}
+	location, err := resolveScope(settings.Project, settings.Global)
+	if err != nil {
+		return err
+	}
+
+	ctrl, err := helpers.NewInitializedControllerFromParsedLayers(parsedLayers)
+	if err != nil {
+		return err
+	}
+
+	var p *domain.PromptPreset
+	if settings.PresetID == "" {
+		helpers.LoadDefaultSessionIfExists(ctrl)
+		p = ctrl.GetData().CurrentPreset
+		if p == nil {
+			return errors.New("the session uses a custom prompt, not a preset (pass a preset ID)")
+		}
+	} else {
+		p, err = ctrl.ResolvePromptPreset(settings.PresetID, location)
+		if err != nil {
+			return err
+		}
+	}
+
+	data, err := presets.MarshalPromptPreset(*p)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("# %s (%s, %s)\n", p.ID, p.Location, p.Format())
+	if p.Location != domain.PresetLocationBuiltin {
+		if path, err := ctrl.PromptPresetPath(p.ID, p.Location); err == nil {
+			fmt.Printf("# %s\n", path)
+		}
+	}
+	fmt.Print(string(data))
+	return nil
+}
+
+func NewShowCobraCommand() (*cobra.Command, error) {
+	glazedCmd, err := NewShowCommand()
+	if err != nil {
+		return nil, err
+	}
+	return cli.BuildCobraCommand(
+		glazedCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{
+			MiddlewaresFunc: cli.CobraCommandDefaultMiddlewares,
+		}),
+	)
+}
@@ -0,0 +1,187 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	geppettolayers "github.com/go-go-golems/geppetto/pkg/layers"
+	gepsettings "github.com/go-go-golems/geppetto/pkg/steps/ai/settings"
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type RefineCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &RefineCommand{}
+
+type RefineSettings struct {
+	Instruction string `glazed.parameter:"instruction"`
+	OutputFile  string `glazed.parameter:"output-file"`
+}
+
+func NewRefineCommand() (*RefineCommand, error) {
+	repoLayer, err := prescribe_layers.NewRepositoryLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository layer")
+	}
+	repoLayerExisting, err := prescribe_layers.WrapAsExistingCobraFlagsLayer(repoLayer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
+	}
+
+	inferenceLayer, err := prescribe_layers.NewInferenceLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create inference layer")
+	}
+
+	geppettoLayers, err := geppettolayers.CreateGeppettoLayers()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create geppetto parameter layers")
+	}
+
+	defaultLayer, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithFields(
+			fields.New(
+				"output-file",
+				fields.TypeString,
+				fields.WithDefault(""),
+				fields.WithHelp("Output file (default: stdout)"),
+				fields.WithShortFlag("o"),
+			),
+		),
+		schema.WithArguments(
+			fields.New(
+				"instruction",
+				fields.TypeString,
+				fields.WithHelp("What to change, e.g. \"make it shorter, mention the migration\""),
+				fields.WithRequired(true),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	layersList := []glazed_layers.ParameterLayer{
+		repoLayerExisting,
+		defaultLayer,
+		inferenceLayer,
+	}
+	layersList = append(layersList, geppettoLayers...)
+
+	cmdDesc := cmds.NewCommandDescription(
+		"refine",
+		cmds.WithShort("Refine the last generated PR description"),
+		cmds.WithLong(`Continue the conversation of the last generation with a follow-up instruction.
+
+The instruction is appended to the previous conversation (.pr-builder/last-generated-turn.yaml), inference is re-run and the YAML is re-parsed. The result replaces .pr-builder/last-generated-pr.yaml, whose generation metadata keeps the refinement history.`),
+		cmds.WithLayersList(
+			layersList...,
+		),
+	)
+
+	return &RefineCommand{CommandDescription: cmdDesc}, nil
+}
+
+func (c *RefineCommand) Run(ctx context.Context, parsedLayers *glazed_layers.ParsedLayers) error {
+	settings := &RefineSettings{}
+	if err := parsedLayers.InitializeStruct(schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "failed to decode refine settings")
+	}
+	if strings.TrimSpace(settings.Instruction) == "" {
+		return errors.New("refinement instruction is required")
+	}
+
+	ctrl, err := helpers.NewInitializedControllerFromParsedLayers(parsedLayers)
+	if err != nil {
+		return err
+	}
+
+	stepSettings, err := gepsettings.NewStepSettingsFromParsedLayers(parsedLayers)
+	if err != nil {
+		return errors.Wrap(err, "failed to build AI step settings from parsed layers")
+	}
+	ctrl.SetStepSettings(stepSettings)
+	if err := helpers.ConfigureInference(ctrl, parsedLayers, stepSettings); err != nil {
+		return err
+	}
+
+	if err := ctrl.LoadLastGeneration(); err != nil {
+		return err
+	}
+	if meta := ctrl.GetData().GenerationMetadata; meta != nil && len(meta.Refinements) > 0 {
+		fmt.Fprintf(os.Stderr, "Previous refinements: %d\n", len(meta.Refinements))
+	}
+
+	fmt.Fprintf(os.Stderr, "Refining PR description...\n")
+	description, err := ctrl.Refine(ctx, settings.Instruction)
+	if err != nil {
+		return errors.Wrap(err, "failed to refine description")
+	}
+
+	data := ctrl.GetData()
+	if last := ctrl.LastGeneration(); last != nil {
+		meta := last.Metadata()
+		helpers.PrintGenerationSummary(os.Stderr, &meta)
+		if last.Usage != nil {
+			if est, err := ctrl.ActualCost(last.Model, *last.Usage); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to compute cost: %v\n", err)
+			} else {
+				helpers.PrintActualCost(os.Stderr, est)
+			}
+		}
+	}
+	if data.GeneratedPRDataParseError != "" {
+		fmt.Fprintf(os.Stderr, "Warning: refined output could not be parsed (%s); keeping the previous PR data\n", data.GeneratedPRDataParseError)
+	}
+
+	// Always persist: the refinement history and conversation advance even if parsing failed.
+	if path, err := ctrl.WriteLastGeneratedPRData(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write last generated PR data: %v\n", err)
+	} else {
+		fmt.Fprintf(os.Stderr, "Parsed PR data written to %s\n", path)
+	}
+
+	if settings.OutputFile != "" {
+		if err := os.WriteFile(settings.OutputFile, []byte(description), 0644); err != nil {
+			return errors.Wrap(err, "failed to write output file")
+		}
+		fmt.Fprintf(os.Stderr, "Description written to %s\n", settings.OutputFile)
+		return nil
+	}
+	fmt.Println(description)
+	return nil
+}
+
+func NewRefineCobraCommand() (*cobra.Command, error) {
+	glazedCmd, err := NewRefineCommand()
+	if err != nil {
+		return nil, err
+	}
+
+	cobraCmd, err := cli.BuildCobraCommand(
+		glazedCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{
+			EnableProfileSettingsLayer: true,
+			MiddlewaresFunc:            aiCommandMiddlewares,
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return cobraCmd, nil
+}
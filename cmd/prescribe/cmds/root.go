@@ -5,9 +5,12 @@ import (
 	"os"
 
 	"github.com/go-go-golems/glazed/pkg/cmds/logging"
+	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/cache"
 	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/context"
 	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/file"
 	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/filter"
+	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/history"
+	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/prompt"
 	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/session"
 	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/tokens"
 	"github.com/pkg/errors"
@@ -54,6 +57,10 @@ func InitRootCmd(rootCmd *cobra.Command) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to build create command")
 	}
+	refineCmd, err := NewRefineCobraCommand()
+	if err != nil {
+		return errors.Wrap(err, "failed to build refine command")
+	}
 	tuiCmd, err := NewTuiCobraCommand()
 	if err != nil {
 		return errors.Wrap(err, "failed to build tui command")
@@ -90,8 +97,26 @@ func InitRootCmd(rootCmd *cobra.Command) error {
 	}
 	rootCmd.AddCommand(contextCmd)
 
-	// Root-level commands (generate, create, tui)
-	rootCmd.AddCommand(generateCmd, createCmd, tuiCmd)
+	cacheCmd, err := cache.NewCacheCmd()
+	if err != nil {
+		return errors.Wrap(err, "failed to build cache command")
+	}
+	rootCmd.AddCommand(cacheCmd)
+
+	historyCmd, err := history.NewHistoryCmd()
+	if err != nil {
+		return errors.Wrap(err, "failed to build history command")
+	}
+	rootCmd.AddCommand(historyCmd)
+
+	promptCmd, err := prompt.NewPromptCmd()
+	if err != nil {
+		return errors.Wrap(err, "failed to build prompt command")
+	}
+	rootCmd.AddCommand(promptCmd)
+
+	// Root-level commands (generate, refine, create, tui)
+	rootCmd.AddCommand(generateCmd, refineCmd, createCmd, tuiCmd)
 
 	return nil
 }
@@ -11,6 +11,7 @@ import (
 	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
 	"github.com/go-go-golems/glazed/pkg/cmds/schema"
 	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
+	"github.com/go-go-golems/prescribe/internal/controller"
 	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -23,6 +24,8 @@ type SessionInitSettings struct {
 	Path        string `glazed.parameter:"path"`
 	Title       string `glazed.parameter:"title"`
 	Description string `glazed.parameter:"description"`
+	// BuiltinFilters overrides defaults.builtin_filter_presets from .pr-builder/config.yaml.
+	BuiltinFilters string `glazed.parameter:"builtin-filters"`
 }
 
 type SessionInitCommand struct {
@@ -41,6 +44,11 @@ func NewSessionInitCommand() (*SessionInitCommand, error) {
 		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
 	}
 
+	promptVarsLayer, err := prescribe_layers.NewPromptVarsLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create prompt variables layer")
+	}
+
 	initLayer, err := schema.NewSection(
 		sessionInitSlug,
 		"Session Init",
@@ -70,6 +78,13 @@ func NewSessionInitCommand() (*SessionInitCommand, error) {
 				fields.WithDefault(""),
 				fields.WithHelp("PR description/notes to persist into session.yaml (only takes effect with --save)"),
 			),
+			fields.New(
+				"builtin-filters",
+				fields.TypeChoice,
+				fields.WithChoices("", controller.BuiltinFilterPresetsSuggest, controller.BuiltinFilterPresetsAuto, controller.BuiltinFilterPresetsOff),
+				fields.WithDefault(""),
+				fields.WithHelp("Builtin filter presets for detected ecosystems: suggest, auto (apply), or off (default: defaults.builtin_filter_presets in .pr-builder/config.yaml, else suggest)"),
+			),
 		),
 	)
 	if err != nil {
@@ -83,6 +98,7 @@ func NewSessionInitCommand() (*SessionInitCommand, error) {
 		cmds.WithLayersList(
 			repoLayerExisting,
 			initLayer,
+			promptVarsLayer,
 		),
 	)
 
@@ -109,6 +125,23 @@ func (c *SessionInitCommand) Run(ctx context.Context, parsedLayers *glazed_layer
 		return errors.Wrap(err, "failed to apply repo default filter presets")
 	}
 
+	mode, err := ctrl.ResolveBuiltinFilterPresetsMode(settings.BuiltinFilters)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve builtin filter preset mode")
+	}
+	suggestions := []controller.FilterPresetSuggestion{}
+	if mode != controller.BuiltinFilterPresetsOff {
+		suggestions, err = ctrl.SuggestBuiltinFilterPresets()
+		if err != nil {
+			return errors.Wrap(err, "failed to detect builtin filter presets")
+		}
+	}
+	if mode == controller.BuiltinFilterPresetsAuto {
+		for _, s := range suggestions {
+			ctrl.ApplyFilterPreset(s.Preset)
+		}
+	}
+
 	data := ctrl.GetData()
 	if strings.TrimSpace(settings.Title) != "" {
 		data.Title = settings.Title
@@ -116,14 +149,30 @@ func (c *SessionInitCommand) Run(ctx context.Context, parsedLayers *glazed_layer
 	if strings.TrimSpace(settings.Description) != "" {
 		data.Description = settings.Description
 	}
+	if _, err := prescribe_layers.ApplyPromptVars(parsedLayers, &data.PromptVars); err != nil {
+		return err
+	}
 
 	fmt.Printf("Initialized PR builder session\n")
 	fmt.Printf("  Source: %s\n", data.SourceBranch)
 	fmt.Printf("  Target: %s\n", data.TargetBranch)
 	fmt.Printf("  Files: %d\n", len(data.ChangedFiles))
+	if summary := data.PromptVars.Summary(); summary != "" {
+		fmt.Printf("  Prompt variables: %s\n", summary)
+	}
 	if n > 0 {
 		fmt.Printf("  Defaults: applied %d filter preset(s)\n", n)
 	}
+	if len(suggestions) > 0 {
+		if mode == controller.BuiltinFilterPresetsAuto {
+			fmt.Printf("  Builtin filters: applied %d preset(s)\n", len(suggestions))
+		} else {
+			fmt.Printf("  Suggested filter presets (apply with `prescribe filter preset apply <id>`):\n")
+		}
+		for _, s := range suggestions {
+			fmt.Printf("    - %s: %s [%s, matches %d file(s)]\n", s.Preset.ID, s.Preset.Name, s.Reason, s.MatchedFiles)
+		}
+	}
 
 	if settings.Save {
 		savePath := settings.Path
@@ -33,7 +33,11 @@ func NewSessionCmd() (*cobra.Command, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to build session token-count command")
 	}
+	setCmd, err := NewSetCobraCommand()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build session set command")
+	}
 
-	cmd.AddCommand(initCmd, saveCmd, loadCmd, showCmd, tokenCountCmd)
+	cmd.AddCommand(initCmd, saveCmd, loadCmd, showCmd, tokenCountCmd, setCmd)
 	return cmd, nil
 }
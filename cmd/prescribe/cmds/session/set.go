@@ -0,0 +1,147 @@
+package session
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-go-golems/glazed/pkg/cli"
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
+	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type SessionSetSettings struct {
+	Preset string `glazed.parameter:"preset"`
+}
+
+type SessionSetCommand struct {
+	*cmds.CommandDescription
+}
+
+var _ cmds.BareCommand = &SessionSetCommand{}
+
+func NewSessionSetCommand() (*SessionSetCommand, error) {
+	repoLayer, err := prescribe_layers.NewRepositoryLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository layer")
+	}
+	repoLayerExisting, err := prescribe_layers.WrapAsExistingCobraFlagsLayer(repoLayer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
+	}
+	promptVarsLayer, err := prescribe_layers.NewPromptVarsLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create prompt variables layer")
+	}
+
+	defaultLayer, err := schema.NewSection(
+		schema.DefaultSlug,
+		"Default",
+		schema.WithFields(
+			fields.New(
+				"preset",
+				fields.TypeString,
+				fields.WithDefault(""),
+				fields.WithHelp("Prompt preset ID to use (its --preset-var values are validated against it)"),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdDesc := cmds.NewCommandDescription(
+		"set",
+		cmds.WithShort("Update prompt template variables"),
+		cmds.WithLong("Update the prompt preset, its parameter values (--preset-var) and the prompt template variables (issue, additional instructions, style switches, custom vars) in session.yaml (only provided flags are applied)."),
+		cmds.WithLayersList(repoLayerExisting, defaultLayer, promptVarsLayer),
+	)
+
+	return &SessionSetCommand{CommandDescription: cmdDesc}, nil
+}
+
+func (c *SessionSetCommand) Run(ctx context.Context, parsedLayers *glazed_layers.ParsedLayers) error {
+	_ = ctx
+
+	settings := &SessionSetSettings{}
+	if err := parsedLayers.InitializeStruct(schema.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "failed to initialize session set settings")
+	}
+
+	ctrl, err := helpers.NewInitializedControllerFromParsedLayers(parsedLayers)
+	if err != nil {
+		return err
+	}
+	helpers.LoadDefaultSessionIfExists(ctrl)
+
+	data := ctrl.GetData()
+	changed, err := prescribe_layers.ApplyPromptVars(parsedLayers, &data.PromptVars)
+	if err != nil {
+		return err
+	}
+	if settings.Preset != "" {
+		if err := ctrl.LoadPromptPreset(settings.Preset); err != nil {
+			return err
+		}
+		changed = true
+	}
+	presetVars, err := prescribe_layers.PresetVarPairs(parsedLayers)
+	if err != nil {
+		return err
+	}
+	if len(presetVars) > 0 {
+		if err := ctrl.SetPresetVars(presetVars); err != nil {
+			return err
+		}
+		changed = true
+	}
+	if !changed {
+		return fmt.Errorf("no prompt variable flags given (see --help)")
+	}
+	// Catch missing required parameters before they break the next generate.
+	if _, err := data.CurrentPreset.ResolveParameters(data.PresetVars); err != nil {
+		return err
+	}
+
+	savePath := ctrl.GetDefaultSessionPath()
+	if err := ctrl.SaveSession(savePath); err != nil {
+		return err
+	}
+	summary := data.PromptVars.Summary()
+	if summary == "" {
+		summary = "defaults"
+	}
+	if data.CurrentPreset != nil {
+		fmt.Printf("Preset: %s\n", data.CurrentPreset.ID)
+	}
+	if params := data.PresetParamsSummary(); params != "" {
+		fmt.Printf("Preset parameters: %s\n", params)
+	}
+	fmt.Printf("Prompt variables: %s\n", summary)
+	fmt.Printf("Session saved: %s\n", savePath)
+	return nil
+}
+
+func NewSetCobraCommand() (*cobra.Command, error) {
+	glazedCmd, err := NewSessionSetCommand()
+	if err != nil {
+		return nil, err
+	}
+
+	cobraCmd, err := cli.BuildCobraCommand(
+		glazedCmd,
+		cli.WithParserConfig(cli.CobraParserConfig{
+			MiddlewaresFunc: cli.CobraCommandDefaultMiddlewares,
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return cobraCmd, nil
+}
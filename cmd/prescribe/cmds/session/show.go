@@ -81,6 +81,20 @@ func (c *SessionShowCommand) RunIntoGlazeProcessor(
 		promptPreview = preview
 	}
 
+	// Output fields beyond the defaults, e.g. "risk_level*, rollout" (* = required).
+	var outputFields any = nil
+	if schema := data.EffectiveOutputSchema(); schema != nil && len(schema.Fields) > 0 {
+		names := make([]string, 0, len(schema.Fields))
+		for _, f := range schema.Fields {
+			name := f.Name
+			if f.Required {
+				name += "*"
+			}
+			names = append(names, name)
+		}
+		outputFields = strings.Join(names, ", ")
+	}
+
 	var prTitle any = nil
 	if strings.TrimSpace(data.Title) != "" {
 		prTitle = strings.TrimSpace(data.Title)
@@ -94,6 +108,16 @@ func (c *SessionShowCommand) RunIntoGlazeProcessor(
 		prDescriptionPreview = preview
 	}
 
+	var promptVars any = nil
+	if summary := data.PromptVars.Summary(); summary != "" {
+		promptVars = summary
+	}
+
+	var presetParams any = nil
+	if summary := data.PresetParamsSummary(); summary != "" {
+		presetParams = summary
+	}
+
 	row := types.NewRow(
 		types.MRP("source_branch", data.SourceBranch),
 		types.MRP("target_branch", data.TargetBranch),
@@ -103,12 +127,18 @@ func (c *SessionShowCommand) RunIntoGlazeProcessor(
 		types.MRP("visible_files", len(visibleFiles)),
 		types.MRP("included_files", includedCount),
 		types.MRP("filtered_files", len(data.GetFilteredFiles())),
+		types.MRP("denied_files", len(data.DeniedFiles)),
 		types.MRP("active_filters", len(data.ActiveFilters)),
 		types.MRP("additional_context_items", len(data.AdditionalContext)),
 		types.MRP("token_count", data.GetTotalTokens()),
 		types.MRP("preset_id", presetID),
 		types.MRP("preset_name", presetName),
+		types.MRP("preset_params", presetParams),
 		types.MRP("prompt_preview", promptPreview),
+		types.MRP("prompt_format", string(data.PromptFormat())),
+		types.MRP("output_mode", string(data.EffectiveOutputMode())),
+		types.MRP("output_fields", outputFields),
+		types.MRP("prompt_vars", promptVars),
 	)
 	return gp.AddRow(ctx, row)
 }
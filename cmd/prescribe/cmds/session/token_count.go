@@ -4,6 +4,8 @@ import (
 	"context"
 	"strings"
 
+	geppettolayers "github.com/go-go-golems/geppetto/pkg/layers"
+	gepsettings "github.com/go-go-golems/geppetto/pkg/steps/ai/settings"
 	"github.com/go-go-golems/glazed/pkg/cli"
 	"github.com/go-go-golems/glazed/pkg/cmds"
 	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
@@ -11,8 +13,8 @@ import (
 	"github.com/go-go-golems/glazed/pkg/middlewares"
 	"github.com/go-go-golems/glazed/pkg/types"
 	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
+	"github.com/go-go-golems/prescribe/internal/controller"
 	"github.com/go-go-golems/prescribe/internal/domain"
-	"github.com/go-go-golems/prescribe/internal/tokens"
 	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -21,6 +23,9 @@ import (
 type SessionTokenCountSettings struct {
 	All             bool `glazed.parameter:"all"`
 	IncludeFiltered bool `glazed.parameter:"include-filtered"`
+	FitBudget       bool `glazed.parameter:"fit-budget"`
+	TokenBudget     int  `glazed.parameter:"token-budget"`
+	Save            bool `glazed.parameter:"save"`
 }
 
 type SessionTokenCountCommand struct {
@@ -51,14 +56,43 @@ func NewSessionTokenCountCommand() (*SessionTokenCountCommand, error) {
 		parameters.WithHelp("Include files that are currently filtered out (in addition to visible files)"),
 		parameters.WithDefault(false),
 	)
+	fitBudgetFlag := parameters.NewParameterDefinition(
+		"fit-budget",
+		parameters.ParameterTypeBool,
+		parameters.WithHelp("Fit the session into the token budget first and report the per-file changes (kind=budget_change)"),
+		parameters.WithDefault(false),
+	)
+	tokenBudgetFlag := parameters.NewParameterDefinition(
+		"token-budget",
+		parameters.ParameterTypeInteger,
+		parameters.WithHelp("With --fit-budget: token budget for files + context (default: session budget.max_tokens)"),
+		parameters.WithDefault(0),
+	)
+	saveFlag := parameters.NewParameterDefinition(
+		"save",
+		parameters.ParameterTypeBool,
+		parameters.WithHelp("With --fit-budget: save the fitted file modes back to the default session"),
+		parameters.WithDefault(false),
+	)
+
+	geppettoLayers, err := geppettolayers.CreateGeppettoLayers()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create geppetto parameter layers")
+	}
+
+	layersList := []glazed_layers.ParameterLayer{
+		repoLayerExisting,
+	}
+	// Used to select the tokenizer (and the model context window for --fit-budget).
+	layersList = append(layersList, geppettoLayers...)
 
 	cmdDesc := cmds.NewCommandDescription(
 		"token-count",
 		cmds.WithShort("Show token breakdown for current session context"),
 		cmds.WithLong("Display a per-element token breakdown for the current session context (included files + additional context)."),
-		cmds.WithFlags(allFlag, includeFilteredFlag),
+		cmds.WithFlags(allFlag, includeFilteredFlag, fitBudgetFlag, tokenBudgetFlag, saveFlag),
 		cmds.WithLayersList(
-			repoLayerExisting,
+			layersList...,
 		),
 	)
 
@@ -66,8 +100,11 @@ func NewSessionTokenCountCommand() (*SessionTokenCountCommand, error) {
 }
 
 func fileModeString(f domain.FileChange) string {
-	if f.Type == domain.FileTypeDiff {
-		return "diff"
+	switch f.Type {
+	case domain.FileTypeDiff, domain.FileTypeDiffReduced, domain.FileTypeNumstat:
+		return string(f.Type)
+	case domain.FileTypeFull:
+		// handled below by version
 	}
 	switch f.Version {
 	case domain.FileVersionBefore:
@@ -84,8 +121,11 @@ func fileModeString(f domain.FileChange) string {
 // effectiveFileContent mirrors the selection logic used when building generation context/prompt vars:
 // prefer after/before content for full-file mode; fall back to diff as a best-effort.
 func effectiveFileContent(f domain.FileChange) (string, string) {
-	if f.Type == domain.FileTypeDiff {
-		return strings.TrimRight(f.Diff, "\n"), "diff"
+	switch f.Type {
+	case domain.FileTypeDiff, domain.FileTypeDiffReduced, domain.FileTypeNumstat:
+		return strings.TrimRight(f.EffectiveDiff(), "\n"), string(f.Type)
+	case domain.FileTypeFull:
+		// handled below by version
 	}
 	// Full-file mode
 	switch f.Version {
@@ -143,10 +183,52 @@ func (c *SessionTokenCountCommand) RunIntoGlazeProcessor(
 	if err != nil {
 		return err
 	}
+	stepSettings, err := gepsettings.NewStepSettingsFromParsedLayers(parsedLayers)
+	if err != nil {
+		return errors.Wrap(err, "failed to build AI step settings from parsed layers")
+	}
+	ctrl.SetStepSettings(stepSettings)
 	helpers.LoadDefaultSessionIfExists(ctrl)
 
+	var budgetReport *controller.BudgetReport
+	if settings.FitBudget {
+		report, err := ctrl.FitResolvedTokenBudget(settings.TokenBudget)
+		if err != nil {
+			return errors.Wrap(err, "failed to fit token budget")
+		}
+		budgetReport = &report
+		if settings.Save {
+			if err := ctrl.SaveSession(ctrl.GetDefaultSessionPath()); err != nil {
+				return errors.Wrap(err, "failed to save session")
+			}
+		}
+	} else if settings.Save {
+		return errors.New("--save requires --fit-budget")
+	}
+
 	data := ctrl.GetData()
-	encoding := tokens.EncodingName()
+	counter := ctrl.TokenCounter()
+	encoding := string(counter.Encoding())
+	tokenizer := counter.Label()
+
+	if budgetReport != nil {
+		for _, ch := range budgetReport.Changes {
+			row := types.NewRow(
+				types.MRP("kind", "budget_change"),
+				types.MRP("encoding", encoding),
+				types.MRP("tokenizer", tokenizer),
+				types.MRP("path", ch.Path),
+				types.MRP("from", string(ch.From)),
+				types.MRP("to", string(ch.To)),
+				types.MRP("tokens_before", ch.TokensBefore),
+				types.MRP("tokens_after", ch.TokensAfter),
+				types.MRP("priority", ch.Priority),
+			)
+			if err := gp.AddRow(ctx, row); err != nil {
+				return err
+			}
+		}
+	}
 
 	visibleFiles := data.GetVisibleFiles()
 	visibleSet := map[string]bool{}
@@ -176,7 +258,7 @@ func (c *SessionTokenCountCommand) RunIntoGlazeProcessor(
 		}
 
 		effContent, effMode := effectiveFileContent(f)
-		effTokens := tokens.Count(effContent)
+		effTokens := counter.Count(effContent)
 
 		// "stored" tokens are what PRData.GetTotalTokens() uses (for visible+included files).
 		// For filtered files, we still report f.Tokens but do not add it to the storedTotal.
@@ -188,6 +270,7 @@ func (c *SessionTokenCountCommand) RunIntoGlazeProcessor(
 		row := types.NewRow(
 			types.MRP("kind", "file"),
 			types.MRP("encoding", encoding),
+			types.MRP("tokenizer", tokenizer),
 			types.MRP("visibility", visibility),
 			types.MRP("path", f.Path),
 			types.MRP("mode", fileModeString(f)),
@@ -208,13 +291,14 @@ func (c *SessionTokenCountCommand) RunIntoGlazeProcessor(
 	// Additional context (always counted in stored total)
 	for i, ac := range data.AdditionalContext {
 		eff := effectiveContextContent(ac)
-		effTokens := tokens.Count(eff)
+		effTokens := counter.Count(eff)
 		storedTotal += ac.Tokens
 		effectiveTotal += effTokens
 
 		row := types.NewRow(
 			types.MRP("kind", "context"),
 			types.MRP("encoding", encoding),
+			types.MRP("tokenizer", tokenizer),
 			types.MRP("context_index", i),
 			types.MRP("context_type", string(ac.Type)),
 			types.MRP("path", ac.Path),
@@ -230,7 +314,7 @@ func (c *SessionTokenCountCommand) RunIntoGlazeProcessor(
 
 	// Git history is currently derived at generation time (not persisted in session.yaml).
 	// To keep token-count aligned with what `generate` will send, we compute it via the canonical request builder.
-	if req, err := ctrl.BuildGenerateDescriptionRequest(); err == nil {
+	if req, err := ctrl.PreviewGenerateDescriptionRequest(); err == nil {
 		for _, ac := range req.AdditionalContext {
 			isDerivedGit := ac.Type == domain.ContextTypeGitHistory ||
 				ac.Type == domain.ContextTypeGitCommit ||
@@ -244,13 +328,14 @@ func (c *SessionTokenCountCommand) RunIntoGlazeProcessor(
 			if strings.TrimSpace(eff) == "" {
 				continue
 			}
-			effTokens := tokens.Count(eff)
+			effTokens := counter.Count(eff)
 			storedTotal += ac.Tokens
 			effectiveTotal += effTokens
 
 			row := types.NewRow(
 				types.MRP("kind", "git_derived"),
 				types.MRP("encoding", encoding),
+				types.MRP("tokenizer", tokenizer),
 				types.MRP("context_type", string(ac.Type)),
 				types.MRP("path", ac.Path),
 				types.MRP("tokens_stored", ac.Tokens),
@@ -268,10 +353,27 @@ func (c *SessionTokenCountCommand) RunIntoGlazeProcessor(
 	summary := types.NewRow(
 		types.MRP("kind", "total"),
 		types.MRP("encoding", encoding),
+		types.MRP("tokenizer", tokenizer),
 		types.MRP("stored_total", storedTotal),
 		types.MRP("effective_total", effectiveTotal),
 		types.MRP("delta", storedTotal-effectiveTotal),
 	)
+	// Cost estimate from the compiled prompt (best-effort; e.g. fails when no files are included).
+	if est, err := ctrl.EstimateCost(); err == nil {
+		summary.Set("prompt_tokens", est.InputTokens)
+		summary.Set("pricing_model", helpers.CostModelLabel(est))
+		if est.Priced {
+			summary.Set("est_input_cost_usd", est.Cost.Input)
+			summary.Set("est_output_cost_usd_max", est.Cost.Output)
+			summary.Set("est_total_cost_usd_max", est.Cost.Total())
+		}
+	}
+	if budgetReport != nil {
+		summary.Set("budget_max_tokens", budgetReport.MaxTokens)
+		summary.Set("budget_source", budgetReport.Source)
+		summary.Set("budget_fits", budgetReport.Fits)
+		summary.Set("budget_changes", len(budgetReport.Changes))
+	}
 	return gp.AddRow(ctx, summary)
 }
 
@@ -5,13 +5,14 @@ import (
 	"os"
 	"strings"
 
+	geppettolayers "github.com/go-go-golems/geppetto/pkg/layers"
 	"github.com/go-go-golems/glazed/pkg/cli"
 	"github.com/go-go-golems/glazed/pkg/cmds"
 	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
 	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
 	"github.com/go-go-golems/glazed/pkg/middlewares"
 	"github.com/go-go-golems/glazed/pkg/types"
-	itokens "github.com/go-go-golems/prescribe/internal/tokens"
+	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
 	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -57,13 +58,24 @@ func NewCountXMLCommand() (*CountXMLCommand, error) {
 		parameters.WithDefault(true),
 	)
 
+	geppettoLayers, err := geppettolayers.CreateGeppettoLayers()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create geppetto parameter layers")
+	}
+
+	layersList := []glazed_layers.ParameterLayer{
+		repoLayerExisting, // accepted for consistency; not used by this command
+	}
+	// Used only to select the tokenizer for the configured provider/model.
+	layersList = append(layersList, geppettoLayers...)
+
 	cmdDesc := cmds.NewCommandDescription(
 		"count-xml",
 		cmds.WithShort("Count tokens per section in an exported XML-ish payload"),
 		cmds.WithLong("Best-effort parser to count tokens per section/tag in an exported XML-ish file (rendered payload or context export)."),
 		cmds.WithFlags(fileFlag, perFileFlag, perItemFlag),
 		cmds.WithLayersList(
-			repoLayerExisting, // accepted for consistency; not used by this command
+			layersList...,
 		),
 	)
 
@@ -168,14 +180,20 @@ func (c *CountXMLCommand) RunIntoGlazeProcessor(
 		return errors.Wrap(err, "failed to read file")
 	}
 	text := string(b)
-	encoding := itokens.EncodingName()
+	counter, err := helpers.TokenCounterFromParsedLayers(parsedLayers)
+	if err != nil {
+		return err
+	}
+	encoding := string(counter.Encoding())
+	tokenizer := counter.Label()
 
 	// Summary row (entire document)
 	_ = gp.AddRow(ctx, types.NewRow(
 		types.MRP("kind", "document"),
 		types.MRP("encoding", encoding),
+		types.MRP("tokenizer", tokenizer),
 		types.MRP("file", settings.File),
-		types.MRP("tokens", itokens.Count(text)),
+		types.MRP("tokens", counter.Count(text)),
 		types.MRP("bytes", len(text)),
 	))
 
@@ -187,9 +205,10 @@ func (c *CountXMLCommand) RunIntoGlazeProcessor(
 			row := types.NewRow(
 				types.MRP("kind", "section"),
 				types.MRP("encoding", encoding),
+				types.MRP("tokenizer", tokenizer),
 				types.MRP("section_tag", tag),
 				types.MRP("section_index", idx),
-				types.MRP("tokens", itokens.Count(sectionText)),
+				types.MRP("tokens", counter.Count(sectionText)),
 				types.MRP("bytes", len(sectionText)),
 			)
 			if err := gp.AddRow(ctx, row); err != nil {
@@ -203,8 +222,9 @@ func (c *CountXMLCommand) RunIntoGlazeProcessor(
 		_ = gp.AddRow(ctx, types.NewRow(
 			types.MRP("kind", "cdata"),
 			types.MRP("encoding", encoding),
+			types.MRP("tokenizer", tokenizer),
 			types.MRP("tag", "system"),
-			types.MRP("tokens", itokens.Count(sys)),
+			types.MRP("tokens", counter.Count(sys)),
 			types.MRP("bytes", len(sys)),
 		))
 	}
@@ -212,8 +232,9 @@ func (c *CountXMLCommand) RunIntoGlazeProcessor(
 		_ = gp.AddRow(ctx, types.NewRow(
 			types.MRP("kind", "cdata"),
 			types.MRP("encoding", encoding),
+			types.MRP("tokenizer", tokenizer),
 			types.MRP("tag", "user"),
-			types.MRP("tokens", itokens.Count(user)),
+			types.MRP("tokens", counter.Count(user)),
 			types.MRP("bytes", len(user)),
 		))
 	}
@@ -237,22 +258,23 @@ func (c *CountXMLCommand) RunIntoGlazeProcessor(
 					d := diffs[0]
 					inner := fileText[d.OpenEnd:d.CloseStart]
 					innerKind = "diff"
-					innerTokens = itokens.Count(inner)
+					innerTokens = counter.Count(inner)
 					innerBytes = len(inner)
 				} else if contents := findTagBlocks(fileText, "content"); len(contents) > 0 {
 					c := contents[0]
 					inner := fileText[c.OpenEnd:c.CloseStart]
 					innerKind = "content"
-					innerTokens = itokens.Count(inner)
+					innerTokens = counter.Count(inner)
 					innerBytes = len(inner)
 				}
 
 				row := types.NewRow(
 					types.MRP("kind", "file"),
 					types.MRP("encoding", encoding),
+					types.MRP("tokenizer", tokenizer),
 					types.MRP("path", path),
 					types.MRP("file_type", ftype),
-					types.MRP("tokens_block", itokens.Count(fileText)),
+					types.MRP("tokens_block", counter.Count(fileText)),
 					types.MRP("bytes_block", len(fileText)),
 					types.MRP("inner_kind", innerKind),
 					types.MRP("tokens_inner", innerTokens),
@@ -284,22 +306,23 @@ func (c *CountXMLCommand) RunIntoGlazeProcessor(
 					t := texts[0]
 					inner := itemText[t.OpenEnd:t.CloseStart]
 					innerKind = "text"
-					innerTokens = itokens.Count(inner)
+					innerTokens = counter.Count(inner)
 					innerBytes = len(inner)
 				} else if contents := findTagBlocks(itemText, "content"); len(contents) > 0 {
 					c := contents[0]
 					inner := itemText[c.OpenEnd:c.CloseStart]
 					innerKind = "content"
-					innerTokens = itokens.Count(inner)
+					innerTokens = counter.Count(inner)
 					innerBytes = len(inner)
 				}
 
 				row := types.NewRow(
 					types.MRP("kind", "item"),
 					types.MRP("encoding", encoding),
+					types.MRP("tokenizer", tokenizer),
 					types.MRP("item_type", itype),
 					types.MRP("path", path),
-					types.MRP("tokens_block", itokens.Count(itemText)),
+					types.MRP("tokens_block", counter.Count(itemText)),
 					types.MRP("bytes_block", len(itemText)),
 					types.MRP("inner_kind", innerKind),
 					types.MRP("tokens_inner", innerTokens),
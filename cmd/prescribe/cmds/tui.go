@@ -4,6 +4,8 @@ import (
 	"context"
 	stderrors "errors"
 	"os"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	geppettolayers "github.com/go-go-golems/geppetto/pkg/layers"
@@ -11,6 +13,7 @@ import (
 	"github.com/go-go-golems/glazed/pkg/cli"
 	"github.com/go-go-golems/glazed/pkg/cmds"
 	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/parameters"
 	"github.com/go-go-golems/prescribe/cmd/prescribe/cmds/helpers"
 	"github.com/go-go-golems/prescribe/internal/tui/app"
 	prescribe_layers "github.com/go-go-golems/prescribe/pkg/layers"
@@ -24,6 +27,13 @@ type TuiCommand struct {
 
 var _ cmds.BareCommand = &TuiCommand{}
 
+type TuiSettings struct {
+	NoCache           bool   `glazed.parameter:"no-cache"`
+	GenerationTimeout string `glazed.parameter:"generation-timeout"`
+	LintRepair        bool   `glazed.parameter:"lint-repair"`
+	Redaction         string `glazed.parameter:"redaction"`
+}
+
 func NewTuiCommand() (*TuiCommand, error) {
 	repoLayer, err := prescribe_layers.NewRepositoryLayer()
 	if err != nil {
@@ -34,13 +44,47 @@ func NewTuiCommand() (*TuiCommand, error) {
 		return nil, errors.Wrap(err, "failed to wrap repository layer as existing flags layer")
 	}
 
+	inferenceLayer, err := prescribe_layers.NewInferenceLayer()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create inference layer")
+	}
+
 	geppettoLayers, err := geppettolayers.CreateGeppettoLayers()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create geppetto parameter layers")
 	}
 
+	noCacheFlag := parameters.NewParameterDefinition(
+		"no-cache",
+		parameters.ParameterTypeBool,
+		parameters.WithHelp("Always run inference, even if an identical request is cached in .pr-builder/cache/"),
+		parameters.WithDefault(false),
+	)
+
+	generationTimeoutFlag := parameters.NewParameterDefinition(
+		"generation-timeout",
+		parameters.ParameterTypeString,
+		parameters.WithHelp("Cancel a generation (including its retries and fallbacks) that runs longer than this (e.g. 5m; 0 disables)"),
+		parameters.WithDefault("10m"),
+	)
+
+	lintRepairFlag := parameters.NewParameterDefinition(
+		"lint-repair",
+		parameters.ParameterTypeBool,
+		parameters.WithHelp("If the lint finds problems in a generated description, ask the model once to fix them (also enabled by lint.repair in the preset)"),
+		parameters.WithDefault(false),
+	)
+
+	redactionFlag := parameters.NewParameterDefinition(
+		"redaction",
+		parameters.ParameterTypeString,
+		parameters.WithHelp("Secret scan of diffs, files and context before they are sent: off, warn, redact or block (default: redaction.mode in .pr-builder/config.yaml, else redact)"),
+		parameters.WithDefault(""),
+	)
+
 	layersList := []glazed_layers.ParameterLayer{
 		repoLayerExisting,
+		inferenceLayer,
 	}
 	layersList = append(layersList, geppettoLayers...)
 
@@ -48,6 +92,7 @@ func NewTuiCommand() (*TuiCommand, error) {
 		"tui",
 		cmds.WithShort("Launch interactive TUI"),
 		cmds.WithLong("Launch the interactive Terminal User Interface for building PR descriptions."),
+		cmds.WithFlags(noCacheFlag, generationTimeoutFlag, lintRepairFlag, redactionFlag),
 		cmds.WithLayersList(
 			layersList...,
 		),
@@ -57,6 +102,19 @@ func NewTuiCommand() (*TuiCommand, error) {
 }
 
 func (c *TuiCommand) Run(ctx context.Context, parsedLayers *glazed_layers.ParsedLayers) error {
+	settings := &TuiSettings{}
+	if err := parsedLayers.InitializeStruct(glazed_layers.DefaultSlug, settings); err != nil {
+		return errors.Wrap(err, "failed to decode tui settings")
+	}
+	generationTimeout := time.Duration(0)
+	if v := strings.TrimSpace(settings.GenerationTimeout); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return errors.Errorf("invalid --generation-timeout %q (expected a duration like 5m)", settings.GenerationTimeout)
+		}
+		generationTimeout = d
+	}
+
 	ctrl, err := helpers.NewInitializedControllerFromParsedLayers(parsedLayers)
 	if err != nil {
 		return err
@@ -67,6 +125,14 @@ func (c *TuiCommand) Run(ctx context.Context, parsedLayers *glazed_layers.Parsed
 		return errors.Wrap(err, "failed to build AI step settings from parsed layers")
 	}
 	ctrl.SetStepSettings(stepSettings)
+	if err := helpers.ConfigureInference(ctrl, parsedLayers, stepSettings); err != nil {
+		return err
+	}
+	ctrl.SetNoCache(settings.NoCache)
+	ctrl.SetLintRepair(settings.LintRepair)
+	if err := helpers.ConfigureRedaction(ctrl, settings.Redaction); err != nil {
+		return err
+	}
 
 	// The TUI requires an initialized, persisted session.
 	// This ensures users explicitly capture their working set (filters + included files) before interacting.
@@ -78,7 +144,8 @@ func (c *TuiCommand) Run(ctx context.Context, parsedLayers *glazed_layers.Parsed
 		return errors.Wrap(err, "failed to load session")
 	}
 
-	p := tea.NewProgram(app.New(ctrl, app.DefaultDeps{}), tea.WithAltScreen())
+	model := app.New(ctrl, app.DefaultDeps{}).WithGenerationTimeout(generationTimeout)
+	p := tea.NewProgram(model, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		return errors.Wrap(err, "failed to run TUI")
 	}
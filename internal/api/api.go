@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/go-go-golems/geppetto/pkg/events"
 	geppettoengine "github.com/go-go-golems/geppetto/pkg/inference/engine"
 	"github.com/go-go-golems/geppetto/pkg/inference/engine/factory"
@@ -14,6 +17,7 @@ import (
 	"github.com/go-go-golems/geppetto/pkg/steps/ai/settings"
 	"github.com/go-go-golems/geppetto/pkg/turns"
 	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/go-go-golems/prescribe/internal/mockengine"
 	"github.com/go-go-golems/prescribe/internal/tokens"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -23,17 +27,141 @@ import (
 // Service provides API operations for generating PR descriptions
 type Service struct {
 	stepSettings *settings.StepSettings
+	// fallbacks are tried in order when inference with stepSettings keeps failing.
+	fallbacks []*settings.StepSettings
+	retry     RetryPolicy
+	// mocks answer inference for engines named "mock" or "mock:<fixture>" (see internal/mockengine).
+	mocks *mockPlayers
+	// recorder appends every inference result to a fixture file (nil = not recording).
+	recorder *mockengine.Recorder
 }
 
 // NewService creates a new API service
 func NewService() *Service {
-	return &Service{}
+	return &Service{retry: DefaultRetryPolicy(), mocks: newMockPlayers()}
 }
 
 // SetStepSettings configures the inference engine settings.
 // Parsing is expected to happen at a higher layer (CLI/TUI), not inside this service.
 func (s *Service) SetStepSettings(stepSettings *settings.StepSettings) {
 	s.stepSettings = stepSettings
+	s.mocks = newMockPlayers()
+}
+
+// SetRecordFixture records every inference result into a mock engine fixture at path,
+// for later replay with --ai-engine replay:<path>. An empty path stops recording.
+func (s *Service) SetRecordFixture(path string) {
+	if path == "" {
+		s.recorder = nil
+		return
+	}
+	s.recorder = mockengine.NewRecorder(path)
+}
+
+// newEngine creates the inference engine for ss: the mock engine if selected, else the provider
+// engine from the geppetto factory. Recording wraps either.
+func (s *Service) newEngine(ss *settings.StepSettings, options ...geppettoengine.Option) (geppettoengine.Engine, error) {
+	var eng geppettoengine.Engine
+	var err error
+	if name := settingsModelName(ss); isMockEngine(name) {
+		var player *mockengine.Player
+		player, err = s.mocks.get(name)
+		if err != nil {
+			return nil, err
+		}
+		eng, err = player.Engine(ss, options...)
+	} else {
+		eng, err = factory.NewEngineFromStepSettings(ss, options...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.recorder != nil {
+		eng = s.recorder.Wrap(eng)
+	}
+	return eng, nil
+}
+
+func isMockEngine(name string) bool {
+	_, ok := mockengine.ParseEngineName(name)
+	return ok
+}
+
+// mockPlayers holds one player per mock engine name. Players are shared by all engines of a
+// service (and its WithTemperature copies), so retries continue with the next fixture response.
+type mockPlayers struct {
+	mu      sync.Mutex
+	players map[string]*mockengine.Player
+}
+
+func newMockPlayers() *mockPlayers {
+	return &mockPlayers{players: map[string]*mockengine.Player{}}
+}
+
+func (m *mockPlayers) get(name string) (*mockengine.Player, error) {
+	if m == nil {
+		return mockengine.NewPlayerForEngine(name)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.players[name]; ok {
+		return p, nil
+	}
+	p, err := mockengine.NewPlayerForEngine(name)
+	if err != nil {
+		return nil, err
+	}
+	m.players[name] = p
+	return p, nil
+}
+
+// WithTemperature returns a service that uses a copy of the step settings (and fallbacks) with
+// the given sampling temperature.
+func (s *Service) WithTemperature(temperature float64) *Service {
+	if s.stepSettings == nil || s.stepSettings.Chat == nil {
+		return s
+	}
+	withTemp := func(ss *settings.StepSettings) *settings.StepSettings {
+		c := ss.Clone()
+		c.Chat.Temperature = &temperature
+		return c
+	}
+	out := *s
+	out.stepSettings = withTemp(s.stepSettings)
+	out.fallbacks = make([]*settings.StepSettings, 0, len(s.fallbacks))
+	for _, fb := range s.fallbacks {
+		out.fallbacks = append(out.fallbacks, withTemp(fb))
+	}
+	return &out
+}
+
+// ModelName returns the configured chat engine (model) name, or "" if none is configured.
+func (s *Service) ModelName() string {
+	return settingsModelName(s.stepSettings)
+}
+
+// Provider returns the configured API type (openai, claude, ...), or "" if none is configured.
+func (s *Service) Provider() string {
+	if s.stepSettings == nil || s.stepSettings.Chat == nil || s.stepSettings.Chat.ApiType == nil {
+		return ""
+	}
+	return string(*s.stepSettings.Chat.ApiType)
+}
+
+// TokenCounter returns the tokenizer matching the configured provider/model (tokens.Default if none).
+func (s *Service) TokenCounter() *tokens.Counter {
+	if s.stepSettings == nil || s.stepSettings.Chat == nil {
+		return tokens.Default()
+	}
+	return tokens.ForModel(s.Provider(), s.ModelName())
+}
+
+// MaxResponseTokens returns the configured output token limit, or 0 if unset.
+func (s *Service) MaxResponseTokens() int {
+	if s.stepSettings == nil || s.stepSettings.Chat == nil || s.stepSettings.Chat.MaxResponseTokens == nil {
+		return 0
+	}
+	return *s.stepSettings.Chat.MaxResponseTokens
 }
 
 // GenerateDescriptionRequest contains the request data for generating a PR description
@@ -47,6 +175,19 @@ type GenerateDescriptionRequest struct {
 	Files             []domain.FileChange
 	AdditionalContext []domain.ContextItem
 	Prompt            string
+	// PromptParts are the sections of a multi-part preset; they replace Prompt when set.
+	PromptParts *domain.PromptParts
+	// PromptVars are the user-set template variables (issue, style switches, custom vars).
+	PromptVars domain.PromptVars
+	// PresetVars are the resolved values of the preset's declared parameters.
+	PresetVars map[string]any
+	// OutputMode selects YAML scraping or a schema-constrained tool call ("" => yaml).
+	OutputMode domain.OutputMode
+	// OutputSchema declares custom/required output fields (nil => the default fields).
+	OutputSchema *domain.OutputSchema
+	// Lint configures the checks of the parsed output (nil => the default checks); with Lint.Repair
+	// the model is asked once to fix what they find.
+	Lint *domain.LintConfig
 }
 
 // GenerateDescriptionResponse contains the generated PR description
@@ -54,148 +195,131 @@ type GenerateDescriptionResponse struct {
 	Description string
 	Parsed      *domain.GeneratedPRData
 	ParseError  string
-	TokensUsed  int
 	Model       string
+	// Usage is the provider-reported token usage summed over all attempts (nil if never reported).
+	Usage *domain.GenerationUsage
+	// StopReason is the stop reason of the attempt whose output was kept.
+	StopReason string
+	// Latency is the wall-clock time of all attempts.
+	Latency time.Duration
+	// Attempts counts inference runs, including retries.
+	Attempts int
+	// AttemptLog lists the attempts (model, error, latency) when there was more than one.
+	AttemptLog []domain.GenerationAttempt
+	// Turn is the conversation that produced Description (kept for refinements).
+	Turn *turns.Turn
+	// OutputMode is the mode that produced Parsed (yaml when a requested tool call fell back to YAML).
+	OutputMode domain.OutputMode
+	// OutputSchema is the schema Parsed was validated against (nil => the default fields).
+	OutputSchema *domain.OutputSchema
+	// CacheKey is the content address of the request (set by callers that use the cache).
+	CacheKey string
+	// Cached is set when the response was served from the cache (no inference ran).
+	Cached bool
+	// LintFindings are the problems the lint found in Parsed (see domain.LintConfig.Lint).
+	LintFindings []domain.LintFinding
+	// LintRepaired is set when Parsed is the answer to the lint repair re-prompt.
+	LintRepaired bool
 }
 
-// GenerateDescription generates a PR description using a real geppetto engine.
-// The engine is created from the configured StepSettings; no parsing happens here.
-func (s *Service) GenerateDescription(ctx context.Context, req GenerateDescriptionRequest) (*GenerateDescriptionResponse, error) {
-	if s.stepSettings == nil {
-		return nil, errors.New("no AI StepSettings configured (configure provider/model flags higher up)")
-	}
-
-	systemPrompt, userPrompt, err := compilePrompt(req)
-	if err != nil {
-		return nil, err
-	}
-
-	// Use Turns directly (no conversation.Manager)
-	seed := turns.NewTurnBuilder().
-		WithSystemPrompt(systemPrompt).
-		WithUserPrompt(userPrompt).
-		Build()
-
-	debugLogTurnSeed(req, seed, systemPrompt, userPrompt)
-
-	eng, err := factory.NewEngineFromStepSettings(s.stepSettings)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create engine from step settings")
-	}
-
-	updatedTurn, err := eng.RunInference(ctx, seed)
-	if err != nil {
-		return nil, errors.Wrap(err, "inference failed")
+// Metadata returns the run metadata in the form persisted next to the PR data.
+func (r *GenerateDescriptionResponse) Metadata() domain.GenerationMetadata {
+	return domain.GenerationMetadata{
+		Model:        r.Model,
+		Usage:        r.Usage,
+		StopReason:   r.StopReason,
+		LatencyMs:    r.Latency.Milliseconds(),
+		Attempts:     r.Attempts,
+		AttemptLog:   r.AttemptLog,
+		OutputMode:   r.OutputMode,
+		OutputSchema: r.OutputSchema,
+		CacheKey:     r.CacheKey,
+		Cached:       r.Cached,
+		LintFindings: r.LintFindings,
+		LintRepaired: r.LintRepaired,
 	}
+}
 
-	description := extractLastAssistantText(updatedTurn)
-	if strings.TrimSpace(description) == "" {
-		// Preserve a minimal signal for callers/debugging
-		description = "<no assistant text produced>"
-	}
+// attemptStats accumulates usage across inference attempts (first run + retries).
+type attemptStats struct {
+	start time.Time
+	usage *domain.GenerationUsage
+	log   []domain.GenerationAttempt
+}
 
-	debugLogAssistantText(updatedTurn, description)
+func newAttemptStats() *attemptStats {
+	return &attemptStats{start: time.Now()}
+}
 
-	var parsed *domain.GeneratedPRData
-	parseErrStr := ""
-	if p, err := ParseGeneratedPRDataFromAssistantText(description); err == nil {
-		parsed = p
-	} else {
-		parsed = p // best-effort: keep partial struct if available
-		parseErrStr = err.Error()
-	}
-	if parsed != nil && !isGeneratedPRDataValid(parsed) {
-		if parseErrStr == "" {
-			parseErrStr = "parsed PR YAML is missing required fields (title/body)"
+// record counts one attempt; t is nil for attempts that failed without a turn.
+func (a *attemptStats) record(t *turns.Turn, attempt domain.GenerationAttempt) {
+	a.log = append(a.log, attempt)
+	if u := usageFromTurn(t); u != nil {
+		if a.usage == nil {
+			a.usage = &domain.GenerationUsage{}
 		}
+		a.usage.Add(*u)
 	}
+}
 
-	// Best-effort retry: if the model likely hit a max token limit and produced invalid/partial YAML,
-	// rerun once with a higher output token budget and a short corrective instruction.
-	if parseErrStr != "" && isLikelyMaxTokensStopReason(getTurnStopReason(updatedTurn)) {
-		retryMax := computeRetryMaxResponseTokens(s.stepSettings)
-		if retryMax > 0 {
-			log.Debug().
-				Str("stop_reason", getTurnStopReason(updatedTurn)).
-				Int("retry_max_response_tokens", retryMax).
-				Msg("api: retrying inference due to invalid YAML + max-tokens stop reason")
-
-			retrySettings := s.stepSettings.Clone()
-			retrySettings.Chat.MaxResponseTokens = &retryMax
-
-			retrySeed := turns.NewTurnBuilder().
-				WithSystemPrompt(systemPrompt).
-				WithUserPrompt(userPrompt + "\n\n" + yamlRepairRetrySuffix()).
-				Build()
-
-			debugLogTurnSeed(req, retrySeed, systemPrompt, userPrompt+"\n\n"+yamlRepairRetrySuffix())
-
-			retryEng, err := factory.NewEngineFromStepSettings(retrySettings)
-			if err != nil {
-				log.Debug().Err(err).Msg("api: retry engine creation failed; keeping first attempt")
-			} else if retryTurn, err := retryEng.RunInference(ctx, retrySeed); err != nil {
-				log.Debug().Err(err).Msg("api: retry inference failed; keeping first attempt")
-			} else {
-				retryDesc := extractLastAssistantText(retryTurn)
-				if strings.TrimSpace(retryDesc) != "" {
-					debugLogAssistantText(retryTurn, retryDesc)
-					rParsed, rErrStr := parseAndValidateGeneratedPRData(retryDesc)
-					if rErrStr == "" {
-						updatedTurn = retryTurn
-						description = retryDesc
-						parsed = rParsed
-						parseErrStr = ""
-					} else {
-						log.Debug().Str("retry_parse_error", rErrStr).Msg("api: retry output still invalid; keeping first attempt")
-					}
-				}
-			}
-		}
+// attemptLog returns the attempts worth reporting: all of them if there was more than one.
+func (a *attemptStats) attemptLog() []domain.GenerationAttempt {
+	if len(a.log) < 2 {
+		return nil
 	}
+	return a.log
+}
 
-	// Best-effort token usage (provider usage might be in Turn.Metadata; we can wire later).
-	tokensUsed := tokens.Count(description)
-
+func newGenerateDescriptionResponse(description string, parsed *domain.GeneratedPRData, parseErr string, t *turns.Turn, stats *attemptStats) *GenerateDescriptionResponse {
 	model := ""
-	if updatedTurn != nil && updatedTurn.Metadata != nil {
-		if v, ok := updatedTurn.Metadata[turns.TurnMetaKeyModel]; ok {
+	if t != nil && t.Metadata != nil {
+		if v, ok := t.Metadata[turns.TurnMetaKeyModel]; ok {
 			if s, ok := v.(string); ok {
 				model = s
 			}
 		}
 	}
-
 	return &GenerateDescriptionResponse{
 		Description: description,
 		Parsed:      parsed,
-		ParseError:  parseErrStr,
-		TokensUsed:  tokensUsed,
+		ParseError:  parseErr,
 		Model:       model,
-	}, nil
+		Usage:       stats.usage,
+		StopReason:  getTurnStopReason(t),
+		Latency:     time.Since(stats.start),
+		Attempts:    len(stats.log),
+		AttemptLog:  stats.attemptLog(),
+		Turn:        t,
+		OutputMode:  domain.OutputModeYAML,
+	}
+}
+
+// GenerateDescription generates a PR description using a real geppetto engine.
+// The engine is created from the configured StepSettings; no parsing happens here.
+func (s *Service) GenerateDescription(ctx context.Context, req GenerateDescriptionRequest) (*GenerateDescriptionResponse, error) {
+	if s.stepSettings == nil {
+		return nil, errors.New("no AI StepSettings configured (configure provider/model flags higher up)")
+	}
+	return s.generate(ctx, req)
 }
 
 // GenerateDescriptionStreaming runs inference with an attached event sink and prints streaming
 // events to the provided writer while still returning the final result.
 //
-// This is intended for stdio streaming (CLI). TUI streaming can re-use the same plumbing with
-// a different router handler.
+// This is intended for stdio streaming (CLI); see GenerateDescriptionWithEventHandler for other
+// consumers.
 func (s *Service) GenerateDescriptionStreaming(ctx context.Context, req GenerateDescriptionRequest, w io.Writer) (*GenerateDescriptionResponse, error) {
+	return s.GenerateDescriptionWithEventHandler(ctx, req, events.StepPrinterFunc("", w))
+}
+
+// GenerateDescriptionWithEventHandler runs inference with an attached event sink and passes every
+// streaming event to handler (a watermill handler on the "chat" topic, e.g. PartialTextHandler)
+// while still returning the final result.
+func (s *Service) GenerateDescriptionWithEventHandler(ctx context.Context, req GenerateDescriptionRequest, handler func(msg *message.Message) error) (*GenerateDescriptionResponse, error) {
 	if s.stepSettings == nil {
 		return nil, errors.New("no AI StepSettings configured (configure provider/model flags higher up)")
 	}
 
-	systemPrompt, userPrompt, err := compilePrompt(req)
-	if err != nil {
-		return nil, err
-	}
-
-	seed := turns.NewTurnBuilder().
-		WithSystemPrompt(systemPrompt).
-		WithUserPrompt(userPrompt).
-		Build()
-
-	debugLogTurnSeed(req, seed, systemPrompt, userPrompt)
-
 	router, err := events.NewEventRouter()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create event router")
@@ -204,44 +328,74 @@ func (s *Service) GenerateDescriptionStreaming(ctx context.Context, req Generate
 		_ = router.Close()
 	}()
 
-	router.AddHandler("chat", "chat", events.StepPrinterFunc("", w))
+	router.AddHandler("chat", "chat", handler)
 
 	watermillSink := middleware.NewWatermillSink(router.Publisher, "chat")
-	eng, err := factory.NewEngineFromStepSettings(s.stepSettings, geppettoengine.WithSink(watermillSink))
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create engine from step settings")
-	}
 
 	eg := errgroup.Group{}
-	ctx, cancel := context.WithCancel(ctx)
+	runCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	eg.Go(func() error {
-		err := router.Run(ctx)
+		err := router.Run(runCtx)
 		if errors.Is(err, context.Canceled) {
 			return nil
 		}
 		return err
 	})
 
-	var updatedTurn *turns.Turn
+	// All attempts (retries, fallbacks and the YAML repair retry) stream through the router, so it
+	// is only stopped once the whole generation is done.
+	var resp *GenerateDescriptionResponse
 	eg.Go(func() error {
 		defer cancel()
 		<-router.Running()
-		t, err := eng.RunInference(ctx, seed)
+		r, err := s.generate(ctx, req, geppettoengine.WithSink(watermillSink))
 		if err != nil {
-			return errors.Wrap(err, "inference failed")
+			return err
 		}
-		updatedTurn = t
+		resp = r
 		return nil
 	})
 
 	if err := eg.Wait(); err != nil {
 		return nil, err
 	}
+	return resp, nil
+}
+
+// generate compiles the prompt, runs inference through the fallback chain with the retry policy,
+// parses the result and, if the output was cut off by the token limit, retries once with a larger
+// budget. options (e.g. event sinks) apply to every attempt.
+func (s *Service) generate(ctx context.Context, req GenerateDescriptionRequest, options ...geppettoengine.Option) (*GenerateDescriptionResponse, error) {
+	systemPrompt, userPrompt, err := compilePrompt(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use Turns directly (no conversation.Manager)
+	seed := turns.NewTurnBuilder().
+		WithSystemPrompt(systemPrompt).
+		WithUserPrompt(userPrompt).
+		Build()
+
+	ctx, err = withOutputMode(ctx, seed, req.OutputMode, req.OutputSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	debugLogTurnSeed(req, seed, systemPrompt, userPrompt)
+
+	chain := s.inferenceChain()
+	stats := newAttemptStats()
+	updatedTurn, used, err := s.runInference(ctx, chain, seed, stats, options...)
+	if err != nil {
+		return nil, errors.Wrap(err, "inference failed")
+	}
 
 	description := extractLastAssistantText(updatedTurn)
 	if strings.TrimSpace(description) == "" {
+		// Preserve a minimal signal for callers/debugging
 		description = "<no assistant text produced>"
 	}
 
@@ -249,30 +403,37 @@ func (s *Service) GenerateDescriptionStreaming(ctx context.Context, req Generate
 
 	var parsed *domain.GeneratedPRData
 	parseErrStr := ""
-	if p, err := ParseGeneratedPRDataFromAssistantText(description); err == nil {
+	outputMode := domain.OutputModeYAML
+	if p, ok := generatedPRDataFromToolCall(updatedTurn); ok && req.OutputMode == domain.OutputModeTool {
+		text, t, err := toolOutputAsYAML(updatedTurn, p)
+		if err != nil {
+			return nil, err
+		}
+		description, updatedTurn, parsed, outputMode = text, t, p, domain.OutputModeTool
+	} else if p, err := ParseGeneratedPRDataFromAssistantText(description); err == nil {
 		parsed = p
 	} else {
 		parsed = p // best-effort: keep partial struct if available
 		parseErrStr = err.Error()
 	}
-	if parsed != nil && !isGeneratedPRDataValid(parsed) {
-		if parseErrStr == "" {
-			parseErrStr = "parsed PR YAML is missing required fields (title/body)"
+	req.OutputSchema.DropUndeclared(parsed)
+	if parsed != nil && parseErrStr == "" {
+		if err := req.OutputSchema.Check(parsed); err != nil {
+			parseErrStr = err.Error()
 		}
 	}
 
-	// Same best-effort retry policy as non-streaming. Note: streaming output to `w` will include
-	// the first attempt's deltas; we still aim to produce a valid final result for stdout + parsed summary.
+	// Best-effort retry: if the model likely hit a max token limit and produced invalid/partial YAML,
+	// rerun once with a higher output token budget and a short corrective instruction, starting
+	// with the model that produced the truncated answer. When streaming, the output includes the
+	// first attempt's deltas; we still aim to produce a valid final result.
 	if parseErrStr != "" && isLikelyMaxTokensStopReason(getTurnStopReason(updatedTurn)) {
-		retryMax := computeRetryMaxResponseTokens(s.stepSettings)
-		if retryMax > 0 {
+		retryChain := withMaxResponseTokens(chain[used:])
+		if len(retryChain) > 0 {
 			log.Debug().
 				Str("stop_reason", getTurnStopReason(updatedTurn)).
-				Int("retry_max_response_tokens", retryMax).
-				Msg("api: retrying inference (streaming) due to invalid YAML + max-tokens stop reason")
-
-			retrySettings := s.stepSettings.Clone()
-			retrySettings.Chat.MaxResponseTokens = &retryMax
+				Int("retry_max_response_tokens", *retryChain[0].Chat.MaxResponseTokens).
+				Msg("api: retrying inference due to invalid YAML + max-tokens stop reason")
 
 			retrySeed := turns.NewTurnBuilder().
 				WithSystemPrompt(systemPrompt).
@@ -281,16 +442,13 @@ func (s *Service) GenerateDescriptionStreaming(ctx context.Context, req Generate
 
 			debugLogTurnSeed(req, retrySeed, systemPrompt, userPrompt+"\n\n"+yamlRepairRetrySuffix())
 
-			retryEng, err := factory.NewEngineFromStepSettings(retrySettings, geppettoengine.WithSink(watermillSink))
-			if err != nil {
-				log.Debug().Err(err).Msg("api: retry engine creation failed; keeping first attempt")
-			} else if retryTurn, err := retryEng.RunInference(ctx, retrySeed); err != nil {
+			if retryTurn, _, err := s.runInference(ctx, retryChain, retrySeed, stats, options...); err != nil {
 				log.Debug().Err(err).Msg("api: retry inference failed; keeping first attempt")
 			} else {
 				retryDesc := extractLastAssistantText(retryTurn)
 				if strings.TrimSpace(retryDesc) != "" {
 					debugLogAssistantText(retryTurn, retryDesc)
-					rParsed, rErrStr := parseAndValidateGeneratedPRData(retryDesc)
+					rParsed, rErrStr := parseAndValidateGeneratedPRData(retryDesc, req.OutputSchema)
 					if rErrStr == "" {
 						updatedTurn = retryTurn
 						description = retryDesc
@@ -304,24 +462,25 @@ func (s *Service) GenerateDescriptionStreaming(ctx context.Context, req Generate
 		}
 	}
 
-	tokensUsed := tokens.Count(description)
-
-	model := ""
-	if updatedTurn != nil && updatedTurn.Metadata != nil {
-		if v, ok := updatedTurn.Metadata[turns.TurnMetaKeyModel]; ok {
-			if s, ok := v.(string); ok {
-				model = s
+	// Lint the parsed output against the change and, if asked to, let the model fix the findings once.
+	var findings []domain.LintFinding
+	repaired := false
+	if parsed != nil && parseErrStr == "" {
+		findings = req.Lint.Lint(parsed, req.Files, req.AdditionalContext)
+		if len(findings) > 0 && req.Lint.RepairEnabled() {
+			if r, ok := s.repairLintFindings(ctx, chain[used:], updatedTurn, req, findings, stats, options...); ok {
+				updatedTurn, description, parsed, findings, outputMode = r.turn, r.text, r.parsed, r.findings, domain.OutputModeYAML
+				repaired = true
 			}
 		}
 	}
 
-	return &GenerateDescriptionResponse{
-		Description: description,
-		Parsed:      parsed,
-		ParseError:  parseErrStr,
-		TokensUsed:  tokensUsed,
-		Model:       model,
-	}, nil
+	resp := newGenerateDescriptionResponse(description, parsed, parseErrStr, updatedTurn, stats)
+	resp.OutputMode = outputMode
+	resp.OutputSchema = req.OutputSchema
+	resp.LintFindings = findings
+	resp.LintRepaired = repaired
+	return resp, nil
 }
 
 func debugLogTurnSeed(req GenerateDescriptionRequest, seed *turns.Turn, systemPrompt, userPrompt string) {
@@ -360,7 +519,6 @@ func debugLogAssistantText(t *turns.Turn, assistantText string) {
 	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(assistantText)))
 	model := ""
 	stopReason := ""
-	var usage *events.Usage
 	if t != nil && t.Metadata != nil {
 		if v, ok := t.Metadata[turns.TurnMetaKeyModel]; ok {
 			if s, ok := v.(string); ok {
@@ -374,32 +532,8 @@ func debugLogAssistantText(t *turns.Turn, assistantText string) {
 				stopReason = fmt.Sprintf("%v", v)
 			}
 		}
-		if v, ok := t.Metadata[turns.TurnMetaKeyUsage]; ok && v != nil {
-			switch u := v.(type) {
-			case *events.Usage:
-				usage = u
-			case events.Usage:
-				uu := u
-				usage = &uu
-			case map[string]any:
-				// tolerate map payloads if they came from serialization boundaries
-				uu := events.Usage{}
-				if x, ok := u["input_tokens"].(int); ok {
-					uu.InputTokens = x
-				} else if x, ok := u["input_tokens"].(int64); ok {
-					uu.InputTokens = int(x)
-				}
-				if x, ok := u["output_tokens"].(int); ok {
-					uu.OutputTokens = x
-				} else if x, ok := u["output_tokens"].(int64); ok {
-					uu.OutputTokens = int(x)
-				}
-				if uu.InputTokens != 0 || uu.OutputTokens != 0 {
-					usage = &uu
-				}
-			}
-		}
 	}
+	usage := usageFromTurn(t)
 	e := log.Debug().
 		Str("model", model).
 		Int("assistant_len", len(assistantText)).
@@ -409,11 +543,68 @@ func debugLogAssistantText(t *turns.Turn, assistantText string) {
 		e = e.Str("stop_reason", stopReason)
 	}
 	if usage != nil {
-		e = e.Int("input_tokens", usage.InputTokens).Int("output_tokens", usage.OutputTokens)
+		e = e.Int("input_tokens", usage.InputTokens).Int("output_tokens", usage.OutputTokens).Int("cached_tokens", usage.CachedTokens).
+			Int("cache_read_tokens", usage.CacheReadTokens).Int("cache_write_tokens", usage.CacheWriteTokens)
 	}
 	e.Msg("api: assistant raw output (last assistant text block)")
 }
 
+// usageFromTurn extracts the provider-reported token usage from the turn metadata.
+func usageFromTurn(t *turns.Turn) *domain.GenerationUsage {
+	if t == nil || t.Metadata == nil {
+		return nil
+	}
+	v, ok := t.Metadata[turns.TurnMetaKeyUsage]
+	if !ok || v == nil {
+		return nil
+	}
+	var usage *events.Usage
+	switch u := v.(type) {
+	case *events.Usage:
+		usage = u
+	case events.Usage:
+		uu := u
+		usage = &uu
+	case map[string]any:
+		// tolerate map payloads if they came from serialization boundaries
+		uu := events.Usage{
+			InputTokens:              intFromAny(u["input_tokens"]),
+			OutputTokens:             intFromAny(u["output_tokens"]),
+			CachedTokens:             intFromAny(u["cached_tokens"]),
+			CacheCreationInputTokens: intFromAny(u["cache_creation_input_tokens"]),
+			CacheReadInputTokens:     intFromAny(u["cache_read_input_tokens"]),
+		}
+		if uu.InputTokens != 0 || uu.OutputTokens != 0 {
+			usage = &uu
+		}
+	}
+	if usage == nil {
+		return nil
+	}
+	// OpenAI counts cached tokens as part of the input; Claude reports cache reads and writes
+	// separately from input_tokens, so they are kept apart.
+	return &domain.GenerationUsage{
+		InputTokens:      usage.InputTokens,
+		OutputTokens:     usage.OutputTokens,
+		CachedTokens:     usage.CachedTokens,
+		CacheReadTokens:  usage.CacheReadInputTokens,
+		CacheWriteTokens: usage.CacheCreationInputTokens,
+	}
+}
+
+func intFromAny(v any) int {
+	switch x := v.(type) {
+	case int:
+		return x
+	case int64:
+		return int(x)
+	case float64:
+		return int(x)
+	default:
+		return 0
+	}
+}
+
 func summarizeForDebug(s string, maxLen int) string {
 	const ellipsis = "\n...\n"
 	if maxLen <= 0 {
@@ -463,6 +654,12 @@ func (s *Service) ValidateRequest(req GenerateDescriptionRequest) error {
 	if !hasIncluded {
 		return fmt.Errorf("at least one file must be included")
 	}
+	if _, err := domain.ParseOutputMode(string(req.OutputMode)); err != nil {
+		return err
+	}
+	if err := req.OutputSchema.Validate(); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -514,8 +711,8 @@ func buildUserContext(req GenerateDescriptionRequest) string {
 			b.WriteString("```text\n")
 			b.WriteString(strings.TrimRight(content, "\n"))
 			b.WriteString("\n```\n\n")
-		case domain.FileTypeDiff:
-			diff := f.Diff
+		case domain.FileTypeDiff, domain.FileTypeDiffReduced, domain.FileTypeNumstat:
+			diff := f.EffectiveDiff()
 			b.WriteString("```diff\n")
 			b.WriteString(strings.TrimRight(diff, "\n"))
 			b.WriteString("\n```\n\n")
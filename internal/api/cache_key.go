@@ -0,0 +1,75 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/pkg/errors"
+)
+
+// cacheKeyVersion is bumped whenever the meaning of cached responses changes (prompt compilation,
+// parsing, ...), so stale entries stop matching.
+const cacheKeyVersion = 1
+
+// cacheKeyInput is everything that determines a generation. API keys and other credentials are
+// deliberately left out.
+type cacheKeyInput struct {
+	Version           int                  `json:"version"`
+	SystemPrompt      string               `json:"system_prompt"`
+	UserPrompt        string               `json:"user_prompt"`
+	Provider          string               `json:"provider"`
+	Model             string               `json:"model"`
+	Temperature       *float64             `json:"temperature,omitempty"`
+	TopP              *float64             `json:"top_p,omitempty"`
+	MaxResponseTokens *int                 `json:"max_response_tokens,omitempty"`
+	Stop              []string             `json:"stop,omitempty"`
+	OutputMode        domain.OutputMode    `json:"output_mode"`
+	OutputSchema      *domain.OutputSchema `json:"output_schema,omitempty"`
+	Lint              *domain.LintConfig   `json:"lint,omitempty"`
+}
+
+// CacheKey returns the content address of req: a sha256 over the compiled system and user prompts
+// plus the model settings that influence the answer.
+func (s *Service) CacheKey(req GenerateDescriptionRequest) (string, error) {
+	systemPrompt, userPrompt, err := compilePrompt(req)
+	if err != nil {
+		return "", err
+	}
+	in := cacheKeyInput{
+		Version:      cacheKeyVersion,
+		SystemPrompt: systemPrompt,
+		UserPrompt:   userPrompt,
+		Provider:     s.Provider(),
+		Model:        s.ModelName(),
+		OutputMode:   req.OutputMode,
+		OutputSchema: req.OutputSchema,
+		Lint:         req.Lint,
+	}
+	if in.OutputMode == "" {
+		in.OutputMode = domain.OutputModeYAML
+	}
+	if s.stepSettings != nil && s.stepSettings.Chat != nil {
+		chat := s.stepSettings.Chat
+		in.Temperature = chat.Temperature
+		in.TopP = chat.TopP
+		in.MaxResponseTokens = chat.MaxResponseTokens
+		in.Stop = chat.Stop
+	}
+	b, err := json.Marshal(in)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal cache key input")
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+}
+
+// PromptHash returns the sha256 of the compiled system and user prompts of req (unlike CacheKey,
+// independent of the model settings).
+func PromptHash(req GenerateDescriptionRequest) (string, error) {
+	systemPrompt, userPrompt, err := compilePrompt(req)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(systemPrompt+"\x00"+userPrompt))), nil
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/go-go-golems/geppetto/pkg/steps/ai/settings"
+	"github.com/go-go-golems/prescribe/internal/domain"
+)
+
+func TestCacheKey(t *testing.T) {
+	ss, err := settings.NewStepSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := "gpt-4o"
+	ss.Chat.Engine = &engine
+	s := NewService()
+	s.SetStepSettings(ss)
+
+	req := GenerateDescriptionRequest{
+		SourceBranch: "feature",
+		TargetBranch: "main",
+		Files:        []domain.FileChange{{Path: "a.go", Diff: "+x", Included: true}},
+		Prompt:       "Describe the change.",
+	}
+	key, err := s.CacheKey(req)
+	if err != nil {
+		t.Fatalf("CacheKey: %v", err)
+	}
+	if len(key) != 64 {
+		t.Fatalf("expected a sha256 hex digest, got %q", key)
+	}
+	if again, _ := s.CacheKey(req); again != key {
+		t.Fatalf("expected a stable key")
+	}
+
+	changed := req
+	changed.Prompt = "Describe the change briefly."
+	if k, _ := s.CacheKey(changed); k == key {
+		t.Fatalf("expected the prompt to change the key")
+	}
+	changed = req
+	changed.OutputMode = domain.OutputModeTool
+	if k, _ := s.CacheKey(changed); k == key {
+		t.Fatalf("expected the output mode to change the key")
+	}
+	if k, _ := s.WithTemperature(0.9).CacheKey(req); k == key {
+		t.Fatalf("expected the temperature to change the key")
+	}
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"strings"
+
+	geppettoengine "github.com/go-go-golems/geppetto/pkg/inference/engine"
+	"github.com/go-go-golems/geppetto/pkg/steps/ai/settings"
+	"github.com/go-go-golems/geppetto/pkg/turns"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// lintRepair is the outcome of a lint repair re-prompt.
+type lintRepair struct {
+	turn     *turns.Turn
+	text     string
+	parsed   *domain.GeneratedPRData
+	findings []domain.LintFinding
+}
+
+// repairLintFindings continues the conversation t with the findings and asks for corrected YAML.
+// The answer is kept (ok) only if it parses, satisfies the schema and has fewer findings; otherwise
+// the original output stands. The attempt is counted in stats either way.
+func (s *Service) repairLintFindings(
+	ctx context.Context,
+	chain []*settings.StepSettings,
+	t *turns.Turn,
+	req GenerateDescriptionRequest,
+	findings []domain.LintFinding,
+	stats *attemptStats,
+	options ...geppettoengine.Option,
+) (lintRepair, bool) {
+	if len(chain) == 0 || t == nil {
+		return lintRepair{}, false
+	}
+	seed := cloneTurn(t)
+	delete(seed.Metadata, turns.TurnMetaKeyUsage)
+	delete(seed.Metadata, turns.TurnMetaKeyStopReason)
+	turns.AppendBlock(seed, turns.NewUserTextBlock(lintRepairPrompt(findings)))
+
+	log.Debug().Int("findings", len(findings)).Msg("api: re-prompting to repair lint findings")
+	repairedTurn, _, err := s.runInference(ctx, chain, seed, stats, options...)
+	if err != nil {
+		log.Debug().Err(err).Msg("api: lint repair inference failed; keeping first answer")
+		return lintRepair{}, false
+	}
+
+	text := extractLastAssistantText(repairedTurn)
+	debugLogAssistantText(repairedTurn, text)
+	parsed, parseErr := parseAndValidateGeneratedPRData(text, req.OutputSchema)
+	if parseErr != "" {
+		log.Debug().Str("parse_error", parseErr).Msg("api: lint repair output invalid; keeping first answer")
+		return lintRepair{}, false
+	}
+	remaining := req.Lint.Lint(parsed, req.Files, req.AdditionalContext)
+	if len(remaining) >= len(findings) {
+		log.Debug().Int("findings", len(remaining)).Msg("api: lint repair did not help; keeping first answer")
+		return lintRepair{}, false
+	}
+	return lintRepair{turn: repairedTurn, text: text, parsed: parsed, findings: remaining}, true
+}
+
+// lintRepairPrompt lists the findings and asks for the corrected YAML.
+func lintRepairPrompt(findings []domain.LintFinding) string {
+	var b strings.Builder
+	b.WriteString("Your PR description has these problems:\n\n")
+	for _, f := range findings {
+		b.WriteString("- " + f.String() + "\n")
+	}
+	b.WriteString(`
+Fix them and output the complete corrected YAML.
+
+Rules:
+- Output the YAML only (no markdown, no code fences, no prose).
+- Keep the same keys as your previous answer.
+- Only mention files, functions and flags that appear in the diff you were given.
+- Replace leftover placeholders with real content or remove them.
+- Keep everything else unchanged.`)
+	return b.String()
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/go-go-golems/prescribe/internal/mockengine"
+)
+
+const lintFirstAnswer = "title: Add refine\nbody: Adds `Controller.Refine` and `internal/ghost/ghost.go`.\n"
+
+func lintRequest(t *testing.T, responses ...mockengine.Response) (*Service, GenerateDescriptionRequest) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fixture.yaml")
+	if err := (&mockengine.Fixture{Responses: responses}).Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	svc := NewService()
+	svc.SetStepSettings(mockStepSettings(t, "replay:"+path, nil))
+
+	req := mockRequest()
+	req.Files = []domain.FileChange{{
+		Path:     "internal/controller/refine.go",
+		Included: true,
+		Type:     domain.FileTypeDiff,
+		Diff:     "+func (c *Controller) Refine(ctx context.Context, instruction string) (string, error) {",
+	}}
+	req.Lint = &domain.LintConfig{Repair: true}
+	return svc, req
+}
+
+func TestService_GenerateDescription_lintRepair(t *testing.T) {
+	svc, req := lintRequest(t,
+		mockengine.Response{Text: lintFirstAnswer},
+		mockengine.Response{Match: "ghost.go", Text: "title: Add refine\nbody: Adds `Controller.Refine`.\n"},
+	)
+
+	resp, err := svc.GenerateDescription(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GenerateDescription: %v", err)
+	}
+	if !resp.LintRepaired || len(resp.LintFindings) != 0 || resp.Attempts != 2 {
+		t.Fatalf("expected the repaired answer, got repaired=%v findings=%v attempts=%d", resp.LintRepaired, resp.LintFindings, resp.Attempts)
+	}
+	if strings.Contains(resp.Parsed.Body, "ghost") {
+		t.Fatalf("expected the repaired body, got %q", resp.Parsed.Body)
+	}
+	if meta := resp.Metadata(); !meta.LintRepaired {
+		t.Fatalf("expected LintRepaired in the metadata")
+	}
+}
+
+func TestService_GenerateDescription_lintRepairKeepsFirstAnswerIfNotBetter(t *testing.T) {
+	svc, req := lintRequest(t,
+		mockengine.Response{Text: lintFirstAnswer},
+		mockengine.Response{Text: "not yaml at all"},
+	)
+
+	resp, err := svc.GenerateDescription(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GenerateDescription: %v", err)
+	}
+	if resp.LintRepaired || resp.Attempts != 2 {
+		t.Fatalf("expected the first answer after a failed repair, got repaired=%v attempts=%d", resp.LintRepaired, resp.Attempts)
+	}
+	if len(resp.LintFindings) != 1 || resp.LintFindings[0].Rule != domain.LintRuleUnknownReference {
+		t.Fatalf("expected the finding of the first answer, got %v", resp.LintFindings)
+	}
+}
+
+func TestService_GenerateDescription_lintWithoutRepair(t *testing.T) {
+	svc, req := lintRequest(t, mockengine.Response{Text: lintFirstAnswer})
+	req.Lint = nil
+
+	resp, err := svc.GenerateDescription(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GenerateDescription: %v", err)
+	}
+	if resp.Attempts != 1 || len(resp.LintFindings) != 1 {
+		t.Fatalf("expected one attempt with one finding, got attempts=%d findings=%v", resp.Attempts, resp.LintFindings)
+	}
+}
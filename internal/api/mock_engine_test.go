@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/geppetto/pkg/steps/ai/settings"
+	"github.com/go-go-golems/prescribe/internal/mockengine"
+)
+
+func mockStepSettings(t *testing.T, engineName string, maxTokens *int) *settings.StepSettings {
+	t.Helper()
+	ss, err := settings.NewStepSettings()
+	if err != nil {
+		t.Fatalf("NewStepSettings: %v", err)
+	}
+	ss.Chat.Engine = &engineName
+	ss.Chat.MaxResponseTokens = maxTokens
+	return ss
+}
+
+func mockRequest() GenerateDescriptionRequest {
+	return GenerateDescriptionRequest{SourceBranch: "feature", TargetBranch: "main", Prompt: "Describe the change."}
+}
+
+// A fixture answer that does not fit the configured max response tokens is truncated on the first
+// attempt (stop reason max_tokens, invalid YAML); the retry raises the budget and succeeds.
+func TestService_GenerateDescription_mockEngineRetriesTruncatedYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.yaml")
+	if err := mockengine.BuiltinFixture().Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	limit := 8
+	svc := NewService()
+	svc.SetStepSettings(mockStepSettings(t, "mock:"+path, &limit))
+
+	resp, err := svc.GenerateDescription(context.Background(), mockRequest())
+	if err != nil {
+		t.Fatalf("GenerateDescription: %v", err)
+	}
+	if resp.Attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", resp.Attempts)
+	}
+	if resp.ParseError != "" || resp.Parsed == nil || resp.Parsed.Title != "Mock PR description" {
+		t.Fatalf("expected the retry to parse, got parsed=%#v err=%q", resp.Parsed, resp.ParseError)
+	}
+	if resp.StopReason != "stop" {
+		t.Fatalf("expected stop reason of the retry, got %q", resp.StopReason)
+	}
+}
+
+func TestService_GenerateDescription_mockEngineError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.yaml")
+	f := &mockengine.Fixture{Responses: []mockengine.Response{{Error: "429 rate limited"}}}
+	if err := f.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	svc := NewService()
+	svc.SetStepSettings(mockStepSettings(t, "replay:"+path, nil))
+	svc.SetRetryPolicy(RetryPolicy{})
+
+	_, err := svc.GenerateDescription(context.Background(), mockRequest())
+	if err == nil || !strings.Contains(err.Error(), "429 rate limited") {
+		t.Fatalf("expected scripted error, got %v", err)
+	}
+}
+
+func TestService_GenerateDescriptionStreaming_mockEngine(t *testing.T) {
+	svc := NewService()
+	svc.SetStepSettings(mockStepSettings(t, "mock", nil))
+
+	var out bytes.Buffer
+	resp, err := svc.GenerateDescriptionStreaming(context.Background(), mockRequest(), &out)
+	if err != nil {
+		t.Fatalf("GenerateDescriptionStreaming: %v", err)
+	}
+	if resp.Parsed == nil || resp.Model != "mock" {
+		t.Fatalf("unexpected response: parsed=%#v model=%q", resp.Parsed, resp.Model)
+	}
+	if !strings.Contains(out.String(), "Mock PR description") {
+		t.Fatalf("expected streamed text, got %q", out.String())
+	}
+}
+
+func TestService_GenerateDescriptionWithEventHandler_partialText(t *testing.T) {
+	svc := NewService()
+	svc.SetStepSettings(mockStepSettings(t, "mock", nil))
+
+	var deltas strings.Builder
+	last := ""
+	resp, err := svc.GenerateDescriptionWithEventHandler(context.Background(), mockRequest(),
+		PartialTextHandler(func(delta, completion string) {
+			deltas.WriteString(delta)
+			last = completion
+		}))
+	if err != nil {
+		t.Fatalf("GenerateDescriptionWithEventHandler: %v", err)
+	}
+	if last == "" || deltas.String() != last {
+		t.Fatalf("expected the deltas to add up to the completion, got %q vs %q", deltas.String(), last)
+	}
+	if resp.Parsed == nil || !strings.Contains(last, resp.Parsed.Title) {
+		t.Fatalf("expected the streamed text to contain the parsed title, got %q", last)
+	}
+}
+
+func TestService_SetStepSettings_missingFixture(t *testing.T) {
+	svc := NewService()
+	svc.SetStepSettings(mockStepSettings(t, "mock:"+filepath.Join(t.TempDir(), "missing.yaml"), nil))
+	if _, err := svc.GenerateDescription(context.Background(), mockRequest()); err == nil {
+		t.Fatalf("expected an error for a missing fixture")
+	}
+}
@@ -74,14 +74,15 @@ func buildTemplateVars(req GenerateDescriptionRequest) map[string]any {
 					})
 				}
 			}
-		case domain.FileTypeDiff:
-			if strings.TrimSpace(f.Diff) != "" {
+		case domain.FileTypeDiff, domain.FileTypeDiffReduced, domain.FileTypeNumstat:
+			if diff := f.EffectiveDiff(); strings.TrimSpace(diff) != "" {
 				// Keep diffs well-delimited per file to avoid “smashed together” ambiguity.
 				// We mirror the XML-ish boundary style used in the export-context separator approach.
 				diffParts = append(diffParts, fmt.Sprintf(
-					"<file name=\"%s\" type=\"diff\">\n<diff>\n%s\n</diff>\n</file>",
+					"<file name=\"%s\" type=\"%s\">\n<diff>\n%s\n</diff>\n</file>",
 					xmlEscapeAttr(f.Path),
-					strings.TrimRight(f.Diff, "\n"),
+					xmlEscapeAttr(string(f.Type)),
+					strings.TrimRight(diff, "\n"),
 				))
 			}
 		}
@@ -128,24 +129,22 @@ func buildTemplateVars(req GenerateDescriptionRequest) map[string]any {
 	diff := strings.TrimSpace(strings.Join(diffParts, "\n\n"))
 	title := strings.TrimSpace(req.Title)
 
-	return map[string]any{
-		// Pinocchio-style prompt variables (subset)
-		"diff":              diff,
-		"code":              codeFiles,
-		"context":           contextFiles,
-		"description":       description,
-		"title":             title,
-		"issue":             "",
-		"commits":           strings.TrimSpace(strings.Join(commitsParts, "\n\n")),
-		"additional_system": "",
-		"additional":        []string{},
-
-		// Defaults from the embedded prompt pack (keep behavior stable)
-		"without_files": true,
-		"concise":       false,
-		"use_bullets":   false,
-		"use_keywords":  false,
+	// User-set variables (issue, style switches, custom vars) and preset parameters first; derived
+	// ones cannot be shadowed.
+	vars := req.PromptVars.TemplateVars()
+	for k, v := range req.PresetVars {
+		if !domain.IsBuiltinPromptVar(k) {
+			vars[k] = v
+		}
 	}
+	// Pinocchio-style prompt variables
+	vars["diff"] = diff
+	vars["code"] = codeFiles
+	vars["context"] = contextFiles
+	vars["description"] = description
+	vars["title"] = title
+	vars["commits"] = strings.TrimSpace(strings.Join(commitsParts, "\n\n"))
+	return vars
 }
 
 // CompilePrompt is an exported wrapper to compile the prompt into the exact (system,user) strings
@@ -170,6 +169,20 @@ func splitCombinedPinocchioPrompt(prompt string) (string, string, bool) {
 }
 
 func compilePrompt(req GenerateDescriptionRequest) (string, string, error) {
+	sys, user, err := compileBasePrompt(req)
+	if err != nil {
+		return "", "", err
+	}
+	if extra := outputSchemaInstructions(req.OutputSchema); extra != "" {
+		user = strings.TrimSpace(user + "\n\n" + extra)
+	}
+	return sys, user, nil
+}
+
+func compileBasePrompt(req GenerateDescriptionRequest) (string, string, error) {
+	if req.PromptParts != nil {
+		return compileMultipartPrompt(req)
+	}
 	combined := strings.TrimSpace(req.Prompt)
 	if combined == "" {
 		return "", buildUserContext(req), nil
@@ -178,7 +191,15 @@ func compilePrompt(req GenerateDescriptionRequest) (string, string, error) {
 	sysTmpl, userTmpl, ok := splitCombinedPinocchioPrompt(combined)
 	if !ok {
 		// Legacy behavior: treat prompt as an already-rendered system prompt and send the context as the user message.
-		return combined, buildUserContext(req), nil
+		// A preset that declares parameters opts into rendering, so its parameters reach the prompt.
+		if len(req.PresetVars) == 0 {
+			return combined, buildUserContext(req), nil
+		}
+		sys, err := renderTemplateString("system-prompt", combined, buildTemplateVars(req))
+		if err != nil {
+			return "", "", errors.Wrap(err, "failed to render system prompt template")
+		}
+		return strings.TrimSpace(sys), buildUserContext(req), nil
 	}
 
 	vars := buildTemplateVars(req)
@@ -193,6 +214,74 @@ func compilePrompt(req GenerateDescriptionRequest) (string, string, error) {
 	return strings.TrimSpace(sys), strings.TrimSpace(user), nil
 }
 
+// compileMultipartPrompt renders the system and user sections of a multi-part preset with its
+// partials, and appends the examples to the system prompt. Without a user section the standard
+// context is sent.
+func compileMultipartPrompt(req GenerateDescriptionRequest) (string, string, error) {
+	parts := req.PromptParts
+	vars := buildTemplateVars(req)
+	defines := parts.PartialDefines()
+
+	sys, err := renderTemplateString("system-prompt", defines+parts.System, vars)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to render system prompt template")
+	}
+	sys = strings.TrimSpace(sys)
+	if examples := parts.FormatExamples(); examples != "" {
+		sys = strings.TrimSpace(sys + "\n\n" + examples)
+	}
+
+	if strings.TrimSpace(parts.User) == "" {
+		return sys, buildUserContext(req), nil
+	}
+	user, err := renderTemplateString("prompt", defines+parts.User, vars)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to render user prompt template")
+	}
+	return sys, strings.TrimSpace(user), nil
+}
+
+// outputSchemaInstructions tells the model about the fields a preset's output schema adds to (or
+// makes required in) the default YAML contract. It is "" for the default schema.
+func outputSchemaInstructions(schema *domain.OutputSchema) string {
+	if schema == nil || len(schema.Fields) == 0 {
+		return ""
+	}
+	lines := []string{"Output schema: in addition to the keys above, the YAML must follow these field rules:"}
+	for _, f := range schema.Fields {
+		kind := "string"
+		if !domain.IsBuiltinOutputField(f.Name) {
+			switch f.FieldType() {
+			case domain.OutputFieldString:
+				kind = "string"
+			case domain.OutputFieldNumber:
+				kind = "number"
+			case domain.OutputFieldBoolean:
+				kind = "boolean"
+			case domain.OutputFieldList:
+				kind = "list of strings"
+			}
+		} else if f.Name == string(domain.PRDataSectionReleaseNotes) {
+			kind = "object with title and body"
+		}
+		if len(f.Enum) > 0 {
+			kind += ", one of: " + strings.Join(f.Enum, ", ")
+		}
+		if f.Required {
+			kind += ", required"
+		} else {
+			kind += ", optional"
+		}
+		line := fmt.Sprintf("- %s (%s)", f.Name, kind)
+		if strings.TrimSpace(f.Description) != "" {
+			line += ": " + strings.TrimSpace(f.Description)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "Put every field at the top level of the YAML, next to title and body.")
+	return strings.Join(lines, "\n")
+}
+
 func xmlEscapeAttr(s string) string {
 	// Minimal XML escaping for attribute safety.
 	s = strings.ReplaceAll(s, "&", "&amp;")
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/go-go-golems/glazed/pkg/helpers/templating"
+	"github.com/go-go-golems/prescribe/internal/domain"
+)
+
+// PromptLintSeverity is the severity of a PromptLintFinding.
+type PromptLintSeverity string
+
+const (
+	// PromptLintError breaks or degrades the rendered prompt (parse errors, unknown variables).
+	PromptLintError PromptLintSeverity = "error"
+	// PromptLintWarning is a likely mistake (a variable that is set but never read).
+	PromptLintWarning PromptLintSeverity = "warning"
+)
+
+// PromptLintFinding is one problem found by LintPrompt.
+type PromptLintFinding struct {
+	Severity PromptLintSeverity
+	// Section is the part of the prompt: "system", "user", "partial <name>" or "prompt".
+	Section string
+	Message string
+}
+
+func (f PromptLintFinding) String() string {
+	return fmt.Sprintf("%s: %s: %s", f.Severity, f.Section, f.Message)
+}
+
+// promptSection is a template of the prompt as compilePrompt renders it.
+type promptSection struct {
+	name string
+	text string
+}
+
+// LintPrompt checks the prompt of req without rendering it: templates that do not parse, calls of
+// undefined templates, references to variables the templates do not receive, and variables set by
+// the user (custom vars, preset parameters, issue, instructions, style switches, language, tone)
+// that no template reads.
+func LintPrompt(req GenerateDescriptionRequest) []PromptLintFinding {
+	findings := []PromptLintFinding{}
+	sections := []promptSection{}
+
+	switch {
+	case req.PromptParts != nil:
+		parts := req.PromptParts
+		names := make([]string, 0, len(parts.Partials))
+		for name := range parts.Partials {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if _, err := templating.CreateTemplate(name).Parse(parts.Partials[name]); err != nil {
+				findings = append(findings, PromptLintFinding{PromptLintError, "partial " + name, err.Error()})
+			}
+		}
+		defines := parts.PartialDefines()
+		sections = append(sections, promptSection{"system", defines + parts.System})
+		if strings.TrimSpace(parts.User) != "" {
+			sections = append(sections, promptSection{"user", defines + parts.User})
+		}
+	default:
+		combined := strings.TrimSpace(req.Prompt)
+		if sys, user, ok := splitCombinedPinocchioPrompt(combined); ok {
+			sections = append(sections, promptSection{"system", sys}, promptSection{"user", user})
+		} else if len(req.PresetVars) > 0 {
+			sections = append(sections, promptSection{"system", combined})
+		} else if strings.Contains(combined, "{{") {
+			findings = append(findings, PromptLintFinding{PromptLintWarning, "prompt",
+				"plain prompts are sent as is, so its {{ ... }} actions are not rendered (add a {{ define \"context\" }} block, use the multi-part format or declare parameters)"})
+		}
+	}
+
+	provided := buildTemplateVars(req)
+	referenced := map[string]bool{}
+	parsed := true
+	for _, section := range sections {
+		tpl, err := templating.CreateTemplate(section.name).Parse(section.text)
+		if err != nil {
+			findings = append(findings, PromptLintFinding{PromptLintError, section.name, err.Error()})
+			parsed = false
+			continue
+		}
+		w := &refWalker{tpl: tpl, refs: map[string]bool{}, walked: map[string]bool{}}
+		w.walk(tpl.Tree.Root, true, true)
+		for _, name := range sortedKeys(w.undefined) {
+			findings = append(findings, PromptLintFinding{PromptLintError, section.name, fmt.Sprintf("template %q is not defined", name)})
+		}
+		refs := w.refs
+		for _, name := range sortedKeys(refs) {
+			referenced[name] = true
+			if _, ok := provided[name]; !ok {
+				findings = append(findings, PromptLintFinding{PromptLintError, section.name, fmt.Sprintf("unknown variable .%s", name)})
+			}
+		}
+	}
+
+	// Which variables are read is only known if every section parsed.
+	if !parsed {
+		return findings
+	}
+	for _, name := range userSetPromptVars(req) {
+		// The tone is usually read through its guidance text.
+		if !referenced[name] && (name != "tone" || !referenced["tone_guidance"]) {
+			findings = append(findings, PromptLintFinding{PromptLintWarning, "prompt", fmt.Sprintf("variable %s is set but the prompt never uses it", name)})
+		}
+	}
+	return findings
+}
+
+// userSetPromptVars returns the variables of req that the user set (sorted).
+func userSetPromptVars(req GenerateDescriptionRequest) []string {
+	names := map[string]bool{}
+	for name := range req.PromptVars.Vars {
+		if !domain.IsBuiltinPromptVar(name) {
+			names[name] = true
+		}
+	}
+	for name := range req.PresetVars {
+		names[name] = true
+	}
+	v := req.PromptVars
+	set := map[string]bool{
+		"issue":             strings.TrimSpace(v.Issue) != "",
+		"additional_system": strings.TrimSpace(v.AdditionalSystem) != "",
+		"additional":        len(v.TemplateVars()["additional"].([]string)) > 0,
+		"without_files":     !v.WithoutFilesOrDefault(),
+		"concise":           v.Concise,
+		"use_bullets":       v.UseBullets,
+		"use_keywords":      v.UseKeywords,
+		"language":          strings.TrimSpace(v.Language) != "",
+		"tone":              strings.TrimSpace(v.Tone) != "",
+	}
+	for name, ok := range set {
+		if ok {
+			names[name] = true
+		}
+	}
+	return sortedKeys(names)
+}
+
+// refWalker collects the top-level variables a template reads, following {{ template }} calls.
+type refWalker struct {
+	tpl       *template.Template
+	refs      map[string]bool
+	undefined map[string]bool
+	// walked records the templates already followed, with ":root" when called with the root data.
+	walked map[string]bool
+}
+
+// walk visits node. dot and dollar tell whether . and $ are the root data (. is rebound inside
+// range and with; both are the call argument inside a called template).
+func (w *refWalker) walk(node parse.Node, dot, dollar bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			w.walk(child, dot, dollar)
+		}
+	case *parse.ActionNode:
+		w.walk(n.Pipe, dot, dollar)
+	case *parse.IfNode:
+		w.walkBranch(&n.BranchNode, dot, dot, dollar)
+	case *parse.RangeNode:
+		w.walkBranch(&n.BranchNode, dot, false, dollar)
+	case *parse.WithNode:
+		w.walkBranch(&n.BranchNode, dot, false, dollar)
+	case *parse.TemplateNode:
+		w.walk(n.Pipe, dot, dollar)
+		w.call(n, dot, dollar)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				w.walk(arg, dot, dollar)
+			}
+		}
+	case *parse.ChainNode:
+		w.walk(n.Node, dot, dollar)
+	case *parse.FieldNode:
+		if dot && len(n.Ident) > 0 {
+			w.refs[n.Ident[0]] = true
+		}
+	case *parse.VariableNode:
+		if dollar && len(n.Ident) > 1 && n.Ident[0] == "$" {
+			w.refs[n.Ident[1]] = true
+		}
+	}
+}
+
+// walkBranch handles if/range/with: the pipeline and the else branch keep dot, the body gets body.
+func (w *refWalker) walkBranch(n *parse.BranchNode, dot, body, dollar bool) {
+	w.walk(n.Pipe, dot, dollar)
+	w.walk(n.List, body, dollar)
+	w.walk(n.ElseList, dot, dollar)
+}
+
+// call follows a {{ template }} call; the called template sees the root data only if the call
+// passes . or $ while they are the root.
+func (w *refWalker) call(n *parse.TemplateNode, dot, dollar bool) {
+	called := w.tpl.Lookup(n.Name)
+	if called == nil || called.Tree == nil {
+		if w.undefined == nil {
+			w.undefined = map[string]bool{}
+		}
+		w.undefined[n.Name] = true
+		return
+	}
+	root := false
+	if n.Pipe != nil && len(n.Pipe.Cmds) == 1 && len(n.Pipe.Cmds[0].Args) == 1 {
+		switch arg := n.Pipe.Cmds[0].Args[0].(type) {
+		case *parse.DotNode:
+			root = dot
+		case *parse.VariableNode:
+			root = dollar && len(arg.Ident) == 1 && arg.Ident[0] == "$"
+		}
+	}
+	key := n.Name
+	if root {
+		key += ":root"
+	}
+	if w.walked[key] {
+		return
+	}
+	w.walked[key] = true
+	w.walk(called.Tree.Root, root, root)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/go-go-golems/prescribe/internal/prompts"
+)
+
+func lintMessages(findings []PromptLintFinding) []string {
+	out := []string{}
+	for _, f := range findings {
+		out = append(out, f.String())
+	}
+	return out
+}
+
+func TestLintPrompt_defaultPromptIsClean(t *testing.T) {
+	req := GenerateDescriptionRequest{
+		Prompt:     prompts.DefaultPrompt(),
+		PromptVars: domain.PromptVars{Issue: "#1", Concise: true, Additional: []string{"x"}, Language: "de", Tone: "reviewers"},
+	}
+	if got := LintPrompt(req); len(got) != 0 {
+		t.Fatalf("expected no findings, got %v", lintMessages(got))
+	}
+}
+
+func TestLintPrompt_reportsUnknownUnusedAndUndefined(t *testing.T) {
+	req := GenerateDescriptionRequest{
+		Prompt: "System.\n{{ define \"context\" }}{{ .dif }} {{ range .code }}{{ .Path }}{{ end }}{{ template \"file\" .title }}{{ template \"nope\" . }}{{ .tone_guidance }}{{ end }}" +
+			"{{ define \"file\" }}{{ .Name }}{{ end }}{{ template \"context\" . }}",
+		PromptVars: domain.PromptVars{Vars: map[string]any{"team": "core"}, Language: "de", Tone: "reviewers"},
+	}
+	want := []string{
+		`error: user: template "nope" is not defined`,
+		"error: user: unknown variable .dif",
+		"warning: prompt: variable language is set but the prompt never uses it",
+		"warning: prompt: variable team is set but the prompt never uses it",
+	}
+	if got := lintMessages(LintPrompt(req)); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestLintPrompt_multipartAndPlain(t *testing.T) {
+	req := GenerateDescriptionRequest{
+		PromptParts: &domain.PromptParts{
+			System:   "{{ template \"rules\" . }}",
+			Partials: map[string]string{"rules": "For {{ .audience }}", "broken": "{{ if }}"},
+		},
+		PresetVars: map[string]any{"audience": "ops", "depth": 2},
+	}
+	got := lintMessages(LintPrompt(req))
+	want := []string{
+		"error: partial broken: template: broken:1: missing value for if",
+		"error: system: template: system:1: missing value for if",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	delete(req.PromptParts.Partials, "broken")
+	got = lintMessages(LintPrompt(req))
+	want = []string{"warning: prompt: variable depth is set but the prompt never uses it"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	plain := GenerateDescriptionRequest{Prompt: "Write for {{ .audience }}."}
+	if got := LintPrompt(plain); len(got) != 1 || got[0].Severity != PromptLintWarning {
+		t.Fatalf("expected a warning about the unrendered plain prompt, got %v", lintMessages(got))
+	}
+}
@@ -145,3 +145,169 @@ func TestCompilePrompt_pinocchioStyleCombinedPrompt_rendersTitleAndDescriptionVa
 		t.Fatalf("expected rendered prompt to contain provided description, got:\n%s", user)
 	}
 }
+
+func TestCompilePrompt_pinocchioStyleCombinedPrompt_rendersPromptVars(t *testing.T) {
+	withoutFiles := false
+	req := GenerateDescriptionRequest{
+		SourceBranch: "feature",
+		TargetBranch: "main",
+		Prompt:       prompts.DefaultPrompt(),
+		Files: []domain.FileChange{
+			{Path: "a.go", Type: domain.FileTypeDiff, Included: true, Diff: "diff --git a/a.go b/a.go\n+added\n"},
+		},
+		PromptVars: domain.PromptVars{
+			Issue:            "#42 crash on start",
+			AdditionalSystem: "Answer in British English.",
+			Additional:       []string{"Mention the migration", " "},
+			WithoutFiles:     &withoutFiles,
+			Concise:          true,
+			UseBullets:       true,
+		},
+	}
+
+	sys, user, err := compilePrompt(req)
+	if err != nil {
+		t.Fatalf("compilePrompt error: %v", err)
+	}
+	if !strings.Contains(sys, "Answer in British English.") {
+		t.Fatalf("expected the additional system prompt, got:\n%s", sys)
+	}
+	for _, want := range []string{"#42 crash on start", "Mention the migration", "Give a concise answer", "Use bullet points in the answer."} {
+		if !strings.Contains(user, want) {
+			t.Fatalf("expected %q in the rendered prompt, got:\n%s", want, user)
+		}
+	}
+	if strings.Contains(user, "Do not mention filenames") || strings.Contains(user, "Use keywords in the answer") {
+		t.Fatalf("expected without_files and use_keywords to be off, got:\n%s", user)
+	}
+}
+
+func TestCompilePrompt_pinocchioStyleCombinedPrompt_rendersLanguageAndTone(t *testing.T) {
+	req := GenerateDescriptionRequest{
+		Prompt: prompts.DefaultPrompt(),
+		Files: []domain.FileChange{
+			{Path: "a.go", Type: domain.FileTypeDiff, Included: true, Diff: "+added\n"},
+		},
+		PromptVars: domain.PromptVars{Language: "de", Tone: "end-users"},
+	}
+
+	_, user, err := compilePrompt(req)
+	if err != nil {
+		t.Fatalf("compilePrompt error: %v", err)
+	}
+	tone, _ := domain.LookupTone("end-users")
+	for _, want := range []string{"release notes in German.", "keep code, identifiers", tone.Guidance} {
+		if !strings.Contains(user, want) {
+			t.Fatalf("expected %q in the rendered prompt, got:\n%s", want, user)
+		}
+	}
+
+	req.PromptVars = domain.PromptVars{}
+	_, user, err = compilePrompt(req)
+	if err != nil {
+		t.Fatalf("compilePrompt error: %v", err)
+	}
+	if strings.Contains(user, "release notes in") || strings.Contains(user, tone.Guidance) {
+		t.Fatalf("expected no language or tone instructions by default, got:\n%s", user)
+	}
+}
+
+func TestCompilePrompt_customVarsCannotShadowDerivedVars(t *testing.T) {
+	req := GenerateDescriptionRequest{
+		Prompt: "System.\n{{ define \"context\" }}team={{ .team }} diff={{ .diff }}{{ end }}{{ template \"context\" . }}",
+		Files: []domain.FileChange{
+			{Path: "a.go", Type: domain.FileTypeDiff, Included: true, Diff: "+real\n"},
+		},
+		PromptVars: domain.PromptVars{Vars: map[string]any{"team": "core", "diff": "fake"}},
+	}
+
+	_, user, err := compilePrompt(req)
+	if err != nil {
+		t.Fatalf("compilePrompt error: %v", err)
+	}
+	if !strings.Contains(user, "team=core") || !strings.Contains(user, "+real") || strings.Contains(user, "fake") {
+		t.Fatalf("expected the custom var and the real diff, got:\n%s", user)
+	}
+	if got := req.PromptVars.ShadowedVars(); len(got) != 1 || got[0] != "diff" {
+		t.Fatalf("expected diff to be reported as shadowed, got %v", got)
+	}
+}
+
+func TestCompilePrompt_rendersPresetVars(t *testing.T) {
+	req := GenerateDescriptionRequest{
+		Prompt: "System.\n{{ define \"context\" }}audience={{ .audience }} depth={{ .depth }} diff={{ .diff }}{{ end }}{{ template \"context\" . }}",
+		Files: []domain.FileChange{
+			{Path: "a.go", Type: domain.FileTypeDiff, Included: true, Diff: "+real\n"},
+		},
+		PresetVars: map[string]any{"audience": "ops", "depth": 2, "diff": "fake"},
+	}
+
+	_, user, err := compilePrompt(req)
+	if err != nil {
+		t.Fatalf("compilePrompt error: %v", err)
+	}
+	if !strings.Contains(user, "audience=ops depth=2") || !strings.Contains(user, "+real") || strings.Contains(user, "fake") {
+		t.Fatalf("expected the preset vars and the real diff, got:\n%s", user)
+	}
+}
+
+func TestCompilePrompt_legacyPromptRenderedOnlyWithPresetVars(t *testing.T) {
+	req := GenerateDescriptionRequest{
+		Prompt: "Write for {{ .audience }} readers.",
+		Files: []domain.FileChange{
+			{Path: "a.go", Type: domain.FileTypeDiff, Included: true, Diff: "+real\n"},
+		},
+	}
+	sys, _, err := compilePrompt(req)
+	if err != nil {
+		t.Fatalf("compilePrompt error: %v", err)
+	}
+	if sys != req.Prompt {
+		t.Fatalf("expected the legacy prompt unchanged, got %q", sys)
+	}
+
+	req.PresetVars = map[string]any{"audience": "ops"}
+	sys, user, err := compilePrompt(req)
+	if err != nil {
+		t.Fatalf("compilePrompt error: %v", err)
+	}
+	if sys != "Write for ops readers." || !strings.Contains(user, "+real") {
+		t.Fatalf("expected the rendered prompt and the context, got %q / %q", sys, user)
+	}
+}
+
+func TestCompilePrompt_multipartPreset(t *testing.T) {
+	req := GenerateDescriptionRequest{
+		Prompt: "ignored {{ define \"context\" }}ignored{{ end }}",
+		PromptParts: &domain.PromptParts{
+			System:   "You write PRs for {{ .audience }}. {{ template \"rules\" . }}",
+			User:     "{{ template \"rules\" . }}\n{{ .diff }}",
+			Examples: []domain.PromptExample{{Input: "+fix typo", Output: "title: Fix typo"}},
+			Partials: map[string]string{"rules": "Be brief."},
+		},
+		Files: []domain.FileChange{
+			{Path: "a.go", Type: domain.FileTypeDiff, Included: true, Diff: "+real\n"},
+		},
+		PresetVars: map[string]any{"audience": "ops"},
+	}
+
+	sys, user, err := compilePrompt(req)
+	if err != nil {
+		t.Fatalf("compilePrompt error: %v", err)
+	}
+	if !strings.HasPrefix(sys, "You write PRs for ops. Be brief.") || !strings.Contains(sys, "<input>\n+fix typo\n</input>") {
+		t.Fatalf("expected the rendered system prompt with the examples, got:\n%s", sys)
+	}
+	if !strings.HasPrefix(user, "Be brief.") || !strings.Contains(user, "+real") || strings.Contains(user, "ignored") {
+		t.Fatalf("expected the rendered user prompt, got:\n%s", user)
+	}
+
+	req.PromptParts.User = ""
+	_, user, err = compilePrompt(req)
+	if err != nil {
+		t.Fatalf("compilePrompt error: %v", err)
+	}
+	if user != buildUserContext(req) {
+		t.Fatalf("expected the standard context without a user section, got:\n%s", user)
+	}
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-go-golems/geppetto/pkg/turns"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/pkg/errors"
+)
+
+// RefineDescription continues the conversation of a previous generation: it appends the
+// instruction as a user block to a copy of t, re-runs inference and re-parses the YAML.
+// The returned response carries the extended turn, so refinements can be chained.
+// The result is validated against schema (the one of the original generation; nil => default fields).
+func (s *Service) RefineDescription(ctx context.Context, t *turns.Turn, instruction string, schema *domain.OutputSchema) (*GenerateDescriptionResponse, error) {
+	if s.stepSettings == nil {
+		return nil, errors.New("no AI StepSettings configured (configure provider/model flags higher up)")
+	}
+	if t == nil {
+		return nil, errors.New("no previous generation to refine")
+	}
+	if strings.TrimSpace(instruction) == "" {
+		return nil, errors.New("refinement instruction is empty")
+	}
+
+	seed := cloneTurn(t)
+	// Drop the previous run's usage/stop reason so they are not attributed to this run.
+	delete(seed.Metadata, turns.TurnMetaKeyUsage)
+	delete(seed.Metadata, turns.TurnMetaKeyStopReason)
+	turns.AppendBlock(seed, turns.NewUserTextBlock(refinementPrompt(instruction)))
+
+	stats := newAttemptStats()
+	updatedTurn, _, err := s.runInference(ctx, s.inferenceChain(), seed, stats)
+	if err != nil {
+		return nil, errors.Wrap(err, "inference failed")
+	}
+
+	description := extractLastAssistantText(updatedTurn)
+	if strings.TrimSpace(description) == "" {
+		description = "<no assistant text produced>"
+	}
+	debugLogAssistantText(updatedTurn, description)
+
+	parsed, parseErrStr := parseAndValidateGeneratedPRData(description, schema)
+	resp := newGenerateDescriptionResponse(description, parsed, parseErrStr, updatedTurn, stats)
+	resp.OutputSchema = schema
+	return resp, nil
+}
+
+// WithAssistantText returns a copy of t whose last assistant text is replaced by text
+// (e.g. after merging candidates), so a refinement continues from what the user kept.
+func WithAssistantText(t *turns.Turn, text string) *turns.Turn {
+	if t == nil {
+		return nil
+	}
+	out := cloneTurn(t)
+	for i := len(out.Blocks) - 1; i >= 0; i-- {
+		b := out.Blocks[i]
+		if b.Kind != turns.BlockKindLLMText || b.Role != turns.RoleAssistant {
+			continue
+		}
+		payload := make(map[string]any, len(b.Payload))
+		for k, v := range b.Payload {
+			payload[k] = v
+		}
+		payload[turns.PayloadKeyText] = text
+		out.Blocks[i].Payload = payload
+		return out
+	}
+	turns.AppendBlock(out, turns.NewAssistantTextBlock(text))
+	return out
+}
+
+// cloneTurn copies the block list and metadata so appending to the copy leaves t untouched.
+func cloneTurn(t *turns.Turn) *turns.Turn {
+	out := &turns.Turn{
+		ID:     t.ID,
+		RunID:  t.RunID,
+		Blocks: append([]turns.Block{}, t.Blocks...),
+	}
+	if t.Metadata != nil {
+		out.Metadata = make(map[turns.TurnMetadataKey]interface{}, len(t.Metadata))
+		for k, v := range t.Metadata {
+			out.Metadata[k] = v
+		}
+	}
+	if t.Data != nil {
+		out.Data = make(map[turns.TurnDataKey]interface{}, len(t.Data))
+		for k, v := range t.Data {
+			out.Data[k] = v
+		}
+	}
+	return out
+}
+
+func refinementPrompt(instruction string) string {
+	return strings.TrimSpace(`
+Revise the PR description you produced above according to this request:
+
+` + strings.TrimSpace(instruction) + `
+
+Rules:
+- Output the complete updated YAML only (no markdown, no code fences, no prose).
+- Keep the same keys as your previous answer (title, body, changelog, release_notes and any others).
+- Keep everything the request does not ask to change.
+`)
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/geppetto/pkg/turns"
+)
+
+func TestWithAssistantTextReplacesLastAssistantTextOnCopy(t *testing.T) {
+	orig := &turns.Turn{}
+	turns.AppendBlock(orig, turns.NewUserTextBlock("describe the PR"))
+	turns.AppendBlock(orig, turns.NewAssistantTextBlock("title: A\nbody: A\n"))
+
+	out := WithAssistantText(orig, "title: B\nbody: B\n")
+	if got := extractLastAssistantText(out); got != "title: B\nbody: B\n" {
+		t.Fatalf("unexpected assistant text %q", got)
+	}
+	if got := extractLastAssistantText(orig); got != "title: A\nbody: A\n" {
+		t.Fatalf("original turn was mutated: %q", got)
+	}
+	if len(out.Blocks) != 2 {
+		t.Fatalf("expected the assistant block to be replaced, got %d blocks", len(out.Blocks))
+	}
+
+	// Without an assistant block, the text is appended.
+	bare := &turns.Turn{}
+	turns.AppendBlock(bare, turns.NewUserTextBlock("describe the PR"))
+	out = WithAssistantText(bare, "title: C\n")
+	if len(out.Blocks) != 2 || len(bare.Blocks) != 1 {
+		t.Fatalf("expected appended block on copy only: out=%d orig=%d", len(out.Blocks), len(bare.Blocks))
+	}
+
+	if WithAssistantText(nil, "x") != nil {
+		t.Fatalf("expected nil for nil turn")
+	}
+}
+
+func TestRefinementPromptIncludesInstruction(t *testing.T) {
+	p := refinementPrompt("  make it shorter  ")
+	if !strings.Contains(p, "\n\nmake it shorter\n\n") {
+		t.Fatalf("instruction not embedded: %q", p)
+	}
+}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	geppettoengine "github.com/go-go-golems/geppetto/pkg/inference/engine"
+	"github.com/go-go-golems/geppetto/pkg/steps/ai/settings"
+	"github.com/go-go-golems/geppetto/pkg/turns"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// RetryPolicy controls how failed inference attempts are retried. Transient errors (rate limits,
+// 5xx responses, timeouts) are retried on the same model with exponential backoff and jitter;
+// once the retries are used up (or on any other error), the next fallback model is tried.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries per model after the first attempt.
+	MaxRetries int
+	// InitialBackoff is the delay before the first retry; it doubles with every retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between retries.
+	MaxBackoff time.Duration
+	// AttemptTimeout bounds a single attempt (0 => no timeout). A timed-out attempt is retried.
+	AttemptTimeout time.Duration
+}
+
+// DefaultRetryPolicy returns the policy used when none is configured.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     2,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+	}
+}
+
+// Backoff returns the delay before retry n (0-based): InitialBackoff * 2^n capped at MaxBackoff,
+// of which the upper half is randomized ("equal jitter") so concurrent clients spread out.
+func (p RetryPolicy) Backoff(n int, rnd func() float64) time.Duration {
+	if p.InitialBackoff <= 0 {
+		return 0
+	}
+	d := p.InitialBackoff
+	for i := 0; i < n && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	half := d / 2
+	return half + time.Duration(rnd()*float64(d-half))
+}
+
+// SetRetryPolicy configures transient-error retries for all inference runs.
+func (s *Service) SetRetryPolicy(p RetryPolicy) {
+	s.retry = p
+}
+
+// SetFallbacks configures the models tried, in order, when the configured one keeps failing.
+// Each entry is a complete set of step settings (e.g. another profile, or the same settings
+// with a cheaper model).
+func (s *Service) SetFallbacks(fallbacks ...*settings.StepSettings) {
+	s.fallbacks = fallbacks
+}
+
+// inferenceChain returns the configured settings followed by the fallbacks.
+func (s *Service) inferenceChain() []*settings.StepSettings {
+	return append([]*settings.StepSettings{s.stepSettings}, s.fallbacks...)
+}
+
+// withMaxResponseTokens returns copies of chain whose output token limit is raised for the
+// YAML repair retry.
+func withMaxResponseTokens(chain []*settings.StepSettings) []*settings.StepSettings {
+	out := make([]*settings.StepSettings, 0, len(chain))
+	for _, ss := range chain {
+		retryMax := computeRetryMaxResponseTokens(ss)
+		if retryMax <= 0 {
+			continue
+		}
+		c := ss.Clone()
+		c.Chat.MaxResponseTokens = &retryMax
+		out = append(out, c)
+	}
+	return out
+}
+
+// runInference runs seed through chain with the service's retry policy and returns the turn of
+// the first successful attempt and the index of the settings that produced it. Each attempt runs
+// on a copy of seed and is recorded in stats. The error of the last attempt is returned when all
+// of them fail.
+func (s *Service) runInference(ctx context.Context, chain []*settings.StepSettings, seed *turns.Turn, stats *attemptStats, options ...geppettoengine.Option) (*turns.Turn, int, error) {
+	if len(chain) == 0 {
+		return nil, -1, errors.New("no AI StepSettings configured (configure provider/model flags higher up)")
+	}
+	policy := s.retry
+	var lastErr error
+	for i, ss := range chain {
+		model := settingsModelName(ss)
+		for retry := 0; ; retry++ {
+			if retry > 0 {
+				delay := policy.Backoff(retry-1, rand.Float64)
+				log.Debug().Err(lastErr).Str("model", model).Int("retry", retry).Dur("backoff", delay).
+					Msg("api: retrying inference after transient error")
+				if err := sleepContext(ctx, delay); err != nil {
+					return nil, -1, err
+				}
+			}
+
+			start := time.Now()
+			t, err := s.runAttempt(ctx, ss, seed, policy.AttemptTimeout, options...)
+			attempt := domain.GenerationAttempt{
+				Model:     model,
+				LatencyMs: time.Since(start).Milliseconds(),
+				Fallback:  i > 0,
+			}
+			if err == nil {
+				if m := turnModel(t); m != "" {
+					attempt.Model = m
+				}
+				stats.record(t, attempt)
+				return t, i, nil
+			}
+			attempt.Error = err.Error()
+			stats.record(nil, attempt)
+			lastErr = err
+
+			if ctx.Err() != nil {
+				// Cancelled by the caller: neither retry nor fall back.
+				return nil, -1, err
+			}
+			if retry >= policy.MaxRetries || !IsTransientError(err) {
+				break
+			}
+		}
+		if i+1 < len(chain) {
+			log.Debug().Err(lastErr).Str("model", model).Str("fallback", settingsModelName(chain[i+1])).
+				Msg("api: inference failed; trying the next fallback model")
+		}
+	}
+	return nil, -1, lastErr
+}
+
+// runAttempt creates a fresh engine for ss and runs one inference on a copy of seed.
+func (s *Service) runAttempt(ctx context.Context, ss *settings.StepSettings, seed *turns.Turn, timeout time.Duration, options ...geppettoengine.Option) (*turns.Turn, error) {
+	eng, err := s.newEngine(ss, options...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create engine from step settings")
+	}
+	attemptCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	t, err := eng.RunInference(attemptCtx, cloneTurn(seed))
+	if err != nil {
+		if ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+			return nil, errors.Wrapf(err, "attempt timed out after %s", timeout)
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+// transientStatusRe matches a retryable HTTP status where the error reports a status ("status code:
+// 429", "status 503", "HTTP 502"), not any number that happens to appear in the message.
+var transientStatusRe = regexp.MustCompile(`\b(?:status(?: code)?|http)[:=]?\s*(408|425|429|500|502|503|504|529)\b`)
+
+// transientMarkers are substrings of provider/network errors that are worth retrying.
+var transientMarkers = []string{
+	"rate limit",
+	"rate_limit",
+	"too many requests",
+	"overloaded",
+	"timeout",
+	"timed out",
+	"temporarily unavailable",
+	"service unavailable",
+	"bad gateway",
+	"internal server error",
+	"connection reset",
+	"connection refused",
+	"unexpected eof",
+}
+
+// IsTransientError reports whether err looks like a rate limit, a server-side error or a timeout,
+// i.e. whether the same request may succeed when retried.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	if transientStatusRe.MatchString(msg) {
+		return true
+	}
+	for _, m := range transientMarkers {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func settingsModelName(ss *settings.StepSettings) string {
+	if ss == nil || ss.Chat == nil || ss.Chat.Engine == nil {
+		return ""
+	}
+	return *ss.Chat.Engine
+}
+
+func turnModel(t *turns.Turn) string {
+	if t == nil || t.Metadata == nil {
+		return ""
+	}
+	if s, ok := t.Metadata[turns.TurnMetaKeyModel].(string); ok {
+		return s
+	}
+	return ""
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-go-golems/prescribe/internal/mockengine"
+	"github.com/pkg/errors"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	cases := []struct {
+		n        int
+		min, max time.Duration
+	}{
+		{0, 500 * time.Millisecond, time.Second},
+		{1, time.Second, 2 * time.Second},
+		{2, 2 * time.Second, 4 * time.Second},
+		{3, 2500 * time.Millisecond, 5 * time.Second}, // capped
+		{40, 2500 * time.Millisecond, 5 * time.Second},
+	}
+	for _, tc := range cases {
+		if got := p.Backoff(tc.n, func() float64 { return 0 }); got != tc.min {
+			t.Fatalf("Backoff(%d) without jitter = %s, want %s", tc.n, got, tc.min)
+		}
+		if got := p.Backoff(tc.n, func() float64 { return 1 }); got != tc.max {
+			t.Fatalf("Backoff(%d) with full jitter = %s, want %s", tc.n, got, tc.max)
+		}
+	}
+	if got := (RetryPolicy{}).Backoff(3, func() float64 { return 1 }); got != 0 {
+		t.Fatalf("expected no backoff without InitialBackoff, got %s", got)
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("error, status code: 429, message: Rate limit reached"), true},
+		{errors.New("POST https://api.anthropic.com: 529 Overloaded"), true},
+		{errors.New("status code: 503, service unavailable"), true},
+		{errors.New("read tcp: connection reset by peer"), true},
+		{errors.Wrap(context.DeadlineExceeded, "attempt timed out after 1s"), true},
+		{errors.Wrap(io.ErrUnexpectedEOF, "stream"), true},
+		{errors.New("HTTP 502 from upstream"), true},
+		{errors.New("status code: 401, invalid api key"), false},
+		{errors.New("status code: 409, conflict"), false},
+		{errors.New("invalid YAML at line 500"), false},
+		{errors.New("prompt has 429 tokens more than the context window allows"), false},
+		{errors.New("request req_503 rejected: invalid model"), false},
+		{errors.New("model gpt-5x does not exist"), false},
+		{context.Canceled, false},
+		{nil, false},
+	}
+	for _, tc := range cases {
+		if got := IsTransientError(tc.err); got != tc.want {
+			t.Fatalf("IsTransientError(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func saveFixture(t *testing.T, responses ...mockengine.Response) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fixture.yaml")
+	if err := (&mockengine.Fixture{Model: "primary", Responses: responses}).Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	return path
+}
+
+var fastRetries = RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+func TestService_GenerateDescription_retriesTransientErrors(t *testing.T) {
+	builtin := mockengine.BuiltinFixture().Responses[0]
+	path := saveFixture(t, mockengine.Response{Error: "status code: 429, rate limited"}, builtin)
+
+	svc := NewService()
+	svc.SetStepSettings(mockStepSettings(t, "mock:"+path, nil))
+	svc.SetRetryPolicy(fastRetries)
+
+	resp, err := svc.GenerateDescription(context.Background(), mockRequest())
+	if err != nil {
+		t.Fatalf("GenerateDescription: %v", err)
+	}
+	if resp.Attempts != 2 || resp.Parsed == nil {
+		t.Fatalf("expected a parsed result after 2 attempts, got %d attempts, parsed=%v", resp.Attempts, resp.Parsed != nil)
+	}
+	log := resp.Metadata().AttemptLog
+	if len(log) != 2 || !strings.Contains(log[0].Error, "429") || log[1].Error != "" || log[1].Fallback {
+		t.Fatalf("unexpected attempt log: %+v", log)
+	}
+}
+
+func TestService_GenerateDescription_fallsBackAfterRetries(t *testing.T) {
+	path := saveFixture(t, mockengine.Response{Error: "503 service unavailable"})
+
+	svc := NewService()
+	svc.SetStepSettings(mockStepSettings(t, "mock:"+path, nil))
+	svc.SetRetryPolicy(fastRetries)
+	svc.SetFallbacks(mockStepSettings(t, "mock", nil))
+
+	resp, err := svc.GenerateDescription(context.Background(), mockRequest())
+	if err != nil {
+		t.Fatalf("GenerateDescription: %v", err)
+	}
+	// 1 attempt + 2 retries on the primary, then the fallback.
+	if resp.Attempts != 4 || resp.Model != "mock" {
+		t.Fatalf("expected the fallback to answer on attempt 4, got %d attempts from %q", resp.Attempts, resp.Model)
+	}
+	last := resp.AttemptLog[len(resp.AttemptLog)-1]
+	if !last.Fallback || last.Error != "" {
+		t.Fatalf("expected a successful fallback attempt, got %+v", last)
+	}
+}
+
+func TestService_GenerateDescription_permanentErrorSkipsRetries(t *testing.T) {
+	path := saveFixture(t, mockengine.Response{Error: "status code: 401, invalid api key"})
+
+	svc := NewService()
+	svc.SetStepSettings(mockStepSettings(t, "mock:"+path, nil))
+	svc.SetRetryPolicy(fastRetries)
+	svc.SetFallbacks(mockStepSettings(t, "mock", nil))
+
+	resp, err := svc.GenerateDescription(context.Background(), mockRequest())
+	if err != nil {
+		t.Fatalf("GenerateDescription: %v", err)
+	}
+	if resp.Attempts != 2 {
+		t.Fatalf("expected no retries before the fallback, got %d attempts", resp.Attempts)
+	}
+
+	svc.SetFallbacks()
+	if _, err := svc.GenerateDescription(context.Background(), mockRequest()); err == nil || !strings.Contains(err.Error(), "invalid api key") {
+		t.Fatalf("expected the last error without fallbacks, got %v", err)
+	}
+}
+
+func TestService_GenerateDescriptionStreaming_attemptTimeout(t *testing.T) {
+	builtin := mockengine.BuiltinFixture().Responses[0]
+	slow := builtin
+	slow.ChunkDelayMs = 50
+	path := saveFixture(t, slow, builtin)
+
+	svc := NewService()
+	svc.SetStepSettings(mockStepSettings(t, "mock:"+path, nil))
+	policy := fastRetries
+	policy.AttemptTimeout = 20 * time.Millisecond
+	svc.SetRetryPolicy(policy)
+
+	resp, err := svc.GenerateDescriptionStreaming(context.Background(), mockRequest(), io.Discard)
+	if err != nil {
+		t.Fatalf("GenerateDescriptionStreaming: %v", err)
+	}
+	if resp.Attempts != 2 || !strings.Contains(resp.AttemptLog[0].Error, "timed out") {
+		t.Fatalf("expected a timed-out first attempt, got %+v", resp.AttemptLog)
+	}
+}
+
+func TestService_GenerateDescriptionStreaming_retriesTruncatedYAML(t *testing.T) {
+	limit := 8
+	svc := NewService()
+	svc.SetStepSettings(mockStepSettings(t, "mock", &limit))
+
+	resp, err := svc.GenerateDescriptionStreaming(context.Background(), mockRequest(), io.Discard)
+	if err != nil {
+		t.Fatalf("GenerateDescriptionStreaming: %v", err)
+	}
+	if resp.Attempts != 2 || resp.ParseError != "" {
+		t.Fatalf("expected the YAML retry to succeed while streaming, got %d attempts, parse error %q", resp.Attempts, resp.ParseError)
+	}
+}
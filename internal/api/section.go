@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-go-golems/geppetto/pkg/events/structuredsink/parsehelpers"
+	geppettoparse "github.com/go-go-golems/geppetto/pkg/steps/parse"
+	"github.com/go-go-golems/geppetto/pkg/turns"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// GenerateSection regenerates a single section of current. The usual context is sent together with
+// the current structured output, and the model is asked to answer with just that one field, so the
+// response stays small. The returned Parsed data is current with only that section replaced;
+// ParseError is set (and Parsed is nil) if the answer did not contain the section.
+func (s *Service) GenerateSection(ctx context.Context, req GenerateDescriptionRequest, current *domain.GeneratedPRData, section domain.PRDataSection) (*GenerateDescriptionResponse, error) {
+	if s.stepSettings == nil {
+		return nil, errors.New("no AI StepSettings configured (configure provider/model flags higher up)")
+	}
+	if current == nil {
+		return nil, errors.New("no generated PR data to update")
+	}
+
+	systemPrompt, userPrompt, err := compilePrompt(req)
+	if err != nil {
+		return nil, err
+	}
+	currentYAML, err := yaml.Marshal(current)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal current PR data")
+	}
+
+	seed := turns.NewTurnBuilder().
+		WithSystemPrompt(systemPrompt).
+		WithUserPrompt(userPrompt).
+		Build()
+	turns.AppendBlock(seed, turns.NewUserTextBlock(sectionPrompt(section, string(currentYAML))))
+
+	debugLogTurnSeed(req, seed, systemPrompt, userPrompt)
+
+	stats := newAttemptStats()
+	updatedTurn, _, err := s.runInference(ctx, s.inferenceChain(), seed, stats)
+	if err != nil {
+		return nil, errors.Wrap(err, "inference failed")
+	}
+
+	answer := extractLastAssistantText(updatedTurn)
+	debugLogAssistantText(updatedTurn, answer)
+
+	partial, err := ParseSectionFromAssistantText(answer, section)
+	if err != nil {
+		return newGenerateDescriptionResponse(answer, nil, err.Error(), updatedTurn, stats), nil
+	}
+
+	merged := *current
+	merged.CopySection(section, partial)
+	b, err := yaml.Marshal(&merged)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal updated PR data")
+	}
+	resp := newGenerateDescriptionResponse(string(b), &merged, "", updatedTurn, stats)
+	resp.OutputSchema = req.OutputSchema
+	return resp, nil
+}
+
+// ParseSectionFromAssistantText extracts a YAML answer that contains (at least) the given section,
+// e.g. "title: ..." or "release_notes: {title, body}". The last fenced YAML block wins; otherwise
+// the whole (optionally fenced) answer is parsed.
+func ParseSectionFromAssistantText(assistantText string, section domain.PRDataSection) (*domain.GeneratedPRData, error) {
+	raw := strings.TrimSpace(assistantText)
+	if raw == "" {
+		return nil, errors.New("empty assistant output")
+	}
+
+	candidates := []string{}
+	if blocks, err := geppettoparse.ExtractYAMLBlocks(raw); err == nil {
+		for i := len(blocks) - 1; i >= 0; i-- {
+			candidates = append(candidates, blocks[i])
+		}
+	}
+	body := raw
+	if strings.HasPrefix(raw, "```") {
+		_, b := parsehelpers.StripCodeFenceBytes([]byte(raw))
+		body = string(b)
+	}
+	candidates = append(candidates, body)
+
+	for _, c := range candidates {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		var out domain.GeneratedPRData
+		if err := yaml.Unmarshal([]byte(repairCommonYAMLFormatting(c)), &out); err != nil {
+			continue
+		}
+		if strings.TrimSpace(out.SectionText(section)) != "" {
+			return &out, nil
+		}
+	}
+	return nil, errors.Errorf("assistant output does not contain a %s section", section)
+}
+
+func sectionPrompt(section domain.PRDataSection, currentYAML string) string {
+	shape := string(section) + ": |\n  ..."
+	if section == domain.PRDataSectionReleaseNotes {
+		shape = "release_notes:\n  title: ...\n  body: |\n    ..."
+	}
+	return strings.TrimSpace(`
+This is the current PR description:
+
+` + strings.TrimSpace(currentYAML) + `
+
+Rewrite only the ` + "`" + string(section) + "`" + ` field, based on the changes above. Answer with YAML that contains just that key:
+
+` + shape + `
+
+No other keys, no markdown fences, no prose.
+`)
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/prescribe/internal/domain"
+)
+
+func TestParseSectionFromAssistantText(t *testing.T) {
+	got, err := ParseSectionFromAssistantText("title: Add section regeneration\n", domain.PRDataSectionTitle)
+	if err != nil {
+		t.Fatalf("title: %v", err)
+	}
+	if got.Title != "Add section regeneration" {
+		t.Fatalf("unexpected title %q", got.Title)
+	}
+
+	fenced := "Here you go:\n\n```yaml\nrelease_notes:\n  title: Faster builds\n  body: |\n    Builds are cached.\n```\n"
+	got, err = ParseSectionFromAssistantText(fenced, domain.PRDataSectionReleaseNotes)
+	if err != nil {
+		t.Fatalf("release notes: %v", err)
+	}
+	if got.ReleaseNotes == nil || got.ReleaseNotes.Title != "Faster builds" || strings.TrimSpace(got.ReleaseNotes.Body) != "Builds are cached." {
+		t.Fatalf("unexpected release notes %+v", got.ReleaseNotes)
+	}
+
+	if _, err := ParseSectionFromAssistantText("title: only a title\n", domain.PRDataSectionChangelog); err == nil {
+		t.Fatalf("expected error when the section is missing")
+	}
+}
+
+func TestSectionPromptIncludesCurrentYAMLAndKey(t *testing.T) {
+	p := sectionPrompt(domain.PRDataSectionChangelog, "title: T\nbody: B\n")
+	if !strings.Contains(p, "title: T\nbody: B") || !strings.Contains(p, "changelog: |") {
+		t.Fatalf("unexpected prompt: %q", p)
+	}
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/go-go-golems/geppetto/pkg/events"
+)
+
+// PartialTextHandler returns an event handler (see GenerateDescriptionWithEventHandler) that
+// calls fn with the delta and the complete text so far for every partial completion event.
+// When an attempt is retried (or a fallback model takes over), completion starts over.
+func PartialTextHandler(fn func(delta, completion string)) func(msg *message.Message) error {
+	return func(msg *message.Message) error {
+		defer msg.Ack()
+
+		e, err := events.NewEventFromJson(msg.Payload)
+		if err != nil {
+			// Not an event we understand; streaming is best-effort.
+			return nil
+		}
+		if p, ok := e.(*events.EventPartialCompletion); ok {
+			fn(p.Delta, p.Completion)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-go-golems/geppetto/pkg/inference/engine"
+	"github.com/go-go-golems/geppetto/pkg/inference/toolcontext"
+	"github.com/go-go-golems/geppetto/pkg/inference/tools"
+	"github.com/go-go-golems/geppetto/pkg/turns"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/invopop/jsonschema"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// prDescriptionToolName is the tool the model calls in domain.OutputModeTool.
+// The tool is never executed: its arguments are the structured output.
+const prDescriptionToolName = "submit_pr_description"
+
+// blockMetaKeyOutputMode tags the blocks withOutputMode adds, so toolOutputAsYAML can drop them.
+const (
+	blockMetaKeyOutputMode  turns.BlockMetadataKey = "prescribe_output_mode"
+	toolInstructionBlockTag                        = "tool_instruction"
+)
+
+// prDescriptionToolDefinition returns the tool whose parameters are the JSON schema of GeneratedPRData,
+// extended with the fields of the preset's output schema (if any).
+func prDescriptionToolDefinition(outputSchema *domain.OutputSchema) tools.ToolDefinition {
+	r := &jsonschema.Reflector{DoNotReference: true, ExpandedStruct: true}
+	schema := r.Reflect(&domain.GeneratedPRData{})
+	// Providers want a plain object schema, not a standalone document.
+	schema.Version = ""
+	schema.ID = ""
+
+	descriptions := map[string]string{
+		"title":         "PR title (one line)",
+		"body":          "PR description body (markdown)",
+		"changelog":     "Changelog entry for the change",
+		"release_notes": "User-facing release notes (title and markdown body)",
+	}
+	if schema.Properties != nil {
+		for pair := schema.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			if d, ok := descriptions[pair.Key]; ok {
+				pair.Value.Description = d
+			}
+		}
+	}
+	addOutputSchemaProperties(schema, outputSchema)
+
+	return tools.ToolDefinition{
+		Name:        prDescriptionToolName,
+		Description: "Submit the generated pull request description.",
+		Parameters:  schema,
+	}
+}
+
+// addOutputSchemaProperties adds the custom fields of outputSchema as properties of schema and marks
+// required fields (built-in or custom) as required.
+func addOutputSchemaProperties(schema *jsonschema.Schema, outputSchema *domain.OutputSchema) {
+	if outputSchema == nil {
+		return
+	}
+	if schema.Properties == nil {
+		schema.Properties = jsonschema.NewProperties()
+	}
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	for _, f := range outputSchema.Fields {
+		if !domain.IsBuiltinOutputField(f.Name) {
+			prop := &jsonschema.Schema{Description: f.Description}
+			switch f.FieldType() {
+			case domain.OutputFieldString:
+				prop.Type = "string"
+				for _, e := range f.Enum {
+					prop.Enum = append(prop.Enum, e)
+				}
+			case domain.OutputFieldNumber:
+				prop.Type = "number"
+			case domain.OutputFieldBoolean:
+				prop.Type = "boolean"
+			case domain.OutputFieldList:
+				prop.Type = "array"
+				prop.Items = &jsonschema.Schema{Type: "string"}
+			}
+			schema.Properties.Set(f.Name, prop)
+		} else if f.Description != "" {
+			if prop, ok := schema.Properties.Get(f.Name); ok {
+				prop.Description = f.Description
+			}
+		}
+		if f.Required && !required[f.Name] {
+			required[f.Name] = true
+			schema.Required = append(schema.Required, f.Name)
+		}
+	}
+}
+
+// withOutputMode prepares the context and seed turn for mode. In tool mode the PR description tool
+// is attached (via the context registry, as geppetto engines expect), a tool call is required and
+// the model is told to submit through the tool instead of writing YAML.
+func withOutputMode(ctx context.Context, seed *turns.Turn, mode domain.OutputMode, schema *domain.OutputSchema) (context.Context, error) {
+	switch mode {
+	case "", domain.OutputModeYAML:
+		return ctx, nil
+	case domain.OutputModeTool:
+		reg := tools.NewInMemoryToolRegistry()
+		if err := reg.RegisterTool(prDescriptionToolName, prDescriptionToolDefinition(schema)); err != nil {
+			return ctx, errors.Wrap(err, "failed to register PR description tool")
+		}
+		if seed.Data == nil {
+			seed.Data = map[turns.TurnDataKey]interface{}{}
+		}
+		seed.Data[turns.DataKeyToolConfig] = engine.ToolConfig{
+			Enabled:          true,
+			ToolChoice:       engine.ToolChoiceRequired,
+			MaxIterations:    1,
+			MaxParallelTools: 1,
+		}
+		turns.AppendBlock(seed, turns.WithBlockMetadata(turns.NewUserTextBlock(
+			"Submit the PR description by calling the `"+prDescriptionToolName+"` tool exactly once, "+
+				"with the fields described above as arguments. Do not write the YAML as text."),
+			map[turns.BlockMetadataKey]interface{}{blockMetaKeyOutputMode: toolInstructionBlockTag}))
+		return toolcontext.WithRegistry(ctx, reg), nil
+	}
+	return ctx, errors.Errorf("unknown output mode %q", mode)
+}
+
+// generatedPRDataFromToolCall returns the PR data from the last valid PR description tool call in t,
+// or false if there is none (the caller then falls back to YAML parsing of the assistant text).
+func generatedPRDataFromToolCall(t *turns.Turn) (*domain.GeneratedPRData, bool) {
+	if t == nil {
+		return nil, false
+	}
+	for i := len(t.Blocks) - 1; i >= 0; i-- {
+		b := t.Blocks[i]
+		if b.Kind != turns.BlockKindToolCall {
+			continue
+		}
+		if name, _ := b.Payload[turns.PayloadKeyName].(string); name != prDescriptionToolName {
+			continue
+		}
+		p, err := generatedPRDataFromToolArgs(b.Payload[turns.PayloadKeyArgs])
+		if err != nil {
+			log.Debug().Err(err).Msg("api: invalid PR description tool call; falling back to YAML")
+			continue
+		}
+		return p, true
+	}
+	return nil, false
+}
+
+func generatedPRDataFromToolArgs(args any) (*domain.GeneratedPRData, error) {
+	var raw []byte
+	switch v := args.(type) {
+	case nil:
+		return nil, errors.New("tool call has no arguments")
+	case string:
+		raw = []byte(v)
+	case json.RawMessage:
+		raw = v
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal tool call arguments")
+		}
+		raw = b
+	}
+	// JSON is valid YAML; decoding through yaml keeps custom schema fields in Extra.
+	var out domain.GeneratedPRData
+	if err := yaml.Unmarshal(raw, &out); err != nil {
+		return nil, errors.Wrap(err, "failed to decode tool call arguments")
+	}
+	if !isGeneratedPRDataValid(&out) {
+		return nil, errors.New("tool call arguments are missing required fields (title/body)")
+	}
+	return &out, nil
+}
+
+// toolOutputAsYAML renders PR data from a tool call as the YAML description, and returns a copy of t
+// where the (never executed) tool call is replaced by that YAML as assistant text and the tool
+// instruction is dropped, so refinements can continue the conversation like in YAML mode.
+func toolOutputAsYAML(t *turns.Turn, p *domain.GeneratedPRData) (string, *turns.Turn, error) {
+	b, err := yaml.Marshal(p)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to marshal PR data from tool call")
+	}
+	out := cloneTurn(t)
+	blocks := out.Blocks[:0:0]
+	for _, blk := range out.Blocks {
+		if blk.Kind == turns.BlockKindToolCall || turns.HasBlockMetadata(blk, blockMetaKeyOutputMode, toolInstructionBlockTag) {
+			continue
+		}
+		blocks = append(blocks, blk)
+	}
+	out.Blocks = blocks
+	delete(out.Data, turns.DataKeyToolConfig)
+	return string(b), WithAssistantText(out, string(b)), nil
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/geppetto/pkg/inference/toolcontext"
+	"github.com/go-go-golems/geppetto/pkg/turns"
+	"github.com/go-go-golems/prescribe/internal/domain"
+)
+
+func TestPRDescriptionToolSchemaMatchesGeneratedPRData(t *testing.T) {
+	def := prDescriptionToolDefinition(nil)
+	if def.Parameters == nil || def.Parameters.Type != "object" {
+		t.Fatalf("expected an object schema, got %+v", def.Parameters)
+	}
+	for _, key := range []string{"title", "body", "changelog", "release_notes"} {
+		if _, ok := def.Parameters.Properties.Get(key); !ok {
+			t.Fatalf("schema is missing property %q", key)
+		}
+	}
+	required := strings.Join(def.Parameters.Required, ",")
+	if required != "title,body,changelog" {
+		t.Fatalf("unexpected required fields %q", required)
+	}
+}
+
+func TestPRDescriptionToolSchemaWithOutputSchema(t *testing.T) {
+	def := prDescriptionToolDefinition(&domain.OutputSchema{Fields: []domain.OutputField{
+		{Name: "risk_level", Required: true, Enum: []string{"low", "high"}},
+		{Name: "affected_services", Type: domain.OutputFieldList},
+		{Name: "release_notes", Required: true},
+	}})
+	risk, ok := def.Parameters.Properties.Get("risk_level")
+	if !ok || risk.Type != "string" || len(risk.Enum) != 2 {
+		t.Fatalf("unexpected risk_level property %+v", risk)
+	}
+	services, ok := def.Parameters.Properties.Get("affected_services")
+	if !ok || services.Type != "array" || services.Items == nil {
+		t.Fatalf("unexpected affected_services property %+v", services)
+	}
+	required := strings.Join(def.Parameters.Required, ",")
+	if required != "title,body,changelog,risk_level,release_notes" {
+		t.Fatalf("unexpected required fields %q", required)
+	}
+
+	tt := &turns.Turn{}
+	turns.AppendBlock(tt, turns.NewToolCallBlock("call_1", prDescriptionToolName,
+		`{"title":"T","body":"B","risk_level":"low","affected_services":["api"]}`))
+	p, ok := generatedPRDataFromToolCall(tt)
+	if !ok || p.Extra["risk_level"] != "low" {
+		t.Fatalf("expected custom fields from tool call, got %+v", p)
+	}
+}
+
+func TestWithOutputModeTool(t *testing.T) {
+	seed := turns.NewTurnBuilder().WithUserPrompt("context").Build()
+	ctx, err := withOutputMode(context.Background(), seed, domain.OutputModeTool, nil)
+	if err != nil {
+		t.Fatalf("withOutputMode: %v", err)
+	}
+	reg, ok := toolcontext.RegistryFrom(ctx)
+	if !ok || len(reg.ListTools()) != 1 || reg.ListTools()[0].Name != prDescriptionToolName {
+		t.Fatalf("expected the PR description tool in the context registry")
+	}
+	if _, ok := seed.Data[turns.DataKeyToolConfig]; !ok {
+		t.Fatalf("expected a tool config on the seed turn")
+	}
+
+	if _, err := withOutputMode(context.Background(), seed, "json", nil); err == nil {
+		t.Fatalf("expected error for unknown output mode")
+	}
+}
+
+func TestToolOutputAsYAML_dropsToolInstruction(t *testing.T) {
+	seed := turns.NewTurnBuilder().WithUserPrompt("context").Build()
+	if _, err := withOutputMode(context.Background(), seed, domain.OutputModeTool, nil); err != nil {
+		t.Fatalf("withOutputMode: %v", err)
+	}
+	turns.AppendBlock(seed, turns.NewToolCallBlock("call_1", prDescriptionToolName, `{"title":"T","body":"B"}`))
+	p, ok := generatedPRDataFromToolCall(seed)
+	if !ok {
+		t.Fatalf("expected PR data from tool call")
+	}
+
+	// Refinements continue from this turn without the tool: it must not ask for a tool call anymore.
+	_, kept, err := toolOutputAsYAML(seed, p)
+	if err != nil {
+		t.Fatalf("toolOutputAsYAML: %v", err)
+	}
+	for _, b := range kept.Blocks {
+		if text, _ := b.Payload[turns.PayloadKeyText].(string); strings.Contains(text, prDescriptionToolName) {
+			t.Fatalf("expected the tool instruction to be dropped, got %q", text)
+		}
+	}
+	if _, ok := kept.Data[turns.DataKeyToolConfig]; ok {
+		t.Fatalf("expected the tool config to be dropped")
+	}
+	if len(kept.Blocks) != 2 || !strings.HasPrefix(extractLastAssistantText(kept), "title: T\nbody: B\n") {
+		t.Fatalf("unexpected turn %+v", kept.Blocks)
+	}
+}
+
+func TestGeneratedPRDataFromToolCall(t *testing.T) {
+	tt := &turns.Turn{}
+	turns.AppendBlock(tt, turns.NewUserTextBlock("describe the PR"))
+	turns.AppendBlock(tt, turns.NewToolCallBlock("call_1", prDescriptionToolName, map[string]any{
+		"title":     "Add tool output mode",
+		"body":      "Uses a tool call instead of YAML scraping.",
+		"changelog": "- tool output mode",
+		"release_notes": map[string]any{
+			"title": "Structured output",
+			"body":  "More reliable parsing.",
+		},
+	}))
+
+	p, ok := generatedPRDataFromToolCall(tt)
+	if !ok {
+		t.Fatalf("expected PR data from tool call")
+	}
+	if p.Title != "Add tool output mode" || p.ReleaseNotes == nil || p.ReleaseNotes.Title != "Structured output" {
+		t.Fatalf("unexpected PR data %+v", p)
+	}
+
+	text, kept, err := toolOutputAsYAML(tt, p)
+	if err != nil {
+		t.Fatalf("toolOutputAsYAML: %v", err)
+	}
+	if !strings.Contains(text, "title: Add tool output mode") {
+		t.Fatalf("unexpected YAML %q", text)
+	}
+	for _, b := range kept.Blocks {
+		if b.Kind == turns.BlockKindToolCall {
+			t.Fatalf("tool call block should be replaced by assistant text")
+		}
+	}
+	if extractLastAssistantText(kept) != text {
+		t.Fatalf("expected the YAML as last assistant text")
+	}
+	if len(tt.Blocks) != 2 {
+		t.Fatalf("original turn was mutated")
+	}
+
+	// Missing body: not a valid submission, callers fall back to YAML.
+	bad := &turns.Turn{}
+	turns.AppendBlock(bad, turns.NewToolCallBlock("call_2", prDescriptionToolName, `{"title":"only a title"}`))
+	if _, ok := generatedPRDataFromToolCall(bad); ok {
+		t.Fatalf("expected invalid tool call to be rejected")
+	}
+}
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-go-golems/geppetto/pkg/turns"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// TranslateDescription translates the title, body, changelog, release notes and the text custom
+// fields of schema (strings without an enum, and lists) of current into language. Code blocks and
+// inline code are replaced by placeholders before the text is sent and put back afterwards, so
+// they come back byte for byte; enum, number and boolean fields are kept as they are. ParseError
+// is set (and Parsed is nil) if the answer does not parse, drops a placeholder or a translated
+// field, or no longer matches schema.
+func (s *Service) TranslateDescription(ctx context.Context, current *domain.GeneratedPRData, language string, schema *domain.OutputSchema) (*GenerateDescriptionResponse, error) {
+	if s.stepSettings == nil {
+		return nil, errors.New("no AI StepSettings configured (configure provider/model flags higher up)")
+	}
+	if current == nil {
+		return nil, errors.New("no generated PR data to translate")
+	}
+	if strings.TrimSpace(language) == "" {
+		return nil, errors.New("no target language")
+	}
+
+	fields := translatableFields(schema, current)
+	masked, segments := protectPRDataCode(current, fields)
+	maskedYAML, err := yaml.Marshal(masked)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal PR data")
+	}
+
+	seed := turns.NewTurnBuilder().
+		WithSystemPrompt(translationSystemPrompt).
+		WithUserPrompt(translationPrompt(language, string(maskedYAML))).
+		Build()
+
+	stats := newAttemptStats()
+	updatedTurn, _, err := s.runInference(ctx, s.inferenceChain(), seed, stats)
+	if err != nil {
+		return nil, errors.Wrap(err, "inference failed")
+	}
+
+	answer := extractLastAssistantText(updatedTurn)
+	debugLogAssistantText(updatedTurn, answer)
+
+	translated, err := ParseGeneratedPRDataFromAssistantText(answer)
+	if err == nil && !isGeneratedPRDataValid(translated) {
+		err = errors.New("translation has no title or body")
+	}
+	if err == nil {
+		translated.Extra, err = mergeTranslatedFields(current.Extra, translated.Extra, fields)
+	}
+	if err == nil {
+		err = restorePRDataCode(translated, segments)
+	}
+	if err == nil {
+		err = schema.Check(translated)
+	}
+	if err != nil {
+		return newGenerateDescriptionResponse(answer, nil, err.Error(), updatedTurn, stats), nil
+	}
+	if current.ReleaseNotes == nil {
+		translated.ReleaseNotes = nil
+	}
+
+	b, err := yaml.Marshal(translated)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal translated PR data")
+	}
+	resp := newGenerateDescriptionResponse(string(b), translated, "", updatedTurn, stats)
+	resp.OutputSchema = schema
+	return resp, nil
+}
+
+// translatableFields returns the custom fields of schema that d has a text value for: strings
+// without an enum (enum values must stay as declared) and lists.
+func translatableFields(schema *domain.OutputSchema, d *domain.GeneratedPRData) []domain.OutputField {
+	out := []domain.OutputField{}
+	for _, f := range schema.CustomFields() {
+		if isEmptyTranslationValue(d.Extra[f.Name]) {
+			continue
+		}
+		switch f.FieldType() {
+		case domain.OutputFieldString:
+			if len(f.Enum) == 0 {
+				out = append(out, f)
+			}
+		case domain.OutputFieldList:
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func isEmptyTranslationValue(v any) bool {
+	switch x := v.(type) {
+	case string:
+		return strings.TrimSpace(x) == ""
+	case []any:
+		return len(x) == 0
+	}
+	return true
+}
+
+// mergeTranslatedFields returns source with the values of fields replaced by their translation.
+// Every translated field must come back.
+func mergeTranslatedFields(source, translated map[string]any, fields []domain.OutputField) (map[string]any, error) {
+	if len(source) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]any, len(source))
+	for k, v := range source {
+		out[k] = v
+	}
+	for _, f := range fields {
+		v, ok := translated[f.Name]
+		if !ok || v == nil {
+			return nil, errors.Errorf("translation dropped field %q", f.Name)
+		}
+		out[f.Name] = v
+	}
+	return out, nil
+}
+
+// protectPRDataCode returns a copy of d (with only the custom fields listed in fields) whose code
+// is replaced by placeholders (see domain.ProtectCode), and the code segments.
+func protectPRDataCode(d *domain.GeneratedPRData, fields []domain.OutputField) (*domain.GeneratedPRData, []string) {
+	var segments []string
+	out := &domain.GeneratedPRData{}
+	out.Title, segments = domain.ProtectCode(d.Title, segments)
+	out.Body, segments = domain.ProtectCode(d.Body, segments)
+	out.Changelog, segments = domain.ProtectCode(d.Changelog, segments)
+	if d.ReleaseNotes != nil {
+		out.ReleaseNotes = &domain.GeneratedPRDataRN{}
+		out.ReleaseNotes.Title, segments = domain.ProtectCode(d.ReleaseNotes.Title, segments)
+		out.ReleaseNotes.Body, segments = domain.ProtectCode(d.ReleaseNotes.Body, segments)
+	}
+	for _, f := range fields {
+		if out.Extra == nil {
+			out.Extra = map[string]any{}
+		}
+		out.Extra[f.Name], segments = protectValueCode(d.Extra[f.Name], segments)
+	}
+	return out, segments
+}
+
+// protectValueCode masks the code of a string, or of the string items of a list.
+func protectValueCode(v any, segments []string) (any, []string) {
+	switch x := v.(type) {
+	case string:
+		return domain.ProtectCode(x, segments)
+	case []any:
+		items := make([]any, len(x))
+		for i, item := range x {
+			items[i], segments = protectValueCode(item, segments)
+		}
+		return items, segments
+	}
+	return v, segments
+}
+
+// restoreValueCode is the inverse of protectValueCode.
+func restoreValueCode(v any, segments []string, used map[int]bool) any {
+	switch x := v.(type) {
+	case string:
+		return domain.RestoreCode(x, segments, used)
+	case []any:
+		items := make([]any, len(x))
+		for i, item := range x {
+			items[i] = restoreValueCode(item, segments, used)
+		}
+		return items
+	}
+	return v
+}
+
+// restorePRDataCode puts the code segments back into d and fails if any of them went missing.
+func restorePRDataCode(d *domain.GeneratedPRData, segments []string) error {
+	used := map[int]bool{}
+	d.Title = domain.RestoreCode(d.Title, segments, used)
+	d.Body = domain.RestoreCode(d.Body, segments, used)
+	d.Changelog = domain.RestoreCode(d.Changelog, segments, used)
+	if d.ReleaseNotes != nil {
+		d.ReleaseNotes.Title = domain.RestoreCode(d.ReleaseNotes.Title, segments, used)
+		d.ReleaseNotes.Body = domain.RestoreCode(d.ReleaseNotes.Body, segments, used)
+	}
+	for k, v := range d.Extra {
+		d.Extra[k] = restoreValueCode(v, segments, used)
+	}
+	missing := []string{}
+	for i := range segments {
+		if !used[i] {
+			missing = append(missing, domain.CodePlaceholder(i))
+		}
+	}
+	if len(missing) > 0 {
+		return errors.Errorf("translation dropped code placeholder(s) %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+const translationSystemPrompt = `You are a translator for software documentation. You translate pull request descriptions, changelog entries and release notes accurately, keeping their meaning, structure and markdown formatting.`
+
+func translationPrompt(language, currentYAML string) string {
+	return strings.TrimSpace(`
+Translate the text values of this pull request description into ` + language + `:
+
+` + strings.TrimSpace(currentYAML) + `
+
+Rules:
+- Keep the YAML keys unchanged, in English.
+- Keep every placeholder like ` + domain.CodePlaceholder(0) + ` exactly as it is, at the matching place in the sentence; each stands for code.
+- Do not translate identifiers, file paths, commands, flags, URLs, issue references or product names.
+- Keep the markdown formatting (lists, headings, links) and the line structure.
+
+Answer with the translated YAML only: no markdown fences, no prose.
+`)
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/go-go-golems/prescribe/internal/mockengine"
+)
+
+func translateService(t *testing.T, responses ...mockengine.Response) *Service {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fixture.yaml")
+	if err := (&mockengine.Fixture{Responses: responses}).Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	svc := NewService()
+	svc.SetStepSettings(mockStepSettings(t, "replay:"+path, nil))
+	return svc
+}
+
+var translateCurrent = &domain.GeneratedPRData{
+	Title: "Add the `--translate` flag",
+	Body:  "Translates the description.\n\n```sh\nprescribe generate --translate\n```\n",
+	Extra: map[string]any{"risk_level": "low"},
+}
+
+func TestService_TranslateDescription_restoresCode(t *testing.T) {
+	// The code is masked in the request, so the answer can only match if it was not sent as is.
+	svc := translateService(t, mockengine.Response{
+		Match: "into German:",
+		Text:  "title: Das Flag @@CODE0@@ hinzufügen\nbody: |\n  Übersetzt die Beschreibung.\n\n  @@CODE1@@\n",
+	})
+
+	resp, err := svc.TranslateDescription(context.Background(), translateCurrent, "German", nil)
+	if err != nil {
+		t.Fatalf("TranslateDescription: %v", err)
+	}
+	if resp.ParseError != "" {
+		t.Fatalf("unexpected parse error: %s", resp.ParseError)
+	}
+	got := resp.Parsed
+	if got.Title != "Das Flag `--translate` hinzufügen" {
+		t.Fatalf("title = %q", got.Title)
+	}
+	if !strings.Contains(got.Body, "```sh\nprescribe generate --translate\n```") || !strings.HasPrefix(got.Body, "Übersetzt") {
+		t.Fatalf("expected the code block to be restored, got:\n%s", got.Body)
+	}
+	if got.Extra["risk_level"] != "low" {
+		t.Fatalf("expected custom fields to be kept, got %v", got.Extra)
+	}
+}
+
+func TestService_TranslateDescription_droppedPlaceholder(t *testing.T) {
+	svc := translateService(t, mockengine.Response{
+		Text: "title: Das Flag hinzufügen\nbody: |\n  Übersetzt die Beschreibung.\n\n  @@CODE1@@\n",
+	})
+
+	resp, err := svc.TranslateDescription(context.Background(), translateCurrent, "German", nil)
+	if err != nil {
+		t.Fatalf("TranslateDescription: %v", err)
+	}
+	if resp.Parsed != nil || !strings.Contains(resp.ParseError, "@@CODE0@@") {
+		t.Fatalf("expected a dropped placeholder error, got parsed=%v err=%q", resp.Parsed, resp.ParseError)
+	}
+}
+
+func TestService_TranslateDescription_customFields(t *testing.T) {
+	schema := &domain.OutputSchema{Fields: []domain.OutputField{
+		{Name: "risk_level", Enum: []string{"low", "high"}},
+		{Name: "summary", Required: true},
+		{Name: "steps", Type: domain.OutputFieldList},
+		{Name: "score", Type: domain.OutputFieldNumber},
+	}}
+	current := &domain.GeneratedPRData{
+		Title: "Add a flag",
+		Body:  "Adds a flag.",
+		Extra: map[string]any{
+			"risk_level": "low",
+			"summary":    "Small change",
+			"steps":      []any{"Run `make test`", "Deploy"},
+			"score":      3,
+		},
+	}
+	// Only the text fields are sent; the enum and the number are not part of the request.
+	svc := translateService(t,
+		mockengine.Response{
+			Match: "summary: Small change",
+			Text: "title: Ein Flag hinzufügen\nbody: Fügt ein Flag hinzu.\nsummary: Kleine Änderung\n" +
+				"steps:\n  - '@@CODE0@@ ausführen'\n  - Ausrollen\n",
+		},
+	)
+
+	resp, err := svc.TranslateDescription(context.Background(), current, "German", schema)
+	if err != nil {
+		t.Fatalf("TranslateDescription: %v", err)
+	}
+	if resp.ParseError != "" {
+		t.Fatalf("unexpected parse error: %s", resp.ParseError)
+	}
+	got := resp.Parsed.Extra
+	if got["summary"] != "Kleine Änderung" || got["risk_level"] != "low" || got["score"] != 3 {
+		t.Fatalf("unexpected custom fields %v", got)
+	}
+	steps, _ := got["steps"].([]any)
+	if len(steps) != 2 || steps[0] != "`make test` ausführen" || steps[1] != "Ausrollen" {
+		t.Fatalf("expected the list to be translated with its code restored, got %v", got["steps"])
+	}
+
+	// A translated field missing from the answer fails the translation.
+	svc = translateService(t, mockengine.Response{Text: "title: Ein Flag hinzufügen\nbody: Fügt ein Flag hinzu.\nsummary: Kleine Änderung\n"})
+	resp, err = svc.TranslateDescription(context.Background(), current, "German", schema)
+	if err != nil {
+		t.Fatalf("TranslateDescription: %v", err)
+	}
+	if resp.Parsed != nil || !strings.Contains(resp.ParseError, `"steps"`) {
+		t.Fatalf("expected a dropped field error, got parsed=%v err=%q", resp.Parsed, resp.ParseError)
+	}
+}
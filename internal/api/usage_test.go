@@ -0,0 +1,71 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/go-go-golems/geppetto/pkg/events"
+	"github.com/go-go-golems/geppetto/pkg/turns"
+	"github.com/go-go-golems/prescribe/internal/domain"
+)
+
+func TestAttemptStatsSumsUsageAcrossRetries(t *testing.T) {
+	mk := func(in, out, cached int) *turns.Turn {
+		return &turns.Turn{Metadata: map[turns.TurnMetadataKey]interface{}{
+			turns.TurnMetaKeyModel:      "gpt-4o",
+			turns.TurnMetaKeyStopReason: "stop",
+			turns.TurnMetaKeyUsage:      &events.Usage{InputTokens: in, OutputTokens: out, CachedTokens: cached},
+		}}
+	}
+
+	stats := newAttemptStats()
+	stats.record(mk(1000, 200, 0), domain.GenerationAttempt{Model: "gpt-4o"})
+	stats.record(nil, domain.GenerationAttempt{Model: "gpt-4o", Error: "429"}) // failed retry without a turn
+	last := mk(1000, 300, 800)
+	stats.record(last, domain.GenerationAttempt{Model: "gpt-4o"})
+
+	resp := newGenerateDescriptionResponse("desc", nil, "", last, stats)
+	if resp.Attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", resp.Attempts)
+	}
+	if resp.Usage == nil {
+		t.Fatalf("expected usage")
+	}
+	if resp.Usage.InputTokens != 2000 || resp.Usage.OutputTokens != 500 || resp.Usage.CachedTokens != 800 {
+		t.Fatalf("unexpected usage: %+v", *resp.Usage)
+	}
+
+	meta := resp.Metadata()
+	if meta.Model != "gpt-4o" || meta.StopReason != "stop" || meta.Attempts != 3 {
+		t.Fatalf("unexpected metadata: %+v", meta)
+	}
+	if len(meta.AttemptLog) != 3 || meta.AttemptLog[1].Error != "429" {
+		t.Fatalf("unexpected attempt log: %+v", meta.AttemptLog)
+	}
+}
+
+func TestAttemptStatsWithoutUsage(t *testing.T) {
+	stats := newAttemptStats()
+	stats.record(&turns.Turn{}, domain.GenerationAttempt{})
+	if stats.usage != nil {
+		t.Fatalf("expected nil usage when the provider reports none, got %+v", *stats.usage)
+	}
+}
+
+func TestUsageFromTurn_claudeCacheIsOnTopOfInput(t *testing.T) {
+	// Claude reports cache reads and writes next to input_tokens, not as part of it.
+	for name, usage := range map[string]any{
+		"typed": &events.Usage{InputTokens: 100, OutputTokens: 50, CacheReadInputTokens: 4000, CacheCreationInputTokens: 1500},
+		"map": map[string]any{
+			"input_tokens": 100, "output_tokens": 50,
+			"cache_read_input_tokens": 4000, "cache_creation_input_tokens": 1500,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got := usageFromTurn(&turns.Turn{Metadata: map[turns.TurnMetadataKey]interface{}{turns.TurnMetaKeyUsage: usage}})
+			want := domain.GenerationUsage{InputTokens: 100, OutputTokens: 50, CacheReadTokens: 4000, CacheWriteTokens: 1500}
+			if got == nil || *got != want {
+				t.Fatalf("expected %+v, got %+v", want, got)
+			}
+		})
+	}
+}
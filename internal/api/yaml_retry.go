@@ -9,7 +9,9 @@ import (
 	"github.com/go-go-golems/prescribe/internal/domain"
 )
 
-func parseAndValidateGeneratedPRData(assistantText string) (*domain.GeneratedPRData, string) {
+// parseAndValidateGeneratedPRData parses assistant text, drops the keys schema does not declare and
+// checks it against schema (nil => default fields).
+func parseAndValidateGeneratedPRData(assistantText string, schema *domain.OutputSchema) (*domain.GeneratedPRData, string) {
 	p, err := ParseGeneratedPRDataFromAssistantText(assistantText)
 	if err != nil {
 		return p, err.Error()
@@ -17,8 +19,9 @@ func parseAndValidateGeneratedPRData(assistantText string) (*domain.GeneratedPRD
 	if p == nil {
 		return nil, "failed to parse PR YAML (no data)"
 	}
-	if !isGeneratedPRDataValid(p) {
-		return p, "parsed PR YAML is missing required fields (title/body)"
+	schema.DropUndeclared(p)
+	if err := schema.Check(p); err != nil {
+		return p, err.Error()
 	}
 	return p, ""
 }
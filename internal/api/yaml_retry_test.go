@@ -1,9 +1,11 @@
 package api
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/go-go-golems/geppetto/pkg/steps/ai/settings"
+	"github.com/go-go-golems/prescribe/internal/domain"
 )
 
 func TestIsLikelyMaxTokensStopReason(t *testing.T) {
@@ -74,15 +76,38 @@ func TestComputeRetryMaxResponseTokens(t *testing.T) {
 func TestParseAndValidateGeneratedPRData(t *testing.T) {
 	t.Run("valid", func(t *testing.T) {
 		in := "title: ok\nbody: |\n  hi\nchangelog: |\n  c\nrelease_notes:\n  title: rn\n  body: |\n    rn body\n"
-		_, errStr := parseAndValidateGeneratedPRData(in)
+		_, errStr := parseAndValidateGeneratedPRData(in, nil)
 		if errStr != "" {
 			t.Fatalf("expected no error, got %q", errStr)
 		}
 	})
 
+	t.Run("custom schema fields are validated", func(t *testing.T) {
+		schema := &domain.OutputSchema{Fields: []domain.OutputField{
+			{Name: "risk_level", Required: true, Enum: []string{"low", "high"}},
+		}}
+		p, errStr := parseAndValidateGeneratedPRData("title: ok\nbody: hi\nrisk_level: high\n", schema)
+		if errStr != "" {
+			t.Fatalf("expected no error, got %q", errStr)
+		}
+		if p.Extra["risk_level"] != "high" {
+			t.Fatalf("expected custom field in Extra, got %+v", p.Extra)
+		}
+		if _, errStr := parseAndValidateGeneratedPRData("title: ok\nbody: hi\n", schema); !strings.Contains(errStr, "risk_level") {
+			t.Fatalf("expected missing risk_level error, got %q", errStr)
+		}
+	})
+
+	t.Run("undeclared keys are dropped", func(t *testing.T) {
+		p, errStr := parseAndValidateGeneratedPRData("title: ok\nbody: hi\nnotes: stray\n", nil)
+		if errStr != "" || p.Extra != nil {
+			t.Fatalf("expected the stray key to be dropped, got %+v (%q)", p.Extra, errStr)
+		}
+	})
+
 	t.Run("missing body treated as invalid", func(t *testing.T) {
 		in := "title: ok\nbody: \"\"\nchangelog: |\n  c\n"
-		_, errStr := parseAndValidateGeneratedPRData(in)
+		_, errStr := parseAndValidateGeneratedPRData(in, nil)
 		if errStr == "" {
 			t.Fatalf("expected error, got empty")
 		}
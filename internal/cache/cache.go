@@ -0,0 +1,272 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-go-golems/geppetto/pkg/turns"
+	"github.com/go-go-golems/geppetto/pkg/turns/serde"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	entrySuffix = ".yaml"
+	turnSuffix  = ".turn.yaml"
+)
+
+// Default eviction limits (overridable under `cache:` in .pr-builder/config.yaml).
+const (
+	DefaultMaxAge   = 30 * 24 * time.Hour
+	DefaultMaxBytes = 50 << 20
+)
+
+// Dir is where generations of a repository are cached.
+func Dir(repoPath string) string {
+	return filepath.Join(repoPath, ".pr-builder", "cache")
+}
+
+// Entry is a cached generation, stored as <key>.yaml. The conversation is stored next to it as
+// <key>.turn.yaml so a cached result can be refined like a fresh one.
+type Entry struct {
+	Key         string                    `yaml:"key"`
+	CreatedAt   time.Time                 `yaml:"created_at"`
+	Description string                    `yaml:"description"`
+	Parsed      *domain.GeneratedPRData   `yaml:"parsed,omitempty"`
+	Generation  domain.GenerationMetadata `yaml:"generation"`
+}
+
+// Info describes a cache entry on disk (see Store.List).
+type Info struct {
+	Key       string
+	CreatedAt time.Time
+	Model     string
+	Title     string
+	// Size is the size of the entry and its conversation in bytes.
+	Size int64
+}
+
+// Policy bounds the cache. Zero values disable the respective limit.
+type Policy struct {
+	MaxAge   time.Duration
+	MaxBytes int64
+}
+
+// DefaultPolicy returns the default eviction limits.
+func DefaultPolicy() Policy {
+	return Policy{MaxAge: DefaultMaxAge, MaxBytes: DefaultMaxBytes}
+}
+
+// Store is a content-addressed cache directory: entries are named by their key.
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+func (s *Store) entryPath(key string) string {
+	return filepath.Join(s.dir, key+entrySuffix)
+}
+
+func (s *Store) turnPath(key string) string {
+	return filepath.Join(s.dir, key+turnSuffix)
+}
+
+// Get returns the entry for key and its conversation (nil if it was not stored).
+// A missing entry is not an error: Get returns nil, nil, nil.
+func (s *Store) Get(key string) (*Entry, *turns.Turn, error) {
+	if !validKey(key) {
+		return nil, nil, errors.Errorf("invalid cache key %q", key)
+	}
+	b, err := os.ReadFile(s.entryPath(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, nil
+		}
+		return nil, nil, errors.Wrap(err, "failed to read cache entry")
+	}
+	var e Entry
+	if err := yaml.Unmarshal(b, &e); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to unmarshal cache entry %s", key)
+	}
+
+	var t *turns.Turn
+	if _, err := os.Stat(s.turnPath(key)); err == nil {
+		t, err = serde.LoadTurnYAML(s.turnPath(key))
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to load cached conversation %s", key)
+		}
+	}
+	return &e, t, nil
+}
+
+// Put stores e (and t, if set) under e.Key, replacing any previous entry.
+func (s *Store) Put(e *Entry, t *turns.Turn) error {
+	if e == nil {
+		return errors.New("cache entry is nil")
+	}
+	if !validKey(e.Key) {
+		return errors.Errorf("invalid cache key %q", e.Key)
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return errors.Wrap(err, "failed to create cache directory")
+	}
+	b, err := yaml.Marshal(e)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal cache entry")
+	}
+	if err := os.WriteFile(s.entryPath(e.Key), b, 0o644); err != nil {
+		return errors.Wrap(err, "failed to write cache entry")
+	}
+	if t != nil {
+		if err := serde.SaveTurnYAML(s.turnPath(e.Key), t, serde.Options{OmitData: true}); err != nil {
+			return errors.Wrap(err, "failed to write cached conversation")
+		}
+	}
+	return nil
+}
+
+// List returns all entries, newest first. Unreadable entries are listed without model/title.
+func (s *Store) List() ([]Info, error) {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to read cache directory")
+	}
+
+	sizes := map[string]int64{}
+	for _, de := range dirEntries {
+		name := de.Name()
+		if de.IsDir() || !strings.HasSuffix(name, entrySuffix) {
+			continue
+		}
+		fi, err := de.Info()
+		if err != nil {
+			continue
+		}
+		// <key>.turn.yaml also ends in .yaml; both files count towards the entry.
+		key := strings.TrimSuffix(strings.TrimSuffix(name, turnSuffix), entrySuffix)
+		if !validKey(key) {
+			continue
+		}
+		sizes[key] += fi.Size()
+	}
+
+	out := []Info{}
+	for key, size := range sizes {
+		info := Info{Key: key, Size: size}
+		if fi, err := os.Stat(s.entryPath(key)); err == nil {
+			info.CreatedAt = fi.ModTime().UTC()
+		}
+		if b, err := os.ReadFile(s.entryPath(key)); err == nil {
+			var e Entry
+			if yaml.Unmarshal(b, &e) == nil {
+				if !e.CreatedAt.IsZero() {
+					info.CreatedAt = e.CreatedAt
+				}
+				info.Model = e.Generation.Model
+				if e.Parsed != nil {
+					info.Title = e.Parsed.Title
+				}
+			}
+		}
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.After(out[j].CreatedAt)
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out, nil
+}
+
+// Remove deletes the entry for key and its conversation.
+func (s *Store) Remove(key string) error {
+	if !validKey(key) {
+		return errors.Errorf("invalid cache key %q", key)
+	}
+	for _, p := range []string{s.entryPath(key), s.turnPath(key)} {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return errors.Wrap(err, "failed to remove cache entry")
+		}
+	}
+	return nil
+}
+
+// Clear removes all entries and returns how many were removed.
+func (s *Store) Clear() (int, error) {
+	infos, err := s.List()
+	if err != nil {
+		return 0, err
+	}
+	for _, info := range infos {
+		if err := s.Remove(info.Key); err != nil {
+			return 0, err
+		}
+	}
+	return len(infos), nil
+}
+
+// Evict removes entries older than p.MaxAge, then the oldest entries until the cache fits
+// p.MaxBytes. It returns how many entries were removed.
+func (s *Store) Evict(p Policy, now time.Time) (int, error) {
+	infos, err := s.List()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	kept := []Info{}
+	for _, info := range infos {
+		if p.MaxAge > 0 && now.Sub(info.CreatedAt) > p.MaxAge {
+			if err := s.Remove(info.Key); err != nil {
+				return removed, err
+			}
+			removed++
+			continue
+		}
+		kept = append(kept, info)
+	}
+
+	if p.MaxBytes > 0 {
+		var total int64
+		for _, info := range kept {
+			total += info.Size
+		}
+		// kept is newest first: drop from the end.
+		for i := len(kept) - 1; i >= 0 && total > p.MaxBytes; i-- {
+			if err := s.Remove(kept[i].Key); err != nil {
+				return removed, err
+			}
+			total -= kept[i].Size
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// validKey accepts hex digests only, so keys can never escape the cache directory.
+func validKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f') {
+			return false
+		}
+	}
+	return true
+}
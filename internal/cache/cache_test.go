@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-go-golems/geppetto/pkg/turns"
+	"github.com/go-go-golems/prescribe/internal/domain"
+)
+
+func putEntry(t *testing.T, s *Store, key string, createdAt time.Time) {
+	t.Helper()
+	turn := &turns.Turn{}
+	turns.AppendBlock(turn, turns.NewUserTextBlock("describe the PR"))
+	e := &Entry{
+		Key:         key,
+		CreatedAt:   createdAt,
+		Description: "title: T\nbody: B\n",
+		Parsed:      &domain.GeneratedPRData{Title: "T " + key, Body: "B", Extra: map[string]any{"risk_level": "low"}},
+		Generation:  domain.GenerationMetadata{Model: "gpt-4o", Attempts: 1},
+	}
+	if err := s.Put(e, turn); err != nil {
+		t.Fatalf("put %s: %v", key, err)
+	}
+}
+
+func TestStorePutGet(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "cache"))
+
+	e, turn, err := s.Get("abc123")
+	if err != nil || e != nil || turn != nil {
+		t.Fatalf("expected a clean miss, got %+v %v %v", e, turn, err)
+	}
+
+	putEntry(t, s, "abc123", time.Now().UTC())
+	e, turn, err = s.Get("abc123")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if e == nil || e.Parsed == nil || e.Parsed.Title != "T abc123" || e.Generation.Model != "gpt-4o" {
+		t.Fatalf("unexpected entry %+v", e)
+	}
+	if e.Parsed.Extra["risk_level"] != "low" {
+		t.Fatalf("expected custom fields to round-trip, got %+v", e.Parsed.Extra)
+	}
+	if turn == nil || len(turn.Blocks) != 1 {
+		t.Fatalf("expected the cached conversation, got %+v", turn)
+	}
+
+	if _, _, err := s.Get("../session"); err == nil {
+		t.Fatalf("expected invalid keys to be rejected")
+	}
+}
+
+func TestStoreListEvictClear(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "cache"))
+	now := time.Now().UTC()
+	putEntry(t, s, "aaa", now.Add(-48*time.Hour))
+	putEntry(t, s, "bbb", now.Add(-2*time.Hour))
+	putEntry(t, s, "ccc", now.Add(-1*time.Hour))
+	// Files that are not cache entries are ignored.
+	if err := os.WriteFile(filepath.Join(s.Dir(), "notes.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	infos, err := s.List()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(infos) != 3 || infos[0].Key != "ccc" || infos[2].Key != "aaa" {
+		t.Fatalf("expected 3 entries newest first, got %+v", infos)
+	}
+	if infos[0].Size == 0 || infos[0].Title != "T ccc" {
+		t.Fatalf("unexpected info %+v", infos[0])
+	}
+
+	// Age: aaa is too old.
+	n, err := s.Evict(Policy{MaxAge: 24 * time.Hour}, now)
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 evicted by age, got %d (%v)", n, err)
+	}
+	// Size: only the newest entry fits.
+	n, err = s.Evict(Policy{MaxBytes: infos[0].Size}, now)
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 evicted by size, got %d (%v)", n, err)
+	}
+	if e, _, _ := s.Get("ccc"); e == nil {
+		t.Fatalf("expected the newest entry to survive eviction")
+	}
+
+	n, err = s.Clear()
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 cleared, got %d (%v)", n, err)
+	}
+	if infos, _ := s.List(); len(infos) != 0 {
+		t.Fatalf("expected empty cache, got %+v", infos)
+	}
+}
@@ -0,0 +1,262 @@
+package controller
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/go-go-golems/prescribe/internal/api"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/go-go-golems/prescribe/internal/tokens"
+)
+
+// BudgetStep is a rung on the per-file degradation ladder used by FitTokenBudget.
+type BudgetStep string
+
+const (
+	BudgetStepFull        BudgetStep = "full"
+	BudgetStepDiff        BudgetStep = "diff"
+	BudgetStepDiffReduced BudgetStep = "diff_reduced"
+	BudgetStepNumstat     BudgetStep = "numstat"
+	BudgetStepExcluded    BudgetStep = "excluded"
+)
+
+// budgetLadder lists the steps that can still be degraded, in order.
+var budgetLadder = []BudgetStep{BudgetStepFull, BudgetStepDiff, BudgetStepDiffReduced, BudgetStepNumstat}
+
+// defaultResponseReserve is kept free for the model's answer when the budget is derived
+// from the model's context window and no max response tokens are configured.
+const defaultResponseReserve = 4096
+
+// BudgetChange records how FitTokenBudget degraded one file.
+type BudgetChange struct {
+	Path         string
+	From         BudgetStep
+	To           BudgetStep
+	TokensBefore int
+	TokensAfter  int
+	Priority     float64
+}
+
+// BudgetReport summarizes a FitTokenBudget run.
+type BudgetReport struct {
+	MaxTokens    int
+	Source       string
+	TokensBefore int
+	TokensAfter  int
+	Fits         bool
+	Changes      []BudgetChange
+}
+
+// TokenBudget is a resolved budget and where it came from ("flag", "session", "model:<name>").
+type TokenBudget struct {
+	MaxTokens int
+	Source    string
+}
+
+func (c *Controller) budgetConfig() domain.BudgetConfig {
+	if c.data.Budget != nil {
+		return *c.data.Budget
+	}
+	return domain.DefaultBudgetConfig()
+}
+
+// ResolveTokenBudget picks the budget for included files + additional context.
+// Precedence: explicit value (e.g. --token-budget) > session budget.max_tokens > the configured model's
+// context window, minus the response reserve and the rendered prompt overhead.
+func (c *Controller) ResolveTokenBudget(explicit int) (TokenBudget, error) {
+	if explicit > 0 {
+		return TokenBudget{MaxTokens: explicit, Source: "flag"}, nil
+	}
+	if c.data.Budget != nil && c.data.Budget.MaxTokens > 0 {
+		return TokenBudget{MaxTokens: c.data.Budget.MaxTokens, Source: "session"}, nil
+	}
+
+	model := ""
+	reserve := defaultResponseReserve
+	if c.apiService != nil {
+		model = c.apiService.ModelName()
+		if n := c.apiService.MaxResponseTokens(); n > 0 {
+			reserve = n
+		}
+	}
+	window := tokens.ContextWindow(model)
+	if window == 0 {
+		return TokenBudget{}, fmt.Errorf("no token budget: set one explicitly or configure budget.max_tokens in the session (unknown context window for model %q)", model)
+	}
+
+	overhead, err := c.promptOverheadTokens()
+	if err != nil {
+		return TokenBudget{}, err
+	}
+	budget := window - reserve - overhead
+	if budget <= 0 {
+		return TokenBudget{}, fmt.Errorf("model %q context window (%d) is too small for the prompt overhead (%d) and response reserve (%d)", model, window, overhead, reserve)
+	}
+	return TokenBudget{MaxTokens: budget, Source: "model:" + model}, nil
+}
+
+// promptOverheadTokens estimates the rendered prompt tokens that are not attributable to
+// included files or additional context (template text, derived git history, ...).
+func (c *Controller) promptOverheadTokens() (int, error) {
+	req, err := c.PreviewGenerateDescriptionRequest()
+	if err != nil {
+		return 0, err
+	}
+	sys, user, err := api.CompilePrompt(req)
+	if err != nil {
+		return 0, err
+	}
+	counter := c.TokenCounter()
+	overhead := counter.Count(sys) + counter.Count(user) - c.data.GetTotalTokens()
+	if overhead < 0 {
+		overhead = 0
+	}
+	return overhead, nil
+}
+
+// FilePriority scores a file for budget fitting; lower-priority files are degraded first.
+func (c *Controller) FilePriority(f domain.FileChange) float64 {
+	cfg := c.budgetConfig()
+	score := 0.0
+	for _, p := range cfg.Priorities {
+		if ok, err := doublestar.Match(p.Pattern, f.Path); err == nil && ok {
+			score += float64(p.Weight)
+		}
+	}
+	if domain.IsTestPath(f.Path) {
+		score += float64(cfg.TestWeight)
+	}
+	score += float64(cfg.ChurnWeight) * math.Log2(1+float64(f.Additions+f.Deletions))
+	return score
+}
+
+func fileBudgetStep(f domain.FileChange) BudgetStep {
+	if !f.Included {
+		return BudgetStepExcluded
+	}
+	switch f.Type {
+	case domain.FileTypeFull:
+		return BudgetStepFull
+	case domain.FileTypeDiffReduced:
+		return BudgetStepDiffReduced
+	case domain.FileTypeNumstat:
+		return BudgetStepNumstat
+	case domain.FileTypeDiff:
+		return BudgetStepDiff
+	default:
+		return BudgetStepDiff
+	}
+}
+
+func degradeFile(f *domain.FileChange, counter *tokens.Counter) {
+	switch fileBudgetStep(*f) {
+	case BudgetStepFull:
+		f.Type = domain.FileTypeDiff
+		f.Version = ""
+	case BudgetStepDiff:
+		f.Type = domain.FileTypeDiffReduced
+	case BudgetStepDiffReduced:
+		f.Type = domain.FileTypeNumstat
+	case BudgetStepNumstat:
+		f.Included = false
+		return
+	case BudgetStepExcluded:
+		return
+	}
+	f.RecountTokens(counter)
+}
+
+// FitTokenBudget degrades visible, included files until included files + additional context fit
+// into maxTokens. Each ladder step (full → diff → reduced-context diff → numstat-only → excluded)
+// is applied to all files, lowest priority first, before moving on to the next step, so
+// high-priority files keep their detail the longest. Filtered files are not touched.
+func (c *Controller) FitTokenBudget(maxTokens int) (BudgetReport, error) {
+	if maxTokens <= 0 {
+		return BudgetReport{}, fmt.Errorf("invalid token budget: %d", maxTokens)
+	}
+
+	report := BudgetReport{
+		MaxTokens:    maxTokens,
+		TokensBefore: c.data.GetTotalTokens(),
+	}
+
+	visible := map[string]bool{}
+	for _, f := range c.data.GetVisibleFiles() {
+		visible[f.Path] = true
+	}
+
+	type candidate struct {
+		index    int
+		priority float64
+	}
+	candidates := make([]candidate, 0, len(c.data.ChangedFiles))
+	for i, f := range c.data.ChangedFiles {
+		if !visible[f.Path] || !f.Included {
+			continue
+		}
+		candidates = append(candidates, candidate{index: i, priority: c.FilePriority(f)})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].priority != candidates[j].priority {
+			return candidates[i].priority < candidates[j].priority
+		}
+		return c.data.ChangedFiles[candidates[i].index].Path < c.data.ChangedFiles[candidates[j].index].Path
+	})
+
+	changes := map[int]*BudgetChange{}
+	order := make([]int, 0)
+	total := report.TokensBefore
+
+	for _, step := range budgetLadder {
+		for _, cand := range candidates {
+			if total <= maxTokens {
+				break
+			}
+			f := &c.data.ChangedFiles[cand.index]
+			if fileBudgetStep(*f) != step {
+				continue
+			}
+			before := f.Tokens
+			if _, ok := changes[cand.index]; !ok {
+				changes[cand.index] = &BudgetChange{
+					Path:         f.Path,
+					From:         step,
+					TokensBefore: before,
+					Priority:     cand.priority,
+				}
+				order = append(order, cand.index)
+			}
+			degradeFile(f, c.data.TokenCounter)
+			after := f.Tokens
+			if !f.Included {
+				after = 0
+			}
+			total += after - before
+			changes[cand.index].To = fileBudgetStep(*f)
+			changes[cand.index].TokensAfter = after
+		}
+	}
+
+	for _, i := range order {
+		report.Changes = append(report.Changes, *changes[i])
+	}
+	report.TokensAfter = c.data.GetTotalTokens()
+	report.Fits = report.TokensAfter <= maxTokens
+	return report, nil
+}
+
+// FitResolvedTokenBudget resolves the budget (see ResolveTokenBudget) and fits the session into it.
+func (c *Controller) FitResolvedTokenBudget(explicit int) (BudgetReport, error) {
+	budget, err := c.ResolveTokenBudget(explicit)
+	if err != nil {
+		return BudgetReport{}, err
+	}
+	report, err := c.FitTokenBudget(budget.MaxTokens)
+	if err != nil {
+		return BudgetReport{}, err
+	}
+	report.Source = budget.Source
+	return report, nil
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/prescribe/internal/domain"
+)
+
+func makeBudgetTestFile(path string, contextLines int) domain.FileChange {
+	var b strings.Builder
+	b.WriteString("diff --git a/" + path + " b/" + path + "\n--- a/" + path + "\n+++ b/" + path + "\n")
+	b.WriteString("@@ -1,")
+	n := 2*contextLines + 1
+	b.WriteString(strconv.Itoa(n) + " +1," + strconv.Itoa(n) + " @@\n")
+	for i := 0; i < contextLines; i++ {
+		b.WriteString(" unchanged context line number " + strconv.Itoa(i) + "\n")
+	}
+	b.WriteString("+added line\n")
+	for i := 0; i < contextLines; i++ {
+		b.WriteString(" trailing context line number " + strconv.Itoa(i) + "\n")
+	}
+	f := domain.FileChange{
+		Path:       path,
+		Included:   true,
+		Additions:  1,
+		Type:       domain.FileTypeDiff,
+		Diff:       b.String(),
+		FullAfter:  strings.Repeat("full file content line\n", 200),
+		FullBefore: strings.Repeat("full file content line\n", 199),
+	}
+	f.RecountTokens(nil)
+	return f
+}
+
+func TestFitTokenBudget_DegradesLowPriorityFirst(t *testing.T) {
+	data := domain.NewPRData()
+	data.ChangedFiles = []domain.FileChange{
+		makeBudgetTestFile("internal/core/core.go", 8),
+		makeBudgetTestFile("internal/core/core_test.go", 8),
+	}
+	// Start the core file in full-file mode.
+	if err := data.ReplaceWithFullFile(0, domain.FileVersionAfter); err != nil {
+		t.Fatalf("ReplaceWithFullFile: %v", err)
+	}
+	c := &Controller{repoPath: t.TempDir(), data: data}
+
+	before := data.GetTotalTokens()
+	budget := before - 10
+	report, err := c.FitTokenBudget(budget)
+	if err != nil {
+		t.Fatalf("FitTokenBudget: %v", err)
+	}
+	if !report.Fits {
+		t.Fatalf("expected budget to fit, got %+v", report)
+	}
+	if report.TokensBefore != before || report.TokensAfter > budget {
+		t.Fatalf("unexpected token totals: %+v", report)
+	}
+	if len(report.Changes) != 1 || report.Changes[0].Path != "internal/core/core.go" ||
+		report.Changes[0].From != BudgetStepFull || report.Changes[0].To != BudgetStepDiff {
+		t.Fatalf("expected only the full file to drop to diff first, got %+v", report.Changes)
+	}
+
+	// A tight budget walks the ladder; the test file is degraded before the core file.
+	report, err = c.FitTokenBudget(data.GetTotalTokens() - 20)
+	if err != nil {
+		t.Fatalf("FitTokenBudget: %v", err)
+	}
+	if len(report.Changes) == 0 || report.Changes[0].Path != "internal/core/core_test.go" {
+		t.Fatalf("expected test file to be degraded first, got %+v", report.Changes)
+	}
+	if report.Changes[0].To != BudgetStepDiffReduced {
+		t.Fatalf("expected test file to move to reduced-context diff, got %+v", report.Changes[0])
+	}
+
+	// An impossible budget excludes everything and reports that it does not fit when context remains.
+	c.AddContextNote(strings.Repeat("note ", 50))
+	report, err = c.FitTokenBudget(1)
+	if err != nil {
+		t.Fatalf("FitTokenBudget: %v", err)
+	}
+	if report.Fits {
+		t.Fatalf("expected budget not to fit, got %+v", report)
+	}
+	for _, f := range data.ChangedFiles {
+		if f.Included {
+			t.Fatalf("expected %s to be excluded", f.Path)
+		}
+	}
+}
+
+func TestFitTokenBudget_PriorityPatterns(t *testing.T) {
+	data := domain.NewPRData()
+	data.ChangedFiles = []domain.FileChange{
+		makeBudgetTestFile("a/important.go", 8),
+		makeBudgetTestFile("b/other.go", 8),
+	}
+	data.Budget = &domain.BudgetConfig{
+		Priorities: []domain.BudgetPriority{{Pattern: "b/**", Weight: 100}},
+	}
+	c := &Controller{repoPath: t.TempDir(), data: data}
+
+	report, err := c.FitTokenBudget(data.GetTotalTokens() - 5)
+	if err != nil {
+		t.Fatalf("FitTokenBudget: %v", err)
+	}
+	if len(report.Changes) != 1 || report.Changes[0].Path != "a/important.go" {
+		t.Fatalf("expected a/important.go (lower priority) to be degraded, got %+v", report.Changes)
+	}
+}
+
+func TestResolveTokenBudget(t *testing.T) {
+	c := &Controller{repoPath: t.TempDir(), data: domain.NewPRData()}
+	if b, err := c.ResolveTokenBudget(1234); err != nil || b.MaxTokens != 1234 || b.Source != "flag" {
+		t.Fatalf("expected explicit budget, got %+v (%v)", b, err)
+	}
+	c.data.Budget = &domain.BudgetConfig{MaxTokens: 999}
+	if b, err := c.ResolveTokenBudget(0); err != nil || b.MaxTokens != 999 || b.Source != "session" {
+		t.Fatalf("expected session budget, got %+v (%v)", b, err)
+	}
+	c.data.Budget = nil
+	if _, err := c.ResolveTokenBudget(0); err == nil {
+		t.Fatalf("expected an error without any budget source")
+	}
+}
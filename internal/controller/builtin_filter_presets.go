@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/go-go-golems/prescribe/internal/filterpresets"
+)
+
+// Builtin filter preset modes for `session init` (see repoDefaultsYAML.BuiltinFilterPresets).
+const (
+	BuiltinFilterPresetsSuggest = "suggest"
+	BuiltinFilterPresetsAuto    = "auto"
+	BuiltinFilterPresetsOff     = "off"
+)
+
+// LoadBuiltinFilterPresets parses the filter presets embedded in the binary.
+func LoadBuiltinFilterPresets() ([]domain.FilterPreset, error) {
+	files, err := filterpresets.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	presets := make([]domain.FilterPreset, 0, len(files))
+	for id, data := range files {
+		preset, err := parseFilterPresetYAML(data)
+		if err != nil {
+			// Embedded assets are covered by tests; a failure here is a build defect.
+			return nil, fmt.Errorf("invalid builtin filter preset %s: %w", id, err)
+		}
+		preset.ID = id
+		preset.Location = domain.PresetLocationBuiltin
+		presets = append(presets, preset)
+	}
+	sort.Slice(presets, func(i, j int) bool { return presets[i].ID < presets[j].ID })
+	return presets, nil
+}
+
+// FilterPresetSuggestion is a builtin preset that is relevant for the current repo and diff.
+type FilterPresetSuggestion struct {
+	Preset domain.FilterPreset
+	// Reason is the detected ecosystem (e.g. "go") or "generic".
+	Reason string
+	// MatchedFiles is the number of changed files the preset would exclude.
+	MatchedFiles int
+}
+
+// SuggestBuiltinFilterPresets detects the repo's ecosystems and returns the builtin presets
+// that would hide at least one changed file. Presets already active (by name) are skipped.
+func (c *Controller) SuggestBuiltinFilterPresets() ([]FilterPresetSuggestion, error) {
+	builtins, err := LoadBuiltinFilterPresets()
+	if err != nil {
+		return nil, err
+	}
+	byID := map[string]domain.FilterPreset{}
+	for _, p := range builtins {
+		byID[p.ID] = p
+	}
+
+	active := map[string]bool{}
+	for _, f := range c.data.ActiveFilters {
+		active[strings.ToLower(f.Name)] = true
+	}
+
+	candidates := filterpresets.CandidatePresets(filterpresets.DetectEcosystems(c.repoPath))
+	ret := make([]FilterPresetSuggestion, 0)
+	for _, id := range filterpresets.SortedIDs(candidates) {
+		p, ok := byID[id]
+		if !ok || active[strings.ToLower(p.Name)] {
+			continue
+		}
+		n := 0
+		for _, file := range c.data.ChangedFiles {
+			if presetMatchesPath(p, file.Path) {
+				n++
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		ret = append(ret, FilterPresetSuggestion{Preset: p, Reason: candidates[id], MatchedFiles: n})
+	}
+	return ret, nil
+}
+
+// ApplyFilterPreset adds a preset to the active filters.
+func (c *Controller) ApplyFilterPreset(p domain.FilterPreset) {
+	c.AddFilter(domain.Filter{
+		Name:        p.Name,
+		Description: p.Description,
+		Rules:       p.Rules,
+	})
+}
+
+// presetMatchesPath reports whether any exclude rule of the preset matches the path.
+func presetMatchesPath(p domain.FilterPreset, path string) bool {
+	for _, r := range p.Rules {
+		if r.Type != domain.FilterTypeExclude {
+			continue
+		}
+		if ok, err := doublestar.Match(r.Pattern, path); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
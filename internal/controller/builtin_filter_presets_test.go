@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-go-golems/prescribe/internal/domain"
+)
+
+func TestBuiltinFilterPresets_LoadAndResolve(t *testing.T) {
+	presets, err := LoadBuiltinFilterPresets()
+	if err != nil {
+		t.Fatalf("LoadBuiltinFilterPresets: %v", err)
+	}
+	if len(presets) == 0 {
+		t.Fatalf("expected builtin presets")
+	}
+	for _, p := range presets {
+		if p.Location != domain.PresetLocationBuiltin {
+			t.Fatalf("expected builtin location for %s, got %q", p.ID, p.Location)
+		}
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	c := &Controller{repoPath: t.TempDir(), data: domain.NewPRData()}
+	p, err := c.LoadFilterPresetByID("lockfiles.yaml")
+	if err != nil {
+		t.Fatalf("LoadFilterPresetByID: %v", err)
+	}
+	if p.Location != domain.PresetLocationBuiltin {
+		t.Fatalf("expected builtin preset, got %q", p.Location)
+	}
+	if err := c.DeleteFilterPreset("lockfiles.yaml", domain.PresetLocationBuiltin); err == nil {
+		t.Fatalf("expected builtin presets to be read-only")
+	}
+}
+
+func TestBuiltinFilterPresets_Suggest(t *testing.T) {
+	tmp := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmp, "go.mod"), []byte("module example.com/x\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	data := domain.NewPRData()
+	data.ChangedFiles = []domain.FileChange{
+		{Path: "main.go"},
+		{Path: "go.sum"},
+		{Path: "internal/foo/foo_test.go"},
+		{Path: "web/dist/app.js"},
+	}
+	c := &Controller{repoPath: tmp, data: data}
+
+	suggestions, err := c.SuggestBuiltinFilterPresets()
+	if err != nil {
+		t.Fatalf("SuggestBuiltinFilterPresets: %v", err)
+	}
+	got := map[string]string{}
+	for _, s := range suggestions {
+		got[s.Preset.ID] = s.Reason
+	}
+	if got["go_generated.yaml"] != "go" {
+		t.Fatalf("expected go_generated.yaml suggested for go, got %+v", got)
+	}
+	if got["tests.yaml"] != "generic" {
+		t.Fatalf("expected tests.yaml suggested, got %+v", got)
+	}
+	// No package.json at the root, so JS build output is not suggested.
+	if _, ok := got["js_build.yaml"]; ok {
+		t.Fatalf("did not expect js_build.yaml without package.json, got %+v", got)
+	}
+	if _, ok := got["lockfiles.yaml"]; ok {
+		t.Fatalf("did not expect lockfiles.yaml without lockfile changes, got %+v", got)
+	}
+}
+
+func TestBuiltinFilterPresets_ModeFromRepoConfig(t *testing.T) {
+	tmp := t.TempDir()
+	c := &Controller{repoPath: tmp, data: domain.NewPRData()}
+
+	mode, err := c.ResolveBuiltinFilterPresetsMode("")
+	if err != nil || mode != BuiltinFilterPresetsSuggest {
+		t.Fatalf("expected suggest by default, got %q (%v)", mode, err)
+	}
+
+	cfgDir := filepath.Join(tmp, ".pr-builder")
+	if err := os.MkdirAll(cfgDir, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	writeCfg := func(s string) {
+		if err := os.WriteFile(filepath.Join(cfgDir, "config.yaml"), []byte(s), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	writeCfg("defaults:\n  filter_presets:\n    - lockfiles.yaml\n")
+	mode, err = c.ResolveBuiltinFilterPresetsMode("")
+	if err != nil || mode != BuiltinFilterPresetsOff {
+		t.Fatalf("expected explicit filter_presets to disable detection, got %q (%v)", mode, err)
+	}
+	if mode, _ := c.ResolveBuiltinFilterPresetsMode(BuiltinFilterPresetsAuto); mode != BuiltinFilterPresetsAuto {
+		t.Fatalf("expected override to win, got %q", mode)
+	}
+
+	writeCfg("defaults:\n  builtin_filter_presets: auto\n")
+	mode, err = c.ResolveBuiltinFilterPresetsMode("")
+	if err != nil || mode != BuiltinFilterPresetsAuto {
+		t.Fatalf("expected auto from config, got %q (%v)", mode, err)
+	}
+
+	writeCfg("defaults:\n  builtin_filter_presets: sometimes\n")
+	if _, err := c.ResolveBuiltinFilterPresetsMode(""); err == nil {
+		t.Fatalf("expected invalid mode error")
+	}
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-go-golems/prescribe/internal/api"
+	"github.com/go-go-golems/prescribe/internal/cache"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// SetNoCache makes generations skip the cache lookup (`generate --no-cache`).
+// Fresh results are still stored, so the next run can reuse them.
+func (c *Controller) SetNoCache(noCache bool) {
+	c.noCache = noCache
+}
+
+// CacheStore returns the generation cache of the repository (.pr-builder/cache/).
+func (c *Controller) CacheStore() *cache.Store {
+	return cache.NewStore(cache.Dir(c.repoPath))
+}
+
+// CachePolicy returns the eviction limits: the defaults, overridden by `cache:` in
+// ~/.pr-builder/config.yaml and then <repo>/.pr-builder/config.yaml.
+func (c *Controller) CachePolicy() (cache.Policy, error) {
+	policy := cache.DefaultPolicy()
+	globalCfg, err := loadGlobalConfig()
+	if err != nil {
+		return policy, err
+	}
+	repoCfg, err := c.loadRepoConfig()
+	if err != nil {
+		return policy, err
+	}
+	for _, cfg := range []repoCacheYAML{globalCfg.Cache, repoCfg.Cache} {
+		if strings.TrimSpace(cfg.MaxAge) != "" {
+			d, err := time.ParseDuration(strings.TrimSpace(cfg.MaxAge))
+			if err != nil {
+				return policy, errors.Wrapf(err, "invalid cache.max_age %q", cfg.MaxAge)
+			}
+			policy.MaxAge = d
+		}
+		if cfg.MaxSizeMB != nil {
+			policy.MaxBytes = int64(*cfg.MaxSizeMB) << 20
+		}
+	}
+	return policy, nil
+}
+
+// cacheKey returns the content address of req, or "" if it cannot be computed (caching is then skipped).
+func (c *Controller) cacheKey(req api.GenerateDescriptionRequest) string {
+	key, err := c.apiService.CacheKey(req)
+	if err != nil {
+		log.Debug().Err(err).Msg("controller: failed to compute cache key; not caching")
+		return ""
+	}
+	return key
+}
+
+// cachedGeneration returns the cached response for key, or nil on a miss (or with --no-cache).
+// No inference runs for a hit, so the response carries no usage and zero attempts.
+func (c *Controller) cachedGeneration(key string) *api.GenerateDescriptionResponse {
+	if key == "" || c.noCache {
+		return nil
+	}
+	start := time.Now()
+	e, t, err := c.CacheStore().Get(key)
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("controller: ignoring unreadable cache entry")
+		return nil
+	}
+	if e == nil || e.Parsed == nil {
+		return nil
+	}
+	meta := e.Generation
+	return &api.GenerateDescriptionResponse{
+		Description:  e.Description,
+		Parsed:       e.Parsed,
+		Model:        meta.Model,
+		StopReason:   meta.StopReason,
+		Latency:      time.Since(start),
+		Turn:         t,
+		OutputMode:   meta.OutputMode,
+		OutputSchema: meta.OutputSchema,
+		CacheKey:     key,
+		Cached:       true,
+		LintRepaired: meta.LintRepaired,
+	}
+}
+
+// storeGeneration caches resp under key and evicts old entries. Only parsed results are cached,
+// so a failed generation is retried on the next run. Cache errors are logged, never returned.
+func (c *Controller) storeGeneration(key string, resp *api.GenerateDescriptionResponse) {
+	if key == "" || resp == nil {
+		return
+	}
+	resp.CacheKey = key
+	if resp.Parsed == nil || resp.ParseError != "" {
+		return
+	}
+	store := c.CacheStore()
+	now := time.Now().UTC()
+	e := &cache.Entry{
+		Key:         key,
+		CreatedAt:   now,
+		Description: resp.Description,
+		Parsed:      resp.Parsed,
+		Generation:  resp.Metadata(),
+	}
+	if err := store.Put(e, resp.Turn); err != nil {
+		log.Warn().Err(err).Msg("controller: failed to store generation in cache")
+		return
+	}
+	policy, err := c.CachePolicy()
+	if err != nil {
+		log.Warn().Err(err).Msg("controller: invalid cache config; using the default limits")
+		policy = cache.DefaultPolicy()
+	}
+	if _, err := store.Evict(policy, now); err != nil {
+		log.Warn().Err(err).Msg("controller: failed to evict old cache entries")
+	}
+}
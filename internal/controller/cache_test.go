@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-go-golems/geppetto/pkg/turns"
+	"github.com/go-go-golems/prescribe/internal/api"
+	"github.com/go-go-golems/prescribe/internal/domain"
+)
+
+func TestGenerationCache_StoreAndHit(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	c := &Controller{repoPath: t.TempDir(), data: domain.NewPRData()}
+	key := "0123abcd"
+
+	turn := &turns.Turn{}
+	turns.AppendBlock(turn, turns.NewUserTextBlock("describe the PR"))
+	c.storeGeneration(key, &api.GenerateDescriptionResponse{
+		Description: "title: T\nbody: B\n",
+		Parsed:      &domain.GeneratedPRData{Title: "T", Body: "B"},
+		Model:       "gpt-4o",
+		Usage:       &domain.GenerationUsage{InputTokens: 100, OutputTokens: 10},
+		Attempts:    1,
+		Latency:     2 * time.Second,
+		Turn:        turn,
+		OutputMode:  domain.OutputModeYAML,
+	})
+
+	resp := c.cachedGeneration(key)
+	if resp == nil {
+		t.Fatalf("expected a cache hit")
+	}
+	if !resp.Cached || resp.CacheKey != key || resp.Parsed.Title != "T" || resp.Model != "gpt-4o" {
+		t.Fatalf("unexpected cached response %+v", resp)
+	}
+	if resp.Usage != nil || resp.Attempts != 0 {
+		t.Fatalf("a cache hit must not report inference usage, got %+v", resp)
+	}
+	if resp.Turn == nil || len(resp.Turn.Blocks) != 1 {
+		t.Fatalf("expected the cached conversation for refinements")
+	}
+	c.applyGeneration(resp)
+	if meta := c.GetData().GenerationMetadata; meta == nil || !meta.Cached || meta.CacheKey != key {
+		t.Fatalf("expected cached generation metadata, got %+v", meta)
+	}
+
+	c.SetNoCache(true)
+	if c.cachedGeneration(key) != nil {
+		t.Fatalf("expected --no-cache to skip the lookup")
+	}
+}
+
+func TestGenerationCache_SkipsParseFailures(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	c := &Controller{repoPath: t.TempDir(), data: domain.NewPRData()}
+	c.storeGeneration("beef", &api.GenerateDescriptionResponse{
+		Description: "not yaml",
+		ParseError:  "failed to parse PR YAML",
+	})
+	if c.cachedGeneration("beef") != nil {
+		t.Fatalf("failed generations must not be cached")
+	}
+}
+
+func TestCachePolicy_Config(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	repo := t.TempDir()
+	c := &Controller{repoPath: repo, data: domain.NewPRData()}
+
+	policy, err := c.CachePolicy()
+	if err != nil {
+		t.Fatalf("CachePolicy: %v", err)
+	}
+	if policy.MaxAge != 30*24*time.Hour || policy.MaxBytes != 50<<20 {
+		t.Fatalf("unexpected default policy %+v", policy)
+	}
+
+	if err := os.MkdirAll(filepath.Join(repo, ".pr-builder"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := "cache:\n  max_age: 24h\n  max_size_mb: 0\n"
+	if err := os.WriteFile(filepath.Join(repo, ".pr-builder", "config.yaml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	policy, err = c.CachePolicy()
+	if err != nil {
+		t.Fatalf("CachePolicy: %v", err)
+	}
+	if policy.MaxAge != 24*time.Hour || policy.MaxBytes != 0 {
+		t.Fatalf("expected repo config to override the defaults, got %+v", policy)
+	}
+}
@@ -0,0 +1,351 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-go-golems/prescribe/internal/api"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/go-go-golems/prescribe/internal/history"
+	"github.com/go-go-golems/prescribe/internal/presets"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// CandidateSpec varies one candidate of a multi-candidate generation.
+// Empty fields keep the session prompt / configured temperature.
+type CandidateSpec struct {
+	Preset      string
+	Temperature *float64
+}
+
+// Label describes the spec for output, e.g. "preset=concise temperature=0.7".
+func (s CandidateSpec) Label() string {
+	parts := []string{}
+	if s.Preset != "" {
+		parts = append(parts, "preset="+s.Preset)
+	}
+	if s.Temperature != nil {
+		parts = append(parts, "temperature="+strconv.FormatFloat(*s.Temperature, 'g', -1, 64))
+	}
+	if len(parts) == 0 {
+		return "session prompt"
+	}
+	return strings.Join(parts, " ")
+}
+
+// Candidate is one generated alternative. Response is nil if the generation failed.
+type Candidate struct {
+	Spec     CandidateSpec
+	Response *api.GenerateDescriptionResponse
+	Err      error
+}
+
+// Parsed returns the parsed PR data of the candidate (nil if generation or parsing failed).
+func (c Candidate) Parsed() *domain.GeneratedPRData {
+	if c.Response == nil {
+		return nil
+	}
+	return c.Response.Parsed
+}
+
+// Valid reports whether the candidate produced PR data that parsed without errors.
+func (c Candidate) Valid() bool {
+	return c.Err == nil && c.Response != nil && c.Response.Parsed != nil && c.Response.ParseError == ""
+}
+
+// CandidateSpecs expands a candidates config into one spec per candidate.
+func CandidateSpecs(cfg domain.CandidatesConfig) []CandidateSpec {
+	specs := make([]CandidateSpec, 0, cfg.Count)
+	for i := 0; i < cfg.Count; i++ {
+		spec := CandidateSpec{}
+		if len(cfg.Presets) > 0 {
+			spec.Preset = cfg.Presets[i%len(cfg.Presets)]
+		}
+		if len(cfg.Temperatures) > 0 {
+			t := cfg.Temperatures[i%len(cfg.Temperatures)]
+			spec.Temperature = &t
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// CandidatesConfig returns the session's candidates config (or the default).
+func (c *Controller) CandidatesConfig() domain.CandidatesConfig {
+	if c.data.Candidates != nil {
+		return *c.data.Candidates
+	}
+	return domain.DefaultCandidatesConfig()
+}
+
+// GenerateCandidates runs one generation per spec concurrently. Failed candidates keep their error;
+// an error is only returned if the request cannot be built or every candidate failed.
+// The candidates are kept until the next call (see Candidates) so a selection can be made later.
+func (c *Controller) GenerateCandidates(ctx context.Context, specs []CandidateSpec) ([]Candidate, error) {
+	if len(specs) == 0 {
+		return nil, errors.New("no candidates requested")
+	}
+
+	base, err := c.BuildGenerateDescriptionRequest()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.apiService.ValidateRequest(base); err != nil {
+		return nil, err
+	}
+
+	// Resolve presets up front so a typo fails fast instead of once per candidate.
+	prompts := map[string]*domain.PromptPreset{}
+	for _, spec := range specs {
+		if spec.Preset == "" {
+			continue
+		}
+		if _, ok := prompts[spec.Preset]; ok {
+			continue
+		}
+		preset, err := presets.ResolvePromptPreset(spec.Preset, c.repoPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to resolve preset %q", spec.Preset)
+		}
+		prompts[spec.Preset] = preset
+	}
+
+	// Requests are built before any candidate runs: building one swaps the current preset.
+	reqs := make([]api.GenerateDescriptionRequest, len(specs))
+	for i, spec := range specs {
+		reqs[i] = base
+		if spec.Preset != "" {
+			if reqs[i], err = c.candidateRequest(prompts[spec.Preset]); err != nil {
+				return nil, errors.Wrapf(err, "candidate %d (preset %s)", i+1, spec.Preset)
+			}
+		}
+	}
+
+	candidates := make([]Candidate, len(specs))
+	var wg sync.WaitGroup
+	for i, spec := range specs {
+		req := reqs[i]
+		svc := c.apiService
+		if spec.Temperature != nil {
+			svc = svc.WithTemperature(*spec.Temperature)
+		}
+
+		wg.Add(1)
+		go func(i int, spec CandidateSpec, svc *api.Service, req api.GenerateDescriptionRequest) {
+			defer wg.Done()
+			resp, err := svc.GenerateDescription(ctx, req)
+			candidates[i] = Candidate{Spec: spec, Response: resp, Err: err}
+		}(i, spec, svc, req)
+	}
+	wg.Wait()
+
+	c.candidates = candidates
+	for i, cand := range candidates {
+		if cand.Err == nil {
+			c.recordHistory(history.KindCandidate, reqs[i], c.candidatePresetID(cand.Spec), cand.Response, nil)
+		}
+	}
+
+	var firstErr error
+	for _, cand := range candidates {
+		if cand.Err == nil {
+			return candidates, nil
+		}
+		if firstErr == nil {
+			firstErr = cand.Err
+		}
+	}
+	return candidates, errors.Wrap(firstErr, "all candidates failed")
+}
+
+// candidateRequest builds the request of a candidate that uses preset. The preset is applied like
+// the session preset (prompt, output mode and schema, lint config, parameters); the session's
+// preset values carry over for the parameters the preset declares too.
+func (c *Controller) candidateRequest(preset *domain.PromptPreset) (api.GenerateDescriptionRequest, error) {
+	prompt, current, values := c.data.CurrentPrompt, c.data.CurrentPreset, c.data.PresetVars
+	defer func() {
+		c.data.CurrentPrompt, c.data.CurrentPreset, c.data.PresetVars = prompt, current, values
+	}()
+
+	shared := map[string]any{}
+	for _, param := range preset.Parameters {
+		if v, ok := values[param.Name]; ok {
+			shared[param.Name] = v
+		}
+	}
+	c.data.CurrentPrompt = preset.Template
+	c.data.CurrentPreset = preset
+	c.data.PresetVars = shared
+	return c.BuildGenerateDescriptionRequest()
+}
+
+// Candidates returns the candidates of the last GenerateCandidates call.
+func (c *Controller) Candidates() []Candidate {
+	return c.candidates
+}
+
+// CandidatePicks maps each section to the index of the candidate it is taken from.
+type CandidatePicks map[domain.PRDataSection]int
+
+// String renders picks 1-based in section order, e.g. "title=2,body=1,changelog=1,release_notes=1".
+func (p CandidatePicks) String() string {
+	parts := make([]string, 0, len(domain.PRDataSections))
+	for _, s := range domain.PRDataSections {
+		if i, ok := p[s]; ok {
+			parts = append(parts, fmt.Sprintf("%s=%d", s, i+1))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// DefaultCandidatePicks takes every section from the first valid candidate
+// (the first successful one if none parsed cleanly).
+func DefaultCandidatePicks(candidates []Candidate) CandidatePicks {
+	best := -1
+	for i, cand := range candidates {
+		if cand.Valid() {
+			best = i
+			break
+		}
+	}
+	if best < 0 {
+		for i, cand := range candidates {
+			if cand.Parsed() != nil {
+				best = i
+				break
+			}
+		}
+	}
+	if best < 0 {
+		best = 0
+	}
+	picks := CandidatePicks{}
+	for _, s := range domain.PRDataSections {
+		picks[s] = best
+	}
+	return picks
+}
+
+// ParseCandidatePicks parses 1-based picks: either a single candidate number ("2") or
+// per-section picks ("title=2,body=1"). Sections not mentioned keep the defaults.
+func ParseCandidatePicks(s string, candidates []Candidate) (CandidatePicks, error) {
+	picks := DefaultCandidatePicks(candidates)
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return picks, nil
+	}
+
+	parseIndex := func(v string) (int, error) {
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || n < 1 || n > len(candidates) {
+			return 0, errors.Errorf("invalid candidate %q (expected 1..%d)", v, len(candidates))
+		}
+		return n - 1, nil
+	}
+
+	if !strings.Contains(s, "=") {
+		i, err := parseIndex(s)
+		if err != nil {
+			return nil, err
+		}
+		for _, sec := range domain.PRDataSections {
+			picks[sec] = i
+		}
+		return picks, nil
+	}
+
+	for _, part := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, errors.Errorf("invalid pick %q (expected section=candidate)", part)
+		}
+		sec, err := domain.ParsePRDataSection(k)
+		if err != nil {
+			return nil, err
+		}
+		i, err := parseIndex(v)
+		if err != nil {
+			return nil, err
+		}
+		picks[sec] = i
+	}
+	return picks, nil
+}
+
+// SelectCandidates merges the picked sections into the generated PR data, as if it had been
+// generated in one run. Usage and attempts cover all candidates (all of them were paid for);
+// latency is the slowest candidate since they ran concurrently.
+func (c *Controller) SelectCandidates(candidates []Candidate, picks CandidatePicks) error {
+	merged := &domain.GeneratedPRData{}
+	sources := map[int]bool{}
+	for _, s := range domain.PRDataSections {
+		i, ok := picks[s]
+		if !ok {
+			return errors.Errorf("no candidate picked for %s", s)
+		}
+		if i < 0 || i >= len(candidates) || candidates[i].Parsed() == nil {
+			return errors.Errorf("candidate %d has no parsed PR data for %s", i+1, s)
+		}
+		merged.CopySection(s, candidates[i].Parsed())
+		sources[i] = true
+	}
+	if strings.TrimSpace(merged.Title) == "" || strings.TrimSpace(merged.Body) == "" {
+		return errors.New("selected PR data is missing title or body")
+	}
+
+	primary := candidates[picks[domain.PRDataSectionBody]].Response
+	// Custom schema fields are not sections; they come with the body.
+	merged.Extra = primary.Parsed.Extra
+	resp := &api.GenerateDescriptionResponse{
+		Parsed:       merged,
+		Model:        primary.Model,
+		StopReason:   primary.StopReason,
+		OutputMode:   primary.OutputMode,
+		OutputSchema: primary.OutputSchema,
+	}
+	if len(sources) == 1 {
+		resp.Description = primary.Description
+		resp.Turn = primary.Turn
+	} else {
+		b, err := yaml.Marshal(merged)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal merged PR data")
+		}
+		resp.Description = string(b)
+		resp.Turn = api.WithAssistantText(primary.Turn, resp.Description)
+	}
+	for _, cand := range candidates {
+		if cand.Response == nil {
+			continue
+		}
+		if cand.Response.Usage != nil {
+			if resp.Usage == nil {
+				resp.Usage = &domain.GenerationUsage{}
+			}
+			resp.Usage.Add(*cand.Response.Usage)
+		}
+		resp.Attempts += cand.Response.Attempts
+		resp.Latency = time.Duration(max(int64(resp.Latency), int64(cand.Response.Latency)))
+	}
+
+	c.data.GeneratedDescription = resp.Description
+	c.data.GeneratedPRData = merged
+	c.data.GeneratedPRDataParseError = ""
+	c.setGenerationResult(resp)
+	bodySpec := candidates[picks[domain.PRDataSectionBody]].Spec
+	c.recordHistory(history.KindSelection, c.historyRequest(), c.candidatePresetID(bodySpec), resp, nil)
+	return nil
+}
+
+// candidatePresetID is the prompt preset ID a candidate was generated with.
+func (c *Controller) candidatePresetID(spec CandidateSpec) string {
+	if spec.Preset != "" {
+		return spec.Preset
+	}
+	return c.currentPresetID()
+}
@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-go-golems/prescribe/internal/api"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/go-go-golems/prescribe/internal/prdata"
+)
+
+func TestCandidateSpecs_CyclesPresetsAndTemperatures(t *testing.T) {
+	specs := CandidateSpecs(domain.CandidatesConfig{
+		Count:        3,
+		Presets:      []string{"a", "b"},
+		Temperatures: []float64{0.2},
+	})
+	if len(specs) != 3 {
+		t.Fatalf("expected 3 specs, got %d", len(specs))
+	}
+	wantPresets := []string{"a", "b", "a"}
+	for i, spec := range specs {
+		if spec.Preset != wantPresets[i] {
+			t.Fatalf("spec %d: preset %q, want %q", i, spec.Preset, wantPresets[i])
+		}
+		if spec.Temperature == nil || *spec.Temperature != 0.2 {
+			t.Fatalf("spec %d: unexpected temperature %v", i, spec.Temperature)
+		}
+	}
+	if got := specs[1].Label(); got != "preset=b temperature=0.2" {
+		t.Fatalf("unexpected label %q", got)
+	}
+}
+
+func testCandidates() []Candidate {
+	return []Candidate{
+		{Err: errors.New("boom")},
+		{Response: &api.GenerateDescriptionResponse{
+			Description: "title: T1\nbody: B1\n",
+			Parsed:      &domain.GeneratedPRData{Title: "T1", Body: "B1", Changelog: "C1"},
+			Model:       "gpt-4o",
+			Usage:       &domain.GenerationUsage{InputTokens: 100, OutputTokens: 10},
+			Latency:     2 * time.Second,
+			Attempts:    1,
+		}},
+		{Response: &api.GenerateDescriptionResponse{
+			Description: "title: T2\nbody: B2\n",
+			Parsed:      &domain.GeneratedPRData{Title: "T2", Body: "B2", Changelog: "C2"},
+			Model:       "gpt-4o",
+			StopReason:  "stop",
+			Usage:       &domain.GenerationUsage{InputTokens: 100, OutputTokens: 20},
+			Latency:     3 * time.Second,
+			Attempts:    2,
+		}},
+	}
+}
+
+func TestParseCandidatePicks(t *testing.T) {
+	cands := testCandidates()
+
+	picks, err := ParseCandidatePicks("", cands)
+	if err != nil {
+		t.Fatalf("default picks: %v", err)
+	}
+	// Candidate 1 failed, so everything defaults to candidate 2 (index 1).
+	if got := picks.String(); got != "title=2,body=2,changelog=2,release_notes=2" {
+		t.Fatalf("unexpected default picks %q", got)
+	}
+
+	picks, err = ParseCandidatePicks("title=3,release-notes=3", cands)
+	if err != nil {
+		t.Fatalf("section picks: %v", err)
+	}
+	if got := picks.String(); got != "title=3,body=2,changelog=2,release_notes=3" {
+		t.Fatalf("unexpected section picks %q", got)
+	}
+
+	for _, bad := range []string{"4", "0", "title", "summary=1"} {
+		if _, err := ParseCandidatePicks(bad, cands); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestSelectCandidates_MergesSectionsAndWritesLastGenerated(t *testing.T) {
+	c := &Controller{repoPath: t.TempDir(), data: domain.NewPRData()}
+	cands := testCandidates()
+
+	picks := CandidatePicks{
+		domain.PRDataSectionTitle:        2,
+		domain.PRDataSectionBody:         1,
+		domain.PRDataSectionChangelog:    1,
+		domain.PRDataSectionReleaseNotes: 1,
+	}
+	if err := c.SelectCandidates(cands, picks); err != nil {
+		t.Fatalf("SelectCandidates: %v", err)
+	}
+
+	got := c.GetData().GeneratedPRData
+	if got.Title != "T2" || got.Body != "B1" || got.Changelog != "C1" {
+		t.Fatalf("unexpected merged data: %+v", got)
+	}
+	meta := c.GetData().GenerationMetadata
+	if meta == nil || meta.Usage == nil {
+		t.Fatalf("expected generation metadata with usage")
+	}
+	if meta.Usage.InputTokens != 200 || meta.Usage.OutputTokens != 30 || meta.Attempts != 3 || meta.LatencyMs != 3000 {
+		t.Fatalf("unexpected metadata: %+v usage=%+v", meta, *meta.Usage)
+	}
+
+	path, err := c.WriteLastGeneratedPRData()
+	if err != nil {
+		t.Fatalf("WriteLastGeneratedPRData: %v", err)
+	}
+	loaded, err := prdata.LoadGeneratedPRDataFromYAMLFile(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded.Title != "T2" || loaded.Body != "B1" {
+		t.Fatalf("unexpected written data: %+v", loaded)
+	}
+
+	// A failed candidate cannot be picked.
+	picks[domain.PRDataSectionTitle] = 0
+	if err := c.SelectCandidates(cands, picks); err == nil {
+		t.Fatalf("expected error when picking a failed candidate")
+	}
+}
+
+func TestCandidateRequest_AppliesThePreset(t *testing.T) {
+	c := &Controller{repoPath: t.TempDir(), data: domain.NewPRData()}
+	c.data.ChangedFiles = []domain.FileChange{{Path: "a.go", Included: true, Diff: "+x\n"}}
+	session := &domain.PromptPreset{
+		ID:         "session.yaml",
+		Template:   "For {{ .team }} and {{ .ticket }}.",
+		Parameters: []domain.PresetParameter{{Name: "team"}, {Name: "ticket"}},
+	}
+	c.SetPrompt(session.Template, session)
+	c.data.PresetVars = map[string]any{"team": "core", "ticket": "OPS-1"}
+
+	schema := &domain.OutputSchema{Fields: []domain.OutputField{{Name: "risk_level", Required: true}}}
+	candidate := &domain.PromptPreset{
+		ID:           "structured.yaml",
+		Template:     "Structured for {{ .team }}.",
+		OutputMode:   domain.OutputModeTool,
+		OutputSchema: schema,
+		Lint:         &domain.LintConfig{Repair: true},
+		Parameters:   []domain.PresetParameter{{Name: "team"}},
+	}
+
+	req, err := c.candidateRequest(candidate)
+	if err != nil {
+		t.Fatalf("candidateRequest: %v", err)
+	}
+	if req.Prompt != candidate.Template || req.OutputMode != domain.OutputModeTool || req.OutputSchema != schema || req.Lint == nil || !req.Lint.Repair {
+		t.Fatalf("expected the candidate preset's contract, got %+v", req)
+	}
+	if len(req.PresetVars) != 1 || req.PresetVars["team"] != "core" {
+		t.Fatalf("expected only the declared session values to carry over, got %v", req.PresetVars)
+	}
+
+	if c.data.CurrentPreset != session || c.data.PresetVars["ticket"] != "OPS-1" || c.data.CurrentPrompt != session.Template {
+		t.Fatalf("expected the session preset to be restored")
+	}
+}
@@ -4,17 +4,18 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
 	"strings"
 
+	"github.com/ThreeDotsLabs/watermill/message"
 	gepsettings "github.com/go-go-golems/geppetto/pkg/steps/ai/settings"
+	"github.com/go-go-golems/geppetto/pkg/turns"
 	"github.com/go-go-golems/prescribe/internal/api"
 	"github.com/go-go-golems/prescribe/internal/domain"
 	"github.com/go-go-golems/prescribe/internal/git"
+	"github.com/go-go-golems/prescribe/internal/history"
 	"github.com/go-go-golems/prescribe/internal/presets"
+	"github.com/go-go-golems/prescribe/internal/redact"
 	"github.com/go-go-golems/prescribe/internal/tokens"
-	"gopkg.in/yaml.v3"
 )
 
 // Controller coordinates between domain data, git, and API services
@@ -23,6 +24,21 @@ type Controller struct {
 	gitService *git.Service
 	apiService *api.Service
 	repoPath   string
+
+	// lastGeneration is the response of the last successful generation (usage, model, ...).
+	lastGeneration *api.GenerateDescriptionResponse
+	// candidates are the alternatives of the last multi-candidate generation.
+	candidates []Candidate
+	// lastTurn is the conversation behind the current generated description (for refinements).
+	lastTurn *turns.Turn
+	// noCache bypasses the generation cache (.pr-builder/cache/) for lookups; results are still stored.
+	noCache bool
+	// lintRepair enables the lint repair re-prompt regardless of the preset (see SetLintRepair).
+	lintRepair bool
+	// redactionMode overrides redaction.mode of the config ("" => config; see SetRedactionMode).
+	redactionMode redact.Mode
+	// redaction is the secret scan report of the last BuildGenerateDescriptionRequest.
+	redaction RedactionReport
 }
 
 // NewController creates a new controller
@@ -47,6 +63,43 @@ func (c *Controller) SetStepSettings(stepSettings *gepsettings.StepSettings) {
 		return
 	}
 	c.apiService.SetStepSettings(stepSettings)
+	c.data.SetTokenCounter(c.apiService.TokenCounter())
+	if c.gitService != nil {
+		c.gitService.SetTokenCounter(c.data.TokenCounter)
+	}
+}
+
+// SetRecordFixture records every inference result into a mock engine fixture at path
+// (`generate --record-fixture`), for offline replay with --ai-engine replay:<path>.
+func (c *Controller) SetRecordFixture(path string) {
+	if c == nil || c.apiService == nil {
+		return
+	}
+	c.apiService.SetRecordFixture(path)
+}
+
+// SetRetryPolicy configures transient-error retries (backoff, per-attempt timeout) for all inference runs.
+func (c *Controller) SetRetryPolicy(p api.RetryPolicy) {
+	if c == nil || c.apiService == nil {
+		return
+	}
+	c.apiService.SetRetryPolicy(p)
+}
+
+// SetFallbacks configures the step settings tried, in order, when the configured model keeps failing.
+func (c *Controller) SetFallbacks(fallbacks ...*gepsettings.StepSettings) {
+	if c == nil || c.apiService == nil {
+		return
+	}
+	c.apiService.SetFallbacks(fallbacks...)
+}
+
+// TokenCounter returns the tokenizer used for token counts (selected from the configured model).
+func (c *Controller) TokenCounter() *tokens.Counter {
+	if c.data.TokenCounter == nil {
+		return tokens.Default()
+	}
+	return c.data.TokenCounter
 }
 
 // Initialize loads the PR data from git
@@ -69,12 +122,13 @@ func (c *Controller) Initialize(targetBranch string) error {
 	c.data.TargetBranch = targetBranch
 
 	// Get changed files
-	files, err := c.gitService.GetChangedFiles(sourceBranch, targetBranch)
+	files, denied, err := c.gitService.GetChangedFiles(sourceBranch, targetBranch)
 	if err != nil {
 		return fmt.Errorf("failed to get changed files: %w", err)
 	}
 
 	c.data.ChangedFiles = files
+	c.data.DeniedFiles = denied
 
 	return nil
 }
@@ -132,15 +186,33 @@ func (c *Controller) RemoveFilter(index int) error {
 	return c.data.RemoveFilter(index)
 }
 
-// AddContextFile adds a file from the repository as context
+// CheckAllowed returns an error naming the rule if any of paths is denied by .prescribeignore.
+func (c *Controller) CheckAllowed(paths ...string) error {
+	if c.gitService == nil {
+		return nil
+	}
+	for _, path := range paths {
+		if rule, ok := c.gitService.Denied(path); ok {
+			return fmt.Errorf("%s is denied by %s", path, rule)
+		}
+	}
+	return nil
+}
+
+// AddContextFile adds a file from the repository as context. Files denied by .prescribeignore
+// are refused.
 func (c *Controller) AddContextFile(path string) error {
+	if err := c.CheckAllowed(path); err != nil {
+		return fmt.Errorf("cannot add context file: %w", err)
+	}
+
 	// Get file content from current branch
 	content, err := c.gitService.GetFileContent(c.data.SourceBranch, path)
 	if err != nil {
 		return fmt.Errorf("failed to get file content: %w", err)
 	}
 
-	tokens_ := tokens.Count(content)
+	tokens_ := c.data.TokenCounter.Count(content)
 
 	c.data.AddContextItem(domain.ContextItem{
 		Type:    domain.ContextTypeFile,
@@ -154,7 +226,7 @@ func (c *Controller) AddContextFile(path string) error {
 
 // AddContextNote adds a text note as context
 func (c *Controller) AddContextNote(content string) {
-	tokens_ := tokens.Count(content)
+	tokens_ := c.data.TokenCounter.Count(content)
 
 	c.data.AddContextItem(domain.ContextItem{
 		Type:    domain.ContextTypeNote,
@@ -168,8 +240,12 @@ func (c *Controller) RemoveContextItem(index int) error {
 	return c.data.RemoveContextItem(index)
 }
 
-// SetPrompt sets the current prompt
+// SetPrompt sets the current prompt. Switching to another preset (or to a custom prompt) drops
+// the parameter values of the previous one.
 func (c *Controller) SetPrompt(prompt string, preset *domain.PromptPreset) {
+	if preset == nil || c.data.CurrentPreset == nil || preset.ID != c.data.CurrentPreset.ID {
+		c.data.PresetVars = nil
+	}
 	c.data.SetPrompt(prompt, preset)
 }
 
@@ -179,7 +255,29 @@ func (c *Controller) LoadPromptPreset(presetID string) error {
 	if err != nil {
 		return err
 	}
-	c.data.SetPrompt(preset.Template, preset)
+	c.SetPrompt(preset.Template, preset)
+	return nil
+}
+
+// SetPresetVars parses key=value pairs into the values of the current preset's parameters and
+// validates them (with the values already set) against the declarations.
+func (c *Controller) SetPresetVars(pairs []string) error {
+	values := map[string]any{}
+	for k, v := range c.data.PresetVars {
+		values[k] = v
+	}
+	for _, kv := range pairs {
+		k, v, ok := strings.Cut(kv, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return fmt.Errorf("invalid --preset-var %q (expected key=value)", kv)
+		}
+		values[k] = v
+	}
+	if _, err := c.data.CurrentPreset.ResolveParameters(values); err != nil {
+		return err
+	}
+	c.data.PresetVars = values
 	return nil
 }
 
@@ -193,53 +291,25 @@ func (c *Controller) LoadGlobalPresets() ([]domain.PromptPreset, error) {
 	return presets.LoadGlobalPresets()
 }
 
-// SavePromptPreset saves a prompt preset
-func (c *Controller) SavePromptPreset(name, description, template string, location domain.PresetLocation) error {
-	var dir string
-
-	if location == domain.PresetLocationProject {
-		dir = filepath.Join(c.repoPath, ".pr-builder", "prompts")
-	} else {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return err
-		}
-		dir = filepath.Join(homeDir, ".pr-builder", "prompts")
-	}
-
-	// Create directory if it doesn't exist
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
-
-	// Create filename from name
-	filename := strings.ToLower(strings.ReplaceAll(name, " ", "_")) + ".yaml"
-	filePath := filepath.Join(dir, filename)
-
-	// Create preset data
-	preset := struct {
-		Name        string `yaml:"name"`
-		Description string `yaml:"description"`
-		Template    string `yaml:"template"`
-	}{
-		Name:        name,
-		Description: description,
-		Template:    template,
-	}
-
-	// Marshal to YAML
-	data, err := yaml.Marshal(preset)
+// BuildGenerateDescriptionRequest builds the canonical API request for generating a PR description.
+// This is intended to be the single source of truth for which inputs are used (visible+included files, prompt, context).
+// Secrets are redacted (or the request is refused) per the redaction settings, and the report is
+// kept for LastRedaction.
+func (c *Controller) BuildGenerateDescriptionRequest() (api.GenerateDescriptionRequest, error) {
+	req, err := c.PreviewGenerateDescriptionRequest()
 	if err != nil {
-		return err
+		return api.GenerateDescriptionRequest{}, err
 	}
-
-	// Write to file
-	return os.WriteFile(filePath, data, 0644)
+	if err := c.redactRequest(&req); err != nil {
+		return api.GenerateDescriptionRequest{}, err
+	}
+	return req, nil
 }
 
-// BuildGenerateDescriptionRequest builds the canonical API request for generating a PR description.
-// This is intended to be the single source of truth for which inputs are used (visible+included files, prompt, context).
-func (c *Controller) BuildGenerateDescriptionRequest() (api.GenerateDescriptionRequest, error) {
+// PreviewGenerateDescriptionRequest builds the same request as BuildGenerateDescriptionRequest
+// without the redaction step, so it has no side effects and never fails in block mode. It is meant
+// for estimates (cost, token budget, token counts), not for requests that are sent.
+func (c *Controller) PreviewGenerateDescriptionRequest() (api.GenerateDescriptionRequest, error) {
 	// Validate we have content to generate from
 	visibleFiles := c.data.GetVisibleFiles()
 	includedFiles := make([]domain.FileChange, 0)
@@ -283,7 +353,7 @@ func (c *Controller) BuildGenerateDescriptionRequest() (api.GenerateDescriptionR
 					Type:    domain.ContextTypeGitHistory,
 					Path:    fmt.Sprintf("%s..%s", c.data.TargetBranch, c.data.SourceBranch),
 					Content: history,
-					Tokens:  tokens.Count(history),
+					Tokens:  c.data.TokenCounter.Count(history),
 				})
 			}
 		}
@@ -327,12 +397,24 @@ func (c *Controller) BuildGenerateDescriptionRequest() (api.GenerateDescriptionR
 				Type:    ctxType,
 				Path:    path,
 				Content: content,
-				Tokens:  tokens.Count(content),
+				Tokens:  c.data.TokenCounter.Count(content),
 			})
 		}
 	}
 
-	return api.GenerateDescriptionRequest{
+	presetVars, err := c.data.CurrentPreset.ResolveParameters(c.data.PresetVars)
+	if err != nil {
+		return api.GenerateDescriptionRequest{}, err
+	}
+	if err := domain.ValidateTone(c.data.PromptVars.Tone); err != nil {
+		return api.GenerateDescriptionRequest{}, err
+	}
+	var promptParts *domain.PromptParts
+	if c.data.CurrentPreset != nil {
+		promptParts = c.data.CurrentPreset.Parts
+	}
+
+	req := api.GenerateDescriptionRequest{
 		SourceBranch:      c.data.SourceBranch,
 		TargetBranch:      c.data.TargetBranch,
 		SourceCommit:      sourceCommit,
@@ -342,7 +424,14 @@ func (c *Controller) BuildGenerateDescriptionRequest() (api.GenerateDescriptionR
 		Files:             includedFiles,
 		AdditionalContext: additionalContext,
 		Prompt:            c.data.CurrentPrompt,
-	}, nil
+		PromptParts:       promptParts,
+		PromptVars:        c.data.PromptVars,
+		PresetVars:        presetVars,
+		OutputMode:        c.data.EffectiveOutputMode(),
+		OutputSchema:      c.data.EffectiveOutputSchema(),
+		Lint:              c.lintConfig(),
+	}
+	return req, nil
 }
 
 // GenerateDescription generates a PR description using the API
@@ -357,21 +446,41 @@ func (c *Controller) GenerateDescription(ctx context.Context) (string, error) {
 		return "", err
 	}
 
+	key := c.cacheKey(req)
+	if resp := c.cachedGeneration(key); resp != nil {
+		c.applyGeneration(resp)
+		return resp.Description, nil
+	}
+
 	// Generate description
 	resp, err := c.apiService.GenerateDescription(ctx, req)
 	if err != nil {
 		return "", err
 	}
+	c.storeGeneration(key, resp)
 
-	c.data.GeneratedDescription = resp.Description
-	c.data.GeneratedPRData = resp.Parsed
-	c.data.GeneratedPRDataParseError = resp.ParseError
+	c.applyGeneration(resp)
+	c.recordHistory(history.KindGenerate, req, c.currentPresetID(), resp, nil)
 	return resp.Description, nil
 }
 
 // GenerateDescriptionStreaming runs inference with stdio streaming enabled and returns the final description.
 // Streaming output is written to w as events arrive.
 func (c *Controller) GenerateDescriptionStreaming(ctx context.Context, w io.Writer) (string, error) {
+	return c.generateStreaming(func(req api.GenerateDescriptionRequest) (*api.GenerateDescriptionResponse, error) {
+		return c.apiService.GenerateDescriptionStreaming(ctx, req, w)
+	})
+}
+
+// GenerateDescriptionWithEventHandler is GenerateDescriptionStreaming with the streaming events
+// passed to handler (see api.PartialTextHandler). A cached result produces no events.
+func (c *Controller) GenerateDescriptionWithEventHandler(ctx context.Context, handler func(msg *message.Message) error) (string, error) {
+	return c.generateStreaming(func(req api.GenerateDescriptionRequest) (*api.GenerateDescriptionResponse, error) {
+		return c.apiService.GenerateDescriptionWithEventHandler(ctx, req, handler)
+	})
+}
+
+func (c *Controller) generateStreaming(run func(api.GenerateDescriptionRequest) (*api.GenerateDescriptionResponse, error)) (string, error) {
 	req, err := c.BuildGenerateDescriptionRequest()
 	if err != nil {
 		return "", err
@@ -381,15 +490,37 @@ func (c *Controller) GenerateDescriptionStreaming(ctx context.Context, w io.Writ
 		return "", err
 	}
 
-	resp, err := c.apiService.GenerateDescriptionStreaming(ctx, req, w)
+	key := c.cacheKey(req)
+	if resp := c.cachedGeneration(key); resp != nil {
+		c.applyGeneration(resp)
+		return resp.Description, nil
+	}
+
+	resp, err := run(req)
 	if err != nil {
 		return "", err
 	}
+	c.storeGeneration(key, resp)
+
+	c.applyGeneration(resp)
+	c.recordHistory(history.KindGenerate, req, c.currentPresetID(), resp, nil)
+	return resp.Description, nil
+}
 
+// applyGeneration makes resp the current generated description.
+func (c *Controller) applyGeneration(resp *api.GenerateDescriptionResponse) {
 	c.data.GeneratedDescription = resp.Description
 	c.data.GeneratedPRData = resp.Parsed
 	c.data.GeneratedPRDataParseError = resp.ParseError
-	return resp.Description, nil
+	c.setGenerationResult(resp)
+}
+
+func (c *Controller) setGenerationResult(resp *api.GenerateDescriptionResponse) {
+	c.lintGeneration(resp)
+	c.lastGeneration = resp
+	c.lastTurn = resp.Turn
+	meta := resp.Metadata()
+	c.data.GenerationMetadata = &meta
 }
 
 // GetRepoFiles returns all files in the repository
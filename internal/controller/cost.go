@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"github.com/go-go-golems/prescribe/internal/api"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/go-go-golems/prescribe/internal/pricing"
+)
+
+// CostEstimate is a priced token count for the configured provider/model.
+// Priced is false when the model has no entry in the pricing table; the token counts are still set.
+type CostEstimate struct {
+	Provider     string
+	Model        string
+	InputTokens  int
+	CachedTokens int
+	// CacheReadTokens and CacheWriteTokens come on top of InputTokens (see domain.GenerationUsage).
+	CacheReadTokens  int
+	CacheWriteTokens int
+	OutputTokens     int
+	Priced           bool
+	Cost             pricing.Cost
+}
+
+// PricingTable returns the builtin pricing table with overrides from ~/.pr-builder/config.yaml
+// and <repo>/.pr-builder/config.yaml applied (repo wins over global).
+func (c *Controller) PricingTable() (pricing.Table, error) {
+	table, err := pricing.Builtin()
+	if err != nil {
+		return pricing.Table{}, err
+	}
+	globalCfg, err := loadGlobalConfig()
+	if err != nil {
+		return pricing.Table{}, err
+	}
+	repoCfg, err := c.loadRepoConfig()
+	if err != nil {
+		return pricing.Table{}, err
+	}
+	return table.WithOverrides(globalCfg.Pricing).WithOverrides(repoCfg.Pricing), nil
+}
+
+func (c *Controller) priceTokens(model string, usage domain.GenerationUsage) (CostEstimate, error) {
+	est := CostEstimate{
+		Model:            model,
+		InputTokens:      usage.InputTokens,
+		CachedTokens:     usage.CachedTokens,
+		CacheReadTokens:  usage.CacheReadTokens,
+		CacheWriteTokens: usage.CacheWriteTokens,
+		OutputTokens:     usage.OutputTokens,
+	}
+	if c.apiService != nil {
+		est.Provider = c.apiService.Provider()
+		if est.Model == "" {
+			est.Model = c.apiService.ModelName()
+		}
+	}
+	table, err := c.PricingTable()
+	if err != nil {
+		return est, err
+	}
+	price, ok := table.Lookup(est.Provider, est.Model)
+	if !ok {
+		// The API type defaults to openai, so the configured provider is not necessarily the model's:
+		// fall back to a match on the model alone and report the provider of that entry.
+		if price, ok = table.Lookup("", est.Model); ok && price.Provider != "" {
+			est.Provider = price.Provider
+		}
+	}
+	if ok {
+		est.Priced = true
+		est.Cost = price.Cost(pricing.Usage{
+			Input:       usage.InputTokens,
+			CachedInput: usage.CachedTokens,
+			CacheRead:   usage.CacheReadTokens,
+			CacheWrite:  usage.CacheWriteTokens,
+			Output:      usage.OutputTokens,
+		})
+	}
+	return est, nil
+}
+
+// EstimateCost prices the compiled prompt for the configured model. The output side is an upper
+// bound: the configured max response tokens, or the default response reserve.
+func (c *Controller) EstimateCost() (CostEstimate, error) {
+	req, err := c.PreviewGenerateDescriptionRequest()
+	if err != nil {
+		return CostEstimate{}, err
+	}
+	sys, user, err := api.CompilePrompt(req)
+	if err != nil {
+		return CostEstimate{}, err
+	}
+	counter := c.TokenCounter()
+	outputTokens := defaultResponseReserve
+	if c.apiService != nil {
+		if n := c.apiService.MaxResponseTokens(); n > 0 {
+			outputTokens = n
+		}
+	}
+	return c.priceTokens("", domain.GenerationUsage{InputTokens: counter.Count(sys) + counter.Count(user), OutputTokens: outputTokens})
+}
+
+// Usage returns the token counts of the estimate.
+func (e CostEstimate) Usage() domain.GenerationUsage {
+	return domain.GenerationUsage{
+		InputTokens:      e.InputTokens,
+		OutputTokens:     e.OutputTokens,
+		CachedTokens:     e.CachedTokens,
+		CacheReadTokens:  e.CacheReadTokens,
+		CacheWriteTokens: e.CacheWriteTokens,
+	}
+}
+
+// ActualCost prices the provider-reported usage of a generation. model may be empty to use the
+// configured model (e.g. when the provider did not report one).
+func (c *Controller) ActualCost(model string, usage domain.GenerationUsage) (CostEstimate, error) {
+	return c.priceTokens(model, usage)
+}
+
+// LastGeneration returns the response of the last successful generation, or nil.
+func (c *Controller) LastGeneration() *api.GenerateDescriptionResponse {
+	return c.lastGeneration
+}
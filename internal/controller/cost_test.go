@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-go-golems/geppetto/pkg/steps/ai/settings"
+	"github.com/go-go-golems/geppetto/pkg/steps/ai/types"
+	"github.com/go-go-golems/prescribe/internal/api"
+	"github.com/go-go-golems/prescribe/internal/domain"
+)
+
+func TestPricingTable_ConfigOverrides(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	repo := t.TempDir()
+	c := &Controller{repoPath: repo, data: domain.NewPRData()}
+
+	write := func(dir, s string) {
+		if err := os.MkdirAll(filepath.Join(dir, ".pr-builder"), 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, ".pr-builder", "config.yaml"), []byte(s), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	write(home, "pricing:\n  - {model: my-model, input_per_mtok: 1, output_per_mtok: 2}\n  - {model: gpt-4o, input_per_mtok: 5, output_per_mtok: 5}\n")
+	write(repo, "pricing:\n  - {model: my-model, input_per_mtok: 3, output_per_mtok: 4}\n")
+
+	table, err := c.PricingTable()
+	if err != nil {
+		t.Fatalf("PricingTable: %v", err)
+	}
+	if p, ok := table.Lookup("", "my-model-v2"); !ok || p.InputPerMTok != 3 {
+		t.Fatalf("expected repo override to win, got %+v (ok=%v)", p, ok)
+	}
+	if p, ok := table.Lookup("openai", "gpt-4o"); !ok || p.InputPerMTok != 5 {
+		t.Fatalf("expected global override to win over builtin, got %+v (ok=%v)", p, ok)
+	}
+
+	est, err := c.ActualCost("my-model", domain.GenerationUsage{InputTokens: 1_000_000, OutputTokens: 500_000})
+	if err != nil {
+		t.Fatalf("ActualCost: %v", err)
+	}
+	if !est.Priced || est.Cost.Total() != 5 {
+		t.Fatalf("unexpected actual cost: %+v", est)
+	}
+}
+
+func TestActualCost_InfersProviderFromModel(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	c := &Controller{repoPath: t.TempDir(), data: domain.NewPRData(), apiService: api.NewService()}
+	ss, err := settings.NewStepSettings()
+	if err != nil {
+		t.Fatalf("NewStepSettings: %v", err)
+	}
+	apiType := types.ApiTypeOpenAI
+	ss.Chat.ApiType = &apiType
+	c.apiService.SetStepSettings(ss)
+
+	est, err := c.ActualCost("claude-sonnet-4-20250514", domain.GenerationUsage{InputTokens: 1_000_000})
+	if err != nil {
+		t.Fatalf("ActualCost: %v", err)
+	}
+	if !est.Priced || est.Provider != "claude" || est.Cost.Input != 3 {
+		t.Fatalf("expected the claude price despite the default openai API type, got %+v", est)
+	}
+}
@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// FilterPresetBundleFormat selects the on-disk format used to share presets across repos.
+type FilterPresetBundleFormat string
+
+const (
+	// FilterPresetBundleYAML is a single YAML document with a `presets:` list.
+	FilterPresetBundleYAML FilterPresetBundleFormat = "yaml"
+	// FilterPresetBundleTar is a tar archive containing one preset YAML file per entry.
+	FilterPresetBundleTar FilterPresetBundleFormat = "tar"
+)
+
+const filterPresetBundleVersion = "1"
+
+type filterPresetBundleYAML struct {
+	Version string                        `yaml:"version"`
+	Presets []filterPresetBundleEntryYAML `yaml:"presets"`
+}
+
+type filterPresetBundleEntryYAML struct {
+	ID               string `yaml:"id"`
+	filterPresetYAML `yaml:",inline"`
+}
+
+// FilterPresetBundleFormatForPath guesses the bundle format from a file extension.
+func FilterPresetBundleFormatForPath(p string) FilterPresetBundleFormat {
+	if strings.EqualFold(filepath.Ext(p), ".tar") {
+		return FilterPresetBundleTar
+	}
+	return FilterPresetBundleYAML
+}
+
+// ExportFilterPresets writes presets into a shareable bundle.
+func ExportFilterPresets(w io.Writer, presets []domain.FilterPreset, format FilterPresetBundleFormat) error {
+	switch format {
+	case FilterPresetBundleTar:
+		tw := tar.NewWriter(w)
+		now := time.Now()
+		for _, p := range presets {
+			data, err := marshalFilterPreset(p.Name, p.Description, p.Rules)
+			if err != nil {
+				return err
+			}
+			hdr := &tar.Header{
+				Name:    bundleEntryID(p),
+				Mode:    0644,
+				Size:    int64(len(data)),
+				ModTime: now,
+			}
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if _, err := tw.Write(data); err != nil {
+				return err
+			}
+		}
+		return tw.Close()
+
+	case FilterPresetBundleYAML:
+		bundle := filterPresetBundleYAML{
+			Version: filterPresetBundleVersion,
+			Presets: make([]filterPresetBundleEntryYAML, 0, len(presets)),
+		}
+		for _, p := range presets {
+			rules := make([]filterPresetRuleYAML, 0, len(p.Rules))
+			for _, r := range p.Rules {
+				rules = append(rules, filterPresetRuleYAML{Type: string(r.Type), Pattern: r.Pattern})
+			}
+			bundle.Presets = append(bundle.Presets, filterPresetBundleEntryYAML{
+				ID: bundleEntryID(p),
+				filterPresetYAML: filterPresetYAML{
+					Name:        p.Name,
+					Description: p.Description,
+					Rules:       rules,
+				},
+			})
+		}
+		data, err := yaml.Marshal(bundle)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+
+	default:
+		return fmt.Errorf("unsupported filter preset bundle format: %s", format)
+	}
+}
+
+func bundleEntryID(p domain.FilterPreset) string {
+	if p.ID != "" {
+		return p.ID
+	}
+	return filterPresetFilename(p.Name)
+}
+
+// ReadFilterPresetBundle parses a bundle produced by ExportFilterPresets.
+// Every entry is validated; the first invalid entry aborts the read.
+func ReadFilterPresetBundle(r io.Reader, format FilterPresetBundleFormat) ([]domain.FilterPreset, error) {
+	presets := make([]domain.FilterPreset, 0)
+
+	switch format {
+	case FilterPresetBundleTar:
+		tr := tar.NewReader(r)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read preset bundle: %w", err)
+			}
+			if hdr.Typeflag != tar.TypeReg {
+				continue
+			}
+			id := path.Base(hdr.Name)
+			if !isFilterPresetFilename(id) {
+				continue
+			}
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s from preset bundle: %w", hdr.Name, err)
+			}
+			p, err := parseFilterPresetYAML(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", hdr.Name, err)
+			}
+			p.ID = id
+			presets = append(presets, p)
+		}
+
+	case FilterPresetBundleYAML:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		var bundle filterPresetBundleYAML
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&bundle); err != nil {
+			return nil, fmt.Errorf("invalid preset bundle YAML: %w", err)
+		}
+		for i, e := range bundle.Presets {
+			entry, err := yaml.Marshal(e.filterPresetYAML)
+			if err != nil {
+				return nil, err
+			}
+			p, err := parseFilterPresetYAML(entry)
+			if err != nil {
+				return nil, fmt.Errorf("preset %d (%s): %w", i, e.ID, err)
+			}
+			p.ID = strings.TrimSpace(e.ID)
+			if p.ID == "" || !isFilterPresetFilename(p.ID) || path.Base(p.ID) != p.ID {
+				p.ID = filterPresetFilename(p.Name)
+			}
+			presets = append(presets, p)
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported filter preset bundle format: %s", format)
+	}
+
+	return presets, nil
+}
+
+// ImportFilterPresets writes bundle presets into a preset location.
+// Existing files are left untouched unless overwrite is set; skipped IDs are returned separately.
+func (c *Controller) ImportFilterPresets(presets []domain.FilterPreset, location domain.PresetLocation, overwrite bool) ([]string, []string, error) {
+	dir, err := c.filterPresetDir(location)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, err
+	}
+
+	imported := make([]string, 0, len(presets))
+	skipped := make([]string, 0)
+	for _, p := range presets {
+		id := bundleEntryID(p)
+		target := filepath.Join(dir, id)
+		if _, err := os.Stat(target); err == nil && !overwrite {
+			skipped = append(skipped, id)
+			continue
+		}
+		data, err := marshalFilterPreset(p.Name, p.Description, p.Rules)
+		if err != nil {
+			return imported, skipped, err
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return imported, skipped, err
+		}
+		imported = append(imported, id)
+	}
+	return imported, skipped, nil
+}
@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/rs/zerolog/log"
 	"gopkg.in/yaml.v3"
 )
 
@@ -21,102 +24,209 @@ type filterPresetRuleYAML struct {
 	Pattern string `yaml:"pattern"` // glob pattern
 }
 
+// FilterPresetFileError describes a preset file that exists on disk but could not be loaded.
+// These are reported (rather than silently skipped) so users can fix broken presets.
+type FilterPresetFileError struct {
+	ID       string
+	Path     string
+	Location domain.PresetLocation
+	Err      error
+}
+
+func (e FilterPresetFileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e FilterPresetFileError) Unwrap() error {
+	return e.Err
+}
+
 // LoadProjectFilterPresets loads filter presets from the project directory: <repo>/.pr-builder/filters
 func (c *Controller) LoadProjectFilterPresets() ([]domain.FilterPreset, error) {
-	presetDir := filepath.Join(c.repoPath, ".pr-builder", "filters")
-	return c.loadFilterPresetsFromDir(presetDir, domain.PresetLocationProject)
+	presets, fileErrs, err := c.ScanFilterPresets(domain.PresetLocationProject)
+	logFilterPresetFileErrors(fileErrs)
+	return presets, err
 }
 
 // LoadGlobalFilterPresets loads filter presets from the global directory: ~/.pr-builder/filters
 func (c *Controller) LoadGlobalFilterPresets() ([]domain.FilterPreset, error) {
-	homeDir, err := os.UserHomeDir()
+	presets, fileErrs, err := c.ScanFilterPresets(domain.PresetLocationGlobal)
+	logFilterPresetFileErrors(fileErrs)
+	return presets, err
+}
+
+// ScanFilterPresets loads all presets from a location and returns the files that failed
+// to load (unreadable, malformed YAML, or invalid rules) alongside the valid presets.
+func (c *Controller) ScanFilterPresets(location domain.PresetLocation) ([]domain.FilterPreset, []FilterPresetFileError, error) {
+	if location == domain.PresetLocationBuiltin {
+		presets, err := LoadBuiltinFilterPresets()
+		return presets, nil, err
+	}
+	dir, err := c.filterPresetDir(location)
+	if err != nil {
+		return nil, nil, err
+	}
+	return loadFilterPresetsFromDir(dir, location)
+}
+
+func logFilterPresetFileErrors(fileErrs []FilterPresetFileError) {
+	for _, fe := range fileErrs {
+		log.Warn().
+			Str("path", fe.Path).
+			Str("location", string(fe.Location)).
+			Err(fe.Err).
+			Msg("skipping invalid filter preset")
+	}
+}
+
+func (c *Controller) filterPresetDir(location domain.PresetLocation) (string, error) {
+	switch location {
+	case domain.PresetLocationProject:
+		return filepath.Join(c.repoPath, ".pr-builder", "filters"), nil
+	case domain.PresetLocationGlobal:
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(homeDir, ".pr-builder", "filters"), nil
+	case domain.PresetLocationBuiltin:
+		// Builtin presets are embedded in the binary and have no backing directory.
+		return "", fmt.Errorf("builtin filter presets are read-only")
+	default:
+		return "", fmt.Errorf("unsupported preset location: %s", location)
+	}
+}
+
+// FilterPresetPath returns the backing file path for a preset ID at the given location. IDs are
+// plain preset filenames, so a path can never point outside the preset directory.
+func (c *Controller) FilterPresetPath(presetID string, location domain.PresetLocation) (string, error) {
+	if !isFilterPresetFilename(presetID) || filepath.Base(presetID) != presetID {
+		return "", fmt.Errorf("invalid filter preset ID: %s", presetID)
+	}
+	dir, err := c.filterPresetDir(location)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	presetDir := filepath.Join(homeDir, ".pr-builder", "filters")
-	return c.loadFilterPresetsFromDir(presetDir, domain.PresetLocationGlobal)
+	return filepath.Join(dir, presetID), nil
 }
 
-func (c *Controller) loadFilterPresetsFromDir(dir string, location domain.PresetLocation) ([]domain.FilterPreset, error) {
+func loadFilterPresetsFromDir(dir string, location domain.PresetLocation) ([]domain.FilterPreset, []FilterPresetFileError, error) {
 	presets := make([]domain.FilterPreset, 0)
+	fileErrs := make([]FilterPresetFileError, 0)
 
 	// Check if directory exists
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		return presets, nil
+		return presets, fileErrs, nil
 	}
 
 	entries, err := os.ReadDir(dir)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
 		}
-		if filepath.Ext(entry.Name()) != ".yaml" && filepath.Ext(entry.Name()) != ".yml" {
+		if !isFilterPresetFilename(entry.Name()) {
 			continue
 		}
 
 		filePath := filepath.Join(dir, entry.Name())
 		data, err := os.ReadFile(filePath)
 		if err != nil {
+			fileErrs = append(fileErrs, FilterPresetFileError{ID: entry.Name(), Path: filePath, Location: location, Err: err})
 			continue
 		}
 
-		var preset filterPresetYAML
-		if err := yaml.Unmarshal(data, &preset); err != nil {
+		preset, err := parseFilterPresetYAML(data)
+		if err != nil {
+			fileErrs = append(fileErrs, FilterPresetFileError{ID: entry.Name(), Path: filePath, Location: location, Err: err})
 			continue
 		}
+		preset.ID = entry.Name()
+		preset.Location = location
 
-		rules := make([]domain.FilterRule, 0, len(preset.Rules))
-		for i, r := range preset.Rules {
-			rules = append(rules, domain.FilterRule{
-				Type:    domain.FilterType(r.Type),
-				Pattern: r.Pattern,
-				Order:   i,
-			})
-		}
+		presets = append(presets, preset)
+	}
+
+	return presets, fileErrs, nil
+}
+
+func isFilterPresetFilename(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
+}
 
-		presets = append(presets, domain.FilterPreset{
-			ID:          entry.Name(),
-			Name:        preset.Name,
-			Description: preset.Description,
-			Rules:       rules,
-			Location:    location,
+// parseFilterPresetYAML decodes and validates a single preset document.
+// Unknown keys are rejected so typos (e.g. "rule:" instead of "rules:") surface as errors.
+func parseFilterPresetYAML(data []byte) (domain.FilterPreset, error) {
+	var raw filterPresetYAML
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&raw); err != nil {
+		return domain.FilterPreset{}, fmt.Errorf("invalid preset YAML: %w", err)
+	}
+
+	rules := make([]domain.FilterRule, 0, len(raw.Rules))
+	for i, r := range raw.Rules {
+		rules = append(rules, domain.FilterRule{
+			Type:    domain.FilterType(r.Type),
+			Pattern: r.Pattern,
+			Order:   i,
 		})
 	}
 
-	return presets, nil
+	preset := domain.FilterPreset{
+		Name:        raw.Name,
+		Description: raw.Description,
+		Rules:       rules,
+	}
+	if problems := ValidateFilterPreset(preset); len(problems) > 0 {
+		return domain.FilterPreset{}, fmt.Errorf("invalid preset: %s", strings.Join(problems, "; "))
+	}
+	return preset, nil
 }
 
-// SaveFilterPreset saves a filter preset under either the project or global preset directory.
-func (c *Controller) SaveFilterPreset(name, description string, rules []domain.FilterRule, location domain.PresetLocation) error {
-	var dir string
-	switch location {
-	case domain.PresetLocationProject:
-		dir = filepath.Join(c.repoPath, ".pr-builder", "filters")
-	case domain.PresetLocationGlobal:
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return err
+// ValidateFilterPreset checks a preset for problems that would make it unusable.
+// It returns a human-readable list of problems (empty if the preset is valid).
+func ValidateFilterPreset(preset domain.FilterPreset) []string {
+	problems := make([]string, 0)
+	if strings.TrimSpace(preset.Name) == "" {
+		problems = append(problems, "missing name")
+	}
+	if len(preset.Rules) == 0 {
+		problems = append(problems, "no rules")
+	}
+	for i, r := range preset.Rules {
+		switch r.Type {
+		case domain.FilterTypeInclude, domain.FilterTypeExclude:
+		default:
+			problems = append(problems, fmt.Sprintf("rule %d: unknown type %q (expected include or exclude)", i, r.Type))
+		}
+		if strings.TrimSpace(r.Pattern) == "" {
+			problems = append(problems, fmt.Sprintf("rule %d: empty pattern", i))
+		} else if !doublestar.ValidatePattern(r.Pattern) {
+			problems = append(problems, fmt.Sprintf("rule %d: invalid glob pattern %q", i, r.Pattern))
 		}
-		dir = filepath.Join(homeDir, ".pr-builder", "filters")
-	case domain.PresetLocationBuiltin:
-		// Builtin presets are shipped with the binary and cannot be saved to.
-		return fmt.Errorf("unsupported preset location: %s", location)
-	default:
-		return fmt.Errorf("unsupported preset location: %s", location)
 	}
+	return problems
+}
 
-	// Create directory if it doesn't exist
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
+var reFilterPresetFilenameUnsafe = regexp.MustCompile(`[^a-z0-9._-]+`)
 
-	filename := strings.ToLower(strings.ReplaceAll(name, " ", "_")) + ".yaml"
-	filePath := filepath.Join(dir, filename)
+// filterPresetFilename derives a stable, filesystem-safe filename from a preset name.
+func filterPresetFilename(name string) string {
+	slug := strings.ToLower(strings.TrimSpace(name))
+	slug = reFilterPresetFilenameUnsafe.ReplaceAllString(slug, "_")
+	slug = strings.Trim(slug, "._")
+	if slug == "" {
+		slug = "preset"
+	}
+	return slug + ".yaml"
+}
 
+func marshalFilterPreset(name, description string, rules []domain.FilterRule) ([]byte, error) {
 	yamlRules := make([]filterPresetRuleYAML, 0, len(rules))
 	for _, r := range rules {
 		yamlRules = append(yamlRules, filterPresetRuleYAML{
@@ -125,16 +235,139 @@ func (c *Controller) SaveFilterPreset(name, description string, rules []domain.F
 		})
 	}
 
-	preset := filterPresetYAML{
+	return yaml.Marshal(filterPresetYAML{
 		Name:        name,
 		Description: description,
 		Rules:       yamlRules,
+	})
+}
+
+// SaveFilterPreset saves a filter preset under either the project or global preset directory.
+//
+// If a preset with the same name (case-insensitive) already exists at that location, its file
+// is overwritten in place instead of creating a second file with a differently derived name.
+// It returns the preset ID (filename) that was written.
+func (c *Controller) SaveFilterPreset(name, description string, rules []domain.FilterRule, location domain.PresetLocation) (string, error) {
+	dir, err := c.filterPresetDir(location)
+	if err != nil {
+		return "", err
+	}
+
+	if problems := ValidateFilterPreset(domain.FilterPreset{Name: name, Rules: rules}); len(problems) > 0 {
+		return "", fmt.Errorf("invalid filter preset: %s", strings.Join(problems, "; "))
+	}
+
+	// Create directory if it doesn't exist
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	filename := filterPresetFilename(name)
+	existing, _, err := loadFilterPresetsFromDir(dir, location)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range existing {
+		if strings.EqualFold(strings.TrimSpace(p.Name), strings.TrimSpace(name)) {
+			filename = p.ID
+			break
+		}
+	}
+
+	data, err := marshalFilterPreset(name, description, rules)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, filename), data, 0644); err != nil {
+		return "", err
 	}
+	return filename, nil
+}
 
-	data, err := yaml.Marshal(preset)
+// ResolveFilterPreset finds a preset by ID. If location is empty, project presets are searched
+// before global presets (same precedence as LoadFilterPresetByID).
+func (c *Controller) ResolveFilterPreset(presetID string, location domain.PresetLocation) (domain.FilterPreset, error) {
+	if location == "" {
+		return c.LoadFilterPresetByID(presetID)
+	}
+	presets, _, err := c.ScanFilterPresets(location)
+	if err != nil {
+		return domain.FilterPreset{}, err
+	}
+	for _, p := range presets {
+		if p.ID == presetID {
+			return p, nil
+		}
+	}
+	return domain.FilterPreset{}, fmt.Errorf("filter preset not found: %s (%s)", presetID, location)
+}
+
+// DeleteFilterPreset removes a preset file. Invalid (unparsable) preset files can be deleted too,
+// which is usually the easiest way to clean them up.
+func (c *Controller) DeleteFilterPreset(presetID string, location domain.PresetLocation) error {
+	path, err := c.FilterPresetPath(presetID, location)
 	if err != nil {
 		return err
 	}
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("filter preset not found: %s (%s)", presetID, location)
+		}
+		return err
+	}
+	return nil
+}
+
+// RenameFilterPreset changes a preset's name and moves it to the filename derived from the new name.
+// It refuses to overwrite a different existing preset.
+func (c *Controller) RenameFilterPreset(presetID, newName string, location domain.PresetLocation) (domain.FilterPreset, error) {
+	if strings.TrimSpace(newName) == "" {
+		return domain.FilterPreset{}, fmt.Errorf("new preset name is required")
+	}
+	preset, err := c.ResolveFilterPreset(presetID, location)
+	if err != nil {
+		return domain.FilterPreset{}, err
+	}
 
-	return os.WriteFile(filePath, data, 0644)
+	newID := filterPresetFilename(newName)
+	oldPath, err := c.FilterPresetPath(preset.ID, preset.Location)
+	if err != nil {
+		return domain.FilterPreset{}, err
+	}
+	newPath, err := c.FilterPresetPath(newID, preset.Location)
+	if err != nil {
+		return domain.FilterPreset{}, err
+	}
+	if newID != preset.ID {
+		if _, err := os.Stat(newPath); err == nil {
+			return domain.FilterPreset{}, fmt.Errorf("filter preset already exists: %s (%s)", newID, preset.Location)
+		}
+	}
+
+	data, err := marshalFilterPreset(newName, preset.Description, preset.Rules)
+	if err != nil {
+		return domain.FilterPreset{}, err
+	}
+	if err := os.WriteFile(newPath, data, 0644); err != nil {
+		return domain.FilterPreset{}, err
+	}
+	if newID != preset.ID {
+		if err := os.Remove(oldPath); err != nil {
+			return domain.FilterPreset{}, err
+		}
+	}
+
+	preset.ID = newID
+	preset.Name = newName
+	return preset, nil
+}
+
+// ValidateFilterPresetFile parses and validates a preset file on disk.
+func ValidateFilterPresetFile(path string) (domain.FilterPreset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return domain.FilterPreset{}, err
+	}
+	return parseFilterPresetYAML(data)
 }
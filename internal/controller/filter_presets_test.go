@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"testing"
@@ -20,7 +21,7 @@ func TestFilterPresets_Project_SaveThenLoad(t *testing.T) {
 		{Type: domain.FilterTypeExclude, Pattern: "**/*spec*", Order: 1},
 	}
 
-	if err := c.SaveFilterPreset("Exclude Tests", "Exclude test files", rules, domain.PresetLocationProject); err != nil {
+	if _, err := c.SaveFilterPreset("Exclude Tests", "Exclude test files", rules, domain.PresetLocationProject); err != nil {
 		t.Fatalf("SaveFilterPreset: %v", err)
 	}
 
@@ -78,7 +79,7 @@ func TestFilterPresets_Global_SaveThenLoad(t *testing.T) {
 		{Type: domain.FilterTypeExclude, Pattern: "**/*.md", Order: 0},
 	}
 
-	if err := c.SaveFilterPreset("Exclude Docs", "Exclude documentation files", rules, domain.PresetLocationGlobal); err != nil {
+	if _, err := c.SaveFilterPreset("Exclude Docs", "Exclude documentation files", rules, domain.PresetLocationGlobal); err != nil {
 		t.Fatalf("SaveFilterPreset: %v", err)
 	}
 
@@ -104,3 +105,157 @@ func TestFilterPresets_Global_SaveThenLoad(t *testing.T) {
 		t.Fatalf("expected %s to exist: %v", presetPath, err)
 	}
 }
+
+func TestFilterPresets_ScanReportsInvalidFiles(t *testing.T) {
+	tmp := t.TempDir()
+	c := &Controller{repoPath: tmp, data: domain.NewPRData()}
+
+	dir := filepath.Join(tmp, ".pr-builder", "filters")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("name: [unterminated\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad_type.yaml"), []byte("name: Bad\nrules:\n  - type: maybe\n    pattern: \"*.go\"\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if _, err := c.SaveFilterPreset("Good", "", []domain.FilterRule{{Type: domain.FilterTypeExclude, Pattern: "*.md"}}, domain.PresetLocationProject); err != nil {
+		t.Fatalf("SaveFilterPreset: %v", err)
+	}
+
+	presets, fileErrs, err := c.ScanFilterPresets(domain.PresetLocationProject)
+	if err != nil {
+		t.Fatalf("ScanFilterPresets: %v", err)
+	}
+	if len(presets) != 1 || presets[0].ID != "good.yaml" {
+		t.Fatalf("expected only good.yaml, got %+v", presets)
+	}
+	if len(fileErrs) != 2 {
+		t.Fatalf("expected 2 file errors, got %d: %+v", len(fileErrs), fileErrs)
+	}
+}
+
+func TestFilterPresets_SaveSameNameReusesFile(t *testing.T) {
+	tmp := t.TempDir()
+	c := &Controller{repoPath: tmp, data: domain.NewPRData()}
+	rules := []domain.FilterRule{{Type: domain.FilterTypeExclude, Pattern: "*.md"}}
+
+	id1, err := c.SaveFilterPreset("Docs", "", rules, domain.PresetLocationProject)
+	if err != nil {
+		t.Fatalf("SaveFilterPreset: %v", err)
+	}
+	id2, err := c.SaveFilterPreset("docs", "updated", rules, domain.PresetLocationProject)
+	if err != nil {
+		t.Fatalf("SaveFilterPreset: %v", err)
+	}
+	if id1 != id2 {
+		t.Fatalf("expected same ID, got %q and %q", id1, id2)
+	}
+
+	presets, err := c.LoadProjectFilterPresets()
+	if err != nil {
+		t.Fatalf("LoadProjectFilterPresets: %v", err)
+	}
+	if len(presets) != 1 || presets[0].Description != "updated" {
+		t.Fatalf("expected a single updated preset, got %+v", presets)
+	}
+}
+
+func TestFilterPresets_RenameAndDelete(t *testing.T) {
+	tmp := t.TempDir()
+	c := &Controller{repoPath: tmp, data: domain.NewPRData()}
+	rules := []domain.FilterRule{{Type: domain.FilterTypeExclude, Pattern: "*.md"}}
+
+	id, err := c.SaveFilterPreset("Docs", "", rules, domain.PresetLocationProject)
+	if err != nil {
+		t.Fatalf("SaveFilterPreset: %v", err)
+	}
+	if _, err := c.SaveFilterPreset("Other", "", rules, domain.PresetLocationProject); err != nil {
+		t.Fatalf("SaveFilterPreset: %v", err)
+	}
+
+	if _, err := c.RenameFilterPreset(id, "Other", domain.PresetLocationProject); err == nil {
+		t.Fatalf("expected rename onto existing preset to fail")
+	}
+
+	renamed, err := c.RenameFilterPreset(id, "Documentation", domain.PresetLocationProject)
+	if err != nil {
+		t.Fatalf("RenameFilterPreset: %v", err)
+	}
+	if renamed.ID != "documentation.yaml" || renamed.Name != "Documentation" {
+		t.Fatalf("unexpected renamed preset: %+v", renamed)
+	}
+	if _, err := os.Stat(filepath.Join(tmp, ".pr-builder", "filters", id)); !os.IsNotExist(err) {
+		t.Fatalf("expected old preset file to be removed, got %v", err)
+	}
+
+	if err := c.DeleteFilterPreset(renamed.ID, domain.PresetLocationProject); err != nil {
+		t.Fatalf("DeleteFilterPreset: %v", err)
+	}
+	if err := c.DeleteFilterPreset("../escape.yaml", domain.PresetLocationProject); err == nil {
+		t.Fatalf("expected path-like preset ID to be rejected")
+	}
+
+	presets, err := c.LoadProjectFilterPresets()
+	if err != nil {
+		t.Fatalf("LoadProjectFilterPresets: %v", err)
+	}
+	if len(presets) != 1 || presets[0].Name != "Other" {
+		t.Fatalf("expected only Other to remain, got %+v", presets)
+	}
+}
+
+func TestFilterPresetPath_RejectsTraversal(t *testing.T) {
+	tmp := t.TempDir()
+	c := &Controller{repoPath: tmp, data: domain.NewPRData()}
+
+	for _, id := range []string{"../../other.yaml", "sub/docs.yaml", "/etc/passwd.yaml", "notes.txt"} {
+		if path, err := c.FilterPresetPath(id, domain.PresetLocationProject); err == nil {
+			t.Fatalf("expected %q to be rejected, got %s", id, path)
+		}
+	}
+	path, err := c.FilterPresetPath("docs.yaml", domain.PresetLocationProject)
+	if err != nil || path != filepath.Join(tmp, ".pr-builder", "filters", "docs.yaml") {
+		t.Fatalf("unexpected path %q (err %v)", path, err)
+	}
+}
+
+func TestFilterPresets_BundleRoundTrip(t *testing.T) {
+	presets := []domain.FilterPreset{
+		{ID: "docs.yaml", Name: "Docs", Rules: []domain.FilterRule{{Type: domain.FilterTypeExclude, Pattern: "**/*.md"}}},
+		{ID: "go_only.yaml", Name: "Go only", Description: "Only Go", Rules: []domain.FilterRule{{Type: domain.FilterTypeInclude, Pattern: "**/*.go"}}},
+	}
+
+	for _, format := range []FilterPresetBundleFormat{FilterPresetBundleYAML, FilterPresetBundleTar} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := ExportFilterPresets(&buf, presets, format); err != nil {
+				t.Fatalf("ExportFilterPresets: %v", err)
+			}
+			got, err := ReadFilterPresetBundle(&buf, format)
+			if err != nil {
+				t.Fatalf("ReadFilterPresetBundle: %v", err)
+			}
+			if len(got) != 2 || got[1].Name != "Go only" || got[1].Description != "Only Go" {
+				t.Fatalf("unexpected bundle contents: %+v", got)
+			}
+
+			c := &Controller{repoPath: t.TempDir(), data: domain.NewPRData()}
+			imported, skipped, err := c.ImportFilterPresets(got, domain.PresetLocationProject, false)
+			if err != nil {
+				t.Fatalf("ImportFilterPresets: %v", err)
+			}
+			if len(imported) != 2 || len(skipped) != 0 {
+				t.Fatalf("expected 2 imported, got imported=%v skipped=%v", imported, skipped)
+			}
+			_, skipped, err = c.ImportFilterPresets(got, domain.PresetLocationProject, false)
+			if err != nil {
+				t.Fatalf("ImportFilterPresets (again): %v", err)
+			}
+			if len(skipped) != 2 {
+				t.Fatalf("expected 2 skipped on re-import, got %v", skipped)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"os"
+	"strings"
+
+	"github.com/go-go-golems/prescribe/internal/api"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/go-go-golems/prescribe/internal/history"
+	"github.com/go-go-golems/prescribe/internal/prdata"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// HistoryStore returns the generation history of the repository (.pr-builder/history/).
+func (c *Controller) HistoryStore() *history.Store {
+	return history.NewStore(history.Dir(c.repoPath))
+}
+
+// recordHistory adds resp to the generation history. req is the request that produced it and
+// preset its prompt preset ID; meta defaults to the metadata of resp. Cached responses are not
+// recorded again. History errors are logged, never returned.
+func (c *Controller) recordHistory(kind history.Kind, req api.GenerateDescriptionRequest, preset string, resp *api.GenerateDescriptionResponse, meta *domain.GenerationMetadata) {
+	if resp == nil || resp.Cached {
+		return
+	}
+	if meta == nil {
+		m := resp.Metadata()
+		meta = &m
+	}
+	promptHash, err := api.PromptHash(req)
+	if err != nil {
+		log.Debug().Err(err).Msg("controller: failed to hash prompt for history")
+	}
+	r := &history.Record{
+		Kind:         kind,
+		SourceBranch: req.SourceBranch,
+		TargetBranch: req.TargetBranch,
+		SourceCommit: req.SourceCommit,
+		TargetCommit: req.TargetCommit,
+		Preset:       preset,
+		PromptHash:   promptHash,
+		Model:        resp.Model,
+		Usage:        resp.Usage,
+		Raw:          resp.Description,
+		Parsed:       resp.Parsed,
+		ParseError:   resp.ParseError,
+		Generation:   meta,
+	}
+	if _, err := c.HistoryStore().Add(r, resp.Turn); err != nil {
+		log.Warn().Err(err).Msg("controller: failed to record generation history")
+	}
+}
+
+// historyRequest is the request of the current session, recorded with generations that do not
+// build one themselves (refinements continue the conversation it started). Its zero value is used
+// if the session cannot be compiled.
+func (c *Controller) historyRequest() api.GenerateDescriptionRequest {
+	req, err := c.BuildGenerateDescriptionRequest()
+	if err != nil {
+		return api.GenerateDescriptionRequest{SourceBranch: c.data.SourceBranch, TargetBranch: c.data.TargetBranch}
+	}
+	return req
+}
+
+// currentPresetID is the ID of the prompt preset in use ("" for a custom prompt).
+func (c *Controller) currentPresetID() string {
+	if c.data.CurrentPreset == nil {
+		return ""
+	}
+	return c.data.CurrentPreset.ID
+}
+
+// RestoreHistory makes the history record ref (see history.Store.Resolve) the current generation
+// and persists it like a fresh one: last-generated-pr.yaml and its conversation, so the draft can
+// be refined or used with `create --use-last`. It returns the record and the written path.
+func (c *Controller) RestoreHistory(ref string) (*history.Record, string, error) {
+	store := c.HistoryStore()
+	id, err := store.Resolve(ref)
+	if err != nil {
+		return nil, "", err
+	}
+	r, t, err := store.Get(id)
+	if err != nil {
+		return nil, "", err
+	}
+	if r.Parsed == nil || strings.TrimSpace(r.Parsed.Title) == "" {
+		reason := r.ParseError
+		if reason == "" {
+			reason = "missing title"
+		}
+		return nil, "", errors.Errorf("history record %s has no parsed PR data (%s)", id, reason)
+	}
+
+	meta := r.Generation
+	if meta == nil {
+		meta = &domain.GenerationMetadata{Model: r.Model, Usage: r.Usage}
+	}
+	c.data.GeneratedDescription = r.Raw
+	c.data.GeneratedPRData = r.Parsed
+	c.data.GeneratedPRDataParseError = ""
+	c.data.GenerationMetadata = meta
+	c.lastGeneration = nil
+	c.lastTurn = t
+	if t == nil {
+		// Do not leave the conversation of another generation behind for `prescribe refine`.
+		turnPath := prdata.LastGeneratedTurnPath(c.repoPath)
+		if err := os.Remove(turnPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, "", errors.Wrap(err, "failed to remove stale conversation")
+		}
+	}
+
+	path, err := c.WriteLastGeneratedPRData()
+	if err != nil {
+		return nil, "", err
+	}
+	return r, path, nil
+}
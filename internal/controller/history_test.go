@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-go-golems/geppetto/pkg/turns"
+	"github.com/go-go-golems/prescribe/internal/api"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/go-go-golems/prescribe/internal/history"
+	"github.com/go-go-golems/prescribe/internal/prdata"
+)
+
+func TestHistory_RecordAndRestore(t *testing.T) {
+	repo := t.TempDir()
+	c := &Controller{repoPath: repo, data: domain.NewPRData()}
+	c.data.SourceBranch, c.data.TargetBranch = "feature", "main"
+	req := api.GenerateDescriptionRequest{SourceBranch: "feature", TargetBranch: "main", SourceCommit: "abc", Prompt: "Describe."}
+
+	turn := &turns.Turn{}
+	turns.AppendBlock(turn, turns.NewUserTextBlock("describe the PR"))
+	c.recordHistory(history.KindGenerate, req, "default", &api.GenerateDescriptionResponse{
+		Description: "title: Good draft\nbody: B\n",
+		Parsed:      &domain.GeneratedPRData{Title: "Good draft", Body: "B"},
+		Model:       "gpt-4o",
+		Usage:       &domain.GenerationUsage{InputTokens: 100, OutputTokens: 10},
+		Turn:        turn,
+	}, nil)
+	// A parse failure is recorded with its raw text; a cache hit is not recorded again.
+	c.recordHistory(history.KindRefine, req, "default", &api.GenerateDescriptionResponse{
+		Description: "not yaml",
+		ParseError:  "failed to parse PR YAML",
+	}, nil)
+	c.recordHistory(history.KindGenerate, req, "default", &api.GenerateDescriptionResponse{Description: "cached", Cached: true}, nil)
+
+	records, err := c.HistoryStore().List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	good := records[1]
+	if good.SourceCommit != "abc" || good.Preset != "default" || good.PromptHash == "" || good.Usage == nil || good.Generation == nil {
+		t.Fatalf("unexpected record %+v", good)
+	}
+	if records[0].Raw != "not yaml" || records[0].ParseError == "" {
+		t.Fatalf("expected the raw text of the parse failure, got %+v", records[0])
+	}
+
+	if _, _, err := c.RestoreHistory("latest"); err == nil {
+		t.Fatalf("expected restoring an unparsed record to fail")
+	}
+	r, path, err := c.RestoreHistory("latest~1")
+	if err != nil {
+		t.Fatalf("RestoreHistory: %v", err)
+	}
+	if r.ID != good.ID || path != prdata.LastGeneratedPRDataPath(repo) {
+		t.Fatalf("unexpected restore %s -> %s", r.ID, path)
+	}
+	data, err := prdata.LoadGeneratedPRDataFromYAMLFile(path)
+	if err != nil || data.Title != "Good draft" {
+		t.Fatalf("expected the restored draft on disk, got %+v (%v)", data, err)
+	}
+	if _, err := os.Stat(prdata.LastGeneratedTurnPath(repo)); err != nil {
+		t.Fatalf("expected the restored conversation: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/prescribe/internal/domain"
+)
+
+func TestDenyList_BlocksContextFiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".prescribeignore"), []byte(".env\nsecrets/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewController(repo)
+	if err != nil {
+		t.Fatalf("NewController: %v", err)
+	}
+
+	err = c.AddContextFile("secrets/prod.yaml")
+	if err == nil || !strings.Contains(err.Error(), ".prescribeignore:2: secrets") {
+		t.Fatalf("expected AddContextFile to be denied, got %v", err)
+	}
+	if err := c.CheckAllowed("main.go", ".env"); err == nil {
+		t.Fatalf("expected CheckAllowed to refuse .env")
+	}
+
+	// A session saved before the pattern existed must not smuggle the content back in.
+	c.data.AdditionalContext = []domain.ContextItem{
+		{Type: domain.ContextTypeFile, Path: ".env", Content: "API_KEY=x"},
+		{Type: domain.ContextTypeFile, Path: "README.md", Content: "readme"},
+		{Type: domain.ContextTypeNote, Content: ".env"},
+	}
+	c.data.GitContext = []domain.GitContextItem{
+		{Kind: domain.GitContextItemKindFileAtRef, Ref: "HEAD", Path: "secrets/prod.yaml"},
+		{Kind: domain.GitContextItemKindFileDiff, From: "main", To: "HEAD", Path: "README.md"},
+		{Kind: domain.GitContextItemKindCommitPatch, Ref: "HEAD", Paths: []string{".env"}},
+		{Kind: domain.GitContextItemKindCommitPatch, Ref: "HEAD", Paths: []string{".env", "main.go"}},
+	}
+	path := filepath.Join(t.TempDir(), "session.yaml")
+	if err := c.SaveSession(path); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	if err := c.LoadSession(path); err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if got := c.data.AdditionalContext; len(got) != 2 || got[0].Path != "README.md" || got[1].Type != domain.ContextTypeNote {
+		t.Fatalf("expected the denied context file to be dropped, got %+v", got)
+	}
+	git := c.data.GitContext
+	if len(git) != 2 || git[0].Path != "README.md" || len(git[1].Paths) != 1 || git[1].Paths[0] != "main.go" {
+		t.Fatalf("expected the denied git context to be dropped, got %+v", git)
+	}
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"github.com/go-go-golems/prescribe/internal/api"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// SetLintRepair makes fresh generations re-prompt once to fix lint findings (`--lint-repair`),
+// in addition to presets that set `lint.repair`.
+func (c *Controller) SetLintRepair(repair bool) {
+	c.lintRepair = repair
+}
+
+// lintConfig is the lint config of the current preset, with Repair forced by SetLintRepair.
+func (c *Controller) lintConfig() *domain.LintConfig {
+	cfg := c.data.EffectiveLintConfig()
+	if !c.lintRepair {
+		return cfg
+	}
+	out := domain.LintConfig{}
+	if cfg != nil {
+		out = *cfg
+	}
+	out.Repair = true
+	return &out
+}
+
+// lintGeneration sets the lint findings of resp for the current PR data (which resp just became),
+// checked against the files and context of the session. Refinements, section regenerations and
+// merged candidates are linted here; so are cached results. If the session cannot be compiled,
+// the findings of resp are kept.
+func (c *Controller) lintGeneration(resp *api.GenerateDescriptionResponse) {
+	if c.data.GeneratedPRData == nil {
+		resp.LintFindings = nil
+		return
+	}
+	req, err := c.PreviewGenerateDescriptionRequest()
+	if err != nil {
+		log.Debug().Err(err).Msg("controller: cannot compile the session; keeping the lint findings of the response")
+		return
+	}
+	resp.LintFindings = req.Lint.Lint(c.data.GeneratedPRData, req.Files, req.AdditionalContext)
+}
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/go-go-golems/prescribe/internal/api"
+	"github.com/go-go-golems/prescribe/internal/domain"
+)
+
+func TestLintGeneration_currentPRData(t *testing.T) {
+	c := &Controller{repoPath: t.TempDir(), data: domain.NewPRData()}
+	c.data.SourceBranch, c.data.TargetBranch = "feature", "main"
+	c.data.ChangedFiles = []domain.FileChange{{Path: "cmd/run.go", Included: true, Type: domain.FileTypeDiff, Diff: "+func Run() {}"}}
+	c.data.CurrentPreset = &domain.PromptPreset{ID: "strict", Lint: &domain.LintConfig{RequiredHeadings: []string{"Testing"}}}
+
+	// A refinement's response carries no findings; they are computed for the data it produced.
+	c.data.GeneratedPRData = &domain.GeneratedPRData{Title: "Add `Run`", Body: "Calls `Stop` too."}
+	c.setGenerationResult(&api.GenerateDescriptionResponse{Parsed: c.data.GeneratedPRData})
+
+	findings := c.data.GenerationMetadata.LintFindings
+	if len(findings) != 2 || findings[0].Rule != domain.LintRuleMissingHeading || findings[1].Rule != domain.LintRuleUnknownReference {
+		t.Fatalf("unexpected findings %v", findings)
+	}
+}
+
+func TestLintConfig_repairOverride(t *testing.T) {
+	c := &Controller{data: domain.NewPRData()}
+	if c.lintConfig() != nil {
+		t.Fatalf("expected no lint config without a preset")
+	}
+	preset := &domain.LintConfig{MaxLength: 100}
+	c.data.CurrentPreset = &domain.PromptPreset{ID: "p", Lint: preset}
+	c.SetLintRepair(true)
+
+	cfg := c.lintConfig()
+	if !cfg.RepairEnabled() || cfg.MaxLength != 100 {
+		t.Fatalf("expected the preset config with repair, got %+v", cfg)
+	}
+	if preset.Repair {
+		t.Fatalf("expected the preset's config to stay untouched")
+	}
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/prescribe/internal/domain"
+)
+
+func TestPresetVars_ValidatedPersistedAndSent(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	repo := t.TempDir()
+	dir := filepath.Join(repo, ".pr-builder", "prompts")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	preset := "name: Audience\ntemplate: \"For {{ .audience }}\"\nparameters:\n  - name: audience\n    type: choice\n    choices: [dev, ops]\n    required: true\n"
+	if err := os.WriteFile(filepath.Join(dir, "audience.yaml"), []byte(preset), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := &Controller{repoPath: repo, data: domain.NewPRData()}
+	if err := c.LoadPromptPreset("audience.yaml"); err != nil {
+		t.Fatalf("LoadPromptPreset: %v", err)
+	}
+
+	if err := c.SetPresetVars([]string{"audience=pm"}); err == nil {
+		t.Fatalf("expected an invalid choice to be rejected")
+	}
+	if err := c.SetPresetVars([]string{"team=core"}); err == nil || !strings.Contains(err.Error(), "has no parameter team") {
+		t.Fatalf("expected an unknown parameter to be rejected, got %v", err)
+	}
+	if err := c.SetPresetVars([]string{"audience=ops"}); err != nil {
+		t.Fatalf("SetPresetVars: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "session.yaml")
+	if err := c.SaveSession(path); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	c.data.PresetVars = nil
+	if err := c.LoadSession(path); err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if got := c.data.PresetParamsSummary(); got != "audience=ops" {
+		t.Fatalf("expected the preset vars to round-trip, got %q", got)
+	}
+
+	c.data.ChangedFiles = []domain.FileChange{{Path: "a.go", Included: true, Diff: "+x\n"}}
+	req, err := c.BuildGenerateDescriptionRequest()
+	if err != nil {
+		t.Fatalf("BuildGenerateDescriptionRequest: %v", err)
+	}
+	if req.PresetVars["audience"] != "ops" {
+		t.Fatalf("expected the preset vars in the request, got %v", req.PresetVars)
+	}
+
+	c.SetPrompt("custom", nil)
+	if len(c.data.PresetVars) != 0 {
+		t.Fatalf("expected switching to a custom prompt to drop the preset vars, got %v", c.data.PresetVars)
+	}
+}
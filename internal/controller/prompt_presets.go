@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-go-golems/prescribe/internal/api"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/go-go-golems/prescribe/internal/presets"
+)
+
+func (c *Controller) promptPresetDir(location domain.PresetLocation) (string, error) {
+	switch location {
+	case domain.PresetLocationProject:
+		return filepath.Join(c.repoPath, ".pr-builder", "prompts"), nil
+	case domain.PresetLocationGlobal:
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(homeDir, ".pr-builder", "prompts"), nil
+	case domain.PresetLocationBuiltin:
+		return "", fmt.Errorf("builtin prompt presets are read-only")
+	default:
+		return "", fmt.Errorf("unsupported preset location: %s", location)
+	}
+}
+
+// PromptPresetPath returns the backing file path for a prompt preset ID at the given location.
+func (c *Controller) PromptPresetPath(presetID string, location domain.PresetLocation) (string, error) {
+	if !isFilterPresetFilename(presetID) || filepath.Base(presetID) != presetID {
+		return "", fmt.Errorf("invalid prompt preset ID: %s", presetID)
+	}
+	dir, err := c.promptPresetDir(location)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, presetID), nil
+}
+
+// ResolvePromptPreset finds a prompt preset by ID. If location is empty, builtin presets are
+// searched first, then project and global presets (same precedence as generate --preset).
+func (c *Controller) ResolvePromptPreset(presetID string, location domain.PresetLocation) (*domain.PromptPreset, error) {
+	if location == "" {
+		return presets.ResolvePromptPreset(presetID, c.repoPath)
+	}
+	if location == domain.PresetLocationBuiltin {
+		for _, p := range domain.GetBuiltinPresets() {
+			if p.ID == presetID {
+				return &p, nil
+			}
+		}
+		return nil, fmt.Errorf("prompt preset not found: %s (%s)", presetID, location)
+	}
+	path, err := c.PromptPresetPath(presetID, location)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("prompt preset not found: %s (%s)", presetID, location)
+	}
+	p, err := presets.LoadPromptPresetFile(path, location)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// SavePromptPreset saves p (its name, description, prompt and settings) under the project or
+// global preset directory. A preset with the same name (case-insensitive) at that location is
+// overwritten in place. It returns the preset ID (filename) that was written.
+func (c *Controller) SavePromptPreset(p domain.PromptPreset, location domain.PresetLocation) (string, error) {
+	if strings.TrimSpace(p.Name) == "" {
+		return "", fmt.Errorf("prompt preset name is required")
+	}
+	if p.Parts == nil && strings.TrimSpace(p.Template) == "" {
+		return "", fmt.Errorf("prompt preset %q has no prompt", p.Name)
+	}
+	for _, param := range p.Parameters {
+		if err := param.Validate(); err != nil {
+			return "", err
+		}
+	}
+	dir, err := c.promptPresetDir(location)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	filename := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(p.Name), " ", "_")) + ".yaml"
+	existing, err := c.LoadProjectPresets()
+	if location == domain.PresetLocationGlobal {
+		existing, err = c.LoadGlobalPresets()
+	}
+	if err != nil {
+		return "", err
+	}
+	for _, e := range existing {
+		if strings.EqualFold(strings.TrimSpace(e.Name), strings.TrimSpace(p.Name)) {
+			filename = e.ID
+			break
+		}
+	}
+	if filepath.Base(filename) != filename || filename == ".yaml" {
+		return "", fmt.Errorf("invalid prompt preset name: %q", p.Name)
+	}
+
+	data, err := presets.MarshalPromptPreset(p)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, filename), data, 0644); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// DeletePromptPreset removes a prompt preset file. Builtin presets cannot be deleted.
+func (c *Controller) DeletePromptPreset(presetID string, location domain.PresetLocation) error {
+	for _, p := range domain.GetBuiltinPresets() {
+		if p.ID == presetID {
+			return fmt.Errorf("builtin prompt presets are read-only: %s", presetID)
+		}
+	}
+	path, err := c.PromptPresetPath(presetID, location)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("prompt preset not found: %s (%s)", presetID, location)
+		}
+		return err
+	}
+	return nil
+}
+
+// ValidatePromptPresetFile parses a prompt preset file and checks its parameter declarations.
+func ValidatePromptPresetFile(path string, location domain.PresetLocation) (domain.PromptPreset, error) {
+	p, err := presets.LoadPromptPresetFile(path, location)
+	if err != nil {
+		return domain.PromptPreset{}, err
+	}
+	if p.Parts == nil && strings.TrimSpace(p.Template) == "" {
+		return domain.PromptPreset{}, fmt.Errorf("%s: no template (or system/user sections)", path)
+	}
+	for _, param := range p.Parameters {
+		if err := param.Validate(); err != nil {
+			return domain.PromptPreset{}, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return p, nil
+}
+
+// LintPrompt checks the current prompt against the session's variables (see api.LintPrompt).
+// Preset parameters that fail to resolve are reported as an error finding, and linting continues
+// with their defaults.
+func (c *Controller) LintPrompt() []api.PromptLintFinding {
+	findings := []api.PromptLintFinding{}
+	presetVars, err := c.data.CurrentPreset.ResolveParameters(c.data.PresetVars)
+	if err != nil {
+		findings = append(findings, api.PromptLintFinding{Severity: api.PromptLintError, Section: "parameters", Message: err.Error()})
+		presetVars = c.data.CurrentPreset.ParameterDefaults()
+	}
+	req := api.GenerateDescriptionRequest{
+		Prompt:     c.data.CurrentPrompt,
+		PromptVars: c.data.PromptVars,
+		PresetVars: presetVars,
+	}
+	if c.data.CurrentPreset != nil {
+		req.PromptParts = c.data.CurrentPreset.Parts
+	}
+	return append(findings, api.LintPrompt(req)...)
+}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/prescribe/internal/domain"
+)
+
+func TestPromptPresets_SaveResolveDelete(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	c := &Controller{repoPath: t.TempDir(), data: domain.NewPRData()}
+
+	p := domain.PromptPreset{
+		Name:       "Release Notes",
+		Parts:      &domain.PromptParts{System: "Be brief about {{ .audience }}.", Partials: map[string]string{"x": "y"}},
+		Parameters: []domain.PresetParameter{{Name: "audience", Default: "ops"}},
+	}
+	id, err := c.SavePromptPreset(p, domain.PresetLocationProject)
+	if err != nil {
+		t.Fatalf("SavePromptPreset: %v", err)
+	}
+	if id != "release_notes.yaml" {
+		t.Fatalf("unexpected preset ID %q", id)
+	}
+
+	got, err := c.ResolvePromptPreset(id, "")
+	if err != nil {
+		t.Fatalf("ResolvePromptPreset: %v", err)
+	}
+	if got.Format() != domain.PromptFormatMultipart || got.Parts.Partials["x"] != "y" || len(got.Parameters) != 1 {
+		t.Fatalf("preset did not round-trip: %+v", got)
+	}
+	if _, err := c.ResolvePromptPreset(id, domain.PresetLocationGlobal); err == nil {
+		t.Fatalf("expected the preset not to be found in the global presets")
+	}
+
+	if err := c.DeletePromptPreset("default", domain.PresetLocationProject); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Fatalf("expected builtin presets to be read-only, got %v", err)
+	}
+	if err := c.DeletePromptPreset(id, domain.PresetLocationProject); err != nil {
+		t.Fatalf("DeletePromptPreset: %v", err)
+	}
+	if _, err := c.ResolvePromptPreset(id, ""); err == nil {
+		t.Fatalf("expected the deleted preset to be gone")
+	}
+}
+
+func TestLintPrompt_ReportsUnresolvedParameters(t *testing.T) {
+	c := &Controller{repoPath: t.TempDir(), data: domain.NewPRData()}
+	c.SetPrompt("For {{ .ticket }}.", &domain.PromptPreset{
+		ID:         "t.yaml",
+		Template:   "For {{ .ticket }}.",
+		Parameters: []domain.PresetParameter{{Name: "ticket", Required: true}},
+	})
+	findings := c.LintPrompt()
+	if len(findings) != 1 || findings[0].Section != "parameters" || !strings.Contains(findings[0].Message, "ticket") {
+		t.Fatalf("expected only the missing parameter, got %v", findings)
+	}
+}
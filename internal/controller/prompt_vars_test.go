@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-go-golems/prescribe/internal/domain"
+)
+
+func TestPromptVars_SessionRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	c := &Controller{repoPath: t.TempDir(), data: domain.NewPRData()}
+	withoutFiles := false
+	want := domain.PromptVars{
+		Issue:            "#42",
+		AdditionalSystem: "Be terse.",
+		Additional:       []string{"Mention the migration"},
+		WithoutFiles:     &withoutFiles,
+		Concise:          true,
+		UseKeywords:      true,
+		Language:         "de",
+		Tone:             "end-users",
+		Vars:             map[string]any{"team": "core", "reviewers": []any{"ana", "bo"}},
+	}
+	c.data.PromptVars = want
+
+	path := filepath.Join(t.TempDir(), "session.yaml")
+	if err := c.SaveSession(path); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	c.data.PromptVars = domain.PromptVars{}
+	if err := c.LoadSession(path); err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if !reflect.DeepEqual(c.data.PromptVars, want) {
+		t.Fatalf("prompt vars did not round-trip:\n got %+v\nwant %+v", c.data.PromptVars, want)
+	}
+
+	c.data.ChangedFiles = []domain.FileChange{{Path: "a.go", Included: true, Diff: "+x\n"}}
+	req, err := c.BuildGenerateDescriptionRequest()
+	if err != nil {
+		t.Fatalf("BuildGenerateDescriptionRequest: %v", err)
+	}
+	if req.PromptVars.Issue != "#42" || !req.PromptVars.Concise {
+		t.Fatalf("expected the prompt vars in the request, got %+v", req.PromptVars)
+	}
+
+	c.data.PromptVars.Tone = "pirate"
+	if _, err := c.BuildGenerateDescriptionRequest(); err == nil {
+		t.Fatalf("expected an unknown tone to be rejected")
+	}
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-go-golems/prescribe/internal/api"
+	"github.com/go-go-golems/prescribe/internal/redact"
+	"github.com/pkg/errors"
+)
+
+// SetRedactionMode overrides redaction.mode of the config (`--redaction`; "" => config).
+func (c *Controller) SetRedactionMode(mode redact.Mode) {
+	c.redactionMode = mode
+}
+
+// RedactionMode returns the effective mode: the override, else redaction.mode of
+// <repo>/.pr-builder/config.yaml, then ~/.pr-builder/config.yaml, else redact.DefaultMode.
+func (c *Controller) RedactionMode() (redact.Mode, error) {
+	mode, _, err := c.redactionSettings()
+	return mode, err
+}
+
+// RedactionReport is the outcome of the secret scan of a request.
+type RedactionReport struct {
+	Mode redact.Mode
+	// Findings were masked (redact), only reported (warn) or blocked the request (block).
+	Findings []redact.Finding
+}
+
+// LastRedaction returns the report of the last BuildGenerateDescriptionRequest.
+func (c *Controller) LastRedaction() RedactionReport {
+	return c.redaction
+}
+
+// redactionSettings resolves the mode and collects the custom patterns of the global and the
+// repository config.
+func (c *Controller) redactionSettings() (redact.Mode, []redact.CustomRule, error) {
+	globalCfg, err := loadGlobalConfig()
+	if err != nil {
+		return "", nil, err
+	}
+	repoCfg, err := c.loadRepoConfig()
+	if err != nil {
+		return "", nil, err
+	}
+	name := string(c.redactionMode)
+	for _, cfg := range []repoRedactionYAML{repoCfg.Redaction, globalCfg.Redaction} {
+		if name == "" {
+			name = cfg.Mode
+		}
+	}
+	mode, err := redact.ParseMode(name)
+	if err != nil {
+		return "", nil, err
+	}
+	custom := append(append([]redact.CustomRule{}, globalCfg.Redaction.Patterns...), repoCfg.Redaction.Patterns...)
+	return mode, custom, nil
+}
+
+// redactRequest scans the diffs, full files, context items, title and description of req for
+// secrets. In redact mode they are masked in req (the session is not modified); in block mode
+// finding any is an error.
+func (c *Controller) redactRequest(req *api.GenerateDescriptionRequest) error {
+	c.redaction = RedactionReport{}
+	mode, custom, err := c.redactionSettings()
+	if err != nil {
+		return err
+	}
+	c.redaction.Mode = mode
+	if mode == redact.ModeOff {
+		return nil
+	}
+	scanner, err := redact.NewScanner(custom)
+	if err != nil {
+		return err
+	}
+
+	findings := []redact.Finding{}
+	scan := func(source string, text *string) bool {
+		out, found := scanner.Scan(source, *text)
+		if len(found) == 0 {
+			return false
+		}
+		findings = append(findings, found...)
+		if mode == redact.ModeRedact {
+			*text = out
+		}
+		return true
+	}
+
+	scan("title", &req.Title)
+	scan("description", &req.Description)
+	scan("issue", &req.PromptVars.Issue)
+	scan("additional_system", &req.PromptVars.AdditionalSystem)
+	req.PromptVars.Additional = append([]string{}, req.PromptVars.Additional...)
+	for i := range req.PromptVars.Additional {
+		scan("additional", &req.PromptVars.Additional[i])
+	}
+	presetVars := make(map[string]any, len(req.PresetVars))
+	for k, v := range req.PresetVars {
+		if text, ok := v.(string); ok {
+			scan("preset_var "+k, &text)
+			v = text
+		}
+		presetVars[k] = v
+	}
+	req.PresetVars = presetVars
+	for i := range req.Files {
+		f := &req.Files[i]
+		changed := scan(f.Path, &f.Diff)
+		changed = scan(f.Path+" (before)", &f.FullBefore) || changed
+		changed = scan(f.Path+" (after)", &f.FullAfter) || changed
+		if changed && mode == redact.ModeRedact {
+			f.RecountTokens(c.data.TokenCounter)
+		}
+	}
+	for i := range req.AdditionalContext {
+		item := &req.AdditionalContext[i]
+		source := item.Path
+		if source == "" {
+			source = string(item.Type)
+		}
+		if scan(source, &item.Content) && mode == redact.ModeRedact {
+			item.Tokens = c.data.TokenCounter.Count(item.Content)
+		}
+	}
+
+	c.redaction.Findings = findings
+	if mode == redact.ModeBlock && len(findings) > 0 {
+		return errors.Errorf("found %d possible secret(s) in the generation inputs (redaction mode block):\n%s",
+			len(findings), formatRedactions(findings, 10))
+	}
+	return nil
+}
+
+// formatRedactions lists up to limit findings, one per line.
+func formatRedactions(findings []redact.Finding, limit int) string {
+	lines := []string{}
+	for i, f := range findings {
+		if i == limit {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(findings)-limit))
+			break
+		}
+		lines = append(lines, "  "+f.String())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/go-go-golems/prescribe/internal/redact"
+)
+
+func newRedactionController(t *testing.T) *Controller {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	c := &Controller{repoPath: t.TempDir(), data: domain.NewPRData()}
+	c.data.SourceBranch, c.data.TargetBranch = "feature", "main"
+	c.data.ChangedFiles = []domain.FileChange{{
+		Path:     ".env.example",
+		Included: true,
+		Type:     domain.FileTypeDiff,
+		Diff:     "+OPENAI_API_KEY=sk-proj-abcdefghijklmnopqrstuvwx\n+PORT=8080",
+	}}
+	c.data.AdditionalContext = []domain.ContextItem{{Type: domain.ContextTypeNote, Content: "ticket corp_abcdef123456"}}
+	return c
+}
+
+func TestBuildGenerateDescriptionRequest_redactsSecrets(t *testing.T) {
+	c := newRedactionController(t)
+	cfg := "redaction:\n  patterns:\n    - name: corp-token\n      regex: corp_[a-z0-9]{12}\n"
+	if err := os.MkdirAll(filepath.Join(c.repoPath, ".pr-builder"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(c.repoPath, ".pr-builder", "config.yaml"), []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := c.BuildGenerateDescriptionRequest()
+	if err != nil {
+		t.Fatalf("BuildGenerateDescriptionRequest: %v", err)
+	}
+	if strings.Contains(req.Files[0].Diff, "sk-proj") || !strings.Contains(req.Files[0].Diff, "+PORT=8080") {
+		t.Fatalf("expected the key to be masked, got %q", req.Files[0].Diff)
+	}
+	if req.AdditionalContext[0].Content != "ticket [REDACTED:corp-token]" {
+		t.Fatalf("expected the custom pattern to apply, got %q", req.AdditionalContext[0].Content)
+	}
+	if !strings.Contains(c.data.ChangedFiles[0].Diff, "sk-proj") {
+		t.Fatalf("expected the session to stay untouched")
+	}
+	report := c.LastRedaction()
+	if report.Mode != redact.ModeRedact || len(report.Findings) != 2 || report.Findings[0].Source != ".env.example" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
+
+func TestBuildGenerateDescriptionRequest_redactionModes(t *testing.T) {
+	c := newRedactionController(t)
+
+	c.SetRedactionMode(redact.ModeWarn)
+	req, err := c.BuildGenerateDescriptionRequest()
+	if err != nil || !strings.Contains(req.Files[0].Diff, "sk-proj") || len(c.LastRedaction().Findings) != 1 {
+		t.Fatalf("expected warn to report without masking, got err=%v report=%+v", err, c.LastRedaction())
+	}
+
+	c.SetRedactionMode(redact.ModeBlock)
+	if _, err := c.BuildGenerateDescriptionRequest(); err == nil || !strings.Contains(err.Error(), ".env.example:1: openai-key sk-p…") {
+		t.Fatalf("expected block to fail with the findings, got %v", err)
+	}
+
+	c.SetRedactionMode(redact.ModeOff)
+	req, err = c.BuildGenerateDescriptionRequest()
+	if err != nil || !strings.Contains(req.Files[0].Diff, "sk-proj") || len(c.LastRedaction().Findings) != 0 {
+		t.Fatalf("expected off to skip the scan, got err=%v report=%+v", err, c.LastRedaction())
+	}
+}
+
+func TestPreviewGenerateDescriptionRequest_noRedaction(t *testing.T) {
+	c := newRedactionController(t)
+	c.SetRedactionMode(redact.ModeBlock)
+
+	// Estimates must neither fail in block mode nor replace the report of the last real request.
+	if _, err := c.promptOverheadTokens(); err != nil {
+		t.Fatalf("expected the estimate to ignore block mode, got %v", err)
+	}
+	req, err := c.PreviewGenerateDescriptionRequest()
+	if err != nil || !strings.Contains(req.Files[0].Diff, "sk-proj") {
+		t.Fatalf("expected the unredacted request, got err=%v", err)
+	}
+	if report := c.LastRedaction(); len(report.Findings) != 0 {
+		t.Fatalf("expected no redaction report, got %+v", report)
+	}
+}
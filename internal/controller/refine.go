@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/go-go-golems/prescribe/internal/history"
+	"github.com/go-go-golems/prescribe/internal/prdata"
+	"github.com/pkg/errors"
+)
+
+// Refine appends instruction to the conversation of the current generated description,
+// re-runs inference and re-parses the YAML. The refinement is recorded in the generation
+// metadata (persisted by WriteLastGeneratedPRData).
+//
+// If nothing was generated in this process, the last generation is loaded from disk first.
+func (c *Controller) Refine(ctx context.Context, instruction string) (string, error) {
+	if strings.TrimSpace(instruction) == "" {
+		return "", errors.New("refinement instruction is empty")
+	}
+	if c.lastTurn == nil {
+		if err := c.LoadLastGeneration(); err != nil {
+			return "", err
+		}
+	}
+
+	var refinements []domain.Refinement
+	if c.data.GenerationMetadata != nil {
+		refinements = append(refinements, c.data.GenerationMetadata.Refinements...)
+	}
+
+	var schema *domain.OutputSchema
+	if c.data.GenerationMetadata != nil {
+		schema = c.data.GenerationMetadata.OutputSchema
+	}
+	resp, err := c.apiService.RefineDescription(ctx, c.lastTurn, instruction, schema)
+	if err != nil {
+		return "", err
+	}
+
+	c.data.GeneratedDescription = resp.Description
+	c.data.GeneratedPRDataParseError = resp.ParseError
+	if resp.Parsed != nil && resp.ParseError == "" {
+		c.data.GeneratedPRData = resp.Parsed
+	}
+	c.setGenerationResult(resp)
+	c.data.GenerationMetadata.Refinements = append(refinements, domain.Refinement{
+		Instruction: strings.TrimSpace(instruction),
+		At:          time.Now().UTC(),
+		Usage:       resp.Usage,
+		ParseError:  resp.ParseError,
+	})
+	c.recordHistory(history.KindRefine, c.historyRequest(), c.currentPresetID(), resp, c.data.GenerationMetadata)
+	return resp.Description, nil
+}
+
+// LoadLastGeneration restores the generated PR data, its metadata and its conversation
+// from .pr-builder/ (written by WriteLastGeneratedPRData).
+func (c *Controller) LoadLastGeneration() error {
+	turnPath := prdata.LastGeneratedTurnPath(c.repoPath)
+	t, err := prdata.LoadTurnFromYAMLFile(turnPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return errors.Errorf("no previous generation found at %s; run 'prescribe generate' first", turnPath)
+		}
+		return err
+	}
+
+	path := prdata.LastGeneratedPRDataPath(c.repoPath)
+	data, err := prdata.LoadGeneratedPRDataFromYAMLFile(path)
+	if err != nil {
+		return err
+	}
+	meta, err := prdata.LoadGenerationMetadataFromYAMLFile(path)
+	if err != nil {
+		return err
+	}
+
+	c.lastTurn = t
+	c.data.GeneratedPRData = data
+	c.data.GeneratedPRDataParseError = ""
+	c.data.GenerationMetadata = meta
+	return nil
+}
+
+// WriteLastGeneratedPRData persists the generated PR data (and generation metadata) to
+// .pr-builder/last-generated-pr.yaml for `prescribe create --use-last`, and its conversation
+// to .pr-builder/last-generated-turn.yaml for `prescribe refine`.
+func (c *Controller) WriteLastGeneratedPRData() (string, error) {
+	if c.data.GeneratedPRData == nil {
+		return "", errors.New("no parsed PR data to write")
+	}
+	path := prdata.LastGeneratedPRDataPath(c.repoPath)
+	if err := prdata.WriteGeneratedPRDataToYAMLFile(path, c.data.GeneratedPRData, c.data.GenerationMetadata); err != nil {
+		return "", err
+	}
+	if c.lastTurn != nil {
+		if err := prdata.WriteTurnToYAMLFile(prdata.LastGeneratedTurnPath(c.repoPath), c.lastTurn); err != nil {
+			return "", err
+		}
+	}
+	return path, nil
+}
@@ -5,38 +5,109 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/go-go-golems/prescribe/internal/pricing"
+	"github.com/go-go-golems/prescribe/internal/redact"
 	"gopkg.in/yaml.v3"
 )
 
 type repoConfigYAML struct {
 	Defaults repoDefaultsYAML `yaml:"defaults,omitempty"`
+	// Pricing overrides/extends the builtin pricing table (see internal/pricing).
+	Pricing []pricing.Price `yaml:"pricing,omitempty"`
+	// Cache bounds the generation cache in .pr-builder/cache/ (see internal/cache).
+	Cache repoCacheYAML `yaml:"cache,omitempty"`
+	// Redaction configures the secret scan of the generation inputs (see internal/redact).
+	Redaction repoRedactionYAML `yaml:"redaction,omitempty"`
+}
+
+type repoRedactionYAML struct {
+	// Mode is off, warn, redact (default) or block.
+	Mode string `yaml:"mode,omitempty"`
+	// Patterns are additional detectors (name + regex).
+	Patterns []redact.CustomRule `yaml:"patterns,omitempty"`
+}
+
+type repoCacheYAML struct {
+	// MaxAge is a Go duration ("720h"); entries older than that are evicted.
+	MaxAge string `yaml:"max_age,omitempty"`
+	// MaxSizeMB caps the total size of the cache; the oldest entries are evicted first.
+	MaxSizeMB *int `yaml:"max_size_mb,omitempty"`
 }
 
 type repoDefaultsYAML struct {
 	FilterPresets []string `yaml:"filter_presets,omitempty"`
+	// BuiltinFilterPresets controls ecosystem detection during `session init`:
+	// "suggest" (default), "auto" (apply suggestions), or "off".
+	// When filter_presets is set and this is not, detection is off.
+	BuiltinFilterPresets string `yaml:"builtin_filter_presets,omitempty"`
 }
 
-// ApplyDefaultFilterPresetsFromRepoConfig loads <repo>/.pr-builder/config.yaml and applies any configured
-// default filter presets to the current controller state.
-//
-// This is intended for "new session" behavior (i.e. when session.yaml is missing).
-// It does not save a session automatically.
-func (c *Controller) ApplyDefaultFilterPresetsFromRepoConfig() (int, error) {
-	cfgPath := filepath.Join(c.repoPath, ".pr-builder", "config.yaml")
+func (c *Controller) loadRepoConfig() (repoConfigYAML, error) {
+	return loadConfigFile(filepath.Join(c.repoPath, ".pr-builder", "config.yaml"))
+}
+
+// loadGlobalConfig loads ~/.pr-builder/config.yaml (same format as the repo config).
+func loadGlobalConfig() (repoConfigYAML, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return repoConfigYAML{}, fmt.Errorf("resolve home dir: %w", err)
+	}
+	return loadConfigFile(filepath.Join(home, ".pr-builder", "config.yaml"))
+}
+
+func loadConfigFile(cfgPath string) (repoConfigYAML, error) {
+	var cfg repoConfigYAML
 
 	b, err := os.ReadFile(cfgPath)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return 0, nil
+			return cfg, nil
 		}
-		return 0, fmt.Errorf("read repo config: %w", err)
+		return cfg, fmt.Errorf("read config %s: %w", cfgPath, err)
 	}
 
-	var cfg repoConfigYAML
 	if err := yaml.Unmarshal(b, &cfg); err != nil {
-		return 0, fmt.Errorf("unmarshal repo config: %w", err)
+		return cfg, fmt.Errorf("unmarshal config %s: %w", cfgPath, err)
+	}
+	return cfg, nil
+}
+
+// ResolveBuiltinFilterPresetsMode returns the effective builtin filter preset mode.
+// A non-empty override (e.g. from a CLI flag) wins over <repo>/.pr-builder/config.yaml.
+func (c *Controller) ResolveBuiltinFilterPresetsMode(override string) (string, error) {
+	mode := strings.TrimSpace(override)
+	if mode == "" {
+		cfg, err := c.loadRepoConfig()
+		if err != nil {
+			return "", err
+		}
+		mode = strings.TrimSpace(cfg.Defaults.BuiltinFilterPresets)
+		if mode == "" && len(cfg.Defaults.FilterPresets) > 0 {
+			mode = BuiltinFilterPresetsOff
+		}
+	}
+	switch mode {
+	case "":
+		return BuiltinFilterPresetsSuggest, nil
+	case BuiltinFilterPresetsSuggest, BuiltinFilterPresetsAuto, BuiltinFilterPresetsOff:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid builtin filter presets mode: %q (expected suggest, auto or off)", mode)
+	}
+}
+
+// ApplyDefaultFilterPresetsFromRepoConfig loads <repo>/.pr-builder/config.yaml and applies any configured
+// default filter presets to the current controller state.
+//
+// This is intended for "new session" behavior (i.e. when session.yaml is missing).
+// It does not save a session automatically.
+func (c *Controller) ApplyDefaultFilterPresetsFromRepoConfig() (int, error) {
+	cfg, err := c.loadRepoConfig()
+	if err != nil {
+		return 0, err
 	}
 
 	if len(cfg.Defaults.FilterPresets) == 0 {
@@ -50,11 +121,7 @@ func (c *Controller) ApplyDefaultFilterPresetsFromRepoConfig() (int, error) {
 			return applied, err
 		}
 
-		c.AddFilter(domain.Filter{
-			Name:        preset.Name,
-			Description: preset.Description,
-			Rules:       preset.Rules,
-		})
+		c.ApplyFilterPreset(preset)
 		applied++
 	}
 
@@ -62,7 +129,7 @@ func (c *Controller) ApplyDefaultFilterPresetsFromRepoConfig() (int, error) {
 }
 
 // LoadFilterPresetByID resolves a filter preset ID (typically a filename like "exclude_tests.yaml")
-// by searching project presets first, then global presets.
+// by searching project presets first, then global presets, then the builtin presets.
 func (c *Controller) LoadFilterPresetByID(presetID string) (domain.FilterPreset, error) {
 	projectPresets, err := c.LoadProjectFilterPresets()
 	if err == nil {
@@ -82,5 +149,14 @@ func (c *Controller) LoadFilterPresetByID(presetID string) (domain.FilterPreset,
 		}
 	}
 
+	builtinPresets, err := LoadBuiltinFilterPresets()
+	if err == nil {
+		for _, p := range builtinPresets {
+			if p.ID == presetID {
+				return p, nil
+			}
+		}
+	}
+
 	return domain.FilterPreset{}, fmt.Errorf("filter preset not found: %s", presetID)
 }
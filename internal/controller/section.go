@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-go-golems/prescribe/internal/api"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/go-go-golems/prescribe/internal/history"
+	"github.com/pkg/errors"
+)
+
+// RegenerateSection regenerates one section of the current generated PR data and keeps the others
+// untouched. It returns the updated YAML. The regeneration is recorded in the refinement history,
+// and the conversation is updated so a later Refine continues from the new text.
+//
+// If nothing was generated in this process, the last generation is loaded from disk first.
+func (c *Controller) RegenerateSection(ctx context.Context, section domain.PRDataSection) (string, error) {
+	if c.data.GeneratedPRData == nil {
+		if err := c.LoadLastGeneration(); err != nil {
+			return "", err
+		}
+	}
+
+	req, err := c.BuildGenerateDescriptionRequest()
+	if err != nil {
+		return "", err
+	}
+	if err := c.apiService.ValidateRequest(req); err != nil {
+		return "", err
+	}
+
+	var refinements []domain.Refinement
+	if c.data.GenerationMetadata != nil {
+		refinements = append(refinements, c.data.GenerationMetadata.Refinements...)
+	}
+
+	resp, err := c.apiService.GenerateSection(ctx, req, c.data.GeneratedPRData, section)
+	if err != nil {
+		return "", err
+	}
+	if resp.ParseError != "" {
+		return "", errors.Errorf("failed to regenerate %s: %s", section, resp.ParseError)
+	}
+
+	// Continue the previous conversation (not the section-only exchange) with the merged result.
+	base := c.lastTurn
+	if base == nil {
+		base = resp.Turn
+	}
+	resp.Turn = api.WithAssistantText(base, resp.Description)
+
+	c.data.GeneratedDescription = resp.Description
+	c.data.GeneratedPRData = resp.Parsed
+	c.data.GeneratedPRDataParseError = ""
+	c.setGenerationResult(resp)
+	c.data.GenerationMetadata.Refinements = append(refinements, domain.Refinement{
+		Instruction: "regenerate " + string(section),
+		Section:     section,
+		At:          time.Now().UTC(),
+		Usage:       resp.Usage,
+	})
+	c.recordHistory(history.KindSection, req, c.currentPresetID(), resp, c.data.GenerationMetadata)
+	return resp.Description, nil
+}
@@ -3,6 +3,9 @@ package controller
 import (
 	"fmt"
 
+	"github.com/rs/zerolog/log"
+
+	"github.com/go-go-golems/prescribe/internal/domain"
 	"github.com/go-go-golems/prescribe/internal/session"
 )
 
@@ -29,10 +32,57 @@ func (c *Controller) LoadSession(path string) error {
 	if err := sess.ApplyToData(c.data, c.repoPath); err != nil {
 		return fmt.Errorf("failed to apply session: %w", err)
 	}
+	c.dropDeniedContext()
 
 	return nil
 }
 
+// dropDeniedContext removes context files and git context items that .prescribeignore denies (e.g.
+// added to the session before the pattern was), so that their content is never sent.
+func (c *Controller) dropDeniedContext() {
+	if c.gitService == nil {
+		return
+	}
+	kept := c.data.AdditionalContext[:0]
+	for _, item := range c.data.AdditionalContext {
+		if item.Type == domain.ContextTypeFile {
+			if rule, ok := c.gitService.Denied(item.Path); ok {
+				log.Warn().Str("path", item.Path).Str("rule", rule).Msg("dropping context file denied by .prescribeignore")
+				continue
+			}
+		}
+		kept = append(kept, item)
+	}
+	c.data.AdditionalContext = kept
+
+	keptGit := c.data.GitContext[:0]
+	for _, item := range c.data.GitContext {
+		if item.Path != "" {
+			if rule, ok := c.gitService.Denied(item.Path); ok {
+				log.Warn().Str("kind", string(item.Kind)).Str("path", item.Path).Str("rule", rule).Msg("dropping git context item denied by .prescribeignore")
+				continue
+			}
+		}
+		if len(item.Paths) > 0 {
+			paths := []string{}
+			for _, p := range item.Paths {
+				if rule, ok := c.gitService.Denied(p); ok {
+					log.Warn().Str("kind", string(item.Kind)).Str("path", p).Str("rule", rule).Msg("dropping git context path denied by .prescribeignore")
+					continue
+				}
+				paths = append(paths, p)
+			}
+			// A patch restricted to denied paths only is dropped (no paths would mean the whole commit).
+			if len(paths) == 0 {
+				continue
+			}
+			item.Paths = paths
+		}
+		keptGit = append(keptGit, item)
+	}
+	c.data.GitContext = keptGit
+}
+
 // GetDefaultSessionPath returns the default session path
 func (c *Controller) GetDefaultSessionPath() string {
 	return session.GetDefaultSessionPath(c.repoPath)
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-go-golems/prescribe/internal/api"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/go-go-golems/prescribe/internal/history"
+	"github.com/pkg/errors"
+)
+
+// Translate translates the current generated PR data into language (a name or an ISO code, see
+// domain.LanguageName), keeping code blocks and inline code intact. It returns the translated YAML.
+// The translation is recorded in the refinement history, and the conversation is updated so a
+// later Refine continues from the translated text.
+//
+// If nothing was generated in this process, the last generation is loaded from disk first.
+func (c *Controller) Translate(ctx context.Context, language string) (string, error) {
+	if strings.TrimSpace(language) == "" {
+		return "", errors.New("no target language: set --language or the session's prompt language")
+	}
+	if c.data.GeneratedPRData == nil {
+		if err := c.LoadLastGeneration(); err != nil {
+			return "", err
+		}
+	}
+	name := domain.LanguageName(language)
+
+	var refinements []domain.Refinement
+	var schema *domain.OutputSchema
+	if c.data.GenerationMetadata != nil {
+		refinements = append(refinements, c.data.GenerationMetadata.Refinements...)
+		schema = c.data.GenerationMetadata.OutputSchema
+	}
+
+	resp, err := c.apiService.TranslateDescription(ctx, c.data.GeneratedPRData, name, schema)
+	if err != nil {
+		return "", err
+	}
+	if resp.ParseError != "" {
+		return "", errors.Errorf("failed to translate to %s: %s", name, resp.ParseError)
+	}
+
+	// Continue the previous conversation (not the translation exchange) with the translated result.
+	base := c.lastTurn
+	if base == nil {
+		base = resp.Turn
+	}
+	resp.Turn = api.WithAssistantText(base, resp.Description)
+
+	c.data.GeneratedDescription = resp.Description
+	c.data.GeneratedPRData = resp.Parsed
+	c.data.GeneratedPRDataParseError = ""
+	c.setGenerationResult(resp)
+	c.data.GenerationMetadata.Refinements = append(refinements, domain.Refinement{
+		Instruction: "translate to " + name,
+		At:          time.Now().UTC(),
+		Usage:       resp.Usage,
+	})
+	c.recordHistory(history.KindTranslate, c.historyRequest(), c.currentPresetID(), resp, c.data.GenerationMetadata)
+	return resp.Description, nil
+}
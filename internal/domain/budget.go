@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"path"
+	"strings"
+)
+
+// BudgetPriority raises (positive weight) or lowers (negative weight) the priority of files
+// matching Pattern when fitting a token budget.
+type BudgetPriority struct {
+	Pattern string
+	Weight  int
+}
+
+// BudgetConfig configures the token budget solver. It is persisted in session.yaml (as `budget:`).
+type BudgetConfig struct {
+	// MaxTokens is the budget for included files + additional context (0 = derive from the model).
+	MaxTokens  int
+	Priorities []BudgetPriority
+	// TestWeight is added to the priority of test files (negative: degrade tests first).
+	TestWeight int
+	// ChurnWeight scales log2(1 + additions + deletions) into the priority.
+	ChurnWeight int
+}
+
+func DefaultBudgetConfig() BudgetConfig {
+	return BudgetConfig{
+		TestWeight:  -10,
+		ChurnWeight: 1,
+	}
+}
+
+// IsTestPath reports whether a path looks like a test file (Go, JS/TS, Python, or a test directory).
+func IsTestPath(p string) bool {
+	base := path.Base(p)
+	switch {
+	case strings.HasSuffix(base, "_test.go"),
+		strings.Contains(base, ".test."),
+		strings.Contains(base, ".spec."),
+		strings.HasPrefix(base, "test_") && strings.HasSuffix(base, ".py"),
+		strings.HasSuffix(base, "_test.py"):
+		return true
+	}
+	for _, dir := range strings.Split(path.Dir(p), "/") {
+		switch dir {
+		case "test", "tests", "__tests__", "testdata":
+			return true
+		}
+	}
+	return false
+}
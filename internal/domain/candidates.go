@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PRDataSection names one section of GeneratedPRData.
+type PRDataSection string
+
+const (
+	PRDataSectionTitle        PRDataSection = "title"
+	PRDataSectionBody         PRDataSection = "body"
+	PRDataSectionChangelog    PRDataSection = "changelog"
+	PRDataSectionReleaseNotes PRDataSection = "release_notes"
+)
+
+// PRDataSections lists all sections in display order.
+var PRDataSections = []PRDataSection{
+	PRDataSectionTitle,
+	PRDataSectionBody,
+	PRDataSectionChangelog,
+	PRDataSectionReleaseNotes,
+}
+
+// ParsePRDataSection parses a section name (e.g. "release_notes"; "release-notes" is accepted too).
+func ParsePRDataSection(s string) (PRDataSection, error) {
+	name := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), "-", "_")
+	for _, sec := range PRDataSections {
+		if string(sec) == name {
+			return sec, nil
+		}
+	}
+	return "", fmt.Errorf("unknown section %q (expected title, body, changelog or release_notes)", s)
+}
+
+// SectionText returns the text of a section, or "" if it is empty.
+// Release notes are rendered as their title followed by their body.
+func (d *GeneratedPRData) SectionText(s PRDataSection) string {
+	if d == nil {
+		return ""
+	}
+	switch s {
+	case PRDataSectionTitle:
+		return d.Title
+	case PRDataSectionBody:
+		return d.Body
+	case PRDataSectionChangelog:
+		return d.Changelog
+	case PRDataSectionReleaseNotes:
+		if d.ReleaseNotes == nil {
+			return ""
+		}
+		return strings.TrimSpace(d.ReleaseNotes.Title + "\n\n" + d.ReleaseNotes.Body)
+	}
+	return ""
+}
+
+// CopySection replaces section s of d with the same section of src.
+func (d *GeneratedPRData) CopySection(s PRDataSection, src *GeneratedPRData) {
+	if src == nil {
+		src = &GeneratedPRData{}
+	}
+	switch s {
+	case PRDataSectionTitle:
+		d.Title = src.Title
+	case PRDataSectionBody:
+		d.Body = src.Body
+	case PRDataSectionChangelog:
+		d.Changelog = src.Changelog
+	case PRDataSectionReleaseNotes:
+		if src.ReleaseNotes == nil {
+			d.ReleaseNotes = nil
+		} else {
+			rn := *src.ReleaseNotes
+			d.ReleaseNotes = &rn
+		}
+	}
+}
+
+// CandidatesConfig configures multi-candidate generation. It is persisted in session.yaml (as `candidates:`).
+//
+// Candidate i uses Presets[i % len(Presets)] and Temperatures[i % len(Temperatures)];
+// empty lists keep the session prompt and the configured temperature.
+type CandidatesConfig struct {
+	Count        int
+	Presets      []string
+	Temperatures []float64
+}
+
+func DefaultCandidatesConfig() CandidatesConfig {
+	return CandidatesConfig{Count: 3}
+}
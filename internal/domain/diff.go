@@ -0,0 +1,202 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-go-golems/prescribe/internal/tokens"
+)
+
+// ReducedDiffContextLines is the number of unchanged lines kept around each change
+// for FileTypeDiffReduced (git's default is 3).
+const ReducedDiffContextLines = 1
+
+// EffectiveDiff returns the diff-like text sent for non-full file modes:
+// the diff, the reduced-context diff, or the numstat summary line.
+func (f FileChange) EffectiveDiff() string {
+	switch f.Type {
+	case FileTypeDiffReduced:
+		return ReduceDiffContext(f.Diff, ReducedDiffContextLines)
+	case FileTypeNumstat:
+		return f.NumstatSummary()
+	case FileTypeDiff, FileTypeFull:
+		return f.Diff
+	default:
+		return f.Diff
+	}
+}
+
+// NumstatSummary returns the one-line summary used for numstat-only files.
+func (f FileChange) NumstatSummary() string {
+	return fmt.Sprintf("%s | +%d -%d (diff omitted)", f.Path, f.Additions, f.Deletions)
+}
+
+// RecountTokens recomputes Tokens for the file's current mode using counter (nil => default encoding).
+func (f *FileChange) RecountTokens(counter *tokens.Counter) {
+	switch f.Type {
+	case FileTypeFull:
+		switch f.Version {
+		case FileVersionBefore:
+			f.Tokens = counter.Count(f.FullBefore)
+		case FileVersionAfter:
+			f.Tokens = counter.Count(f.FullAfter)
+		case FileVersionBoth:
+			f.Tokens = counter.Count(f.FullBefore) + counter.Count(f.FullAfter)
+		default:
+			// Best effort: count both if version is unspecified.
+			f.Tokens = counter.Count(f.FullBefore) + counter.Count(f.FullAfter)
+		}
+	case FileTypeDiff, FileTypeDiffReduced, FileTypeNumstat:
+		f.Tokens = counter.Count(f.EffectiveDiff())
+	default:
+		f.Tokens = counter.Count(f.Diff)
+	}
+}
+
+var hunkHeaderRe = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@(.*)$`)
+
+// ReduceDiffContext rewrites a unified diff so that at most contextLines unchanged lines
+// surround each change. Hunks are split where the remaining context no longer touches and
+// hunk headers are recomputed. Input that does not look like a unified diff is returned as-is.
+func ReduceDiffContext(diff string, contextLines int) string {
+	if contextLines < 0 {
+		contextLines = 0
+	}
+	lines := strings.Split(diff, "\n")
+	out := make([]string, 0, len(lines))
+
+	for i := 0; i < len(lines); {
+		m := hunkHeaderRe.FindStringSubmatch(lines[i])
+		if m == nil {
+			out = append(out, lines[i])
+			i++
+			continue
+		}
+
+		// Collect the hunk body, bounded by the line counts in the header.
+		oldLeft, newLeft := hunkCount(m[2]), hunkCount(m[4])
+		j := i + 1
+		for j < len(lines) && len(lines[j]) > 0 {
+			l := lines[j]
+			if l[0] == '\\' {
+				j++
+				continue
+			}
+			if oldLeft <= 0 && newLeft <= 0 {
+				break
+			}
+			switch l[0] {
+			case ' ':
+				oldLeft--
+				newLeft--
+			case '-':
+				oldLeft--
+			case '+':
+				newLeft--
+			default:
+				oldLeft, newLeft = 0, 0
+				j--
+			}
+			j++
+		}
+		out = append(out, reduceHunk(m, lines[i+1:j], contextLines)...)
+		i = j
+	}
+
+	return strings.Join(out, "\n")
+}
+
+func hunkCount(s string) int {
+	if s == "" {
+		return 1
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func reduceHunk(header []string, body []string, contextLines int) []string {
+	oldLn, _ := strconv.Atoi(header[1])
+	newLn, _ := strconv.Atoi(header[3])
+	section := header[5]
+
+	type pos struct{ oldLn, newLn int }
+	positions := make([]pos, len(body))
+	keep := make([]bool, len(body))
+
+	for k, l := range body {
+		positions[k] = pos{oldLn, newLn}
+		switch l[0] {
+		case ' ':
+			oldLn++
+			newLn++
+		case '-':
+			oldLn++
+		case '+':
+			newLn++
+		}
+	}
+
+	for k, l := range body {
+		if l[0] != '+' && l[0] != '-' {
+			continue
+		}
+		keep[k] = true
+		// Walk outwards over context lines only.
+		for d, n := 1, 0; k-d >= 0 && n < contextLines; d++ {
+			if body[k-d][0] == ' ' {
+				keep[k-d] = true
+				n++
+			}
+		}
+		for d, n := 1, 0; k+d < len(body) && n < contextLines; d++ {
+			if body[k+d][0] == ' ' {
+				keep[k+d] = true
+				n++
+			}
+		}
+	}
+	for k, l := range body {
+		if l[0] == '\\' && k > 0 && keep[k-1] {
+			keep[k] = true
+		}
+	}
+
+	out := make([]string, 0, len(body)+1)
+	for k := 0; k < len(body); {
+		if !keep[k] {
+			k++
+			continue
+		}
+		end := k
+		for end < len(body) && keep[end] {
+			end++
+		}
+		oldCount, newCount := 0, 0
+		for _, l := range body[k:end] {
+			switch l[0] {
+			case ' ':
+				oldCount++
+				newCount++
+			case '-':
+				oldCount++
+			case '+':
+				newCount++
+			}
+		}
+		oldStart, newStart := positions[k].oldLn, positions[k].newLn
+		// Unified diff convention: an empty range points at the line before it.
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		out = append(out, fmt.Sprintf("@@ -%d,%d +%d,%d @@%s", oldStart, oldCount, newStart, newCount, section))
+		section = ""
+		out = append(out, body[k:end]...)
+		k = end
+	}
+	return out
+}
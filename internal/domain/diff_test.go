@@ -0,0 +1,60 @@
+package domain
+
+import "testing"
+
+func TestReduceDiffContext(t *testing.T) {
+	diff := `diff --git a/f.txt b/f.txt
+--- a/f.txt
++++ b/f.txt
+@@ -1,9 +1,9 @@ func main() {
+ a
+ b
+ c
+-d
++D
+ e
+ f
+ g
+-h
++H
+`
+	// The second hunk has no trailing context, so the counts reflect only g/h.
+	want := `diff --git a/f.txt b/f.txt
+--- a/f.txt
++++ b/f.txt
+@@ -3,3 +3,3 @@ func main() {
+ c
+-d
++D
+ e
+@@ -7,2 +7,2 @@
+ g
+-h
++H
+`
+	got := ReduceDiffContext(diff, 1)
+	if got != want {
+		t.Fatalf("unexpected reduced diff:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestReduceDiffContext_KeepsRemovedDashLines(t *testing.T) {
+	// A removed line whose content starts with "-- " must not end the hunk.
+	diff := "@@ -1,3 +1,2 @@\n a\n--- comment\n b\n"
+	got := ReduceDiffContext(diff, 0)
+	want := "@@ -2,1 +1,0 @@\n--- comment\n"
+	if got != want {
+		t.Fatalf("unexpected reduced diff: %q want %q", got, want)
+	}
+}
+
+func TestRecountTokens_Numstat(t *testing.T) {
+	f := FileChange{Path: "x.go", Additions: 3, Deletions: 1, Type: FileTypeNumstat, Diff: "lots of diff text here"}
+	f.RecountTokens(nil)
+	if f.EffectiveDiff() != "x.go | +3 -1 (diff omitted)" {
+		t.Fatalf("unexpected numstat summary: %q", f.EffectiveDiff())
+	}
+	if f.Tokens == 0 {
+		t.Fatalf("expected numstat tokens to be counted")
+	}
+}
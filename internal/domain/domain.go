@@ -3,6 +3,7 @@ package domain
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/bmatcuk/doublestar/v4"
 	"github.com/go-go-golems/prescribe/internal/prompts"
@@ -23,11 +24,22 @@ type FileChange struct {
 	FullAfter  string
 }
 
+// DeniedFile is a changed file that matches the .prescribeignore deny list.
+type DeniedFile struct {
+	Path string
+	// Rule is the deny list rule that matched ("file:line: pattern").
+	Rule string
+}
+
 type FileType string
 
 const (
 	FileTypeDiff FileType = "diff"
 	FileTypeFull FileType = "full_file"
+	// FileTypeDiffReduced sends the diff with only ReducedDiffContextLines of context.
+	FileTypeDiffReduced FileType = "diff_reduced"
+	// FileTypeNumstat sends only the path and added/deleted line counts.
+	FileTypeNumstat FileType = "numstat"
 )
 
 type FileVersion string
@@ -138,6 +150,38 @@ type PromptPreset struct {
 	Description string
 	Template    string
 	Location    PresetLocation
+	// OutputMode selects how the structured PR data is requested ("" => OutputModeYAML).
+	OutputMode OutputMode
+	// OutputSchema declares additional/required output fields (nil => the default fields).
+	OutputSchema *OutputSchema
+	// Lint configures the checks of the generated output (nil => the default checks).
+	Lint *LintConfig
+	// Parameters are the typed template variables the preset declares.
+	Parameters []PresetParameter
+	// Parts are the sections of a multi-part preset (nil => Template is used).
+	Parts *PromptParts
+}
+
+// OutputMode selects how the structured PR data (GeneratedPRData) is obtained from the model.
+type OutputMode string
+
+const (
+	// OutputModeYAML asks for YAML in the assistant text and parses it (with salvage heuristics).
+	OutputModeYAML OutputMode = "yaml"
+	// OutputModeTool asks the model to call a tool whose JSON schema matches GeneratedPRData.
+	// The YAML parsing is kept as the fallback if no valid tool call is produced.
+	OutputModeTool OutputMode = "tool"
+)
+
+// ParseOutputMode parses an output mode name; "" yields OutputModeYAML.
+func ParseOutputMode(s string) (OutputMode, error) {
+	switch OutputMode(strings.ToLower(strings.TrimSpace(s))) {
+	case "", OutputModeYAML:
+		return OutputModeYAML, nil
+	case OutputModeTool:
+		return OutputModeTool, nil
+	}
+	return "", fmt.Errorf("unknown output mode %q (expected yaml or tool)", s)
 }
 
 type PresetLocation string
@@ -159,17 +203,35 @@ type PRData struct {
 	Description string
 
 	// Files
-	ChangedFiles      []FileChange
+	ChangedFiles []FileChange
+	// DeniedFiles are changed files dropped by .prescribeignore (never read or sent; not persisted)
+	DeniedFiles       []DeniedFile
 	AdditionalContext []ContextItem
 	GitHistory        *GitHistoryConfig
 	GitContext        []GitContextItem
 
+	// Token budget solver configuration (nil => DefaultBudgetConfig, no explicit budget)
+	Budget *BudgetConfig
+
+	// Multi-candidate generation configuration (nil => DefaultCandidatesConfig)
+	Candidates *CandidatesConfig
+
+	// Tokenizer used for Tokens fields (nil => tokens.Default; not persisted)
+	TokenCounter *tokens.Counter
+
 	// Filters
 	ActiveFilters []Filter
 
 	// Prompt
 	CurrentPrompt string
 	CurrentPreset *PromptPreset
+	// PromptVars are the user-set template variables (issue, style switches, custom vars)
+	PromptVars PromptVars
+	// PresetVars are the values of the current preset's declared parameters (--preset-var)
+	PresetVars map[string]any
+
+	// OutputMode overrides the preset's output mode ("" => preset, else yaml; not persisted)
+	OutputMode OutputMode
 
 	// Generated description
 	GeneratedDescription string
@@ -177,6 +239,94 @@ type PRData struct {
 	// Parsed structured PR data (best-effort, not persisted in session.yaml)
 	GeneratedPRData           *GeneratedPRData
 	GeneratedPRDataParseError string
+
+	// How the last description was generated (usage, latency, ...; not persisted in session.yaml)
+	GenerationMetadata *GenerationMetadata
+}
+
+// GenerationUsage is the provider-reported token usage of a generation, summed over all
+// inference attempts (including retries).
+type GenerationUsage struct {
+	InputTokens  int `yaml:"input_tokens" json:"input_tokens"`
+	OutputTokens int `yaml:"output_tokens" json:"output_tokens"`
+	// CachedTokens is the part of InputTokens served from the provider's prompt cache (OpenAI).
+	CachedTokens int `yaml:"cached_tokens,omitempty" json:"cached_tokens,omitempty"`
+	// CacheReadTokens and CacheWriteTokens are prompt cache reads and writes reported on top of
+	// InputTokens (Claude).
+	CacheReadTokens  int `yaml:"cache_read_tokens,omitempty" json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int `yaml:"cache_write_tokens,omitempty" json:"cache_write_tokens,omitempty"`
+}
+
+// CacheText describes the prompt cache use, e.g. "800 cached" or "0 cached, 4000 cache read,
+// 1500 cache write".
+func (u GenerationUsage) CacheText() string {
+	text := fmt.Sprintf("%d cached", u.CachedTokens)
+	if u.CacheReadTokens > 0 {
+		text += fmt.Sprintf(", %d cache read", u.CacheReadTokens)
+	}
+	if u.CacheWriteTokens > 0 {
+		text += fmt.Sprintf(", %d cache write", u.CacheWriteTokens)
+	}
+	return text
+}
+
+// Add accumulates another attempt's usage.
+func (u *GenerationUsage) Add(o GenerationUsage) {
+	u.InputTokens += o.InputTokens
+	u.OutputTokens += o.OutputTokens
+	u.CachedTokens += o.CachedTokens
+	u.CacheReadTokens += o.CacheReadTokens
+	u.CacheWriteTokens += o.CacheWriteTokens
+}
+
+// GenerationMetadata describes a generation run. It is stored next to the PR data
+// (last-generated-pr.yaml) but is not part of the PR content.
+type GenerationMetadata struct {
+	Model string `yaml:"model,omitempty" json:"model,omitempty"`
+	// Usage is nil when the provider did not report usage.
+	Usage      *GenerationUsage `yaml:"usage,omitempty" json:"usage,omitempty"`
+	StopReason string           `yaml:"stop_reason,omitempty" json:"stop_reason,omitempty"`
+	LatencyMs  int64            `yaml:"latency_ms" json:"latency_ms"`
+	Attempts   int              `yaml:"attempts" json:"attempts"`
+	// AttemptLog lists every inference attempt (model, outcome, latency) when there was more than
+	// one, e.g. after transient-error retries or a fallback to another model.
+	AttemptLog []GenerationAttempt `yaml:"attempt_log,omitempty" json:"attempt_log,omitempty"`
+	// OutputMode is the mode that produced the PR data (yaml if a tool call fell back to YAML).
+	OutputMode OutputMode `yaml:"output_mode,omitempty" json:"output_mode,omitempty"`
+	// OutputSchema is the preset's output schema the PR data was validated against (nil => default).
+	OutputSchema *OutputSchema `yaml:"output_schema,omitempty" json:"output_schema,omitempty"`
+	// CacheKey is the content address of the generation inputs (see .pr-builder/cache/<key>.yaml).
+	CacheKey string `yaml:"cache_key,omitempty" json:"cache_key,omitempty"`
+	// Cached is set when the result was served from the cache instead of running inference.
+	Cached bool `yaml:"cached,omitempty" json:"cached,omitempty"`
+	// Refinements lists the follow-up instructions and section regenerations applied to the
+	// original generation, oldest first.
+	Refinements []Refinement `yaml:"refinements,omitempty" json:"refinements,omitempty"`
+	// LintFindings are the problems the post-generation lint found in the current PR data.
+	LintFindings []LintFinding `yaml:"lint_findings,omitempty" json:"lint_findings,omitempty"`
+	// LintRepaired is set when the PR data is the answer to a lint repair re-prompt.
+	LintRepaired bool `yaml:"lint_repaired,omitempty" json:"lint_repaired,omitempty"`
+}
+
+// GenerationAttempt is one inference run of a generation.
+type GenerationAttempt struct {
+	Model     string `yaml:"model" json:"model"`
+	LatencyMs int64  `yaml:"latency_ms" json:"latency_ms"`
+	// Error is set when the attempt failed (the next retry or fallback then ran).
+	Error string `yaml:"error,omitempty" json:"error,omitempty"`
+	// Fallback is set when the attempt used a fallback model instead of the configured one.
+	Fallback bool `yaml:"fallback,omitempty" json:"fallback,omitempty"`
+}
+
+// Refinement is one conversational follow-up ("make it shorter") applied to a generated description.
+type Refinement struct {
+	Instruction string           `yaml:"instruction" json:"instruction"`
+	At          time.Time        `yaml:"at" json:"at"`
+	Usage       *GenerationUsage `yaml:"usage,omitempty" json:"usage,omitempty"`
+	// ParseError is set when the refined output could not be parsed as PR YAML.
+	ParseError string `yaml:"parse_error,omitempty" json:"parse_error,omitempty"`
+	// Section is set when only that section was regenerated (`generate --section`).
+	Section PRDataSection `yaml:"section,omitempty" json:"section,omitempty"`
 }
 
 // GeneratedPRData represents the structured PR output format we ask the LLM to produce (YAML).
@@ -186,6 +336,8 @@ type GeneratedPRData struct {
 	Body         string             `yaml:"body" json:"body"`
 	Changelog    string             `yaml:"changelog" json:"changelog"`
 	ReleaseNotes *GeneratedPRDataRN `yaml:"release_notes,omitempty" json:"release_notes,omitempty"`
+	// Extra holds the custom fields of a preset's output schema (e.g. risk_level), as top-level keys.
+	Extra map[string]any `yaml:",inline" json:"-"`
 }
 
 type GeneratedPRDataRN struct {
@@ -274,6 +426,22 @@ func matchesPattern(path, pattern string) bool {
 	return matched
 }
 
+// SetTokenCounter switches the tokenizer and recounts all files and context items.
+func (d *PRData) SetTokenCounter(counter *tokens.Counter) {
+	d.TokenCounter = counter
+	d.RecountTokens()
+}
+
+// RecountTokens recomputes Tokens for all files and context items with the current TokenCounter.
+func (d *PRData) RecountTokens() {
+	for i := range d.ChangedFiles {
+		d.ChangedFiles[i].RecountTokens(d.TokenCounter)
+	}
+	for i := range d.AdditionalContext {
+		d.AdditionalContext[i].Tokens = d.TokenCounter.Count(d.AdditionalContext[i].Content)
+	}
+}
+
 // GetTotalTokens calculates total tokens for all included content
 func (d *PRData) GetTotalTokens() int {
 	total := 0
@@ -313,14 +481,7 @@ func (d *PRData) ReplaceWithFullFile(index int, version FileVersion) error {
 	file.Version = version
 
 	// Recalculate tokens based on version
-	switch version {
-	case FileVersionBefore:
-		file.Tokens = tokens.Count(file.FullBefore)
-	case FileVersionAfter:
-		file.Tokens = tokens.Count(file.FullAfter)
-	case FileVersionBoth:
-		file.Tokens = tokens.Count(file.FullBefore) + tokens.Count(file.FullAfter)
-	}
+	file.RecountTokens(d.TokenCounter)
 
 	return nil
 }
@@ -334,7 +495,7 @@ func (d *PRData) RestoreToDiff(index int) error {
 	file := &d.ChangedFiles[index]
 	file.Type = FileTypeDiff
 	file.Version = ""
-	file.Tokens = tokens.Count(file.Diff)
+	file.RecountTokens(d.TokenCounter)
 
 	return nil
 }
@@ -373,6 +534,33 @@ func (d *PRData) SetPrompt(prompt string, preset *PromptPreset) {
 	d.CurrentPreset = preset
 }
 
+// EffectiveOutputMode returns the OutputMode override, else the current preset's mode, else yaml.
+func (d *PRData) EffectiveOutputMode() OutputMode {
+	if d.OutputMode != "" {
+		return d.OutputMode
+	}
+	if d.CurrentPreset != nil && d.CurrentPreset.OutputMode != "" {
+		return d.CurrentPreset.OutputMode
+	}
+	return OutputModeYAML
+}
+
+// EffectiveOutputSchema returns the current preset's output schema (nil => the default fields).
+func (d *PRData) EffectiveOutputSchema() *OutputSchema {
+	if d.CurrentPreset != nil {
+		return d.CurrentPreset.OutputSchema
+	}
+	return nil
+}
+
+// EffectiveLintConfig returns the current preset's lint config (nil => the default checks).
+func (d *PRData) EffectiveLintConfig() *LintConfig {
+	if d.CurrentPreset != nil {
+		return d.CurrentPreset.Lint
+	}
+	return nil
+}
+
 // GetBuiltinPresets returns the built-in prompt presets
 func GetBuiltinPresets() []PromptPreset {
 	return []PromptPreset{
@@ -0,0 +1,236 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// DefaultLintMaxLength is the body length limit when a preset sets none (GitHub's PR body limit).
+const DefaultLintMaxLength = 65536
+
+// LintConfig configures the checks run on the generated PR data (`lint:` in a prompt preset).
+// A nil config runs the default checks.
+type LintConfig struct {
+	// MaxLength is the maximum body length in characters (0 => DefaultLintMaxLength).
+	MaxLength int `yaml:"max_length,omitempty" json:"max_length,omitempty"`
+	// RequiredHeadings are markdown headings the body must contain (matched case-insensitively).
+	RequiredHeadings []string `yaml:"required_headings,omitempty" json:"required_headings,omitempty"`
+	// CheckReferences checks that code references exist in the change (nil => true).
+	CheckReferences *bool `yaml:"check_references,omitempty" json:"check_references,omitempty"`
+	// Repair re-prompts the model once to fix the findings of a fresh generation.
+	Repair bool `yaml:"repair,omitempty" json:"repair,omitempty"`
+}
+
+// LintRule names a check.
+type LintRule string
+
+const (
+	// LintRuleUnknownReference flags a `code` reference (path, identifier or flag) that does not
+	// occur in the included files or context.
+	LintRuleUnknownReference LintRule = "unknown-reference"
+	LintRuleMaxLength        LintRule = "max-length"
+	LintRuleMissingHeading   LintRule = "missing-heading"
+	// LintRulePlaceholder flags leftover template placeholders such as {{ .Title }} or [insert summary].
+	LintRulePlaceholder LintRule = "placeholder"
+)
+
+// LintFinding is one problem found in the generated PR data.
+type LintFinding struct {
+	Rule    LintRule      `yaml:"rule" json:"rule"`
+	Section PRDataSection `yaml:"section,omitempty" json:"section,omitempty"`
+	Message string        `yaml:"message" json:"message"`
+}
+
+func (f LintFinding) String() string {
+	if f.Section == "" {
+		return fmt.Sprintf("%s: %s", f.Rule, f.Message)
+	}
+	return fmt.Sprintf("%s (%s): %s", f.Rule, f.Section, f.Message)
+}
+
+// MaxLengthOrDefault returns MaxLength, or DefaultLintMaxLength if unset.
+func (c *LintConfig) MaxLengthOrDefault() int {
+	if c == nil || c.MaxLength <= 0 {
+		return DefaultLintMaxLength
+	}
+	return c.MaxLength
+}
+
+// ReferencesChecked reports whether code references are checked.
+func (c *LintConfig) ReferencesChecked() bool {
+	return c == nil || c.CheckReferences == nil || *c.CheckReferences
+}
+
+// RepairEnabled reports whether findings of a fresh generation trigger a repair re-prompt.
+func (c *LintConfig) RepairEnabled() bool {
+	return c != nil && c.Repair
+}
+
+var (
+	reFencedBlock = regexp.MustCompile("(?s)```.*?(```|$)")
+	reInlineCode  = regexp.MustCompile("`([^`\n]+)`")
+	reHeading     = regexp.MustCompile(`(?m)^ {0,3}#{1,6}[ \t]+(.+?)[ \t#]*$`)
+	reVersion     = regexp.MustCompile(`^v?\d+(\.\d+)*$`)
+	rePlaceholder = []*regexp.Regexp{
+		regexp.MustCompile(`\{\{[^}]*\}\}`),
+		regexp.MustCompile(`(?i)\[(insert|add|describe|your|todo|tbd|placeholder)\b[^\]]*\]`),
+		regexp.MustCompile(`(?i)<(insert|add|describe|your|todo|tbd|placeholder)\b[^>]*>`),
+		regexp.MustCompile(`\bTBD\b`),
+		regexp.MustCompile(`(?i)\blorem ipsum\b`),
+	}
+)
+
+// Lint checks generated PR data against the change it describes: `code` references must occur in
+// the paths or contents of files (or in the context items), the body must stay within the
+// length limit and contain the required headings, and no template placeholder may be left over.
+func (c *LintConfig) Lint(d *GeneratedPRData, files []FileChange, context []ContextItem) []LintFinding {
+	if d == nil {
+		return nil
+	}
+	findings := []LintFinding{}
+
+	if n, limit := len([]rune(d.Body)), c.MaxLengthOrDefault(); n > limit {
+		findings = append(findings, LintFinding{
+			Rule:    LintRuleMaxLength,
+			Section: PRDataSectionBody,
+			Message: fmt.Sprintf("body is %d characters long (limit %d)", n, limit),
+		})
+	}
+
+	if c != nil && len(c.RequiredHeadings) > 0 {
+		headings := map[string]bool{}
+		for _, m := range reHeading.FindAllStringSubmatch(stripFencedBlocks(d.Body), -1) {
+			headings[normalizeHeading(m[1])] = true
+		}
+		for _, h := range c.RequiredHeadings {
+			if strings.TrimSpace(h) != "" && !headings[normalizeHeading(h)] {
+				findings = append(findings, LintFinding{
+					Rule:    LintRuleMissingHeading,
+					Section: PRDataSectionBody,
+					Message: fmt.Sprintf("missing heading %q", strings.TrimSpace(h)),
+				})
+			}
+		}
+	}
+
+	idx := newReferenceIndex(files, context)
+	for _, section := range PRDataSections {
+		text := stripFencedBlocks(d.SectionText(section))
+		if text == "" {
+			continue
+		}
+		for _, p := range placeholders(text) {
+			findings = append(findings, LintFinding{
+				Rule:    LintRulePlaceholder,
+				Section: section,
+				Message: fmt.Sprintf("leftover placeholder %q", p),
+			})
+		}
+		if !c.ReferencesChecked() {
+			continue
+		}
+		for _, ref := range codeReferences(text) {
+			if !idx.knows(ref) {
+				findings = append(findings, LintFinding{
+					Rule:    LintRuleUnknownReference,
+					Section: section,
+					Message: fmt.Sprintf("`%s` does not appear in the included files", ref),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+func stripFencedBlocks(s string) string {
+	return reFencedBlock.ReplaceAllString(s, "")
+}
+
+func normalizeHeading(s string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(s), ":"))
+}
+
+// placeholders returns the placeholders in s, outside of inline code.
+func placeholders(s string) []string {
+	s = reInlineCode.ReplaceAllString(s, "")
+	out := []string{}
+	for _, re := range rePlaceholder {
+		out = append(out, re.FindAllString(s, -1)...)
+	}
+	return out
+}
+
+// codeReferences returns the distinct inline code spans of s that look like a path, an identifier
+// or a flag. Plain words (`nil`, `yaml`), commands with spaces, URLs and versions are skipped.
+func codeReferences(s string) []string {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, m := range reInlineCode.FindAllStringSubmatch(s, -1) {
+		ref := strings.TrimRight(strings.TrimSpace(m[1]), ".,:;")
+		ref = strings.TrimSuffix(ref, "()")
+		if len(ref) < 3 || strings.ContainsAny(ref, " \t") || strings.Contains(ref, "://") || reVersion.MatchString(ref) {
+			continue
+		}
+		if !strings.ContainsAny(ref, "/._-(") && strings.ToLower(ref) == ref {
+			continue
+		}
+		if !seen[ref] {
+			seen[ref] = true
+			out = append(out, ref)
+		}
+	}
+	return out
+}
+
+// referenceIndex answers whether a code reference occurs in the material sent to the model.
+type referenceIndex struct {
+	paths []string
+	text  string
+}
+
+func newReferenceIndex(files []FileChange, context []ContextItem) referenceIndex {
+	idx := referenceIndex{}
+	var b strings.Builder
+	for _, f := range files {
+		idx.paths = append(idx.paths, f.Path)
+		b.WriteString(f.Path + "\n" + f.Diff + "\n" + f.FullBefore + "\n" + f.FullAfter + "\n")
+	}
+	for _, item := range context {
+		b.WriteString(item.Path + "\n" + item.Content + "\n")
+	}
+	idx.text = b.String()
+	return idx
+}
+
+// knows matches ref against the file paths (exact, by suffix or as a directory), then against the
+// contents. Flags are looked up without their dashes, and qualified names (ctrl.Refine) match when
+// every part occurs.
+func (idx referenceIndex) knows(ref string) bool {
+	p := strings.TrimPrefix(ref, "./")
+	for _, fp := range idx.paths {
+		if fp == p || strings.HasSuffix(fp, "/"+p) || strings.HasPrefix(fp, strings.TrimSuffix(p, "/")+"/") || path.Base(fp) == p {
+			return true
+		}
+	}
+	if i := strings.Index(ref, "("); i > 0 {
+		ref = ref[:i]
+	}
+	ref = strings.TrimLeft(ref, "-")
+	if strings.Contains(idx.text, ref) {
+		return true
+	}
+	parts := strings.Split(ref, ".")
+	last := parts[len(parts)-1]
+	if len(parts) < 2 || last == "" || strings.ToLower(last[:1]) == last[:1] {
+		// Not a qualified Go name (file names like lint.go must match as a whole).
+		return false
+	}
+	for _, part := range parts {
+		if !strings.Contains(idx.text, part) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func lintFiles() []FileChange {
+	return []FileChange{{
+		Path: "internal/controller/refine.go",
+		Diff: "+func (c *Controller) Refine(ctx context.Context, instruction string) (string, error) {\n+\tflag := \"lint-repair\"",
+	}}
+}
+
+func lintRules(findings []LintFinding) []string {
+	out := []string{}
+	for _, f := range findings {
+		out = append(out, string(f.Rule)+":"+f.Message)
+	}
+	return out
+}
+
+func TestLint_references(t *testing.T) {
+	d := &GeneratedPRData{
+		Title: "Add `Controller.Refine`",
+		Body: strings.Join([]string{
+			"Known: `internal/controller/refine.go`, `refine.go`, `internal/controller/`, `c.Refine()`, `--lint-repair`.",
+			"Skipped: `nil`, `go test ./...`, `v1.2.3`, `https://example.com/x.go`.",
+			"Unknown: `internal/ghost.go`, `Controller.Publish`, `--dry-run`, `lint.go`.",
+			"```go\nFenced(`NotChecked`)\n```",
+		}, "\n"),
+	}
+	got := lintRules((*LintConfig)(nil).Lint(d, lintFiles(), nil))
+	want := []string{
+		"unknown-reference:`internal/ghost.go` does not appear in the included files",
+		"unknown-reference:`Controller.Publish` does not appear in the included files",
+		"unknown-reference:`--dry-run` does not appear in the included files",
+		"unknown-reference:`lint.go` does not appear in the included files",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected findings:\n%s", strings.Join(got, "\n"))
+	}
+
+	// Context items count as known material; the check can be turned off.
+	ctx := []ContextItem{{Path: "notes", Content: "Controller.Publish internal/ghost.go --dry-run lint.go"}}
+	if got := (*LintConfig)(nil).Lint(d, lintFiles(), ctx); len(got) != 0 {
+		t.Fatalf("expected context items to be known, got %v", got)
+	}
+	off := false
+	if got := (&LintConfig{CheckReferences: &off}).Lint(d, lintFiles(), nil); len(got) != 0 {
+		t.Fatalf("expected no findings with check_references: false, got %v", got)
+	}
+}
+
+func TestLint_lengthHeadingsAndPlaceholders(t *testing.T) {
+	cfg := &LintConfig{MaxLength: 60, RequiredHeadings: []string{"Summary", "Testing:"}}
+	d := &GeneratedPRData{
+		Title: "Add refine",
+		Body:  "## summary\nDoes things. TBD\n\n[Insert testing notes here]\n\n`{{ .Kept }}` is code.",
+		ReleaseNotes: &GeneratedPRDataRN{
+			Title: "Refine",
+			Body:  "Thanks {{ .Author }}",
+		},
+	}
+	got := lintRules(cfg.Lint(d, lintFiles(), nil))
+	want := []string{
+		"max-length:body is 80 characters long (limit 60)",
+		`missing-heading:missing heading "Testing:"`,
+		`placeholder:leftover placeholder "[Insert testing notes here]"`,
+		`placeholder:leftover placeholder "TBD"`,
+		`placeholder:leftover placeholder "{{ .Author }}"`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected findings:\n%s", strings.Join(got, "\n"))
+	}
+	if f := cfg.Lint(d, lintFiles(), nil)[4]; f.Section != PRDataSectionReleaseNotes {
+		t.Fatalf("expected the placeholder in release_notes, got %s", f.Section)
+	}
+}
+
+func TestLintConfig_defaults(t *testing.T) {
+	var cfg *LintConfig
+	if cfg.MaxLengthOrDefault() != DefaultLintMaxLength || !cfg.ReferencesChecked() || cfg.RepairEnabled() {
+		t.Fatalf("unexpected defaults for a nil config")
+	}
+	if got := cfg.Lint(&GeneratedPRData{Title: "t", Body: strings.Repeat("x", DefaultLintMaxLength+1)}, nil, nil); len(got) != 1 || got[0].Rule != LintRuleMaxLength {
+		t.Fatalf("expected the default length limit, got %v", got)
+	}
+}
@@ -0,0 +1,156 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Tone is a named audience/tone preset for the generated text (template variables `tone` and
+// `tone_guidance`).
+type Tone struct {
+	ID       string
+	Audience string
+	// Guidance is the instruction given to the model.
+	Guidance string
+}
+
+// BuiltinTones are the tone presets selectable with --tone.
+var BuiltinTones = []Tone{
+	{
+		ID:       "reviewers",
+		Audience: "code reviewers",
+		Guidance: "Write for the code reviewers: precise and technical. Explain why the code changed, " +
+			"name the affected components and point out what needs careful review (migrations, " +
+			"behavior changes, risky spots).",
+	},
+	{
+		ID:       "end-users",
+		Audience: "end users (release notes)",
+		Guidance: "Write for end users, as in release notes: describe the visible effect of the change " +
+			"in plain language and leave out implementation details, internal names and file paths.",
+	},
+	{
+		ID:       "stakeholders",
+		Audience: "product owners and managers",
+		Guidance: "Write for non-technical stakeholders: lead with the impact and the risk of the " +
+			"change, keep technical details to a minimum and stay brief.",
+	},
+}
+
+// LookupTone returns the builtin tone with the given ID.
+func LookupTone(id string) (Tone, bool) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	for _, t := range BuiltinTones {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return Tone{}, false
+}
+
+// ToneIDs returns the IDs of the builtin tones.
+func ToneIDs() []string {
+	ids := make([]string, 0, len(BuiltinTones))
+	for _, t := range BuiltinTones {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
+
+// ValidateTone returns an error if id is set and not a builtin tone.
+func ValidateTone(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return nil
+	}
+	if _, ok := LookupTone(id); !ok {
+		return fmt.Errorf("unknown tone %q (available: %s)", id, strings.Join(ToneIDs(), ", "))
+	}
+	return nil
+}
+
+var languageNames = map[string]string{
+	"de": "German",
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"it": "Italian",
+	"ja": "Japanese",
+	"nl": "Dutch",
+	"pl": "Polish",
+	"pt": "Portuguese",
+	"zh": "Chinese",
+}
+
+// LanguageName returns the name the prompt uses for an output language: ISO 639-1 codes
+// ("de", "de-CH") become the English name ("German", "German (CH)"), anything else is kept as is.
+func LanguageName(language string) string {
+	language = strings.TrimSpace(language)
+	code, region, _ := strings.Cut(strings.ReplaceAll(language, "_", "-"), "-")
+	name, ok := languageNames[strings.ToLower(code)]
+	if !ok {
+		return language
+	}
+	if region != "" {
+		return fmt.Sprintf("%s (%s)", name, strings.ToUpper(region))
+	}
+	return name
+}
+
+// CodePlaceholder is the token ProtectCode puts in place of the i-th code segment.
+func CodePlaceholder(i int) string {
+	return fmt.Sprintf("@@CODE%d@@", i)
+}
+
+var inlineCodeRe = regexp.MustCompile("`[^`\n]+`")
+
+// ProtectCode replaces the fenced code blocks and inline code spans of text with placeholders
+// (numbered after the segments already collected) and returns the text and the segments, so a
+// translation cannot alter code, identifiers or commands. RestoreCode reverses it.
+func ProtectCode(text string, segments []string) (string, []string) {
+	var out []string
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimLeft(lines[i], " \t")
+		fence := ""
+		if strings.HasPrefix(trimmed, "```") {
+			fence = "```"
+		} else if strings.HasPrefix(trimmed, "~~~") {
+			fence = "~~~"
+		}
+		if fence == "" {
+			out = append(out, inlineCodeRe.ReplaceAllStringFunc(lines[i], func(code string) string {
+				segments = append(segments, code)
+				return CodePlaceholder(len(segments) - 1)
+			}))
+			continue
+		}
+		// The block runs to the closing fence (or the end of the text if it is not closed).
+		end := len(lines) - 1
+		for j := i + 1; j < len(lines); j++ {
+			if strings.HasPrefix(strings.TrimSpace(lines[j]), fence) && strings.Trim(strings.TrimSpace(lines[j]), fence[:1]) == "" {
+				end = j
+				break
+			}
+		}
+		// The indentation of the opening fence stays in the text, next to the placeholder.
+		indent := lines[i][:len(lines[i])-len(trimmed)]
+		segments = append(segments, strings.Join(append([]string{trimmed}, lines[i+1:end+1]...), "\n"))
+		out = append(out, indent+CodePlaceholder(len(segments)-1))
+		i = end
+	}
+	return strings.Join(out, "\n"), segments
+}
+
+// RestoreCode puts the segments back in place of their placeholders and records the indexes it
+// found in used, so the caller can tell whether a segment was dropped.
+func RestoreCode(text string, segments []string, used map[int]bool) string {
+	for i, seg := range segments {
+		ph := CodePlaceholder(i)
+		if strings.Contains(text, ph) {
+			used[i] = true
+			text = strings.ReplaceAll(text, ph, seg)
+		}
+	}
+	return text
+}
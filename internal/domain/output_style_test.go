@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLanguageName(t *testing.T) {
+	for in, want := range map[string]string{
+		"de":       "German",
+		"DE":       "German",
+		"de-CH":    "German (CH)",
+		"pt_br":    "Portuguese (BR)",
+		"Deutsch":  "Deutsch",
+		" German ": "German",
+		"":         "",
+	} {
+		if got := LanguageName(in); got != want {
+			t.Errorf("LanguageName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestValidateTone(t *testing.T) {
+	if err := ValidateTone(""); err != nil {
+		t.Fatalf("empty tone: %v", err)
+	}
+	if err := ValidateTone("End-Users"); err != nil {
+		t.Fatalf("end-users: %v", err)
+	}
+	err := ValidateTone("pirate")
+	if err == nil || !strings.Contains(err.Error(), "reviewers, end-users, stakeholders") {
+		t.Fatalf("expected an unknown tone error listing the tones, got %v", err)
+	}
+}
+
+func TestProtectCode_roundTrip(t *testing.T) {
+	text := "Use `Controller.Translate` here.\n\n  ```go\n  x := `raw`\n  ```\nDone, see `a` and `b`.\n~~~\nunclosed"
+	masked, segments := ProtectCode(text, []string{"earlier"})
+
+	if strings.Contains(masked, "`") || strings.Contains(masked, "~~~") {
+		t.Fatalf("expected all code to be masked, got:\n%s", masked)
+	}
+	if len(segments) != 6 || segments[0] != "earlier" || segments[1] != "`Controller.Translate`" {
+		t.Fatalf("unexpected segments: %q", segments)
+	}
+	if !strings.Contains(masked, "\n  "+CodePlaceholder(2)+"\n") {
+		t.Fatalf("expected the fenced block to keep its indentation, got:\n%s", masked)
+	}
+
+	used := map[int]bool{}
+	if got := RestoreCode(masked, segments, used); got != text {
+		t.Fatalf("round trip mismatch:\n%s\nwant:\n%s", got, text)
+	}
+	if used[0] || !used[1] || !used[5] {
+		t.Fatalf("unexpected used set: %v", used)
+	}
+}
+
+func TestPromptVars_languageAndTone(t *testing.T) {
+	v := PromptVars{Language: "de", Tone: "reviewers"}
+	vars := v.TemplateVars()
+	tone, _ := LookupTone("reviewers")
+	if vars["language"] != "German" || vars["tone"] != "reviewers" || vars["tone_guidance"] != tone.Guidance {
+		t.Fatalf("unexpected template vars: language=%v tone=%v guidance=%v", vars["language"], vars["tone"], vars["tone_guidance"])
+	}
+	if got := v.Summary(); got != "language: German, tone: reviewers" {
+		t.Fatalf("Summary() = %q", got)
+	}
+	if (PromptVars{Vars: map[string]any{"tone": "x"}}).ShadowedVars()[0] != "tone" {
+		t.Fatalf("expected tone to be a built-in variable")
+	}
+}
@@ -0,0 +1,272 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PresetParameterType is the type of a declared prompt preset parameter (the names follow
+// pinocchio's command YAML).
+type PresetParameterType string
+
+const (
+	PresetParameterString     PresetParameterType = "string"
+	PresetParameterInt        PresetParameterType = "int"
+	PresetParameterFloat      PresetParameterType = "float"
+	PresetParameterBool       PresetParameterType = "bool"
+	PresetParameterChoice     PresetParameterType = "choice"
+	PresetParameterStringList PresetParameterType = "stringList"
+)
+
+// PresetParameter is a typed variable declared by a prompt preset (`parameters:` in the preset
+// file). Its value is available to the template as {{ .<name> }}.
+type PresetParameter struct {
+	Name string `yaml:"name" json:"name"`
+	// Type is one of string (default), int, float, bool, choice or stringList.
+	Type    PresetParameterType `yaml:"type,omitempty" json:"type,omitempty"`
+	Default any                 `yaml:"default,omitempty" json:"default,omitempty"`
+	Help    string              `yaml:"help,omitempty" json:"help,omitempty"`
+	// Choices are the allowed values of a choice parameter.
+	Choices  []string `yaml:"choices,omitempty" json:"choices,omitempty"`
+	Required bool     `yaml:"required,omitempty" json:"required,omitempty"`
+}
+
+// ParameterType returns Type, or string if unset.
+func (p PresetParameter) ParameterType() PresetParameterType {
+	if p.Type == "" {
+		return PresetParameterString
+	}
+	return p.Type
+}
+
+// Validate checks the declaration itself: known type, choices for choice parameters, a name that
+// does not shadow a built-in template variable, and a default of the right type.
+func (p PresetParameter) Validate() error {
+	name := strings.TrimSpace(p.Name)
+	if name == "" {
+		return errors.New("preset parameter without a name")
+	}
+	if IsBuiltinPromptVar(name) {
+		return errors.Errorf("preset parameter %q shadows a built-in template variable", name)
+	}
+	switch p.ParameterType() {
+	case PresetParameterString, PresetParameterInt, PresetParameterFloat, PresetParameterBool, PresetParameterStringList:
+	case PresetParameterChoice:
+		if len(p.Choices) == 0 {
+			return errors.Errorf("preset parameter %q: choice needs choices", name)
+		}
+	default:
+		return errors.Errorf("preset parameter %q: unknown type %q (expected string, int, float, bool, choice or stringList)", name, p.Type)
+	}
+	if p.Default != nil {
+		if _, err := p.Coerce(p.Default); err != nil {
+			return errors.Wrap(err, "invalid default")
+		}
+	}
+	return nil
+}
+
+// Coerce converts v (from YAML or a key=value flag) to the parameter's type.
+func (p PresetParameter) Coerce(v any) (any, error) {
+	switch p.ParameterType() {
+	case PresetParameterInt:
+		switch x := v.(type) {
+		case int:
+			return x, nil
+		case int64:
+			return int(x), nil
+		case float64:
+			if x == float64(int(x)) {
+				return int(x), nil
+			}
+		case string:
+			if n, err := strconv.Atoi(strings.TrimSpace(x)); err == nil {
+				return n, nil
+			}
+		}
+		return nil, errors.Errorf("%s: %v is not an integer", p.Name, v)
+	case PresetParameterFloat:
+		switch x := v.(type) {
+		case float64:
+			return x, nil
+		case int:
+			return float64(x), nil
+		case int64:
+			return float64(x), nil
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(x), 64); err == nil {
+				return f, nil
+			}
+		}
+		return nil, errors.Errorf("%s: %v is not a number", p.Name, v)
+	case PresetParameterBool:
+		switch x := v.(type) {
+		case bool:
+			return x, nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(x)); err == nil {
+				return b, nil
+			}
+		}
+		return nil, errors.Errorf("%s: %v is not true or false", p.Name, v)
+	case PresetParameterChoice:
+		s := strings.TrimSpace(fmt.Sprint(v))
+		for _, c := range p.Choices {
+			if c == s {
+				return s, nil
+			}
+		}
+		return nil, errors.Errorf("%s: %q is not one of %s", p.Name, s, strings.Join(p.Choices, ", "))
+	case PresetParameterStringList:
+		switch x := v.(type) {
+		case []string:
+			return append([]string{}, x...), nil
+		case []any:
+			out := make([]string, 0, len(x))
+			for _, item := range x {
+				out = append(out, fmt.Sprint(item))
+			}
+			return out, nil
+		case string:
+			out := []string{}
+			for _, item := range strings.Split(x, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					out = append(out, item)
+				}
+			}
+			return out, nil
+		}
+		return nil, errors.Errorf("%s: %v is not a list", p.Name, v)
+	default:
+		switch v.(type) {
+		case []any, map[string]any:
+			return nil, errors.Errorf("%s: %v is not a string", p.Name, v)
+		}
+		return fmt.Sprint(v), nil
+	}
+}
+
+// zero is the value of an optional parameter without default, so templates can test {{ if .x }}.
+func (p PresetParameter) zero() any {
+	switch p.ParameterType() {
+	case PresetParameterInt:
+		return 0
+	case PresetParameterFloat:
+		return 0.0
+	case PresetParameterBool:
+		return false
+	case PresetParameterStringList:
+		return []string{}
+	default:
+		return ""
+	}
+}
+
+// ResolveParameters validates values against the declared parameters and returns the typed value
+// of every parameter (defaults and zero values filled in). Unknown names and missing required
+// parameters are errors.
+func (p *PromptPreset) ResolveParameters(values map[string]any) (map[string]any, error) {
+	var params []PresetParameter
+	presetName := "the current prompt"
+	if p != nil {
+		params = p.Parameters
+		presetName = fmt.Sprintf("preset %s", p.ID)
+	}
+	declared := map[string]PresetParameter{}
+	for _, param := range params {
+		if err := param.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "%s", presetName)
+		}
+		declared[param.Name] = param
+	}
+
+	unknown := []string{}
+	for name := range values {
+		if _, ok := declared[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, errors.Errorf("%s has no parameter %s (declared: %s)", presetName, strings.Join(unknown, ", "), p.parameterList())
+	}
+
+	out := map[string]any{}
+	missing := []string{}
+	for _, param := range params {
+		v, ok := values[param.Name]
+		switch {
+		case ok:
+		case param.Default != nil:
+			v = param.Default
+		case param.Required:
+			missing = append(missing, param.Name)
+			continue
+		default:
+			out[param.Name] = param.zero()
+			continue
+		}
+		typed, err := param.Coerce(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s", presetName)
+		}
+		out[param.Name] = typed
+	}
+	if len(missing) > 0 {
+		return nil, errors.Errorf("%s requires --preset-var for %s", presetName, strings.Join(missing, ", "))
+	}
+	return out, nil
+}
+
+// ParameterDefaults returns the default (or zero) value of every declared parameter, skipping
+// defaults that do not match their type.
+func (p *PromptPreset) ParameterDefaults() map[string]any {
+	out := map[string]any{}
+	if p == nil {
+		return out
+	}
+	for _, param := range p.Parameters {
+		out[param.Name] = param.zero()
+		if param.Default != nil {
+			if v, err := param.Coerce(param.Default); err == nil {
+				out[param.Name] = v
+			}
+		}
+	}
+	return out
+}
+
+// parameterList describes the declared parameters for error messages ("none" if there are none).
+func (p *PromptPreset) parameterList() string {
+	if p == nil || len(p.Parameters) == 0 {
+		return "none"
+	}
+	parts := make([]string, 0, len(p.Parameters))
+	for _, param := range p.Parameters {
+		s := fmt.Sprintf("%s (%s)", param.Name, param.ParameterType())
+		if len(param.Choices) > 0 {
+			s = fmt.Sprintf("%s (%s)", param.Name, strings.Join(param.Choices, "|"))
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// PresetParamsSummary lists the preset parameter values set in the session, e.g.
+// "audience=ops, depth=2" ("" if none).
+func (d *PRData) PresetParamsSummary() string {
+	names := make([]string, 0, len(d.PresetVars))
+	for k := range d.PresetVars {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, k := range names {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, d.PresetVars[k]))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func paramPreset() *PromptPreset {
+	return &PromptPreset{
+		ID: "audience.yaml",
+		Parameters: []PresetParameter{
+			{Name: "audience", Type: PresetParameterChoice, Choices: []string{"dev", "ops"}, Default: "dev"},
+			{Name: "depth", Type: PresetParameterInt},
+			{Name: "ticket", Required: true},
+			{Name: "tags", Type: PresetParameterStringList},
+		},
+	}
+}
+
+func TestResolveParameters_coercesAndFillsDefaults(t *testing.T) {
+	got, err := paramPreset().ResolveParameters(map[string]any{"ticket": "OPS-1", "depth": "3", "tags": "a, b"})
+	if err != nil {
+		t.Fatalf("ResolveParameters: %v", err)
+	}
+	want := map[string]any{"audience": "dev", "depth": 3, "ticket": "OPS-1", "tags": []string{"a", "b"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestResolveParameters_errors(t *testing.T) {
+	cases := []struct {
+		name   string
+		values map[string]any
+		want   string
+	}{
+		{"unknown", map[string]any{"ticket": "x", "team": "core"}, "has no parameter team"},
+		{"required", map[string]any{}, "requires --preset-var for ticket"},
+		{"choice", map[string]any{"ticket": "x", "audience": "pm"}, `"pm" is not one of dev, ops`},
+		{"int", map[string]any{"ticket": "x", "depth": "deep"}, "depth: deep is not an integer"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := paramPreset().ResolveParameters(tc.values)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected an error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestResolveParameters_withoutPreset(t *testing.T) {
+	var p *PromptPreset
+	if got, err := p.ResolveParameters(nil); err != nil || len(got) != 0 {
+		t.Fatalf("expected no parameters, got %v, %v", got, err)
+	}
+	if _, err := p.ResolveParameters(map[string]any{"audience": "ops"}); err == nil || !strings.Contains(err.Error(), "declared: none") {
+		t.Fatalf("expected an unknown-parameter error, got %v", err)
+	}
+}
+
+func TestPresetParameterValidate(t *testing.T) {
+	for _, p := range []PresetParameter{
+		{Name: "diff"},
+		{Name: "x", Type: "date"},
+		{Name: "x", Type: PresetParameterChoice},
+		{Name: "x", Type: PresetParameterBool, Default: "maybe"},
+	} {
+		if err := p.Validate(); err == nil {
+			t.Fatalf("expected %+v to be invalid", p)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PromptFormat is the way a prompt is written.
+type PromptFormat string
+
+const (
+	// PromptFormatPlain is a system prompt sent as is; the change goes into the user message.
+	PromptFormatPlain PromptFormat = "plain"
+	// PromptFormatCombined is a single template whose `{{ define "context" }}` block starts the
+	// user prompt (the pinocchio layout of the default prompt).
+	PromptFormatCombined PromptFormat = "combined"
+	// PromptFormatMultipart is a preset with explicit system, user, examples and partials sections.
+	PromptFormatMultipart PromptFormat = "multipart"
+)
+
+// PromptExample is a sample input and the answer expected for it (few-shot).
+type PromptExample struct {
+	Input  string `yaml:"input" json:"input"`
+	Output string `yaml:"output" json:"output"`
+}
+
+// PromptParts are the sections of a multi-part prompt preset. System and User are templates; the
+// Partials are `define` blocks available to both of them.
+type PromptParts struct {
+	System string `yaml:"system,omitempty" json:"system,omitempty"`
+	// User renders the user message ("" => the standard context: diff, files, notes).
+	User     string            `yaml:"user,omitempty" json:"user,omitempty"`
+	Examples []PromptExample   `yaml:"examples,omitempty" json:"examples,omitempty"`
+	Partials map[string]string `yaml:"partials,omitempty" json:"partials,omitempty"`
+}
+
+// PartialDefines returns the partials as `define` blocks (sorted by name) to prepend to a template.
+func (p *PromptParts) PartialDefines() string {
+	if p == nil || len(p.Partials) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(p.Partials))
+	for name := range p.Partials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "{{ define %q }}%s{{ end }}", name, p.Partials[name])
+	}
+	return b.String()
+}
+
+// FormatExamples renders the examples as <example> blocks for the system prompt ("" if none).
+func (p *PromptParts) FormatExamples() string {
+	if p == nil || len(p.Examples) == 0 {
+		return ""
+	}
+	blocks := make([]string, 0, len(p.Examples))
+	for _, e := range p.Examples {
+		blocks = append(blocks, fmt.Sprintf("<example>\n<input>\n%s\n</input>\n<output>\n%s\n</output>\n</example>",
+			strings.TrimSpace(e.Input), strings.TrimSpace(e.Output)))
+	}
+	return "Examples of inputs and the expected answers:\n\n" + strings.Join(blocks, "\n\n")
+}
+
+// IsCombinedPrompt reports whether prompt uses the combined format.
+func IsCombinedPrompt(prompt string) bool {
+	return strings.Contains(prompt, "{{ define \"context\"") || strings.Contains(prompt, "{{define \"context\"")
+}
+
+// Format returns the format of the preset.
+func (p *PromptPreset) Format() PromptFormat {
+	if p.Parts != nil {
+		return PromptFormatMultipart
+	}
+	if IsCombinedPrompt(p.Template) {
+		return PromptFormatCombined
+	}
+	return PromptFormatPlain
+}
+
+// PromptFormat returns the format of the current prompt (preset or custom).
+func (d *PRData) PromptFormat() PromptFormat {
+	if d.CurrentPreset != nil {
+		return d.CurrentPreset.Format()
+	}
+	if IsCombinedPrompt(d.CurrentPrompt) {
+		return PromptFormatCombined
+	}
+	return PromptFormatPlain
+}
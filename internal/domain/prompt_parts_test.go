@@ -0,0 +1,28 @@
+package domain
+
+import "testing"
+
+func TestPromptFormat(t *testing.T) {
+	cases := []struct {
+		preset *PromptPreset
+		want   PromptFormat
+	}{
+		{&PromptPreset{Template: "Write a PR description."}, PromptFormatPlain},
+		{&PromptPreset{Template: "System.\n{{define \"context\"}}{{ .diff }}{{end}}"}, PromptFormatCombined},
+		{&PromptPreset{Parts: &PromptParts{System: "System."}}, PromptFormatMultipart},
+	}
+	for _, tc := range cases {
+		if got := tc.preset.Format(); got != tc.want {
+			t.Fatalf("Format() = %s, want %s (%+v)", got, tc.want, tc.preset)
+		}
+	}
+
+	d := NewPRData()
+	if got := d.PromptFormat(); got != PromptFormatCombined {
+		t.Fatalf("expected the default prompt to be combined, got %s", got)
+	}
+	d.SetPrompt("", cases[2].preset)
+	if got := d.PromptFormat(); got != PromptFormatMultipart {
+		t.Fatalf("expected the preset's format, got %s", got)
+	}
+}
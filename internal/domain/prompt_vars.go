@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PromptVars are the prompt template variables set by the user rather than derived from the change
+// (issue, extra instructions, style switches), plus arbitrary variables for custom templates.
+type PromptVars struct {
+	// Issue is the issue this PR addresses (template variable `issue`).
+	Issue string
+	// AdditionalSystem is appended to the system prompt (`additional_system`).
+	AdditionalSystem string
+	// Additional are extra instructions, one per entry (`additional`).
+	Additional []string
+	// WithoutFiles asks the model not to mention file names (`without_files`; nil => true).
+	WithoutFiles *bool
+	// Concise, UseBullets and UseKeywords select the answer style (`concise`, `use_bullets`,
+	// `use_keywords`).
+	Concise     bool
+	UseBullets  bool
+	UseKeywords bool
+	// Language is the output language, a name or an ISO code like "de" (`language`; "" => the
+	// prompt's default, English).
+	Language string
+	// Tone is the ID of a builtin tone preset (`tone`, with its guidance as `tone_guidance`).
+	Tone string
+	// Vars are user-defined variables (`vars:` in session.yaml). They cannot shadow built-in names.
+	Vars map[string]any
+}
+
+// BuiltinPromptVarNames are the variables the prescribe prompt templates receive. Custom Vars
+// with these names are ignored.
+var BuiltinPromptVarNames = []string{
+	"diff", "code", "context", "description", "title", "commits",
+	"issue", "additional_system", "additional",
+	"without_files", "concise", "use_bullets", "use_keywords",
+	"language", "tone", "tone_guidance",
+}
+
+// IsBuiltinPromptVar reports whether name is one of BuiltinPromptVarNames.
+func IsBuiltinPromptVar(name string) bool {
+	for _, n := range BuiltinPromptVarNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// WithoutFilesOrDefault returns WithoutFiles, or true if unset (the embedded prompt's default).
+func (v PromptVars) WithoutFilesOrDefault() bool {
+	return v.WithoutFiles == nil || *v.WithoutFiles
+}
+
+// TemplateVars returns the user-settable template variables, custom ones included.
+func (v PromptVars) TemplateVars() map[string]any {
+	vars := map[string]any{}
+	for k, val := range v.Vars {
+		if !IsBuiltinPromptVar(k) {
+			vars[k] = val
+		}
+	}
+	additional := []string{}
+	for _, a := range v.Additional {
+		if strings.TrimSpace(a) != "" {
+			additional = append(additional, strings.TrimSpace(a))
+		}
+	}
+	vars["issue"] = strings.TrimSpace(v.Issue)
+	vars["additional_system"] = strings.TrimSpace(v.AdditionalSystem)
+	vars["additional"] = additional
+	vars["without_files"] = v.WithoutFilesOrDefault()
+	vars["concise"] = v.Concise
+	vars["use_bullets"] = v.UseBullets
+	vars["use_keywords"] = v.UseKeywords
+	vars["language"] = LanguageName(v.Language)
+	vars["tone"] = ""
+	vars["tone_guidance"] = ""
+	if tone, ok := LookupTone(v.Tone); ok {
+		vars["tone"] = tone.ID
+		vars["tone_guidance"] = tone.Guidance
+	}
+	return vars
+}
+
+// Summary lists the variables that differ from the defaults, e.g.
+// "issue, concise, bullets, 2 instruction(s), language: German, tone: end-users, vars: team"
+// ("" if none).
+func (v PromptVars) Summary() string {
+	parts := []string{}
+	if strings.TrimSpace(v.Issue) != "" {
+		parts = append(parts, "issue")
+	}
+	if strings.TrimSpace(v.AdditionalSystem) != "" {
+		parts = append(parts, "additional system prompt")
+	}
+	if n := len(v.TemplateVars()["additional"].([]string)); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d instruction(s)", n))
+	}
+	if !v.WithoutFilesOrDefault() {
+		parts = append(parts, "mention files")
+	}
+	if v.Concise {
+		parts = append(parts, "concise")
+	}
+	if v.UseBullets {
+		parts = append(parts, "bullets")
+	}
+	if v.UseKeywords {
+		parts = append(parts, "keywords")
+	}
+	if strings.TrimSpace(v.Language) != "" {
+		parts = append(parts, "language: "+LanguageName(v.Language))
+	}
+	if strings.TrimSpace(v.Tone) != "" {
+		parts = append(parts, "tone: "+strings.TrimSpace(v.Tone))
+	}
+	names := []string{}
+	for k := range v.Vars {
+		names = append(names, k)
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		parts = append(parts, "vars: "+strings.Join(names, ", "))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ShadowedVars returns the names of custom Vars that collide with built-in variables, sorted.
+func (v PromptVars) ShadowedVars() []string {
+	out := []string{}
+	for k := range v.Vars {
+		if IsBuiltinPromptVar(k) {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
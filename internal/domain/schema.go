@@ -0,0 +1,268 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OutputFieldType is the type of a custom output field.
+type OutputFieldType string
+
+const (
+	OutputFieldString  OutputFieldType = "string"
+	OutputFieldNumber  OutputFieldType = "number"
+	OutputFieldBoolean OutputFieldType = "boolean"
+	OutputFieldList    OutputFieldType = "list"
+)
+
+// OutputField declares one field of the structured output.
+//
+// Fields named like a built-in section (title, body, changelog, release_notes) only adjust that
+// section (e.g. make changelog required); their type is fixed. Any other name is a custom field
+// that is stored in GeneratedPRData.Extra.
+type OutputField struct {
+	Name        string          `yaml:"name" json:"name"`
+	Type        OutputFieldType `yaml:"type,omitempty" json:"type,omitempty"`
+	Required    bool            `yaml:"required,omitempty" json:"required,omitempty"`
+	Description string          `yaml:"description,omitempty" json:"description,omitempty"`
+	// Enum restricts a string field to these values.
+	Enum []string `yaml:"enum,omitempty" json:"enum,omitempty"`
+}
+
+// OutputSchema is the structured output a prompt preset asks for (`output_schema:` in the preset).
+// A nil schema means the default fields: title and body (required), changelog and release_notes.
+type OutputSchema struct {
+	Fields []OutputField `yaml:"fields" json:"fields"`
+}
+
+// IsBuiltinOutputField reports whether name is one of the GeneratedPRData sections.
+func IsBuiltinOutputField(name string) bool {
+	for _, s := range PRDataSections {
+		if string(s) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldType returns the declared type, defaulting to string.
+func (f OutputField) FieldType() OutputFieldType {
+	if f.Type == "" {
+		return OutputFieldString
+	}
+	return f.Type
+}
+
+// Label is the human-readable field name used when rendering, e.g. "Risk level" for risk_level.
+func (f OutputField) Label() string {
+	label := strings.ReplaceAll(f.Name, "_", " ")
+	if label == "" {
+		return label
+	}
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+// Validate checks the schema declaration itself (names, types, enums).
+func (s *OutputSchema) Validate() error {
+	if s == nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	for i, f := range s.Fields {
+		if strings.TrimSpace(f.Name) == "" {
+			return fmt.Errorf("output schema field %d has no name", i+1)
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("output schema field %q is declared twice", f.Name)
+		}
+		seen[f.Name] = true
+		if f.Name == "generation" {
+			return fmt.Errorf("output schema field %q is reserved for generation metadata", f.Name)
+		}
+
+		if IsBuiltinOutputField(f.Name) {
+			if f.Type != "" || len(f.Enum) > 0 {
+				return fmt.Errorf("output schema field %q is built in; only required/description can be set", f.Name)
+			}
+			continue
+		}
+		switch f.FieldType() {
+		case OutputFieldString, OutputFieldNumber, OutputFieldBoolean, OutputFieldList:
+		default:
+			return fmt.Errorf("output schema field %q has unknown type %q (expected string, number, boolean or list)", f.Name, f.Type)
+		}
+		if len(f.Enum) > 0 && f.FieldType() != OutputFieldString {
+			return fmt.Errorf("output schema field %q: enum is only supported for string fields", f.Name)
+		}
+	}
+	return nil
+}
+
+// CustomFields returns the declared non-built-in fields, in declaration order.
+func (s *OutputSchema) CustomFields() []OutputField {
+	if s == nil {
+		return nil
+	}
+	out := []OutputField{}
+	for _, f := range s.Fields {
+		if !IsBuiltinOutputField(f.Name) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// DropUndeclared removes the values of d.Extra that s does not declare as custom fields (all of
+// them for a nil schema), so stray keys in the model's answer never reach the PR.
+func (s *OutputSchema) DropUndeclared(d *GeneratedPRData) {
+	if d == nil || len(d.Extra) == 0 {
+		return
+	}
+	declared := map[string]bool{}
+	for _, f := range s.CustomFields() {
+		declared[f.Name] = true
+	}
+	for k := range d.Extra {
+		if !declared[k] {
+			delete(d.Extra, k)
+		}
+	}
+	if len(d.Extra) == 0 {
+		d.Extra = nil
+	}
+}
+
+// Check validates PR data against the schema. Title and body are always required.
+func (s *OutputSchema) Check(d *GeneratedPRData) error {
+	if d == nil {
+		return fmt.Errorf("no PR data")
+	}
+	missing := []string{}
+	if strings.TrimSpace(d.Title) == "" {
+		missing = append(missing, "title")
+	}
+	if strings.TrimSpace(d.Body) == "" {
+		missing = append(missing, "body")
+	}
+	if s != nil {
+		for _, f := range s.Fields {
+			if f.Name == "title" || f.Name == "body" || !f.Required {
+				continue
+			}
+			if IsBuiltinOutputField(f.Name) {
+				if strings.TrimSpace(d.SectionText(PRDataSection(f.Name))) == "" {
+					missing = append(missing, f.Name)
+				}
+				continue
+			}
+			if isEmptyOutputValue(d.Extra[f.Name]) {
+				missing = append(missing, f.Name)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("parsed PR YAML is missing required fields (%s)", strings.Join(missing, "/"))
+	}
+
+	for _, f := range s.CustomFields() {
+		v, ok := d.Extra[f.Name]
+		if !ok || v == nil {
+			continue
+		}
+		if err := checkOutputValue(f, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isEmptyOutputValue(v any) bool {
+	switch x := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(x) == ""
+	case []any:
+		return len(x) == 0
+	}
+	return false
+}
+
+func checkOutputValue(f OutputField, v any) error {
+	switch f.FieldType() {
+	case OutputFieldString:
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("field %q must be a string, got %T", f.Name, v)
+		}
+		if len(f.Enum) > 0 {
+			for _, e := range f.Enum {
+				if strings.EqualFold(strings.TrimSpace(s), e) {
+					return nil
+				}
+			}
+			return fmt.Errorf("field %q must be one of %s, got %q", f.Name, strings.Join(f.Enum, ", "), s)
+		}
+	case OutputFieldNumber:
+		switch v.(type) {
+		case int, int64, uint64, float64:
+		default:
+			return fmt.Errorf("field %q must be a number, got %T", f.Name, v)
+		}
+	case OutputFieldBoolean:
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("field %q must be a boolean, got %T", f.Name, v)
+		}
+	case OutputFieldList:
+		items, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("field %q must be a list, got %T", f.Name, v)
+		}
+		for _, item := range items {
+			switch item.(type) {
+			case string, int, int64, uint64, float64, bool:
+			default:
+				return fmt.Errorf("field %q must be a list of scalars, got an item of type %T", f.Name, item)
+			}
+		}
+	}
+	return nil
+}
+
+// RenderBody returns the PR body with the custom fields of s appended (in schema order), e.g.
+//
+//	**Risk level:** high
+//
+// Lists become bullet lists and multi-line strings get their own paragraph. Values not declared
+// in s are not rendered; with a nil schema the body is returned as is.
+func (d *GeneratedPRData) RenderBody(s *OutputSchema) string {
+	if d == nil {
+		return ""
+	}
+	fields := s.CustomFields()
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(d.Body, "\n"))
+	for _, f := range fields {
+		v, ok := d.Extra[f.Name]
+		if !ok || isEmptyOutputValue(v) {
+			continue
+		}
+		b.WriteString("\n\n**" + f.Label() + ":**")
+		switch x := v.(type) {
+		case []any:
+			for _, item := range x {
+				b.WriteString(fmt.Sprintf("\n- %v", item))
+			}
+		case string:
+			if strings.Contains(strings.TrimSpace(x), "\n") {
+				b.WriteString("\n\n" + strings.TrimSpace(x))
+			} else {
+				b.WriteString(" " + strings.TrimSpace(x))
+			}
+		default:
+			b.WriteString(fmt.Sprintf(" %v", x))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func testOutputSchema() *OutputSchema {
+	return &OutputSchema{Fields: []OutputField{
+		{Name: "changelog", Required: true},
+		{Name: "risk_level", Required: true, Enum: []string{"low", "medium", "high"}},
+		{Name: "testing_notes"},
+		{Name: "affected_services", Type: OutputFieldList},
+		{Name: "needs_migration", Type: OutputFieldBoolean},
+	}}
+}
+
+func TestOutputSchemaValidate(t *testing.T) {
+	if err := testOutputSchema().Validate(); err != nil {
+		t.Fatalf("expected valid schema, got %v", err)
+	}
+
+	bad := []*OutputSchema{
+		{Fields: []OutputField{{Name: ""}}},
+		{Fields: []OutputField{{Name: "rollout"}, {Name: "rollout"}}},
+		{Fields: []OutputField{{Name: "rollout", Type: "date"}}},
+		{Fields: []OutputField{{Name: "score", Type: OutputFieldNumber, Enum: []string{"1"}}}},
+		{Fields: []OutputField{{Name: "body", Type: OutputFieldList}}},
+		{Fields: []OutputField{{Name: "generation"}}},
+	}
+	for _, s := range bad {
+		if err := s.Validate(); err == nil {
+			t.Fatalf("expected error for %+v", s.Fields)
+		}
+	}
+}
+
+func TestOutputSchemaCheck(t *testing.T) {
+	s := testOutputSchema()
+	ok := &GeneratedPRData{
+		Title:     "T",
+		Body:      "B",
+		Changelog: "- c",
+		Extra: map[string]any{
+			"risk_level":        "High",
+			"affected_services": []any{"api"},
+			"needs_migration":   false,
+		},
+	}
+	if err := s.Check(ok); err != nil {
+		t.Fatalf("expected valid data, got %v", err)
+	}
+
+	missing := &GeneratedPRData{Title: "T", Body: "B"}
+	err := s.Check(missing)
+	if err == nil || !strings.Contains(err.Error(), "changelog/risk_level") {
+		t.Fatalf("expected missing changelog/risk_level, got %v", err)
+	}
+
+	wrongEnum := &GeneratedPRData{Title: "T", Body: "B", Changelog: "c", Extra: map[string]any{"risk_level": "extreme"}}
+	if err := s.Check(wrongEnum); err == nil {
+		t.Fatalf("expected enum error")
+	}
+	wrongType := &GeneratedPRData{Title: "T", Body: "B", Changelog: "c", Extra: map[string]any{"risk_level": "low", "affected_services": "api"}}
+	if err := s.Check(wrongType); err == nil {
+		t.Fatalf("expected list type error")
+	}
+
+	// The nil schema is the default contract: title and body only.
+	if err := (*OutputSchema)(nil).Check(&GeneratedPRData{Title: "T", Body: "B"}); err != nil {
+		t.Fatalf("expected default schema to accept title+body, got %v", err)
+	}
+	if err := (*OutputSchema)(nil).Check(&GeneratedPRData{Title: "T"}); err == nil {
+		t.Fatalf("expected default schema to require body")
+	}
+}
+
+func TestGeneratedPRDataRenderBody(t *testing.T) {
+	d := &GeneratedPRData{
+		Body: "Does things.\n",
+		Extra: map[string]any{
+			"risk_level":        "low",
+			"affected_services": []any{"api", "worker"},
+			"testing_notes":     "Ran the suite.\nClicked around.",
+			"zzz_unknown":       "stray",
+		},
+	}
+	got := d.RenderBody(testOutputSchema())
+	want := "Does things.\n\n" +
+		"**Risk level:** low\n\n" +
+		"**Testing notes:**\n\nRan the suite.\nClicked around.\n\n" +
+		"**Affected services:**\n- api\n- worker"
+	if got != want {
+		t.Fatalf("unexpected body:\n%s\n--- want ---\n%s", got, want)
+	}
+
+	plain := &GeneratedPRData{Body: "Just the body", Extra: map[string]any{"notes": "stray"}}
+	if plain.RenderBody(nil) != "Just the body" {
+		t.Fatalf("expected the body unchanged without a schema")
+	}
+}
+
+func TestOutputSchemaDropUndeclared(t *testing.T) {
+	d := &GeneratedPRData{Title: "T", Body: "B", Extra: map[string]any{"risk_level": "low", "notes": "stray"}}
+	testOutputSchema().DropUndeclared(d)
+	if len(d.Extra) != 1 || d.Extra["risk_level"] != "low" {
+		t.Fatalf("expected only the declared field to be kept, got %v", d.Extra)
+	}
+
+	(*OutputSchema)(nil).DropUndeclared(d)
+	if d.Extra != nil {
+		t.Fatalf("expected the default schema to keep no custom values, got %v", d.Extra)
+	}
+}
@@ -266,9 +266,9 @@ func buildXML(req api.GenerateDescriptionRequest) string {
 			b.WriteString("<content>\n")
 			b.WriteString(xmlEscape(strings.TrimRight(content, "\n")))
 			b.WriteString("\n</content>\n")
-		case domain.FileTypeDiff:
+		case domain.FileTypeDiff, domain.FileTypeDiffReduced, domain.FileTypeNumstat:
 			b.WriteString("<diff>\n")
-			b.WriteString(xmlEscape(strings.TrimRight(f.Diff, "\n")))
+			b.WriteString(xmlEscape(strings.TrimRight(f.EffectiveDiff(), "\n")))
 			b.WriteString("\n</diff>\n")
 		}
 		b.WriteString("</file>\n")
@@ -384,9 +384,9 @@ func buildMarkdown(req api.GenerateDescriptionRequest) string {
 			b.WriteString("```text\n")
 			b.WriteString(strings.TrimRight(content, "\n"))
 			b.WriteString("\n```\n\n")
-		case domain.FileTypeDiff:
+		case domain.FileTypeDiff, domain.FileTypeDiffReduced, domain.FileTypeNumstat:
 			b.WriteString("```diff\n")
-			b.WriteString(strings.TrimRight(f.Diff, "\n"))
+			b.WriteString(strings.TrimRight(f.EffectiveDiff(), "\n"))
 			b.WriteString("\n```\n\n")
 		}
 	}
@@ -458,7 +458,7 @@ func buildSimple(req api.GenerateDescriptionRequest) string {
 			}
 			b.WriteString(strings.TrimRight(content, "\n"))
 		} else {
-			b.WriteString(strings.TrimRight(f.Diff, "\n"))
+			b.WriteString(strings.TrimRight(f.EffectiveDiff(), "\n"))
 		}
 		b.WriteString(fmt.Sprintf("\n--- END FILE: %s ---\n\n", f.Path))
 	}
@@ -492,7 +492,7 @@ func buildBeginEnd(req api.GenerateDescriptionRequest) string {
 			}
 			b.WriteString(strings.TrimRight(content, "\n"))
 		} else {
-			b.WriteString(strings.TrimRight(f.Diff, "\n"))
+			b.WriteString(strings.TrimRight(f.EffectiveDiff(), "\n"))
 		}
 		b.WriteString(fmt.Sprintf("\n--- END FILE: %s ---\n\n", f.Path))
 	}
@@ -526,7 +526,7 @@ func buildDefault(req api.GenerateDescriptionRequest) string {
 			}
 			b.WriteString(strings.TrimRight(content, "\n"))
 		} else {
-			b.WriteString(strings.TrimRight(f.Diff, "\n"))
+			b.WriteString(strings.TrimRight(f.EffectiveDiff(), "\n"))
 		}
 		b.WriteString("\n\n")
 	}
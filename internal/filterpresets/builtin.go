@@ -0,0 +1,98 @@
+package filterpresets
+
+import (
+	"embed"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+//go:embed assets/*.yaml
+var assets embed.FS
+
+// Files returns the raw builtin filter preset files, keyed by preset ID (filename).
+func Files() (map[string][]byte, error) {
+	entries, err := assets.ReadDir("assets")
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte, len(entries))
+	for _, entry := range entries {
+		data, err := assets.ReadFile(path.Join("assets", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		files[entry.Name()] = data
+	}
+	return files, nil
+}
+
+// Ecosystem ties a set of marker files at the repository root to the builtin presets
+// that are relevant for repos of that kind.
+type Ecosystem struct {
+	Name    string
+	Markers []string
+	Presets []string
+}
+
+// Ecosystems is the detection table used by DetectEcosystems.
+var Ecosystems = []Ecosystem{
+	{Name: "go", Markers: []string{"go.mod", "go.work"}, Presets: []string{"go_generated.yaml"}},
+	{Name: "javascript", Markers: []string{"package.json"}, Presets: []string{"js_build.yaml"}},
+	{Name: "protobuf", Markers: []string{"buf.yaml", "buf.gen.yaml"}, Presets: []string{"protobuf_generated.yaml"}},
+}
+
+// GenericPresets apply to any repo; they are suggested whenever they match changed files.
+var GenericPresets = []string{
+	"lockfiles.yaml",
+	"protobuf_generated.yaml",
+	"snapshots.yaml",
+	"tests.yaml",
+}
+
+// DetectEcosystems returns the names of the ecosystems whose marker files exist at the repo root.
+func DetectEcosystems(repoPath string) []string {
+	ret := make([]string, 0)
+	for _, eco := range Ecosystems {
+		for _, marker := range eco.Markers {
+			if _, err := os.Stat(filepath.Join(repoPath, marker)); err == nil {
+				ret = append(ret, eco.Name)
+				break
+			}
+		}
+	}
+	return ret
+}
+
+// CandidatePresets maps each relevant builtin preset ID to the reason it is relevant
+// ("generic" or the detected ecosystem name), given the detected ecosystems.
+func CandidatePresets(ecosystems []string) map[string]string {
+	ret := map[string]string{}
+	for _, id := range GenericPresets {
+		ret[id] = "generic"
+	}
+	detected := map[string]bool{}
+	for _, name := range ecosystems {
+		detected[name] = true
+	}
+	for _, eco := range Ecosystems {
+		if !detected[eco.Name] {
+			continue
+		}
+		for _, id := range eco.Presets {
+			ret[id] = eco.Name
+		}
+	}
+	return ret
+}
+
+// SortedIDs returns the keys of a candidate map in a stable order.
+func SortedIDs(candidates map[string]string) []string {
+	ids := make([]string, 0, len(candidates))
+	for id := range candidates {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -15,12 +15,12 @@ const (
 	gitContextDefaultMaxTokens = 2_000
 )
 
-func truncateWithCaps(s string, maxBytes, maxTokens int) (string, bool) {
+func truncateWithCaps(counter *tokens.Counter, s string, maxBytes, maxTokens int) (string, bool) {
 	original := s
 
 	if maxTokens > 0 {
 		// Best-effort: binary search the largest prefix under maxTokens.
-		if tokens.Count(s) > maxTokens {
+		if counter.Count(s) > maxTokens {
 			runes := []rune(s)
 			lo, hi := 0, len(runes)
 			best := 0
@@ -30,7 +30,7 @@ func truncateWithCaps(s string, maxBytes, maxTokens int) (string, bool) {
 					break
 				}
 				cand := string(runes[:mid])
-				if tokens.Count(cand) <= maxTokens {
+				if counter.Count(cand) <= maxTokens {
 					best = mid
 					lo = mid + 1
 				} else {
@@ -175,7 +175,7 @@ func (s *Service) BuildCommitMetadataContext(ref string, includeNumstat bool) (s
 				ns.Deletions,
 			))
 		}
-		numstatBody, _ := truncateWithCaps(nsb.String(), gitContextDefaultMaxBytes, gitContextDefaultMaxTokens)
+		numstatBody, _ := truncateWithCaps(s.counter, nsb.String(), gitContextDefaultMaxBytes, gitContextDefaultMaxTokens)
 		b.WriteString("<numstat>\n")
 		b.WriteString(strings.TrimRight(numstatBody, "\n"))
 		b.WriteString("\n")
@@ -196,10 +196,26 @@ func (s *Service) BuildCommitPatchContext(ref string, paths []string) (string, e
 		return "", err
 	}
 
+	for _, p := range paths {
+		if err := s.checkAllowed(p); err != nil {
+			return "", err
+		}
+	}
+	excludes, err := s.deniedInCommit(hdr.SHA)
+	if err != nil {
+		return "", err
+	}
+
 	args := []string{"show", "--format=", "--patch", hdr.SHA}
-	if len(paths) > 0 {
+	if len(paths) > 0 || len(excludes) > 0 {
 		args = append(args, "--")
 		args = append(args, paths...)
+		if len(paths) == 0 {
+			args = append(args, ".")
+		}
+		for _, p := range excludes {
+			args = append(args, ":(exclude,literal)"+p)
+		}
 	}
 	cmd := exec.Command("git", args...)
 	cmd.Dir = s.repoPath
@@ -207,7 +223,7 @@ func (s *Service) BuildCommitPatchContext(ref string, paths []string) (string, e
 	if err != nil {
 		return "", errors.Wrap(err, "failed to read commit patch")
 	}
-	patch, _ := truncateWithCaps(string(out), gitContextDefaultMaxBytes, gitContextDefaultMaxTokens)
+	patch, _ := truncateWithCaps(s.counter, string(out), gitContextDefaultMaxBytes, gitContextDefaultMaxTokens)
 
 	shortSHA := hdr.SHA
 	if len(shortSHA) > 7 {
@@ -226,6 +242,24 @@ func (s *Service) BuildCommitPatchContext(ref string, paths []string) (string, e
 	return b.String(), nil
 }
 
+// deniedInCommit returns the files touched by a commit that .prescribeignore denies.
+func (s *Service) deniedInCommit(sha string) ([]string, error) {
+	if s.deny.Len() == 0 {
+		return nil, nil
+	}
+	stats, err := s.getCommitNumstat(sha)
+	if err != nil {
+		return nil, err
+	}
+	denied := []string{}
+	for _, st := range stats {
+		if _, ok := s.Denied(st.Path); ok {
+			denied = append(denied, st.Path)
+		}
+	}
+	return denied, nil
+}
+
 func (s *Service) BuildFileAtRefContext(ref, filePath string) (string, error) {
 	if strings.TrimSpace(ref) == "" || strings.TrimSpace(filePath) == "" {
 		return "", nil
@@ -235,7 +269,7 @@ func (s *Service) BuildFileAtRefContext(ref, filePath string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	content, _ = truncateWithCaps(content, gitContextDefaultMaxBytes, gitContextDefaultMaxTokens)
+	content, _ = truncateWithCaps(s.counter, content, gitContextDefaultMaxBytes, gitContextDefaultMaxTokens)
 
 	var b strings.Builder
 	b.WriteString(fmt.Sprintf("<git_file_at_ref ref=\"%s\" path=\"%s\">\n", xmlEscapeAttr(ref), xmlEscapeAttr(filePath)))
@@ -249,6 +283,9 @@ func (s *Service) BuildFileDiffContext(fromRef, toRef, filePath string) (string,
 	if strings.TrimSpace(fromRef) == "" || strings.TrimSpace(toRef) == "" || strings.TrimSpace(filePath) == "" {
 		return "", nil
 	}
+	if err := s.checkAllowed(filePath); err != nil {
+		return "", err
+	}
 
 	cmd := exec.Command("git", "diff", fromRef, toRef, "--", filePath)
 	cmd.Dir = s.repoPath
@@ -256,7 +293,7 @@ func (s *Service) BuildFileDiffContext(fromRef, toRef, filePath string) (string,
 	if err != nil {
 		return "", errors.Wrap(err, "failed to read file diff")
 	}
-	diff, _ := truncateWithCaps(string(out), gitContextDefaultMaxBytes, gitContextDefaultMaxTokens)
+	diff, _ := truncateWithCaps(s.counter, string(out), gitContextDefaultMaxBytes, gitContextDefaultMaxTokens)
 
 	var b strings.Builder
 	b.WriteString(fmt.Sprintf(
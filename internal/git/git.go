@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/go-go-golems/prescribe/internal/ignore"
 	"github.com/go-go-golems/prescribe/internal/tokens"
 	"github.com/pkg/errors"
 )
@@ -17,6 +18,10 @@ import (
 // Service provides git operations
 type Service struct {
 	repoPath string
+	// deny is the .prescribeignore deny list; denied files are never read.
+	deny *ignore.Matcher
+	// counter counts the tokens of diffs and caps context items (nil => tokens.DefaultEncoding).
+	counter *tokens.Counter
 }
 
 // NewService creates a new git service
@@ -27,7 +32,30 @@ func NewService(repoPath string) (*Service, error) {
 		return nil, fmt.Errorf("not a git repository: %s", repoPath)
 	}
 
-	return &Service{repoPath: repoPath}, nil
+	deny, err := ignore.Load(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{repoPath: repoPath, deny: deny}, nil
+}
+
+// SetTokenCounter sets the tokenizer used for the token counts and caps of later reads.
+func (s *Service) SetTokenCounter(counter *tokens.Counter) {
+	s.counter = counter
+}
+
+// Denied reports whether path matches the .prescribeignore deny list, and the matching rule.
+func (s *Service) Denied(path string) (string, bool) {
+	return s.deny.Match(path)
+}
+
+// checkAllowed returns an error if path is denied by .prescribeignore.
+func (s *Service) checkAllowed(path string) error {
+	if rule, ok := s.Denied(path); ok {
+		return errors.Errorf("%s is denied by %s", path, rule)
+	}
+	return nil
 }
 
 // ResolveCommit resolves a git ref (branch name, tag, SHA, etc) to a full commit SHA.
@@ -85,18 +113,20 @@ func (s *Service) GetDiff(sourceBranch, targetBranch string) (string, error) {
 	return string(output), nil
 }
 
-// GetChangedFiles returns a list of changed files between two branches
-func (s *Service) GetChangedFiles(sourceBranch, targetBranch string) ([]domain.FileChange, error) {
+// GetChangedFiles returns a list of changed files between two branches. Files denied by
+// .prescribeignore are left out without being read; they are returned separately.
+func (s *Service) GetChangedFiles(sourceBranch, targetBranch string) ([]domain.FileChange, []domain.DeniedFile, error) {
 	// Get list of changed files with stats
 	cmd := exec.Command("git", "diff", "--numstat", fmt.Sprintf("%s...%s", targetBranch, sourceBranch))
 	cmd.Dir = s.repoPath
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get changed files: %w", err)
+		return nil, nil, fmt.Errorf("failed to get changed files: %w", err)
 	}
 
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
 	files := make([]domain.FileChange, 0, len(lines))
+	denied := []domain.DeniedFile{}
 
 	for _, line := range lines {
 		if line == "" {
@@ -111,19 +141,23 @@ func (s *Service) GetChangedFiles(sourceBranch, targetBranch string) ([]domain.F
 		additions := 0
 		deletions := 0
 		path := parts[2]
+		if rule, ok := s.Denied(path); ok {
+			denied = append(denied, domain.DeniedFile{Path: path, Rule: rule})
+			continue
+		}
 
 		// Parse additions and deletions
 		if parts[0] != "-" {
 			v, err := strconv.Atoi(parts[0])
 			if err != nil {
-				return nil, errors.Wrapf(err, "failed to parse additions for %s: %q", path, parts[0])
+				return nil, nil, errors.Wrapf(err, "failed to parse additions for %s: %q", path, parts[0])
 			}
 			additions = v
 		}
 		if parts[1] != "-" {
 			v, err := strconv.Atoi(parts[1])
 			if err != nil {
-				return nil, errors.Wrapf(err, "failed to parse deletions for %s: %q", path, parts[1])
+				return nil, nil, errors.Wrapf(err, "failed to parse deletions for %s: %q", path, parts[1])
 			}
 			deletions = v
 		}
@@ -139,7 +173,7 @@ func (s *Service) GetChangedFiles(sourceBranch, targetBranch string) ([]domain.F
 		fullAfter, _ := s.GetFileContent(sourceBranch, path)
 
 		// Count tokens using tokenizer (preflight estimate)
-		tokens_ := tokens.Count(diff)
+		tokens_ := s.counter.Count(diff)
 
 		files = append(files, domain.FileChange{
 			Path:       path,
@@ -154,11 +188,14 @@ func (s *Service) GetChangedFiles(sourceBranch, targetBranch string) ([]domain.F
 		})
 	}
 
-	return files, nil
+	return files, denied, nil
 }
 
-// GetFileDiff returns the diff for a specific file
+// GetFileDiff returns the diff for a specific file. Files denied by .prescribeignore are an error.
 func (s *Service) GetFileDiff(sourceBranch, targetBranch, filePath string) (string, error) {
+	if err := s.checkAllowed(filePath); err != nil {
+		return "", err
+	}
 	cmd := exec.Command("git", "diff", fmt.Sprintf("%s...%s", targetBranch, sourceBranch), "--", filePath)
 	cmd.Dir = s.repoPath
 	output, err := cmd.Output()
@@ -168,8 +205,12 @@ func (s *Service) GetFileDiff(sourceBranch, targetBranch, filePath string) (stri
 	return string(output), nil
 }
 
-// GetFileContent returns the content of a file at a specific branch/commit
+// GetFileContent returns the content of a file at a specific branch/commit.
+// Files denied by .prescribeignore are an error.
 func (s *Service) GetFileContent(ref, filePath string) (string, error) {
+	if err := s.checkAllowed(filePath); err != nil {
+		return "", err
+	}
 	cmd := exec.Command("git", "show", fmt.Sprintf("%s:%s", ref, filePath))
 	cmd.Dir = s.repoPath
 	output, err := cmd.Output()
@@ -0,0 +1,40 @@
+package history
+
+import (
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+)
+
+// DiffText is what diffs compare: the parsed PR data as YAML, or the raw text if it did not parse.
+func (r *Record) DiffText() (string, error) {
+	return PRDataText(r.Parsed, r.Raw)
+}
+
+// PRDataText renders data as YAML, or returns fallback when data is nil.
+func PRDataText(data *domain.GeneratedPRData, fallback string) (string, error) {
+	if data == nil {
+		return fallback, nil
+	}
+	b, err := yaml.Marshal(data)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal PR data")
+	}
+	return string(b), nil
+}
+
+// Diff returns a unified diff from a to b ("" if they are equal).
+func Diff(aName, a, bName, b string) (string, error) {
+	out, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(a),
+		B:        difflib.SplitLines(b),
+		FromFile: aName,
+		ToFile:   bName,
+		Context:  3,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to diff")
+	}
+	return out, nil
+}
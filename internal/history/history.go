@@ -0,0 +1,289 @@
+package history
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-go-golems/geppetto/pkg/turns"
+	"github.com/go-go-golems/geppetto/pkg/turns/serde"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	recordSuffix = ".yaml"
+	turnSuffix   = ".turn.yaml"
+)
+
+// Dir is where the generation history of a repository is kept.
+func Dir(repoPath string) string {
+	return filepath.Join(repoPath, ".pr-builder", "history")
+}
+
+// Kind is what produced a record.
+type Kind string
+
+const (
+	KindGenerate  Kind = "generate"
+	KindRefine    Kind = "refine"
+	KindSection   Kind = "section"
+	KindCandidate Kind = "candidate"
+	KindTranslate Kind = "translate"
+	// KindSelection is the merge of picked candidate sections (no inference of its own).
+	KindSelection Kind = "selection"
+)
+
+// Record is one generation, stored as <id>.yaml. The conversation is stored next to it as
+// <id>.turn.yaml so a restored draft can be refined like a fresh one.
+type Record struct {
+	ID        string    `yaml:"id"`
+	CreatedAt time.Time `yaml:"created_at"`
+	Kind      Kind      `yaml:"kind"`
+
+	SourceBranch string `yaml:"source_branch,omitempty"`
+	TargetBranch string `yaml:"target_branch,omitempty"`
+	SourceCommit string `yaml:"source_commit,omitempty"`
+	TargetCommit string `yaml:"target_commit,omitempty"`
+
+	// Preset is the prompt preset ID (empty for a custom prompt).
+	Preset string `yaml:"preset,omitempty"`
+	// PromptHash is the sha256 of the compiled system and user prompts.
+	PromptHash string `yaml:"prompt_hash,omitempty"`
+
+	Model string                  `yaml:"model,omitempty"`
+	Usage *domain.GenerationUsage `yaml:"usage,omitempty"`
+
+	// Raw is the assistant text as returned by the model (before parsing).
+	Raw        string                  `yaml:"raw"`
+	Parsed     *domain.GeneratedPRData `yaml:"parsed,omitempty"`
+	ParseError string                  `yaml:"parse_error,omitempty"`
+
+	// Generation is the full metadata (attempts, output schema, refinements, ...), kept for restore.
+	Generation *domain.GenerationMetadata `yaml:"generation,omitempty"`
+}
+
+// Title returns the parsed title, or "" if the record did not parse.
+func (r *Record) Title() string {
+	if r == nil || r.Parsed == nil {
+		return ""
+	}
+	return r.Parsed.Title
+}
+
+// timeKeyLayout is the time part of a record ID: UTC, to the nanosecond, zero-padded.
+const timeKeyLayout = "20060102-150405-000000000"
+
+func timeKey(t time.Time) string {
+	return t.UTC().Format("20060102-150405") + fmt.Sprintf("-%09d", t.Nanosecond())
+}
+
+// idTime returns the time key of id (false for IDs without one, e.g. from older versions).
+func idTime(id string) (time.Time, bool) {
+	n := len(timeKeyLayout)
+	if len(id) <= n || id[15] != '-' || id[n] != '-' {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("20060102-150405.000000000", id[:15]+"."+id[16:n])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// Store is a history directory; records are named by their ID (time key and a hash of the raw
+// answer), which sorts by creation time.
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+func (s *Store) recordPath(id string) string {
+	return filepath.Join(s.dir, id+recordSuffix)
+}
+
+func (s *Store) turnPath(id string) string {
+	return filepath.Join(s.dir, id+turnSuffix)
+}
+
+// Add stores r (and t, if set) under a new ID derived from r.CreatedAt and r.Raw, and returns the ID.
+// IDs sort in the order records were added.
+func (s *Store) Add(r *Record, t *turns.Turn) (string, error) {
+	if r == nil {
+		return "", errors.New("history record is nil")
+	}
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now()
+	}
+	r.CreatedAt = r.CreatedAt.UTC()
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return "", errors.Wrap(err, "failed to create history directory")
+	}
+
+	// The time key must sort after every stored record, so records made within the same second (or
+	// with the same CreatedAt) stay in insertion order.
+	at := r.CreatedAt
+	ids, err := s.ids()
+	if err != nil {
+		return "", err
+	}
+	if len(ids) > 0 {
+		if newest, ok := idTime(ids[0]); ok && !at.After(newest) {
+			at = newest.Add(time.Nanosecond)
+		}
+	}
+	r.ID = fmt.Sprintf("%s-%x", timeKey(at), sha256.Sum256([]byte(r.Raw)))[:len(timeKeyLayout)+9]
+
+	b, err := yaml.Marshal(r)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal history record")
+	}
+	if err := os.WriteFile(s.recordPath(r.ID), b, 0o644); err != nil {
+		return "", errors.Wrap(err, "failed to write history record")
+	}
+	if t != nil {
+		if err := serde.SaveTurnYAML(s.turnPath(r.ID), t, serde.Options{OmitData: true}); err != nil {
+			return "", errors.Wrap(err, "failed to write history conversation")
+		}
+	}
+	return r.ID, nil
+}
+
+// Get returns the record with id and its conversation (nil if it was not stored).
+func (s *Store) Get(id string) (*Record, *turns.Turn, error) {
+	if !validID(id) {
+		return nil, nil, errors.Errorf("invalid history id %q", id)
+	}
+	b, err := os.ReadFile(s.recordPath(id))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, errors.Errorf("no history record %q", id)
+		}
+		return nil, nil, errors.Wrap(err, "failed to read history record")
+	}
+	var r Record
+	if err := yaml.Unmarshal(b, &r); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to unmarshal history record %s", id)
+	}
+	r.ID = id
+
+	var t *turns.Turn
+	if _, err := os.Stat(s.turnPath(id)); err == nil {
+		t, err = serde.LoadTurnYAML(s.turnPath(id))
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to load history conversation %s", id)
+		}
+	}
+	return &r, t, nil
+}
+
+// List returns all records, newest first. Unreadable records are skipped.
+func (s *Store) List() ([]*Record, error) {
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Record, 0, len(ids))
+	for _, id := range ids {
+		r, _, err := s.Get(id)
+		if err != nil {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// ids returns the IDs of all records, newest first.
+func (s *Store) ids() ([]string, error) {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to read history directory")
+	}
+	ids := []string{}
+	for _, de := range dirEntries {
+		name := de.Name()
+		if de.IsDir() || !strings.HasSuffix(name, recordSuffix) || strings.HasSuffix(name, turnSuffix) {
+			continue
+		}
+		if id := strings.TrimSuffix(name, recordSuffix); validID(id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+	return ids, nil
+}
+
+// Resolve turns a reference into a record ID. A reference is an ID, a unique ID prefix,
+// "latest" (the newest record) or "latest~N" (the N-th record before the newest).
+func (s *Store) Resolve(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	ids, err := s.ids()
+	if err != nil {
+		return "", err
+	}
+	if len(ids) == 0 {
+		return "", errors.Errorf("no generation history in %s", s.dir)
+	}
+
+	if ref == "latest" || strings.HasPrefix(ref, "latest~") {
+		n := 0
+		if rest := strings.TrimPrefix(ref, "latest"); rest != "" {
+			n, err = strconv.Atoi(strings.TrimPrefix(rest, "~"))
+			if err != nil || n < 0 {
+				return "", errors.Errorf("invalid history reference %q (expected latest~N)", ref)
+			}
+		}
+		if n >= len(ids) {
+			return "", errors.Errorf("history has only %d record(s)", len(ids))
+		}
+		return ids[n], nil
+	}
+
+	matches := []string{}
+	for _, id := range ids {
+		if id == ref {
+			return id, nil
+		}
+		if ref != "" && strings.HasPrefix(id, ref) {
+			matches = append(matches, id)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", errors.Errorf("no history record matches %q", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", errors.Errorf("history reference %q is ambiguous (%d records match)", ref, len(matches))
+	}
+}
+
+// validID accepts lowercase alphanumerics and dashes, so IDs can never escape the history directory.
+func validID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
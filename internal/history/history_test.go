@@ -0,0 +1,131 @@
+package history
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-go-golems/geppetto/pkg/turns"
+	"github.com/go-go-golems/prescribe/internal/domain"
+)
+
+func addRecord(t *testing.T, s *Store, at time.Time, title string) string {
+	t.Helper()
+	turn := &turns.Turn{}
+	turns.AppendBlock(turn, turns.NewUserTextBlock("describe the PR"))
+	id, err := s.Add(&Record{
+		CreatedAt: at,
+		Kind:      KindGenerate,
+		Model:     "gpt-4o",
+		Raw:       "title: " + title + "\nbody: B\n",
+		Parsed:    &domain.GeneratedPRData{Title: title, Body: "B"},
+	}, turn)
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	return id
+}
+
+func TestStore_AddListGet(t *testing.T) {
+	s := NewStore(t.TempDir())
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	first := addRecord(t, s, at, "First")
+	second := addRecord(t, s, at.Add(time.Minute), "Second")
+	// Same time and text: the record gets a later time key instead of overwriting.
+	dup := addRecord(t, s, at.Add(time.Minute), "Second")
+
+	if !strings.HasPrefix(first, "20260102-030405-000000000-") || dup == second || !strings.HasPrefix(dup, "20260102-030505-000000001-") {
+		t.Fatalf("unexpected ids %q %q %q", first, second, dup)
+	}
+
+	records, err := s.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(records) != 3 || records[0].ID != dup || records[2].ID != first {
+		t.Fatalf("expected newest first, got %d records", len(records))
+	}
+
+	r, turn, err := s.Get(first)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if r.Title() != "First" || r.Model != "gpt-4o" || turn == nil || len(turn.Blocks) != 1 {
+		t.Fatalf("unexpected record %+v (turn %v)", r, turn)
+	}
+}
+
+// Records added within the same second (e.g. candidates and their selection) are listed and
+// resolved in the order they were added, whatever the hash of their answer.
+func TestStore_sameSecondOrder(t *testing.T) {
+	s := NewStore(t.TempDir())
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	titles := []string{"zeta", "alpha", "mid", "beta", "omega", "one", "two", "three", "four", "five", "six"}
+	ids := []string{}
+	for i, title := range titles {
+		ids = append(ids, addRecord(t, s, at.Add(time.Duration(i%3)*time.Millisecond), title))
+	}
+
+	records, err := s.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(records) != len(titles) {
+		t.Fatalf("expected %d records, got %d", len(titles), len(records))
+	}
+	for i, r := range records {
+		if want := titles[len(titles)-1-i]; r.Title() != want {
+			t.Fatalf("record %d: got %q, want %q", i, r.Title(), want)
+		}
+	}
+	for n, want := range map[string]string{"latest": ids[len(ids)-1], "latest~1": ids[len(ids)-2], "latest~10": ids[0]} {
+		if got, err := s.Resolve(n); err != nil || got != want {
+			t.Fatalf("Resolve(%q) = %q, %v; want %q", n, got, err, want)
+		}
+	}
+}
+
+func TestStore_Resolve(t *testing.T) {
+	s := NewStore(t.TempDir())
+	if _, err := s.Resolve("latest"); err == nil {
+		t.Fatalf("expected an error for an empty history")
+	}
+
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	first := addRecord(t, s, at, "First")
+	second := addRecord(t, s, at.Add(time.Hour), "Second")
+
+	for ref, want := range map[string]string{
+		"latest":      second,
+		"latest~0":    second,
+		"latest~1":    first,
+		first:         first,
+		"20260102-03": first,
+	} {
+		got, err := s.Resolve(ref)
+		if err != nil || got != want {
+			t.Fatalf("Resolve(%q) = %q, %v; want %q", ref, got, err, want)
+		}
+	}
+	for _, ref := range []string{"latest~2", "latest~x", "2026", "nope", "../x"} {
+		if _, err := s.Resolve(ref); err == nil {
+			t.Fatalf("expected Resolve(%q) to fail", ref)
+		}
+	}
+	if _, _, err := s.Get("../x"); err == nil {
+		t.Fatalf("expected an invalid id to be rejected")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	out, err := Diff("a", "title: A\nbody: B\n", "b", "title: C\nbody: B\n")
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if !strings.Contains(out, "-title: A") || !strings.Contains(out, "+title: C") {
+		t.Fatalf("unexpected diff %q", out)
+	}
+	if out, _ := Diff("a", "same\n", "b", "same\n"); out != "" {
+		t.Fatalf("expected no diff for equal texts, got %q", out)
+	}
+}
@@ -0,0 +1,180 @@
+// Package ignore implements the .prescribeignore deny list: files matching it are never read into
+// a prompt, whatever the filters, sessions or context commands say.
+package ignore
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/pkg/errors"
+)
+
+// FileName is the name of the deny list, both at the repository root and in ~/.pr-builder/.
+const FileName = ".prescribeignore"
+
+// rule is one pattern line of a deny list (gitignore syntax).
+type rule struct {
+	pattern string
+	negate  bool
+	// dirOnly patterns ("build/") match directories only.
+	dirOnly bool
+	// anchored patterns contain a slash and match from the root; others match any path component.
+	anchored bool
+	source   string
+	line     int
+}
+
+func (r rule) String() string {
+	p := r.pattern
+	if r.negate {
+		p = "!" + p
+	}
+	return fmt.Sprintf("%s:%d: %s", r.source, r.line, p)
+}
+
+func (r rule) matches(p string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if !r.anchored {
+		p = path.Base(p)
+	}
+	ok, err := doublestar.Match(r.pattern, p)
+	return err == nil && ok
+}
+
+// Matcher decides whether a repository path is denied. The zero value and nil deny nothing.
+type Matcher struct {
+	rules []rule
+	// global is the global deny list. It is matched on its own, so the patterns of this matcher
+	// cannot re-include what it denies.
+	global *Matcher
+}
+
+// Parse adds the patterns of a deny list (content of source) to the matcher. Blank lines and
+// comments are skipped; `!` re-includes, a trailing `/` matches directories only, and a leading
+// or inner `/` anchors the pattern to the repository root.
+func (m *Matcher) Parse(source, content string) error {
+	sc := bufio.NewScanner(strings.NewReader(content))
+	n := 0
+	for sc.Scan() {
+		n++
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r := rule{source: source, line: n}
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			r.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		if !doublestar.ValidatePattern(line) {
+			return errors.Errorf("%s:%d: invalid pattern %q", source, n, sc.Text())
+		}
+		r.pattern = line
+		m.rules = append(m.rules, r)
+	}
+	return sc.Err()
+}
+
+// Match reports whether p (slash-separated, relative to the repository root) is denied, along with
+// the rule that denied it ("file:line: pattern"). As in git, the last matching rule wins, and a
+// file inside a denied directory cannot be re-included.
+func (m *Matcher) Match(p string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	if rule, ok := m.global.Match(p); ok {
+		return rule, true
+	}
+	if len(m.rules) == 0 {
+		return "", false
+	}
+	p = strings.Trim(path.Clean(filepath.ToSlash(p)), "/")
+	if p == "" || p == "." {
+		return "", false
+	}
+	parts := strings.Split(p, "/")
+	for i := 1; i <= len(parts); i++ {
+		isDir := i < len(parts)
+		if r, ok := m.last(strings.Join(parts[:i], "/"), isDir); ok && !r.negate {
+			return r.String(), true
+		}
+	}
+	return "", false
+}
+
+// last returns the last rule matching p.
+func (m *Matcher) last(p string, isDir bool) (rule, bool) {
+	for i := len(m.rules) - 1; i >= 0; i-- {
+		if m.rules[i].matches(p, isDir) {
+			return m.rules[i], true
+		}
+	}
+	return rule{}, false
+}
+
+// Len returns the number of patterns, those of the global list included.
+func (m *Matcher) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.rules) + m.global.Len()
+}
+
+// GlobalPath returns the path of the global deny list (~/.pr-builder/.prescribeignore).
+func GlobalPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".pr-builder", FileName), nil
+}
+
+// Load reads the global deny list and the one at the root of repoPath; both are optional. Each list
+// is matched on its own and a path is denied if either denies it, so a repository cannot re-include
+// what the global list denies.
+func Load(repoPath string) (*Matcher, error) {
+	m := &Matcher{}
+	if global, err := GlobalPath(); err == nil {
+		g := &Matcher{}
+		if err := g.parseFile(global, "~/.pr-builder/"+FileName); err != nil {
+			return nil, err
+		}
+		m.global = g
+	}
+	if err := m.parseFile(filepath.Join(repoPath, FileName), FileName); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// parseFile parses the deny list at path (if it exists), naming it source in matched rules.
+func (m *Matcher) parseFile(path, source string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to read %s", path)
+	}
+	return m.Parse(source, string(content))
+}
@@ -0,0 +1,103 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatcher_GitignoreSyntax(t *testing.T) {
+	m := &Matcher{}
+	err := m.Parse(".prescribeignore", `# secrets
+.env*
+!.env.example
+/config/prod.yaml
+secrets/
+**/*.pem
+docs/**/internal-*.md
+\#literal
+`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	cases := map[string]bool{
+		".env":                       true,
+		"app/.env.local":             true,
+		".env.example":               false,
+		"config/prod.yaml":           true,
+		"app/config/prod.yaml":       false,
+		"secrets/db.txt":             true,
+		"app/secrets/nested/key.txt": true,
+		"secrets":                    false, // a file named like the dir-only pattern
+		"certs/server.pem":           true,
+		"docs/a/b/internal-notes.md": true,
+		"docs/public.md":             false,
+		"#literal":                   true,
+		"main.go":                    false,
+	}
+	for path, want := range cases {
+		if _, got := m.Match(path); got != want {
+			t.Errorf("Match(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	rule, _ := m.Match("config/prod.yaml")
+	if rule != ".prescribeignore:4: config/prod.yaml" {
+		t.Fatalf("unexpected rule %q", rule)
+	}
+}
+
+func TestMatcher_DeniedDirectoryCannotBeReincluded(t *testing.T) {
+	m := &Matcher{}
+	if err := m.Parse("x", "vendor/\n!vendor/keep.go\n"); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if _, ok := m.Match("vendor/keep.go"); !ok {
+		t.Fatalf("expected a file under a denied directory to stay denied")
+	}
+
+	var nilMatcher *Matcher
+	if _, ok := nilMatcher.Match("anything"); ok {
+		t.Fatalf("expected a nil matcher to deny nothing")
+	}
+}
+
+func TestLoad_GlobalCannotBeOverridden(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(home, ".pr-builder"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".pr-builder", FileName), []byte("*.key\n*.pem\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, FileName), []byte("*.log\n!test-fixture.pem\n!debug.log\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := Load(repo)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if rule, ok := m.Match("deploy/id.key"); !ok || rule != "~/.pr-builder/.prescribeignore:1: *.key" {
+		t.Fatalf("expected the global rule to deny, got %q %v", rule, ok)
+	}
+	if rule, ok := m.Match("test-fixture.pem"); !ok || rule != "~/.pr-builder/.prescribeignore:2: *.pem" {
+		t.Fatalf("expected the repo list not to re-include what the global list denies, got %q %v", rule, ok)
+	}
+	if _, ok := m.Match("debug.log"); ok {
+		t.Fatalf("expected the repo list to re-include within itself")
+	}
+	if m.Len() != 5 {
+		t.Fatalf("expected 5 patterns, got %d", m.Len())
+	}
+
+	if err := os.WriteFile(filepath.Join(repo, FileName), []byte("[\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(repo); err == nil {
+		t.Fatalf("expected an invalid pattern to fail")
+	}
+}
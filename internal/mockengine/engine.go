@@ -0,0 +1,270 @@
+package mockengine
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-go-golems/geppetto/pkg/events"
+	"github.com/go-go-golems/geppetto/pkg/inference/engine"
+	"github.com/go-go-golems/geppetto/pkg/inference/toolcontext"
+	"github.com/go-go-golems/geppetto/pkg/steps/ai/settings"
+	"github.com/go-go-golems/geppetto/pkg/turns"
+	"github.com/go-go-golems/prescribe/internal/tokens"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultChunkSize = 24
+
+// Player hands out the responses of a fixture. It is shared by all engines created for one
+// service, so a retry (which creates a new engine) continues with the next response.
+type Player struct {
+	mu      sync.Mutex
+	fixture *Fixture
+	next    int
+}
+
+func NewPlayer(f *Fixture) *Player {
+	return &Player{fixture: f}
+}
+
+// NewPlayerForEngine returns the player selected by an --ai-engine value (see ParseEngineName).
+func NewPlayerForEngine(engineName string) (*Player, error) {
+	path, ok := ParseEngineName(engineName)
+	if !ok {
+		return nil, errors.Errorf("%q is not a mock engine", engineName)
+	}
+	if path == "" {
+		return NewPlayer(BuiltinFixture()), nil
+	}
+	f, err := LoadFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewPlayer(f), nil
+}
+
+// take returns the next response matching userText.
+func (p *Player) take(userText string) Response {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	rs := p.fixture.Responses
+	for i := p.next; i < len(rs); i++ {
+		if rs[i].Match == "" || strings.Contains(userText, rs[i].Match) {
+			p.next = i + 1
+			return rs[i]
+		}
+	}
+	// Exhausted: repeat the last matching response.
+	for i := len(rs) - 1; i >= 0; i-- {
+		if rs[i].Match == "" || strings.Contains(userText, rs[i].Match) {
+			return rs[i]
+		}
+	}
+	return rs[len(rs)-1]
+}
+
+// Engine returns an engine that plays the fixture with the given settings (max response tokens
+// truncate the text) and options (event sinks receive simulated streaming events).
+func (p *Player) Engine(ss *settings.StepSettings, options ...engine.Option) (engine.Engine, error) {
+	cfg := engine.NewConfig()
+	if err := engine.ApplyOptions(cfg, options...); err != nil {
+		return nil, err
+	}
+	return &Engine{player: p, settings: ss, config: cfg}, nil
+}
+
+// Engine is a geppetto engine that answers from a fixture instead of calling a provider.
+type Engine struct {
+	player   *Player
+	settings *settings.StepSettings
+	config   *engine.Config
+}
+
+var _ engine.Engine = &Engine{}
+
+func (e *Engine) RunInference(ctx context.Context, t *turns.Turn) (*turns.Turn, error) {
+	if t == nil {
+		t = &turns.Turn{}
+	}
+	resp := e.player.take(lastUserText(t))
+
+	model := e.player.fixture.Model
+	if model == "" {
+		model = EngineMock
+	}
+	metadata := events.EventMetadata{
+		ID:     uuid.New(),
+		RunID:  t.RunID,
+		TurnID: t.ID,
+		LLMInferenceData: events.LLMInferenceData{
+			Model: model,
+		},
+	}
+	if e.settings != nil && e.settings.Chat != nil {
+		metadata.Temperature = e.settings.Chat.Temperature
+		metadata.TopP = e.settings.Chat.TopP
+		metadata.MaxTokens = e.settings.Chat.MaxResponseTokens
+	}
+	e.publish(ctx, events.NewStartEvent(metadata))
+
+	if resp.Error != "" {
+		err := errors.New(resp.Error)
+		e.publish(ctx, events.NewErrorEvent(metadata, err))
+		return nil, err
+	}
+
+	text, stopReason := e.truncate(resp.Text)
+	if resp.StopReason != "" {
+		stopReason = resp.StopReason
+	}
+
+	toolCall := resp.ToolCall
+	if toolCall == nil {
+		toolCall = impliedToolCall(ctx, t, text)
+	}
+	if toolCall != nil {
+		// Providers answer a forced tool call with the call only.
+		text = ""
+	}
+
+	chunkSize := resp.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+	completion := ""
+	for len(completion) < len(text) {
+		if err := ctx.Err(); err != nil {
+			e.publish(ctx, events.NewErrorEvent(metadata, err))
+			return nil, err
+		}
+		end := len(completion) + chunkSize
+		if end > len(text) {
+			end = len(text)
+		}
+		delta := text[len(completion):end]
+		completion = text[:end]
+		e.publish(ctx, events.NewPartialCompletionEvent(metadata, delta, completion))
+		if resp.ChunkDelayMs > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Duration(resp.ChunkDelayMs) * time.Millisecond):
+			}
+		}
+	}
+
+	usage := events.Usage{}
+	if resp.Usage != nil {
+		usage.InputTokens = resp.Usage.InputTokens
+		usage.OutputTokens = resp.Usage.OutputTokens
+		usage.CachedTokens = resp.Usage.CachedTokens
+		usage.CacheReadInputTokens = resp.Usage.CacheReadTokens
+		usage.CacheCreationInputTokens = resp.Usage.CacheWriteTokens
+	} else {
+		usage.InputTokens = tokens.Default().Count(turnText(t))
+		usage.OutputTokens = tokens.Default().Count(text)
+	}
+	metadata.Usage = &usage
+	metadata.StopReason = &stopReason
+
+	if text != "" {
+		turns.AppendBlock(t, turns.NewAssistantTextBlock(text))
+	}
+	if toolCall != nil {
+		call := events.ToolCall{ID: "mock_" + uuid.NewString(), Name: toolCall.Name}
+		if b, err := json.Marshal(toolCall.Args); err == nil {
+			call.Input = string(b)
+		}
+		e.publish(ctx, events.NewToolCallEvent(metadata, call))
+		turns.AppendBlock(t, turns.NewToolCallBlock(call.ID, toolCall.Name, toolCall.Args))
+	}
+	turns.SetTurnMetadata(t, turns.TurnMetaKeyModel, model)
+	turns.SetTurnMetadata(t, turns.TurnMetaKeyStopReason, stopReason)
+	turns.SetTurnMetadata(t, turns.TurnMetaKeyUsage, &usage)
+
+	e.publish(ctx, events.NewFinalEvent(metadata, text))
+	return t, nil
+}
+
+// truncate cuts text to the configured max response tokens, like a provider would, and reports
+// the stop reason.
+func (e *Engine) truncate(text string) (string, string) {
+	if e.settings == nil || e.settings.Chat == nil || e.settings.Chat.MaxResponseTokens == nil {
+		return text, "stop"
+	}
+	limit := *e.settings.Chat.MaxResponseTokens
+	if limit <= 0 || tokens.Default().Count(text) <= limit {
+		return text, "stop"
+	}
+	// Shrink by lines first (keeps the output readable), then by bytes.
+	lines := strings.SplitAfter(text, "\n")
+	out := ""
+	for _, l := range lines {
+		if tokens.Default().Count(out+l) > limit {
+			break
+		}
+		out += l
+	}
+	if out == "" {
+		for n := len(text); n > 0; n /= 2 {
+			if tokens.Default().Count(text[:n]) <= limit {
+				out = text[:n]
+				break
+			}
+		}
+	}
+	return out, "max_tokens"
+}
+
+func (e *Engine) publish(ctx context.Context, ev events.Event) {
+	for _, sink := range e.config.EventSinks {
+		_ = sink.PublishEvent(ev)
+	}
+	events.PublishEventToContext(ctx, ev)
+}
+
+// impliedToolCall turns a YAML answer into a call of the only registered tool when the turn
+// requires a tool call, so plain-text fixtures (and the built-in response) work in tool mode.
+func impliedToolCall(ctx context.Context, t *turns.Turn, text string) *ToolCall {
+	cfg, ok := t.Data[turns.DataKeyToolConfig].(engine.ToolConfig)
+	if !ok || !cfg.Enabled || cfg.ToolChoice != engine.ToolChoiceRequired {
+		return nil
+	}
+	reg, ok := toolcontext.RegistryFrom(ctx)
+	if !ok || len(reg.ListTools()) != 1 {
+		return nil
+	}
+	var args map[string]any
+	if err := yaml.Unmarshal([]byte(text), &args); err != nil || len(args) == 0 {
+		return nil
+	}
+	return &ToolCall{Name: reg.ListTools()[0].Name, Args: args}
+}
+
+func lastUserText(t *turns.Turn) string {
+	for i := len(t.Blocks) - 1; i >= 0; i-- {
+		b := t.Blocks[i]
+		if b.Kind != turns.BlockKindUser {
+			continue
+		}
+		if s, ok := b.Payload[turns.PayloadKeyText].(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+func turnText(t *turns.Turn) string {
+	var sb strings.Builder
+	for _, b := range t.Blocks {
+		if s, ok := b.Payload[turns.PayloadKeyText].(string); ok {
+			sb.WriteString(s)
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
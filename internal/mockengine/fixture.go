@@ -0,0 +1,132 @@
+package mockengine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Fixture is a scripted sequence of model responses, stored as YAML:
+//
+//	model: mock-fixture
+//	responses:
+//	  - text: |
+//	      title: ...
+//	    stop_reason: stop
+//	  - error: "rate limited"
+//
+// Each inference consumes the next response; once all are used, the last one repeats.
+type Fixture struct {
+	// Model is reported as the model of every response ("mock" if empty).
+	Model     string     `yaml:"model,omitempty"`
+	Responses []Response `yaml:"responses"`
+}
+
+// Response is one scripted inference result.
+type Response struct {
+	// Match, if set, restricts the response to requests whose last user message contains it.
+	// Unmatched responses are skipped (in order); responses without Match match everything.
+	Match string `yaml:"match,omitempty"`
+	// Text is the assistant text.
+	Text string `yaml:"text,omitempty"`
+	// ToolCall is returned as a tool call block (tool output mode).
+	ToolCall *ToolCall `yaml:"tool_call,omitempty"`
+	// StopReason defaults to "stop", or "max_tokens" if Text was truncated to the configured
+	// max response tokens.
+	StopReason string `yaml:"stop_reason,omitempty"`
+	// Usage defaults to token counts of the request and Text.
+	Usage *domain.GenerationUsage `yaml:"usage,omitempty"`
+	// Error makes the inference fail with this message.
+	Error string `yaml:"error,omitempty"`
+	// ChunkSize is the size of the streamed deltas in bytes (default 24).
+	ChunkSize int `yaml:"chunk_size,omitempty"`
+	// ChunkDelayMs pauses between streamed deltas (useful for demos).
+	ChunkDelayMs int `yaml:"chunk_delay_ms,omitempty"`
+}
+
+// ToolCall is a scripted tool call.
+type ToolCall struct {
+	Name string         `yaml:"name"`
+	Args map[string]any `yaml:"args"`
+}
+
+// LoadFixture reads a fixture file.
+func LoadFixture(path string) (*Fixture, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read mock engine fixture")
+	}
+	var f Fixture
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal mock engine fixture %s", path)
+	}
+	if len(f.Responses) == 0 {
+		return nil, errors.Errorf("mock engine fixture %s has no responses", path)
+	}
+	return &f, nil
+}
+
+// Save writes the fixture to path.
+func (f *Fixture) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrap(err, "failed to create directory for mock engine fixture")
+	}
+	b, err := yaml.Marshal(f)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal mock engine fixture")
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return errors.Wrap(err, "failed to write mock engine fixture")
+	}
+	return nil
+}
+
+// EngineName prefixes select the mock engine through the normal AI settings (--ai-engine):
+//
+//	mock                 built-in canned response
+//	mock:<fixture.yaml>  scripted responses from a fixture
+//	replay:<fixture.yaml> same as mock:<fixture.yaml> (e.g. for a recorded session)
+const (
+	EngineMock   = "mock"
+	enginePrefix = "mock:"
+	replayPrefix = "replay:"
+)
+
+// ParseEngineName reports whether engine selects the mock engine, and the fixture path if any.
+func ParseEngineName(engine string) (fixturePath string, ok bool) {
+	e := strings.TrimSpace(engine)
+	switch {
+	case e == EngineMock:
+		return "", true
+	case strings.HasPrefix(e, enginePrefix):
+		return strings.TrimSpace(strings.TrimPrefix(e, enginePrefix)), true
+	case strings.HasPrefix(e, replayPrefix):
+		return strings.TrimSpace(strings.TrimPrefix(e, replayPrefix)), true
+	}
+	return "", false
+}
+
+// BuiltinFixture is the canned response of the plain "mock" engine: a valid PR YAML answer.
+func BuiltinFixture() *Fixture {
+	return &Fixture{
+		Model: EngineMock,
+		Responses: []Response{{
+			Text: `title: Mock PR description
+body: |
+  This description was produced by the built-in mock engine; no provider was called.
+
+  Use ` + "`--ai-engine mock:<fixture.yaml>`" + ` to script responses.
+changelog: |
+  - Mock changelog entry
+release_notes:
+  title: Mock release notes
+  body: |
+    Nothing user-facing changed.
+`,
+		}},
+	}
+}
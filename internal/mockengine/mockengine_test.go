@@ -0,0 +1,217 @@
+package mockengine
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-go-golems/geppetto/pkg/events"
+	"github.com/go-go-golems/geppetto/pkg/inference/engine"
+	"github.com/go-go-golems/geppetto/pkg/steps/ai/settings"
+	"github.com/go-go-golems/geppetto/pkg/turns"
+)
+
+type collectSink struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (s *collectSink) PublishEvent(ev events.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, ev)
+	return nil
+}
+
+func userTurn(text string) *turns.Turn {
+	return turns.NewTurnBuilder().WithSystemPrompt("sys").WithUserPrompt(text).Build()
+}
+
+func run(t *testing.T, p *Player, ss *settings.StepSettings, user string, opts ...engine.Option) (*turns.Turn, error) {
+	t.Helper()
+	eng, err := p.Engine(ss, opts...)
+	if err != nil {
+		t.Fatalf("Engine: %v", err)
+	}
+	return eng.RunInference(context.Background(), userTurn(user))
+}
+
+func assistantText(t *turns.Turn) string {
+	for i := len(t.Blocks) - 1; i >= 0; i-- {
+		if t.Blocks[i].Kind == turns.BlockKindLLMText {
+			s, _ := t.Blocks[i].Payload[turns.PayloadKeyText].(string)
+			return s
+		}
+	}
+	return ""
+}
+
+func TestParseEngineName(t *testing.T) {
+	cases := []struct {
+		in   string
+		path string
+		ok   bool
+	}{
+		{"mock", "", true},
+		{"mock:fx.yaml", "fx.yaml", true},
+		{"replay: rec.yaml", "rec.yaml", true},
+		{"gpt-4o", "", false},
+		{"mockingbird", "", false},
+	}
+	for _, tc := range cases {
+		path, ok := ParseEngineName(tc.in)
+		if path != tc.path || ok != tc.ok {
+			t.Fatalf("ParseEngineName(%q) = %q, %v; want %q, %v", tc.in, path, ok, tc.path, tc.ok)
+		}
+	}
+}
+
+func TestPlayer_sequenceMatchAndRepeat(t *testing.T) {
+	p := NewPlayer(&Fixture{Responses: []Response{
+		{Match: "refine", Text: "refined"},
+		{Text: "first"},
+		{Text: "second"},
+	}})
+
+	// "refine" does not match the first request, so it is skipped.
+	for _, want := range []string{"first", "second", "second"} {
+		out, err := run(t, p, nil, "generate")
+		if err != nil {
+			t.Fatalf("RunInference: %v", err)
+		}
+		if got := assistantText(out); got != want {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+	}
+
+	p = NewPlayer(&Fixture{Responses: []Response{{Match: "refine", Text: "refined"}, {Text: "plain"}}})
+	out, err := run(t, p, nil, "please refine this")
+	if err != nil {
+		t.Fatalf("RunInference: %v", err)
+	}
+	if got := assistantText(out); got != "refined" {
+		t.Fatalf("expected matched response, got %q", got)
+	}
+}
+
+func TestEngine_truncatesToMaxResponseTokens(t *testing.T) {
+	ss, err := settings.NewStepSettings()
+	if err != nil {
+		t.Fatalf("NewStepSettings: %v", err)
+	}
+	limit := 5
+	ss.Chat.MaxResponseTokens = &limit
+
+	text := strings.Repeat("word ", 20) + "\n" + strings.Repeat("more ", 20) + "\n"
+	out, err := run(t, NewPlayer(&Fixture{Responses: []Response{{Text: text}}}), ss, "go")
+	if err != nil {
+		t.Fatalf("RunInference: %v", err)
+	}
+	if got := assistantText(out); len(got) >= len(text) {
+		t.Fatalf("expected truncated text, got %d bytes", len(got))
+	}
+	if got := out.Metadata[turns.TurnMetaKeyStopReason]; got != "max_tokens" {
+		t.Fatalf("expected max_tokens stop reason, got %v", got)
+	}
+}
+
+func TestEngine_errorResponse(t *testing.T) {
+	sink := &collectSink{}
+	_, err := run(t, NewPlayer(&Fixture{Responses: []Response{{Error: "rate limited"}}}), nil, "go", engine.WithSink(sink))
+	if err == nil || err.Error() != "rate limited" {
+		t.Fatalf("expected scripted error, got %v", err)
+	}
+	if len(sink.events) != 2 || sink.events[1].Type() != events.EventTypeError {
+		t.Fatalf("expected start + error events, got %d events", len(sink.events))
+	}
+}
+
+func TestEngine_streamsDeltas(t *testing.T) {
+	sink := &collectSink{}
+	text := "title: streamed\nbody: hello\n"
+	out, err := run(t, NewPlayer(&Fixture{Responses: []Response{{Text: text, ChunkSize: 5}}}), nil, "go", engine.WithSink(sink))
+	if err != nil {
+		t.Fatalf("RunInference: %v", err)
+	}
+
+	var sb strings.Builder
+	partials := 0
+	for _, ev := range sink.events {
+		if p, ok := ev.(*events.EventPartialCompletion); ok {
+			partials++
+			sb.WriteString(p.Delta)
+		}
+	}
+	if sb.String() != text {
+		t.Fatalf("deltas do not add up to the text: %q", sb.String())
+	}
+	if want := (len(text) + 4) / 5; partials != want {
+		t.Fatalf("expected %d partial events, got %d", want, partials)
+	}
+	if last := sink.events[len(sink.events)-1]; last.Type() != events.EventTypeFinal {
+		t.Fatalf("expected final event last, got %s", last.Type())
+	}
+	usage, ok := out.Metadata[turns.TurnMetaKeyUsage].(*events.Usage)
+	if !ok || usage.InputTokens == 0 || usage.OutputTokens == 0 {
+		t.Fatalf("expected estimated usage, got %#v", out.Metadata[turns.TurnMetaKeyUsage])
+	}
+}
+
+func TestEngine_scriptedToolCall(t *testing.T) {
+	p := NewPlayer(&Fixture{Responses: []Response{{
+		Text:     "ignored",
+		ToolCall: &ToolCall{Name: "emit", Args: map[string]any{"title": "T"}},
+	}}})
+	out, err := run(t, p, nil, "go")
+	if err != nil {
+		t.Fatalf("RunInference: %v", err)
+	}
+	if got := assistantText(out); got != "" {
+		t.Fatalf("expected no assistant text with a tool call, got %q", got)
+	}
+	var found bool
+	for _, b := range out.Blocks {
+		if b.Kind == turns.BlockKindToolCall && b.Payload[turns.PayloadKeyName] == "emit" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected a tool call block")
+	}
+}
+
+func TestRecorder_roundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rec", "fixture.yaml")
+	rec := NewRecorder(path)
+	inner := NewPlayer(&Fixture{Model: "gpt-test", Responses: []Response{{Text: "one"}, {Error: "boom"}}})
+
+	for i := 0; i < 2; i++ {
+		eng, err := inner.Engine(nil)
+		if err != nil {
+			t.Fatalf("Engine: %v", err)
+		}
+		_, _ = rec.Wrap(eng).RunInference(context.Background(), userTurn("go"))
+	}
+
+	f, err := LoadFixture(path)
+	if err != nil {
+		t.Fatalf("LoadFixture: %v", err)
+	}
+	if f.Model != "gpt-test" || len(f.Responses) != 2 {
+		t.Fatalf("unexpected fixture: %#v", f)
+	}
+	if f.Responses[0].Text != "one" || f.Responses[0].StopReason != "stop" || f.Responses[0].Usage == nil {
+		t.Fatalf("unexpected first response: %#v", f.Responses[0])
+	}
+	if f.Responses[1].Error != "boom" {
+		t.Fatalf("expected recorded error, got %#v", f.Responses[1])
+	}
+
+	// The recording replays like the original session.
+	out, err := run(t, NewPlayer(f), nil, "go")
+	if err != nil || assistantText(out) != "one" {
+		t.Fatalf("replay: %v, %q", err, assistantText(out))
+	}
+}
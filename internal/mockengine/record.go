@@ -0,0 +1,131 @@
+package mockengine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/go-go-golems/geppetto/pkg/events"
+	"github.com/go-go-golems/geppetto/pkg/inference/engine"
+	"github.com/go-go-golems/geppetto/pkg/turns"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/pkg/errors"
+)
+
+// Recorder appends every inference result of the engines it wraps to a fixture file, so a real
+// session can later be replayed with --ai-engine replay:<path>.
+type Recorder struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewRecorder(path string) *Recorder {
+	return &Recorder{path: path}
+}
+
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Wrap returns an engine that records the results (and errors) of inner.
+func (r *Recorder) Wrap(inner engine.Engine) engine.Engine {
+	return &recordingEngine{inner: inner, recorder: r}
+}
+
+type recordingEngine struct {
+	inner    engine.Engine
+	recorder *Recorder
+}
+
+func (e *recordingEngine) RunInference(ctx context.Context, t *turns.Turn) (*turns.Turn, error) {
+	before := 0
+	if t != nil {
+		before = len(t.Blocks)
+	}
+	out, err := e.inner.RunInference(ctx, t)
+	if err != nil {
+		if rerr := e.recorder.append("", Response{Error: err.Error()}); rerr != nil {
+			return nil, errors.Wrapf(err, "inference failed (and recording it failed: %v)", rerr)
+		}
+		return out, err
+	}
+	model, resp := responseFromTurn(out, before)
+	if rerr := e.recorder.append(model, resp); rerr != nil {
+		return nil, rerr
+	}
+	return out, nil
+}
+
+func (r *Recorder) append(model string, resp Response) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f := &Fixture{}
+	if _, err := os.Stat(r.path); err == nil {
+		loaded, err := LoadFixture(r.path)
+		if err != nil {
+			return err
+		}
+		f = loaded
+	}
+	if f.Model == "" {
+		f.Model = model
+	}
+	f.Responses = append(f.Responses, resp)
+	return f.Save(r.path)
+}
+
+// responseFromTurn converts the blocks an engine appended to t (from index before on) and the
+// turn metadata into a fixture response.
+func responseFromTurn(t *turns.Turn, before int) (string, Response) {
+	resp := Response{}
+	if t == nil {
+		return "", resp
+	}
+	if before > len(t.Blocks) {
+		before = len(t.Blocks)
+	}
+	for _, b := range t.Blocks[before:] {
+		switch b.Kind {
+		case turns.BlockKindLLMText:
+			if s, ok := b.Payload[turns.PayloadKeyText].(string); ok {
+				resp.Text += s
+			}
+		case turns.BlockKindToolCall:
+			name, _ := b.Payload[turns.PayloadKeyName].(string)
+			args, _ := b.Payload[turns.PayloadKeyArgs].(map[string]any)
+			resp.ToolCall = &ToolCall{Name: name, Args: args}
+		default:
+		}
+	}
+
+	model := ""
+	if t.Metadata != nil {
+		if v, ok := t.Metadata[turns.TurnMetaKeyModel].(string); ok {
+			model = v
+		}
+		if v, ok := t.Metadata[turns.TurnMetaKeyStopReason]; ok && v != nil {
+			resp.StopReason = fmt.Sprintf("%v", v)
+		}
+		switch u := t.Metadata[turns.TurnMetaKeyUsage].(type) {
+		case *events.Usage:
+			if u != nil {
+				resp.Usage = usageFromEvents(*u)
+			}
+		case events.Usage:
+			resp.Usage = usageFromEvents(u)
+		}
+	}
+	return model, resp
+}
+
+func usageFromEvents(u events.Usage) *domain.GenerationUsage {
+	return &domain.GenerationUsage{
+		InputTokens:      u.InputTokens,
+		OutputTokens:     u.OutputTokens,
+		CachedTokens:     u.CachedTokens,
+		CacheReadTokens:  u.CacheReadInputTokens,
+		CacheWriteTokens: u.CacheCreationInputTokens,
+	}
+}
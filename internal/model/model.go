@@ -109,6 +109,9 @@ type PRBuilderModel struct {
 
 	// Generated description
 	GeneratedDescription string
+
+	// TokenCounter counts file tokens (nil => tokens.DefaultEncoding).
+	TokenCounter *tokens.Counter
 }
 
 // NewPRBuilderModel creates a new model
@@ -237,11 +240,11 @@ func (m *PRBuilderModel) ReplaceWithFullFile(index int, version FileVersion) err
 	// Recalculate tokens based on version
 	switch version {
 	case FileVersionBefore:
-		file.Tokens = tokens.Count(file.FullBefore)
+		file.Tokens = m.TokenCounter.Count(file.FullBefore)
 	case FileVersionAfter:
-		file.Tokens = tokens.Count(file.FullAfter)
+		file.Tokens = m.TokenCounter.Count(file.FullAfter)
 	case FileVersionBoth:
-		file.Tokens = tokens.Count(file.FullBefore) + tokens.Count(file.FullAfter)
+		file.Tokens = m.TokenCounter.Count(file.FullBefore) + m.TokenCounter.Count(file.FullAfter)
 	}
 
 	return nil
@@ -256,7 +259,7 @@ func (m *PRBuilderModel) RestoreToDiff(index int) error {
 	file := &m.ChangedFiles[index]
 	file.Type = FileTypeDiff
 	file.Version = ""
-	file.Tokens = tokens.Count(file.Diff)
+	file.Tokens = m.TokenCounter.Count(file.Diff)
 
 	return nil
 }
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/go-go-golems/geppetto/pkg/turns"
+	"github.com/go-go-golems/geppetto/pkg/turns/serde"
 	"github.com/go-go-golems/prescribe/internal/domain"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
@@ -16,6 +18,11 @@ func LastGeneratedPRDataPath(repoPath string) string {
 	return filepath.Join(repoPath, ".pr-builder", "last-generated-pr.yaml")
 }
 
+// LastGeneratedTurnPath is the conversation behind last-generated-pr.yaml (used by `prescribe refine`).
+func LastGeneratedTurnPath(repoPath string) string {
+	return filepath.Join(repoPath, ".pr-builder", "last-generated-turn.yaml")
+}
+
 func FailurePRDataPath(repoPath string, now time.Time) string {
 	// Use a timestamped filename so repeated failures don't overwrite.
 	ts := now.UTC().Format("20060102-150405")
@@ -32,6 +39,11 @@ func LoadGeneratedPRDataFromYAMLFile(path string) (*domain.GeneratedPRData, erro
 	if err := yaml.Unmarshal(b, &out); err != nil {
 		return nil, errors.Wrap(err, "failed to unmarshal PR data YAML")
 	}
+	// Custom output schema fields land in Extra; the generation metadata is not one of them.
+	delete(out.Extra, generationKey)
+	if len(out.Extra) == 0 {
+		out.Extra = nil
+	}
 
 	if strings.TrimSpace(out.Title) == "" {
 		return nil, errors.New("invalid PR data YAML: missing title")
@@ -43,7 +55,34 @@ func LoadGeneratedPRDataFromYAMLFile(path string) (*domain.GeneratedPRData, erro
 	return &out, nil
 }
 
-func WriteGeneratedPRDataToYAMLFile(path string, data *domain.GeneratedPRData) error {
+// The on-disk layout is the PR data at the top level (so the file stays loadable as plain
+// GeneratedPRData) plus optional generation metadata under generationKey.
+const generationKey = "generation"
+
+// generationMetadataFile reads just the metadata. The PR data is not embedded here: yaml.v3
+// ignores the inline Extra map of an inlined struct, which would drop custom fields.
+type generationMetadataFile struct {
+	Generation *domain.GenerationMetadata `yaml:"generation,omitempty"`
+}
+
+// LoadGenerationMetadataFromYAMLFile returns the generation metadata stored next to the PR data,
+// or nil if the file has none (e.g. it was written by hand or by an older version).
+func LoadGenerationMetadataFromYAMLFile(path string) (*domain.GenerationMetadata, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read PR data YAML file")
+	}
+
+	var out generationMetadataFile
+	if err := yaml.Unmarshal(b, &out); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal PR data YAML")
+	}
+	return out.Generation, nil
+}
+
+// WriteGeneratedPRDataToYAMLFile writes data to path. meta is optional; when set it is
+// stored under a "generation" key.
+func WriteGeneratedPRDataToYAMLFile(path string, data *domain.GeneratedPRData, meta *domain.GenerationMetadata) error {
 	if data == nil {
 		return errors.New("generated PR data is nil")
 	}
@@ -59,10 +98,20 @@ func WriteGeneratedPRDataToYAMLFile(path string, data *domain.GeneratedPRData) e
 		return errors.Wrap(err, "failed to create directory for PR data YAML")
 	}
 
+	if _, ok := data.Extra[generationKey]; ok {
+		return errors.Errorf("generated PR data has a reserved field %q", generationKey)
+	}
 	b, err := yaml.Marshal(data)
 	if err != nil {
 		return errors.Wrap(err, "failed to marshal PR data YAML")
 	}
+	if meta != nil {
+		mb, err := yaml.Marshal(generationMetadataFile{Generation: meta})
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal generation metadata YAML")
+		}
+		b = append(b, mb...)
+	}
 
 	if err := os.WriteFile(path, b, 0o644); err != nil {
 		return errors.Wrap(err, "failed to write PR data YAML file")
@@ -70,3 +119,26 @@ func WriteGeneratedPRDataToYAMLFile(path string, data *domain.GeneratedPRData) e
 
 	return nil
 }
+
+// WriteTurnToYAMLFile persists the conversation of a generation so it can be refined later.
+func WriteTurnToYAMLFile(path string, t *turns.Turn) error {
+	if t == nil {
+		return errors.New("turn is nil")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrap(err, "failed to create directory for turn YAML")
+	}
+	// Turn.Data holds runtime-only payloads (tool registries etc.); only blocks and metadata are needed.
+	if err := serde.SaveTurnYAML(path, t, serde.Options{OmitData: true}); err != nil {
+		return errors.Wrap(err, "failed to write turn YAML file")
+	}
+	return nil
+}
+
+func LoadTurnFromYAMLFile(path string) (*turns.Turn, error) {
+	t, err := serde.LoadTurnYAML(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load turn YAML file")
+	}
+	return t, nil
+}
@@ -1,9 +1,12 @@
 package prdata
 
 import (
+	"errors"
+	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/go-go-golems/geppetto/pkg/turns"
 	"github.com/go-go-golems/prescribe/internal/domain"
 )
 
@@ -16,7 +19,7 @@ func TestWriteThenLoadGeneratedPRDataYAML(t *testing.T) {
 		Body:  "B",
 	}
 
-	if err := WriteGeneratedPRDataToYAMLFile(p, in); err != nil {
+	if err := WriteGeneratedPRDataToYAMLFile(p, in, nil); err != nil {
 		t.Fatalf("write failed: %v", err)
 	}
 
@@ -32,3 +35,119 @@ func TestWriteThenLoadGeneratedPRDataYAML(t *testing.T) {
 		t.Fatalf("body mismatch: got %q want %q", out.Body, in.Body)
 	}
 }
+
+func TestWriteGeneratedPRDataYAMLWithGenerationMetadata(t *testing.T) {
+	tmp := t.TempDir()
+	p := filepath.Join(tmp, "last-generated-pr.yaml")
+
+	meta := &domain.GenerationMetadata{
+		Model:      "gpt-4o",
+		Usage:      &domain.GenerationUsage{InputTokens: 1200, OutputTokens: 300, CachedTokens: 1000},
+		StopReason: "stop",
+		LatencyMs:  1500,
+		Attempts:   2,
+	}
+	if err := WriteGeneratedPRDataToYAMLFile(p, &domain.GeneratedPRData{Title: "T", Body: "B"}, meta); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	// The PR data must stay loadable on its own.
+	out, err := LoadGeneratedPRDataFromYAMLFile(p)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if out.Title != "T" || out.Body != "B" {
+		t.Fatalf("unexpected PR data: %+v", out)
+	}
+
+	got, err := LoadGenerationMetadataFromYAMLFile(p)
+	if err != nil {
+		t.Fatalf("load metadata failed: %v", err)
+	}
+	if got == nil || got.Usage == nil {
+		t.Fatalf("expected generation metadata with usage, got %+v", got)
+	}
+	if got.Model != meta.Model || got.StopReason != meta.StopReason || got.Attempts != meta.Attempts || got.LatencyMs != meta.LatencyMs {
+		t.Fatalf("metadata mismatch: got %+v want %+v", got, meta)
+	}
+	if *got.Usage != *meta.Usage {
+		t.Fatalf("usage mismatch: got %+v want %+v", *got.Usage, *meta.Usage)
+	}
+}
+
+func TestWriteThenLoadGeneratedPRDataYAMLWithCustomFields(t *testing.T) {
+	tmp := t.TempDir()
+	p := filepath.Join(tmp, "last-generated-pr.yaml")
+
+	in := &domain.GeneratedPRData{
+		Title: "T",
+		Body:  "B",
+		Extra: map[string]any{
+			"risk_level":        "high",
+			"affected_services": []any{"api", "worker"},
+		},
+	}
+	meta := &domain.GenerationMetadata{
+		Model: "gpt-4o",
+		OutputSchema: &domain.OutputSchema{Fields: []domain.OutputField{
+			{Name: "risk_level", Required: true, Enum: []string{"low", "medium", "high"}},
+			{Name: "affected_services", Type: domain.OutputFieldList},
+		}},
+	}
+	if err := WriteGeneratedPRDataToYAMLFile(p, in, meta); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	out, err := LoadGeneratedPRDataFromYAMLFile(p)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if out.Extra["risk_level"] != "high" {
+		t.Fatalf("expected custom field to round-trip, got %+v", out.Extra)
+	}
+	if services, _ := out.Extra["affected_services"].([]any); len(services) != 2 {
+		t.Fatalf("expected list field to round-trip, got %+v", out.Extra)
+	}
+	if _, ok := out.Extra["generation"]; ok {
+		t.Fatalf("generation metadata must not leak into the PR data")
+	}
+
+	got, err := LoadGenerationMetadataFromYAMLFile(p)
+	if err != nil {
+		t.Fatalf("load metadata failed: %v", err)
+	}
+	if got == nil || got.OutputSchema == nil || len(got.OutputSchema.Fields) != 2 {
+		t.Fatalf("expected output schema in metadata, got %+v", got)
+	}
+	if err := got.OutputSchema.Check(out); err != nil {
+		t.Fatalf("loaded data should satisfy its schema: %v", err)
+	}
+}
+
+func TestWriteThenLoadTurnYAML(t *testing.T) {
+	p := LastGeneratedTurnPath(t.TempDir())
+
+	if _, err := LoadTurnFromYAMLFile(p); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected not-exist error for missing turn, got %v", err)
+	}
+
+	in := &turns.Turn{}
+	turns.AppendBlock(in, turns.NewSystemTextBlock("system"))
+	turns.AppendBlock(in, turns.NewUserTextBlock("describe the PR"))
+	turns.AppendBlock(in, turns.NewAssistantTextBlock("title: T\nbody: B\n"))
+	if err := WriteTurnToYAMLFile(p, in); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	out, err := LoadTurnFromYAMLFile(p)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(out.Blocks) != 3 {
+		t.Fatalf("expected 3 blocks, got %d", len(out.Blocks))
+	}
+	last := out.Blocks[2]
+	if last.Role != turns.RoleAssistant || last.Payload[turns.PayloadKeyText] != "title: T\nbody: B\n" {
+		t.Fatalf("unexpected assistant block: %+v", last)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 
 	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
 )
 
@@ -83,30 +84,86 @@ func loadPresetsFromDir(dir string, location domain.PresetLocation) ([]domain.Pr
 			continue
 		}
 
-		filePath := filepath.Join(dir, entry.Name())
-		data, err := os.ReadFile(filePath)
+		preset, err := LoadPromptPresetFile(filepath.Join(dir, entry.Name()), location)
 		if err != nil {
 			continue
 		}
+		presets = append(presets, preset)
+	}
 
-		var preset struct {
-			Name        string `yaml:"name"`
-			Description string `yaml:"description"`
-			Template    string `yaml:"template"`
-		}
+	return presets, nil
+}
 
-		if err := yaml.Unmarshal(data, &preset); err != nil {
-			continue
-		}
+// promptPresetYAML is the file format of a prompt preset.
+type promptPresetYAML struct {
+	Name         string                   `yaml:"name"`
+	Description  string                   `yaml:"description,omitempty"`
+	Template     string                   `yaml:"template,omitempty"`
+	OutputMode   string                   `yaml:"output_mode,omitempty"`
+	OutputSchema *domain.OutputSchema     `yaml:"output_schema,omitempty"`
+	Lint         *domain.LintConfig       `yaml:"lint,omitempty"`
+	Parameters   []domain.PresetParameter `yaml:"parameters,omitempty"`
+	// Multi-part format (replaces template)
+	System   string                 `yaml:"system,omitempty"`
+	User     string                 `yaml:"user,omitempty"`
+	Examples []domain.PromptExample `yaml:"examples,omitempty"`
+	Partials map[string]string      `yaml:"partials,omitempty"`
+}
 
-		presets = append(presets, domain.PromptPreset{
-			ID:          entry.Name(),
-			Name:        preset.Name,
-			Description: preset.Description,
-			Template:    preset.Template,
-			Location:    location,
-		})
+// LoadPromptPresetFile parses a single prompt preset file. The preset ID is the file name.
+func LoadPromptPresetFile(path string, location domain.PresetLocation) (domain.PromptPreset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return domain.PromptPreset{}, err
 	}
 
-	return presets, nil
+	var preset promptPresetYAML
+	if err := yaml.Unmarshal(data, &preset); err != nil {
+		return domain.PromptPreset{}, errors.Wrapf(err, "failed to parse %s", path)
+	}
+
+	var parts *domain.PromptParts
+	if preset.System != "" || preset.User != "" {
+		parts = &domain.PromptParts{
+			System:   preset.System,
+			User:     preset.User,
+			Examples: preset.Examples,
+			Partials: preset.Partials,
+		}
+		preset.Template = ""
+	}
+
+	return domain.PromptPreset{
+		ID:           filepath.Base(path),
+		Name:         preset.Name,
+		Description:  preset.Description,
+		Template:     preset.Template,
+		Location:     location,
+		OutputMode:   domain.OutputMode(preset.OutputMode),
+		OutputSchema: preset.OutputSchema,
+		Lint:         preset.Lint,
+		Parameters:   preset.Parameters,
+		Parts:        parts,
+	}, nil
+}
+
+// MarshalPromptPreset encodes p in the preset file format (ID and location are not stored).
+func MarshalPromptPreset(p domain.PromptPreset) ([]byte, error) {
+	out := promptPresetYAML{
+		Name:         p.Name,
+		Description:  p.Description,
+		Template:     p.Template,
+		OutputMode:   string(p.OutputMode),
+		OutputSchema: p.OutputSchema,
+		Lint:         p.Lint,
+		Parameters:   p.Parameters,
+	}
+	if p.Parts != nil {
+		out.Template = ""
+		out.System = p.Parts.System
+		out.User = p.Parts.User
+		out.Examples = p.Parts.Examples
+		out.Partials = p.Parts.Partials
+	}
+	return yaml.Marshal(out)
 }
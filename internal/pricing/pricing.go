@@ -0,0 +1,136 @@
+package pricing
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed assets/pricing.yaml
+var builtinYAML []byte
+
+// Price is the cost of one model in USD per million tokens.
+type Price struct {
+	// Provider is the geppetto API type (openai, claude, gemini, ...); empty matches any provider.
+	Provider string `yaml:"provider,omitempty"`
+	// Model is matched as a prefix of the model name.
+	Model         string  `yaml:"model"`
+	InputPerMTok  float64 `yaml:"input_per_mtok"`
+	OutputPerMTok float64 `yaml:"output_per_mtok"`
+	// CachedInputPerMTok prices cached prompt tokens (cache reads); nil means cached tokens cost the
+	// input price.
+	CachedInputPerMTok *float64 `yaml:"cached_input_per_mtok,omitempty"`
+	// CacheWritePerMTok prices prompt tokens written to the cache; nil means the input price.
+	CacheWritePerMTok *float64 `yaml:"cache_write_per_mtok,omitempty"`
+}
+
+// Table is an ordered list of prices; see Lookup for matching rules.
+type Table struct {
+	Prices []Price `yaml:"prices"`
+}
+
+// Builtin returns the embedded pricing table.
+func Builtin() (Table, error) {
+	var t Table
+	if err := yaml.Unmarshal(builtinYAML, &t); err != nil {
+		return Table{}, errors.Wrap(err, "failed to parse builtin pricing table")
+	}
+	return t, nil
+}
+
+// WithOverrides returns a table in which overrides win over existing entries for the same prefix.
+func (t Table) WithOverrides(overrides []Price) Table {
+	prices := make([]Price, 0, len(overrides)+len(t.Prices))
+	prices = append(prices, overrides...)
+	prices = append(prices, t.Prices...)
+	return Table{Prices: prices}
+}
+
+// Lookup returns the price for a provider/model. The longest matching model prefix wins;
+// on ties the earlier entry (i.e. an override) wins. Provider prefixes like "openai/" in
+// the model name are ignored.
+func (t Table) Lookup(provider, model string) (Price, bool) {
+	m := strings.ToLower(strings.TrimSpace(model))
+	if i := strings.LastIndex(m, "/"); i >= 0 {
+		m = m[i+1:]
+	}
+	if m == "" {
+		return Price{}, false
+	}
+	provider = strings.ToLower(strings.TrimSpace(provider))
+
+	best, bestLen := -1, 0
+	for i, p := range t.Prices {
+		prefix := strings.ToLower(strings.TrimSpace(p.Model))
+		if prefix == "" || !strings.HasPrefix(m, prefix) {
+			continue
+		}
+		if p.Provider != "" && provider != "" && !strings.EqualFold(p.Provider, provider) {
+			continue
+		}
+		if best < 0 || len(prefix) > bestLen {
+			best, bestLen = i, len(prefix)
+		}
+	}
+	if best < 0 {
+		return Price{}, false
+	}
+	return t.Prices[best], true
+}
+
+// Cost is a priced token count in USD.
+type Cost struct {
+	Input  float64
+	Output float64
+}
+
+// Total returns input + output cost.
+func (c Cost) Total() float64 {
+	return c.Input + c.Output
+}
+
+// Usage is a token count to price.
+type Usage struct {
+	Input int
+	// CachedInput is the part of Input served from a prompt cache (OpenAI).
+	CachedInput int
+	// CacheRead and CacheWrite are prompt cache reads and writes counted on top of Input (Claude).
+	CacheRead  int
+	CacheWrite int
+	Output     int
+}
+
+// Cost prices a request. Cache reads (CachedInput and CacheRead) cost the cached input price,
+// cache writes the cache write price.
+func (p Price) Cost(u Usage) Cost {
+	cachedInput := u.CachedInput
+	if cachedInput > u.Input {
+		cachedInput = u.Input
+	}
+	cachedPrice := p.InputPerMTok
+	if p.CachedInputPerMTok != nil {
+		cachedPrice = *p.CachedInputPerMTok
+	}
+	writePrice := p.InputPerMTok
+	if p.CacheWritePerMTok != nil {
+		writePrice = *p.CacheWritePerMTok
+	}
+	input := float64(u.Input-cachedInput)*p.InputPerMTok +
+		float64(cachedInput+u.CacheRead)*cachedPrice +
+		float64(u.CacheWrite)*writePrice
+	return Cost{
+		Input:  input / 1e6,
+		Output: float64(u.Output) * p.OutputPerMTok / 1e6,
+	}
+}
+
+// FormatUSD renders a cost with enough precision for sub-cent amounts.
+func FormatUSD(v float64) string {
+	if v != 0 && v < 0.01 {
+		return fmt.Sprintf("$%.4f", v)
+	}
+	return fmt.Sprintf("$%.2f", v)
+}
@@ -0,0 +1,64 @@
+package pricing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLookup_LongestPrefixAndOverrides(t *testing.T) {
+	table, err := Builtin()
+	if err != nil {
+		t.Fatalf("Builtin: %v", err)
+	}
+
+	p, ok := table.Lookup("openai", "gpt-4o-mini-2024-07-18")
+	if !ok || p.Model != "gpt-4o-mini" {
+		t.Fatalf("expected gpt-4o-mini, got %+v (ok=%v)", p, ok)
+	}
+	p, ok = table.Lookup("openai", "openai/gpt-4o")
+	if !ok || p.Model != "gpt-4o" {
+		t.Fatalf("expected gpt-4o, got %+v (ok=%v)", p, ok)
+	}
+	if _, ok := table.Lookup("claude", "gpt-4o"); ok {
+		t.Fatalf("expected provider mismatch to not match")
+	}
+	if _, ok := table.Lookup("ollama", "qwen3"); ok {
+		t.Fatalf("expected unknown model to not match")
+	}
+
+	table = table.WithOverrides([]Price{{Model: "gpt-4o", InputPerMTok: 1, OutputPerMTok: 2}})
+	p, ok = table.Lookup("openai", "gpt-4o")
+	if !ok || p.InputPerMTok != 1 {
+		t.Fatalf("expected override to win, got %+v", p)
+	}
+
+	// Prefixes are compared after normalization, so padding or case cannot win a tie.
+	table = Table{Prices: []Price{
+		{Model: "  GPT-4  ", InputPerMTok: 1},
+		{Model: "gpt-4o", InputPerMTok: 2},
+	}}
+	p, ok = table.Lookup("openai", "gpt-4o")
+	if !ok || p.InputPerMTok != 2 {
+		t.Fatalf("expected the longer normalized prefix to win, got %+v", p)
+	}
+}
+
+func TestPrice_Cost(t *testing.T) {
+	cached := 0.5
+	p := Price{InputPerMTok: 2, OutputPerMTok: 10, CachedInputPerMTok: &cached}
+	c := p.Cost(Usage{Input: 1_000_000, CachedInput: 200_000, Output: 100_000})
+	if math.Abs(c.Input-1.7) > 1e-9 || math.Abs(c.Output-1.0) > 1e-9 || math.Abs(c.Total()-2.7) > 1e-9 {
+		t.Fatalf("unexpected cost: %+v", c)
+	}
+
+	// Claude-style cache reads and writes come on top of the input.
+	write := 2.5
+	p.CacheWritePerMTok = &write
+	c = p.Cost(Usage{Input: 100_000, CacheRead: 2_000_000, CacheWrite: 400_000})
+	if math.Abs(c.Input-(0.2+1.0+1.0)) > 1e-9 {
+		t.Fatalf("unexpected cache cost: %+v", c)
+	}
+	if got := FormatUSD(0.0012); got != "$0.0012" {
+		t.Fatalf("FormatUSD = %q", got)
+	}
+}
@@ -0,0 +1,197 @@
+// Package redact finds secrets (API keys, private keys, credentials) in the content sent to the
+// model and masks them before it leaves the machine.
+package redact
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Mode selects what happens to detected secrets.
+type Mode string
+
+const (
+	// ModeOff disables scanning.
+	ModeOff Mode = "off"
+	// ModeWarn reports secrets but sends the content unchanged.
+	ModeWarn Mode = "warn"
+	// ModeRedact replaces secrets with a [REDACTED:<rule>] marker (the default).
+	ModeRedact Mode = "redact"
+	// ModeBlock refuses to build the request while secrets are present.
+	ModeBlock Mode = "block"
+)
+
+// DefaultMode is used when neither a flag nor the config selects a mode.
+const DefaultMode = ModeRedact
+
+// ParseMode parses a mode name; "" yields DefaultMode.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return DefaultMode, nil
+	case ModeOff, ModeWarn, ModeRedact, ModeBlock:
+		return m, nil
+	}
+	return "", errors.Errorf("unknown redaction mode %q (expected off, warn, redact or block)", s)
+}
+
+// Rule is a named detector. If the pattern has a capture group named "secret", only that group is
+// the secret (e.g. the value of KEY=value); otherwise the whole match is.
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	// minEntropy, if set, drops matches whose secret has less Shannon entropy (bits per char).
+	minEntropy float64
+}
+
+// CustomRule is a user-defined detector (`redaction.patterns` in .pr-builder/config.yaml).
+type CustomRule struct {
+	Name  string `yaml:"name"`
+	Regex string `yaml:"regex"`
+}
+
+// BuiltinRules returns the built-in detectors.
+func BuiltinRules() []Rule {
+	return []Rule{
+		{Name: "private-key", Pattern: regexp.MustCompile(`-----BEGIN[A-Z ]*PRIVATE KEY-----[\s\S]*?(-----END[A-Z ]*PRIVATE KEY-----|$)`)},
+		{Name: "aws-access-key", Pattern: regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+		{Name: "github-token", Pattern: regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{40,})\b`)},
+		{Name: "anthropic-key", Pattern: regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_\-]{20,}`)},
+		{Name: "openai-key", Pattern: regexp.MustCompile(`\bsk-(proj-)?[A-Za-z0-9_\-]{20,}`)},
+		{Name: "stripe-key", Pattern: regexp.MustCompile(`\b[sr]k_(live|test)_[A-Za-z0-9]{16,}\b`)},
+		{Name: "slack-token", Pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9\-]{10,}`)},
+		{Name: "google-api-key", Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+		{Name: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]{10,}\.eyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}`)},
+		// KEY=value lines as in .env files, also inside diffs (+/- prefix) and YAML (KEY: value).
+		// Only upper-case names: `token = newToken()` in code is not a credential.
+		{Name: "env-assignment", Pattern: regexp.MustCompile(`(?m)^[+\- ]?[ \t]*(export[ \t]+)?[A-Z0-9_]*(SECRET|TOKEN|PASSWORD|PASSWD|API_?KEY|PRIVATE_KEY|ACCESS_KEY|CREDENTIALS?)[A-Z0-9_]*[ \t]*[=:][ \t]*["']?(?P<secret>[^\s"'#]{6,})`)},
+		// Quoted literals that look random (hashes in go.sum or commit SHAs are unquoted or hex-only).
+		{Name: "high-entropy-string", Pattern: regexp.MustCompile(`["'](?P<secret>[A-Za-z0-9+/=_\-]{24,})["']`), minEntropy: 4.0},
+	}
+}
+
+// Finding is one detected secret. It never contains the secret itself.
+type Finding struct {
+	Rule string `json:"rule" yaml:"rule"`
+	// Source is what contained the secret: a file path or a context item path.
+	Source string `json:"source" yaml:"source"`
+	// Line is the 1-based line in the scanned text (of the diff or file content).
+	Line int `json:"line" yaml:"line"`
+	// Masked is a hint such as "AKIA…(20 chars)".
+	Masked string `json:"masked" yaml:"masked"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d: %s %s", f.Source, f.Line, f.Rule, f.Masked)
+}
+
+// Scanner applies rules to text.
+type Scanner struct {
+	rules []Rule
+}
+
+// NewScanner returns a scanner with the built-in rules plus custom. Custom rules with an invalid
+// regex are an error.
+func NewScanner(custom []CustomRule) (*Scanner, error) {
+	rules := BuiltinRules()
+	for i, c := range custom {
+		name := strings.TrimSpace(c.Name)
+		if name == "" {
+			name = fmt.Sprintf("custom-%d", i+1)
+		}
+		re, err := regexp.Compile(c.Regex)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid redaction pattern %q", name)
+		}
+		rules = append(rules, Rule{Name: name, Pattern: re})
+	}
+	return &Scanner{rules: rules}, nil
+}
+
+// span is the byte range of a secret in the scanned text.
+type span struct {
+	start, end int
+	rule       string
+}
+
+// Scan finds the secrets in text (from source) and returns text with each of them replaced by
+// [REDACTED:<rule>], along with the findings. Overlapping matches are reported once.
+func (s *Scanner) Scan(source, text string) (string, []Finding) {
+	if s == nil || text == "" {
+		return text, nil
+	}
+	spans := []span{}
+	for _, r := range s.rules {
+		secretGroup := r.Pattern.SubexpIndex("secret")
+		for _, m := range r.Pattern.FindAllStringSubmatchIndex(text, -1) {
+			start, end := m[0], m[1]
+			if secretGroup > 0 && m[2*secretGroup] >= 0 {
+				start, end = m[2*secretGroup], m[2*secretGroup+1]
+			}
+			if start == end || (r.minEntropy > 0 && !looksRandom(text[start:end], r.minEntropy)) {
+				continue
+			}
+			spans = append(spans, span{start: start, end: end, rule: r.Name})
+		}
+	}
+	if len(spans) == 0 {
+		return text, nil
+	}
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var b strings.Builder
+	findings := []Finding{}
+	pos := 0
+	for _, sp := range spans {
+		if sp.start < pos {
+			continue // overlaps a secret already masked
+		}
+		b.WriteString(text[pos:sp.start])
+		b.WriteString("[REDACTED:" + sp.rule + "]")
+		findings = append(findings, Finding{
+			Rule:   sp.rule,
+			Source: source,
+			Line:   strings.Count(text[:sp.start], "\n") + 1,
+			Masked: mask(text[sp.start:sp.end]),
+		})
+		pos = sp.end
+	}
+	b.WriteString(text[pos:])
+	return b.String(), findings
+}
+
+// mask keeps the first 4 characters of a secret (enough to recognize the key type) and its length.
+func mask(secret string) string {
+	r := []rune(secret)
+	if len(r) <= 8 {
+		return fmt.Sprintf("(%d chars)", len(r))
+	}
+	return fmt.Sprintf("%s…(%d chars)", string(r[:4]), len(r))
+}
+
+// looksRandom reports whether s mixes letters and digits with at least minEntropy bits per char.
+// Hex-only strings (hashes, SHAs) are not considered random.
+func looksRandom(s string, minEntropy float64) bool {
+	if strings.Trim(s, "0123456789abcdefABCDEF") == "" {
+		return false
+	}
+	if !strings.ContainsAny(s, "0123456789") {
+		return false
+	}
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	entropy := 0.0
+	n := float64(len(s))
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= minEntropy
+}
@@ -7,7 +7,6 @@ import (
 
 	"github.com/go-go-golems/prescribe/internal/domain"
 	"github.com/go-go-golems/prescribe/internal/presets"
-	"github.com/go-go-golems/prescribe/internal/tokens"
 	"gopkg.in/yaml.v3"
 )
 
@@ -40,6 +39,36 @@ type Session struct {
 
 	// Prompt
 	Prompt PromptConfig `yaml:"prompt"`
+
+	// User-defined prompt template variables (passed through to custom templates)
+	Vars map[string]any `yaml:"vars,omitempty"`
+
+	// Token budget solver configuration
+	Budget *BudgetConfig `yaml:"budget,omitempty"`
+
+	// Multi-candidate generation configuration
+	Candidates *CandidatesConfig `yaml:"candidates,omitempty"`
+}
+
+// CandidatesConfig represents the persisted multi-candidate generation settings in the session.
+type CandidatesConfig struct {
+	Count        int       `yaml:"count,omitempty"`
+	Presets      []string  `yaml:"presets,omitempty"`
+	Temperatures []float64 `yaml:"temperatures,omitempty"`
+}
+
+// BudgetConfig represents the persisted token budget settings in the session.
+type BudgetConfig struct {
+	MaxTokens   int              `yaml:"max_tokens,omitempty"`
+	Priorities  []BudgetPriority `yaml:"priorities,omitempty"`
+	TestWeight  *int             `yaml:"test_weight,omitempty"`
+	ChurnWeight *int             `yaml:"churn_weight,omitempty"`
+}
+
+// BudgetPriority represents a path-pattern priority adjustment.
+type BudgetPriority struct {
+	Pattern string `yaml:"pattern"`
+	Weight  int    `yaml:"weight"`
 }
 
 // GitHistoryConfig represents the persisted git history settings in the session.
@@ -64,7 +93,7 @@ type GitContextItemConfig struct {
 type FileConfig struct {
 	Path     string `yaml:"path"`
 	Included bool   `yaml:"included"`
-	Mode     string `yaml:"mode"` // "diff", "full_before", "full_after", "full_both"
+	Mode     string `yaml:"mode"` // "diff", "diff_reduced", "numstat", "full_before", "full_after", "full_both"
 }
 
 // FilterConfig represents a filter in the session
@@ -91,6 +120,20 @@ type ContextConfig struct {
 type PromptConfig struct {
 	Preset   string `yaml:"preset,omitempty"`   // preset ID if using preset
 	Template string `yaml:"template,omitempty"` // custom template if not using preset
+
+	// Template variables of the embedded prompt (see domain.PromptVars)
+	Issue            string   `yaml:"issue,omitempty"`
+	AdditionalSystem string   `yaml:"additional_system,omitempty"`
+	Additional       []string `yaml:"additional,omitempty"`
+	WithoutFiles     *bool    `yaml:"without_files,omitempty"` // nil => true
+	Concise          bool     `yaml:"concise,omitempty"`
+	UseBullets       bool     `yaml:"use_bullets,omitempty"`
+	UseKeywords      bool     `yaml:"use_keywords,omitempty"`
+	Language         string   `yaml:"language,omitempty"` // output language, e.g. German or de
+	Tone             string   `yaml:"tone,omitempty"`     // builtin tone preset, e.g. end-users
+
+	// Values of the preset's declared parameters (only with preset)
+	PresetVars map[string]any `yaml:"preset_vars,omitempty"`
 }
 
 // NewSession creates a new session from PR data
@@ -137,7 +180,12 @@ func NewSession(data *domain.PRData) *Session {
 	// Convert files
 	for _, file := range data.ChangedFiles {
 		mode := "diff"
-		if file.Type == domain.FileTypeFull {
+		switch file.Type {
+		case domain.FileTypeDiffReduced, domain.FileTypeNumstat:
+			mode = string(file.Type)
+		case domain.FileTypeDiff:
+			// default mode
+		case domain.FileTypeFull:
 			switch file.Version {
 			case domain.FileVersionBefore:
 				mode = "full_before"
@@ -181,16 +229,64 @@ func NewSession(data *domain.PRData) *Session {
 		})
 	}
 
+	// Convert budget
+	if data.Budget != nil {
+		testWeight := data.Budget.TestWeight
+		churnWeight := data.Budget.ChurnWeight
+		session.Budget = &BudgetConfig{
+			MaxTokens:   data.Budget.MaxTokens,
+			TestWeight:  &testWeight,
+			ChurnWeight: &churnWeight,
+		}
+		for _, p := range data.Budget.Priorities {
+			session.Budget.Priorities = append(session.Budget.Priorities, BudgetPriority{Pattern: p.Pattern, Weight: p.Weight})
+		}
+	}
+
+	// Convert candidates
+	if data.Candidates != nil {
+		session.Candidates = &CandidatesConfig{
+			Count:        data.Candidates.Count,
+			Presets:      append([]string{}, data.Candidates.Presets...),
+			Temperatures: append([]float64{}, data.Candidates.Temperatures...),
+		}
+	}
+
 	// Convert prompt
 	if data.CurrentPreset != nil {
 		session.Prompt = PromptConfig{
 			Preset: data.CurrentPreset.ID,
 		}
+		if len(data.PresetVars) > 0 {
+			session.Prompt.PresetVars = make(map[string]any, len(data.PresetVars))
+			for k, v := range data.PresetVars {
+				session.Prompt.PresetVars[k] = v
+			}
+		}
 	} else {
 		session.Prompt = PromptConfig{
 			Template: data.CurrentPrompt,
 		}
 	}
+	vars := data.PromptVars
+	session.Prompt.Issue = vars.Issue
+	session.Prompt.AdditionalSystem = vars.AdditionalSystem
+	session.Prompt.Additional = append([]string(nil), vars.Additional...)
+	if vars.WithoutFiles != nil {
+		withoutFiles := *vars.WithoutFiles
+		session.Prompt.WithoutFiles = &withoutFiles
+	}
+	session.Prompt.Concise = vars.Concise
+	session.Prompt.UseBullets = vars.UseBullets
+	session.Prompt.UseKeywords = vars.UseKeywords
+	session.Prompt.Language = vars.Language
+	session.Prompt.Tone = vars.Tone
+	if len(vars.Vars) > 0 {
+		session.Vars = make(map[string]any, len(vars.Vars))
+		for k, v := range vars.Vars {
+			session.Vars[k] = v
+		}
+	}
 
 	return session
 }
@@ -217,6 +313,10 @@ func (s *Session) ApplyToData(data *domain.PRData, repoPath string) error {
 			switch fc.Mode {
 			case "diff":
 				file.Type = domain.FileTypeDiff
+			case "diff_reduced":
+				file.Type = domain.FileTypeDiffReduced
+			case "numstat":
+				file.Type = domain.FileTypeNumstat
 			case "full_before":
 				file.Type = domain.FileTypeFull
 				file.Version = domain.FileVersionBefore
@@ -230,22 +330,7 @@ func (s *Session) ApplyToData(data *domain.PRData, repoPath string) error {
 
 			// Recompute tokens based on selected mode so the TUI totals are consistent
 			// immediately after loading a session.
-			switch file.Type {
-			case domain.FileTypeDiff:
-				file.Tokens = tokens.Count(file.Diff)
-			case domain.FileTypeFull:
-				switch file.Version {
-				case domain.FileVersionBefore:
-					file.Tokens = tokens.Count(file.FullBefore)
-				case domain.FileVersionAfter:
-					file.Tokens = tokens.Count(file.FullAfter)
-				case domain.FileVersionBoth:
-					file.Tokens = tokens.Count(file.FullBefore) + tokens.Count(file.FullAfter)
-				default:
-					// Best effort: count both if version is unspecified.
-					file.Tokens = tokens.Count(file.FullBefore) + tokens.Count(file.FullAfter)
-				}
-			}
+			file.RecountTokens(data.TokenCounter)
 		}
 	}
 
@@ -270,7 +355,7 @@ func (s *Session) ApplyToData(data *domain.PRData, repoPath string) error {
 	// Apply context
 	data.AdditionalContext = make([]domain.ContextItem, 0)
 	for _, cc := range s.Context {
-		tokens_ := tokens.Count(cc.Content)
+		tokens_ := data.TokenCounter.Count(cc.Content)
 		data.AdditionalContext = append(data.AdditionalContext, domain.ContextItem{
 			Type:    domain.ContextType(cc.Type),
 			Path:    cc.Path,
@@ -305,12 +390,67 @@ func (s *Session) ApplyToData(data *domain.PRData, repoPath string) error {
 		})
 	}
 
+	// Apply budget config (missing fields fall back to defaults)
+	data.Budget = nil
+	if s.Budget != nil {
+		cfg := domain.DefaultBudgetConfig()
+		cfg.MaxTokens = s.Budget.MaxTokens
+		if s.Budget.TestWeight != nil {
+			cfg.TestWeight = *s.Budget.TestWeight
+		}
+		if s.Budget.ChurnWeight != nil {
+			cfg.ChurnWeight = *s.Budget.ChurnWeight
+		}
+		for _, p := range s.Budget.Priorities {
+			cfg.Priorities = append(cfg.Priorities, domain.BudgetPriority{Pattern: p.Pattern, Weight: p.Weight})
+		}
+		data.Budget = &cfg
+	}
+
+	// Apply candidates config (missing count falls back to the default)
+	data.Candidates = nil
+	if s.Candidates != nil {
+		cfg := domain.DefaultCandidatesConfig()
+		if s.Candidates.Count > 0 {
+			cfg.Count = s.Candidates.Count
+		}
+		cfg.Presets = append(cfg.Presets, s.Candidates.Presets...)
+		cfg.Temperatures = append(cfg.Temperatures, s.Candidates.Temperatures...)
+		data.Candidates = &cfg
+	}
+
+	// Apply prompt template variables
+	data.PromptVars = domain.PromptVars{
+		Issue:            s.Prompt.Issue,
+		AdditionalSystem: s.Prompt.AdditionalSystem,
+		Additional:       append([]string(nil), s.Prompt.Additional...),
+		WithoutFiles:     s.Prompt.WithoutFiles,
+		Concise:          s.Prompt.Concise,
+		UseBullets:       s.Prompt.UseBullets,
+		UseKeywords:      s.Prompt.UseKeywords,
+		Language:         s.Prompt.Language,
+		Tone:             s.Prompt.Tone,
+	}
+	if len(s.Vars) > 0 {
+		data.PromptVars.Vars = make(map[string]any, len(s.Vars))
+		for k, v := range s.Vars {
+			data.PromptVars.Vars[k] = v
+		}
+	}
+
 	// Apply prompt
+	data.PresetVars = nil
 	if s.Prompt.Preset != "" {
 		// Find and apply preset (checks builtin, project, and global presets)
 		preset, err := presets.ResolvePromptPreset(s.Prompt.Preset, repoPath)
 		if err == nil {
 			data.SetPrompt(preset.Template, preset)
+			if len(s.Prompt.PresetVars) > 0 {
+				data.PresetVars = make(map[string]any, len(s.Prompt.PresetVars))
+				for k, v := range s.Prompt.PresetVars {
+					data.PresetVars[k] = v
+				}
+			}
 			return nil
 		}
 		// If preset not found, fall through to template if available
@@ -0,0 +1,44 @@
+package tokens
+
+import "strings"
+
+// contextWindows maps model name prefixes to context window sizes (in tokens).
+// More specific prefixes must come first.
+var contextWindows = []struct {
+	prefix string
+	tokens int
+}{
+	{"gpt-5", 400000},
+	{"gpt-4.1", 1047576},
+	{"gpt-4o", 128000},
+	{"gpt-4-turbo", 128000},
+	{"gpt-4", 8192},
+	{"gpt-3.5-turbo", 16385},
+	{"o1-mini", 128000},
+	{"o1", 200000},
+	{"o3", 200000},
+	{"o4-mini", 200000},
+	{"claude-", 200000},
+	{"gemini-1.5-pro", 2097152},
+	{"gemini-", 1048576},
+	{"mistral-large", 128000},
+	{"llama3", 8192},
+}
+
+// ContextWindow returns the context window size for a model name, or 0 if unknown.
+// Provider prefixes like "openai/" are ignored.
+func ContextWindow(model string) int {
+	m := strings.ToLower(strings.TrimSpace(model))
+	if i := strings.LastIndex(m, "/"); i >= 0 {
+		m = m[i+1:]
+	}
+	if m == "" {
+		return 0
+	}
+	for _, cw := range contextWindows {
+		if strings.HasPrefix(m, cw.prefix) {
+			return cw.tokens
+		}
+	}
+	return 0
+}
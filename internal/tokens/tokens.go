@@ -1,23 +1,98 @@
 package tokens
 
 import (
-	"os"
 	"strings"
 	"sync"
 
 	"github.com/tiktoken-go/tokenizer"
 )
 
-// Count returns the token count for s using a real tokenizer (tiktoken-style BPE).
+// Counter counts tokens with one tokenizer encoding (tiktoken-style BPE).
 //
 // This is intended for "preflight" budgeting in the prescribe TUI, i.e. counting
 // tokens before calling any provider. It is not provider-billing-authoritative.
 //
-// Encoding selection:
-// - default: cl100k_base
-// - override with PRESCRIBE_TOKEN_ENCODING (supported: cl100k_base, o200k_base, r50k_base, p50k_base, p50k_edit)
-func Count(s string) int {
-	codec := getCodec()
+// Counters are cheap values: codecs are loaded lazily and shared per encoding, so
+// callers can build one per model (see ForModel) and switch models at runtime.
+type Counter struct {
+	encoding tokenizer.Encoding
+	model    string
+	// approximate is set when the model's provider tokenizer is not available locally
+	// and encoding is only a stand-in (e.g. Claude, Gemini).
+	approximate bool
+}
+
+// DefaultEncoding is used when no model is configured or the model is unknown.
+const DefaultEncoding = tokenizer.Cl100kBase
+
+// NewCounter returns a counter for an explicit encoding.
+func NewCounter(encoding tokenizer.Encoding) *Counter {
+	return &Counter{encoding: encoding}
+}
+
+// Default returns the counter used when no model is configured.
+func Default() *Counter {
+	return NewCounter(DefaultEncoding)
+}
+
+// modelEncodings maps model name prefixes to encodings. More specific prefixes must come first.
+var modelEncodings = []struct {
+	prefix      string
+	encoding    tokenizer.Encoding
+	approximate bool
+}{
+	{"gpt-5", tokenizer.O200kBase, false},
+	{"gpt-4.1", tokenizer.O200kBase, false},
+	{"gpt-4o", tokenizer.O200kBase, false},
+	{"chatgpt-4o", tokenizer.O200kBase, false},
+	{"o1", tokenizer.O200kBase, false},
+	{"o3", tokenizer.O200kBase, false},
+	{"o4", tokenizer.O200kBase, false},
+	{"gpt-4", tokenizer.Cl100kBase, false},
+	{"gpt-3.5", tokenizer.Cl100kBase, false},
+	{"text-embedding-3", tokenizer.Cl100kBase, false},
+	{"text-davinci-003", tokenizer.P50kBase, false},
+	{"text-davinci-002", tokenizer.P50kBase, false},
+	{"davinci", tokenizer.R50kBase, false},
+	{"claude-", tokenizer.Cl100kBase, true},
+	{"gemini-", tokenizer.O200kBase, true},
+}
+
+// providerEncodings is the fallback when the model name is unknown, keyed by provider/API type.
+var providerEncodings = map[string]struct {
+	encoding    tokenizer.Encoding
+	approximate bool
+}{
+	"openai":    {tokenizer.O200kBase, false},
+	"claude":    {tokenizer.Cl100kBase, true},
+	"anthropic": {tokenizer.Cl100kBase, true},
+	"gemini":    {tokenizer.O200kBase, true},
+}
+
+// ForModel selects the counter for a provider (API type, e.g. "openai", "claude") and model name.
+// The model table wins over the provider fallback; unknown models use DefaultEncoding.
+// Provider prefixes in the model name like "openai/" are ignored.
+func ForModel(provider, model string) *Counter {
+	m := strings.ToLower(strings.TrimSpace(model))
+	if i := strings.LastIndex(m, "/"); i >= 0 {
+		m = m[i+1:]
+	}
+	if m != "" {
+		for _, me := range modelEncodings {
+			if strings.HasPrefix(m, me.prefix) {
+				return &Counter{encoding: me.encoding, model: model, approximate: me.approximate}
+			}
+		}
+	}
+	if pe, ok := providerEncodings[strings.ToLower(strings.TrimSpace(provider))]; ok {
+		return &Counter{encoding: pe.encoding, model: model, approximate: pe.approximate}
+	}
+	return &Counter{encoding: DefaultEncoding, model: model, approximate: m != ""}
+}
+
+// Count returns the token count for s. A nil counter uses DefaultEncoding.
+func (c *Counter) Count(s string) int {
+	codec := getCodec(c.Encoding())
 	if codec == nil {
 		// Fallback: keep old behavior if tokenizer init fails for any reason.
 		return len(s) / 4
@@ -31,49 +106,58 @@ func Count(s string) int {
 	return n
 }
 
-func EncodingName() string {
-	_, name := getCodecAndName()
-	return name
+// Encoding returns the encoding name (e.g. "cl100k_base").
+func (c *Counter) Encoding() tokenizer.Encoding {
+	if c == nil || c.encoding == "" {
+		return DefaultEncoding
+	}
+	return c.encoding
 }
 
-var (
-	codecOnce sync.Once
-	codec     tokenizer.Codec
-	codecName string
-)
+// Model returns the model the counter was selected for, or "" for explicit/default counters.
+func (c *Counter) Model() string {
+	if c == nil {
+		return ""
+	}
+	return c.model
+}
 
-func getCodec() tokenizer.Codec {
-	c, _ := getCodecAndName()
-	return c
+// Approximate reports whether the encoding only approximates the model's real tokenizer.
+func (c *Counter) Approximate() bool {
+	return c != nil && c.approximate
 }
 
-func getCodecAndName() (tokenizer.Codec, string) {
-	codecOnce.Do(func() {
-		enc := tokenizer.Cl100kBase
-		if v, ok := os.LookupEnv("PRESCRIBE_TOKEN_ENCODING"); ok {
-			switch strings.TrimSpace(strings.ToLower(v)) {
-			case string(tokenizer.O200kBase):
-				enc = tokenizer.O200kBase
-			case string(tokenizer.Cl100kBase):
-				enc = tokenizer.Cl100kBase
-			case string(tokenizer.R50kBase):
-				enc = tokenizer.R50kBase
-			case string(tokenizer.P50kBase):
-				enc = tokenizer.P50kBase
-			case string(tokenizer.P50kEdit):
-				enc = tokenizer.P50kEdit
-			}
-		}
+// Label describes the tokenizer for output, e.g. "o200k_base (gpt-4o)" or
+// "cl100k_base (approx. for claude-sonnet-4)".
+func (c *Counter) Label() string {
+	enc := string(c.Encoding())
+	model := c.Model()
+	switch {
+	case model == "":
+		return enc
+	case c.Approximate():
+		return enc + " (approx. for " + model + ")"
+	default:
+		return enc + " (" + model + ")"
+	}
+}
 
-		c, err := tokenizer.Get(enc)
-		if err != nil {
-			// Leave codec nil; Count will fall back to heuristic.
-			codec = nil
-			codecName = ""
-			return
-		}
-		codec = c
-		codecName = c.GetName()
-	})
-	return codec, codecName
+var (
+	codecsMu sync.Mutex
+	codecs   = map[tokenizer.Encoding]tokenizer.Codec{}
+)
+
+func getCodec(enc tokenizer.Encoding) tokenizer.Codec {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	if c, ok := codecs[enc]; ok {
+		return c
+	}
+	c, err := tokenizer.Get(enc)
+	if err != nil {
+		// Cache the failure too; Count will fall back to heuristic.
+		c = nil
+	}
+	codecs[enc] = c
+	return c
 }
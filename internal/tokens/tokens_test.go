@@ -0,0 +1,49 @@
+package tokens
+
+import (
+	"testing"
+
+	"github.com/tiktoken-go/tokenizer"
+)
+
+func TestForModel(t *testing.T) {
+	cases := []struct {
+		provider    string
+		model       string
+		encoding    tokenizer.Encoding
+		approximate bool
+	}{
+		{"openai", "gpt-4o-mini", tokenizer.O200kBase, false},
+		{"openai", "openai/gpt-4.1", tokenizer.O200kBase, false},
+		{"openai", "gpt-4-turbo", tokenizer.Cl100kBase, false},
+		{"openai", "o3-mini", tokenizer.O200kBase, false},
+		{"claude", "claude-sonnet-4-20250514", tokenizer.Cl100kBase, true},
+		{"openai", "some-new-model", tokenizer.O200kBase, false},
+		{"claude", "", tokenizer.Cl100kBase, true},
+		{"", "", DefaultEncoding, false},
+		{"ollama", "qwen3", DefaultEncoding, true},
+	}
+	for _, tc := range cases {
+		c := ForModel(tc.provider, tc.model)
+		if c.Encoding() != tc.encoding || c.Approximate() != tc.approximate {
+			t.Errorf("ForModel(%q, %q) = %s approx=%v, want %s approx=%v",
+				tc.provider, tc.model, c.Encoding(), c.Approximate(), tc.encoding, tc.approximate)
+		}
+	}
+}
+
+func TestCounter_Label(t *testing.T) {
+	if got := Default().Label(); got != "cl100k_base" {
+		t.Fatalf("Default().Label() = %q", got)
+	}
+	if got := ForModel("openai", "gpt-4o").Label(); got != "o200k_base (gpt-4o)" {
+		t.Fatalf("gpt-4o label = %q", got)
+	}
+	if got := ForModel("claude", "claude-3-5-haiku").Label(); got != "cl100k_base (approx. for claude-3-5-haiku)" {
+		t.Fatalf("claude label = %q", got)
+	}
+	var nilCounter *Counter
+	if nilCounter.Count("hello world") != Default().Count("hello world") {
+		t.Fatalf("nil counter should count with the default encoding")
+	}
+}
@@ -0,0 +1,28 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/go-go-golems/prescribe/internal/domain"
+)
+
+// maxDeniedLines caps the denied files listed on the main screen.
+const maxDeniedLines = 3
+
+// deniedLines list the changed files dropped by .prescribeignore. Unlike filtered files they can
+// not be shown or included, so they get their own block instead of a place in the file list.
+func deniedLines(data *domain.PRData) []string {
+	denied := data.DeniedFiles
+	if len(denied) == 0 {
+		return nil
+	}
+	lines := []string{fmt.Sprintf("DENIED by .prescribeignore (never read or sent): %d file(s)", len(denied))}
+	for i, f := range denied {
+		if i == maxDeniedLines-1 && len(denied) > maxDeniedLines {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(denied)-i))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("  ⊘ %s  (%s)", f.Path, f.Rule))
+	}
+	return lines
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-go-golems/prescribe/internal/domain"
+)
+
+func TestMain_ShowsDeniedFiles(t *testing.T) {
+	m := newTestModel(t)
+	data := m.ctrl.GetData()
+	data.ChangedFiles = []domain.FileChange{{Path: "main.go", Included: true}}
+	data.DeniedFiles = []domain.DeniedFile{
+		{Path: ".env", Rule: ".prescribeignore:1: .env"},
+		{Path: "secrets/a.key", Rule: ".prescribeignore:2: secrets"},
+		{Path: "secrets/b.key", Rule: ".prescribeignore:2: secrets"},
+		{Path: "secrets/c.key", Rule: ".prescribeignore:2: secrets"},
+	}
+
+	m = update(t, m, tea.WindowSizeMsg{Width: 120, Height: 40})
+	view := m.view()
+	if !strings.Contains(view, "1 visible, 0 filtered, 4 denied") || !strings.Contains(view, "DENIED by .prescribeignore") {
+		t.Fatalf("expected the denied files on the main screen, got:\n%s", view)
+	}
+	if !strings.Contains(view, ".env  (.prescribeignore:1: .env)") || !strings.Contains(view, "... and 2 more") {
+		t.Fatalf("expected the capped denied list, got:\n%s", view)
+	}
+	if got := m.headerHeight(); got != 8+len(deniedLines(data))+1 {
+		t.Fatalf("unexpected header height %d", got)
+	}
+}
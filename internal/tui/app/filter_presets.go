@@ -18,8 +18,12 @@ func loadFilterPresetsCmd(ctrl *controller.Controller) tea.Cmd {
 		if err != nil {
 			return events.FilterPresetsLoadFailedMsg{Err: err}
 		}
+		builtin, err := controller.LoadBuiltinFilterPresets()
+		if err != nil {
+			return events.FilterPresetsLoadFailedMsg{Err: err}
+		}
 
-		presets := make([]events.FilterPresetSummary, 0, len(project)+len(global))
+		presets := make([]events.FilterPresetSummary, 0, len(project)+len(global)+len(builtin))
 		for _, p := range project {
 			presets = append(presets, events.FilterPresetSummary{
 				ID:          p.ID,
@@ -37,10 +41,20 @@ func loadFilterPresetsCmd(ctrl *controller.Controller) tea.Cmd {
 			})
 		}
 
-		// Stable ordering: project first, then global; within each, sort by Name then ID.
+		for _, p := range builtin {
+			presets = append(presets, events.FilterPresetSummary{
+				ID:          p.ID,
+				Name:        p.Name,
+				Description: p.Description,
+				Location:    "builtin",
+			})
+		}
+
+		// Stable ordering: project first, then global, then builtin; within each, sort by Name then ID.
+		locationRank := map[string]int{"project": 0, "global": 1, "builtin": 2}
 		sort.SliceStable(presets, func(i, j int) bool {
 			if presets[i].Location != presets[j].Location {
-				return presets[i].Location < presets[j].Location
+				return locationRank[presets[i].Location] < locationRank[presets[j].Location]
 			}
 			if presets[i].Name != presets[j].Name {
 				return presets[i].Name < presets[j].Name
@@ -0,0 +1,154 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-go-golems/prescribe/internal/tui/events"
+	"github.com/pkg/errors"
+)
+
+// generation is the inference run in flight while the app is in ModeGenerating.
+type generation struct {
+	id      int
+	label   string
+	started time.Time
+	// from is the mode to return to when the run is cancelled or times out.
+	from   Mode
+	cancel context.CancelFunc
+
+	// stream is set for runs that stream their output (see startStreamingGeneration).
+	stream   *streamMailbox
+	streamed streamUpdate
+}
+
+// generationDoneMsg wraps the result of a run so results of cancelled runs can be dropped.
+type generationDoneMsg struct {
+	id       int
+	timedOut bool
+	msg      tea.Msg
+}
+
+// WithGenerationTimeout returns a copy of m whose generations are cancelled after d (0 => no timeout).
+func (m Model) WithGenerationTimeout(d time.Duration) Model {
+	m.genTimeout = d
+	return m
+}
+
+// startGeneration switches to ModeGenerating and runs run with a context that is cancelled by
+// esc/ctrl+c (see cancelGeneration) or when the generation timeout expires. While a cancelled run
+// is still stopping, nothing is started (m.gen stays nil).
+func (m Model) startGeneration(label string, run func(ctx context.Context) tea.Msg) (Model, tea.Cmd) {
+	if m.stopping != 0 {
+		var cmd tea.Cmd
+		m.status, cmd = m.status.Update(events.ShowToastMsg{
+			Text:     "The cancelled generation is still stopping, try again in a moment",
+			Level:    events.ToastWarning,
+			Duration: 3 * time.Second,
+		})
+		return m, cmd
+	}
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if m.genTimeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), m.genTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	m.genSeq++
+	id := m.genSeq
+	m.gen = &generation{
+		id:      id,
+		label:   label,
+		started: m.deps.Now(),
+		from:    m.mode,
+		cancel:  cancel,
+	}
+	m.mode = ModeGenerating
+	m.recomputeLayout()
+
+	return m, tea.Batch(m.spinner.Tick, func() tea.Msg {
+		defer cancel()
+		msg := run(ctx)
+		return generationDoneMsg{
+			id:       id,
+			timedOut: errors.Is(ctx.Err(), context.DeadlineExceeded),
+			msg:      msg,
+		}
+	})
+}
+
+// cancelGeneration aborts the run in flight and returns to the screen it was started from. The
+// run is tracked in m.stopping until its result arrives.
+func (m Model) cancelGeneration() (Model, tea.Cmd) {
+	if m.gen == nil {
+		return m, nil
+	}
+	m.gen.cancel()
+	m.stopping = m.gen.id
+	m = m.endGeneration(m.gen.from)
+
+	var cmd tea.Cmd
+	m.status, cmd = m.status.Update(events.ShowToastMsg{
+		Text:     "Generation cancelled",
+		Level:    events.ToastWarning,
+		Duration: 3 * time.Second,
+	})
+	return m, cmd
+}
+
+// finishGeneration handles the result of a run. Results of cancelled runs are dropped; a run that
+// failed because the generation timeout expired returns to the screen it was started from.
+func (m Model) finishGeneration(msg generationDoneMsg) (tea.Model, tea.Cmd) {
+	if msg.id == m.stopping {
+		m.stopping = 0
+	}
+	if m.gen == nil || m.gen.id != msg.id {
+		return m, nil
+	}
+	if msg.timedOut && isGenerationFailure(msg.msg) {
+		m = m.endGeneration(m.gen.from)
+		var cmd tea.Cmd
+		m.status, cmd = m.status.Update(events.ShowToastMsg{
+			Text:     "Generation timed out after " + m.genTimeout.String(),
+			Level:    events.ToastError,
+			Duration: 5 * time.Second,
+		})
+		return m, cmd
+	}
+	m = m.endGeneration(m.mode)
+	return m.Update(msg.msg)
+}
+
+// endGeneration leaves ModeGenerating for mode, dropping any partially streamed text.
+func (m Model) endGeneration(mode Mode) Model {
+	if m.gen.stream != nil {
+		m.result.SetContent(m.generatedDesc)
+		m.status.SetInfo(m.costInfo)
+	}
+	m.gen = nil
+	m.mode = mode
+	m.recomputeLayout()
+	return m
+}
+
+// generationElapsed is the time since the run in flight started, rounded to seconds.
+func (m Model) generationElapsed() time.Duration {
+	if m.gen == nil {
+		return 0
+	}
+	return m.deps.Now().Sub(m.gen.started).Truncate(time.Second)
+}
+
+func isGenerationFailure(msg tea.Msg) bool {
+	switch msg.(type) {
+	case events.DescriptionGenerationFailedMsg,
+		events.DescriptionRefineFailedMsg,
+		events.SectionRegenerationFailedMsg,
+		events.CandidatesGenerationFailedMsg:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-go-golems/prescribe/internal/controller"
+	"github.com/go-go-golems/prescribe/internal/tui/events"
+)
+
+type fakeDeps struct{ now time.Time }
+
+func (d fakeDeps) Now() time.Time                    { return d.now }
+func (fakeDeps) ClipboardWriteAll(text string) error { return nil }
+
+func newTestModel(t *testing.T) Model {
+	t.Helper()
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatalf("mkdir .git: %v", err)
+	}
+	ctrl, err := controller.NewController(repo)
+	if err != nil {
+		t.Fatalf("NewController: %v", err)
+	}
+	return New(ctrl, fakeDeps{now: time.Unix(0, 0)})
+}
+
+// blockingRun waits until the generation is cancelled, like an inference that honors its context.
+func blockingRun(ctx context.Context) tea.Msg {
+	<-ctx.Done()
+	return events.DescriptionGenerationFailedMsg{Err: ctx.Err()}
+}
+
+// runGeneration executes the command returned by startGeneration and returns its result.
+func runGeneration(t *testing.T, cmd tea.Cmd) generationDoneMsg {
+	t.Helper()
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok {
+		t.Fatalf("expected a batch command")
+	}
+	for _, c := range batch {
+		if done, ok := c().(generationDoneMsg); ok {
+			return done
+		}
+	}
+	t.Fatalf("batch has no generation command")
+	return generationDoneMsg{}
+}
+
+func update(t *testing.T, m Model, msg tea.Msg) Model {
+	t.Helper()
+	next, _ := m.Update(msg)
+	return next.(Model)
+}
+
+func TestGeneration_EscCancelsAndDropsResult(t *testing.T) {
+	m, cmd := newTestModel(t).startGeneration("Generating PR description...", blockingRun)
+	if m.mode != ModeGenerating {
+		t.Fatalf("expected ModeGenerating, got %v", m.mode)
+	}
+
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.mode != ModeMain || m.gen != nil {
+		t.Fatalf("expected cancel to return to ModeMain, got mode %v (gen %v)", m.mode, m.gen)
+	}
+	if !strings.Contains(m.status.View(), "Generation cancelled") {
+		t.Fatalf("expected a cancel toast, got %q", m.status.View())
+	}
+
+	// The run observes the cancellation; its late failure must not open the result screen.
+	done := runGeneration(t, cmd)
+	m = update(t, m, done)
+	if m.mode != ModeMain || m.err != nil {
+		t.Fatalf("expected the cancelled result to be dropped, got mode %v err %v", m.mode, m.err)
+	}
+}
+
+func TestGeneration_NoNewRunUntilTheCancelledOneStops(t *testing.T) {
+	m, cmd := newTestModel(t).startGeneration("Generating PR description...", blockingRun)
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+
+	// The cancelled run may still touch the controller: a new trigger is refused until it is done.
+	m, _ = m.startGeneration("Generating candidates...", blockingRun)
+	if m.mode != ModeMain || m.gen != nil {
+		t.Fatalf("expected the new run to be refused, got mode %v", m.mode)
+	}
+	if !strings.Contains(m.status.View(), "still stopping") {
+		t.Fatalf("expected a stopping toast, got %q", m.status.View())
+	}
+
+	m = update(t, m, runGeneration(t, cmd))
+	m, _ = m.startGeneration("Generating candidates...", blockingRun)
+	if m.mode != ModeGenerating || m.gen == nil {
+		t.Fatalf("expected a new run once the cancelled one stopped, got mode %v", m.mode)
+	}
+	m.gen.cancel()
+}
+
+func TestGeneration_CtrlCCancelsInsteadOfQuitting(t *testing.T) {
+	m, _ := newTestModel(t).startGeneration("Generating PR description...", blockingRun)
+	// Quitting would leave the model in ModeGenerating.
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyCtrlC})
+	if m.mode != ModeMain || m.gen != nil {
+		t.Fatalf("expected ctrl+c to cancel and return to ModeMain, got %v", m.mode)
+	}
+}
+
+func TestGeneration_Timeout(t *testing.T) {
+	m, cmd := newTestModel(t).WithGenerationTimeout(10*time.Millisecond).
+		startGeneration("Generating PR description...", blockingRun)
+
+	done := runGeneration(t, cmd)
+	if !done.timedOut {
+		t.Fatalf("expected the run to time out")
+	}
+	m = update(t, m, done)
+	if m.mode != ModeMain || m.gen != nil {
+		t.Fatalf("expected a timeout to return to ModeMain, got mode %v", m.mode)
+	}
+	if !strings.Contains(m.status.View(), "timed out after 10ms") {
+		t.Fatalf("expected a timeout toast, got %q", m.status.View())
+	}
+}
+
+func TestGeneration_SuccessShowsResult(t *testing.T) {
+	m, cmd := newTestModel(t).startGeneration("Generating PR description...", func(context.Context) tea.Msg {
+		return events.DescriptionGeneratedMsg{Text: "title: done"}
+	})
+	m.deps = fakeDeps{now: time.Unix(12, 0)}
+	if got := m.generationStatusText(); !strings.Contains(got, "Generating PR description... 12s") {
+		t.Fatalf("expected elapsed time in status, got %q", got)
+	}
+
+	m = update(t, m, runGeneration(t, cmd))
+	if m.mode != ModeResult || m.generatedDesc != "title: done" || m.gen != nil {
+		t.Fatalf("expected the result screen, got mode %v desc %q", m.mode, m.generatedDesc)
+	}
+}
+
+func TestStreamMailbox_KeepsLatestUpdate(t *testing.T) {
+	box := newStreamMailbox()
+	box.offer(streamUpdate{text: "a", tokens: 1})
+	box.offer(streamUpdate{text: "ab", tokens: 2})
+	box.close()
+	box.offer(streamUpdate{text: "abc", tokens: 3}) // after close: dropped, no panic
+
+	msg := waitForStreamCmd(7, box)()
+	got, ok := msg.(generationStreamMsg)
+	if !ok || got.id != 7 || got.text != "ab" || got.tokens != 2 {
+		t.Fatalf("expected the latest update, got %#v", msg)
+	}
+	if msg := waitForStreamCmd(7, box)(); msg != nil {
+		t.Fatalf("expected nil once the stream is closed, got %#v", msg)
+	}
+}
+
+func TestGeneration_StreamUpdatesFollowCurrentRun(t *testing.T) {
+	m, _ := newTestModel(t).startGeneration("Generating PR description...", blockingRun)
+	m.gen.stream = newStreamMailbox()
+	m.deps = fakeDeps{now: time.Unix(2, 0)}
+	m = update(t, m, tea.WindowSizeMsg{Width: 80, Height: 24})
+
+	m = update(t, m, generationStreamMsg{id: m.gen.id, streamUpdate: streamUpdate{text: "title: Add", tokens: 10}})
+	if m.gen.streamed.text != "title: Add" || !strings.Contains(m.view(), "title: Add") {
+		t.Fatalf("expected the streamed text in the generating view, got %q", m.view())
+	}
+	if got := m.streamInfoText(); got != "Streaming: 10 tokens | 5.0 tok/s" {
+		t.Fatalf("unexpected stream info %q", got)
+	}
+
+	// Updates of an earlier (cancelled) run are ignored.
+	m = update(t, m, generationStreamMsg{id: m.gen.id - 1, streamUpdate: streamUpdate{text: "stale", tokens: 99}})
+	if m.gen.streamed.text != "title: Add" {
+		t.Fatalf("expected a stale update to be ignored, got %q", m.gen.streamed.text)
+	}
+
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if strings.Contains(m.result.View(), "title: Add") {
+		t.Fatalf("expected cancel to drop the partial text")
+	}
+}
+
+func TestCostEstimate_DebouncedAndDeferredDuringGeneration(t *testing.T) {
+	m := newTestModel(t)
+	m.costInfo = "stale"
+	m, _ = m.scheduleCostEstimate()
+	m, _ = m.scheduleCostEstimate()
+
+	// Only the last change of a burst is estimated.
+	m = update(t, m, costEstimateDueMsg{seq: m.costSeq - 1})
+	if m.costInfo != "stale" {
+		t.Fatalf("expected an outdated tick to be ignored, got %q", m.costInfo)
+	}
+
+	// While a run changes the controller, the estimate waits.
+	m, cmd := m.startGeneration("Generating PR description...", blockingRun)
+	next, tick := m.Update(costEstimateDueMsg{seq: m.costSeq})
+	m = next.(Model)
+	if m.costInfo != "stale" || tick == nil {
+		t.Fatalf("expected the estimate to be deferred, got %q", m.costInfo)
+	}
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	m = update(t, m, runGeneration(t, cmd))
+
+	// No files are included, so the estimate is hidden.
+	m = update(t, m, costEstimateDueMsg{seq: m.costSeq})
+	if m.costInfo != "" {
+		t.Fatalf("expected the estimate to run, got %q", m.costInfo)
+	}
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-go-golems/prescribe/internal/controller"
+	"github.com/go-go-golems/prescribe/internal/tui/events"
+)
+
+// loadHistoryCmd reads the generation history (.pr-builder/history/) for the history browser.
+func loadHistoryCmd(ctrl *controller.Controller) tea.Cmd {
+	return func() tea.Msg {
+		records, err := ctrl.HistoryStore().List()
+		if err != nil {
+			return events.HistoryLoadFailedMsg{Err: err}
+		}
+		entries := make([]events.HistoryEntry, 0, len(records))
+		for _, r := range records {
+			preview, err := r.DiffText()
+			if err != nil {
+				preview = r.Raw
+			}
+			entries = append(entries, events.HistoryEntry{
+				ID:         r.ID,
+				CreatedAt:  r.CreatedAt,
+				Kind:       string(r.Kind),
+				Model:      r.Model,
+				Title:      r.Title(),
+				Preview:    preview,
+				ParseError: r.ParseError,
+			})
+		}
+		return events.HistoryLoadedMsg{Entries: entries}
+	}
+}
+
+// restoreHistoryCmd makes the record id the current generation, like `prescribe history restore`.
+func restoreHistoryCmd(ctrl *controller.Controller, id string) tea.Cmd {
+	return func() tea.Msg {
+		r, path, err := ctrl.RestoreHistory(id)
+		if err != nil {
+			return events.HistoryRestoreFailedMsg{Err: err}
+		}
+		return events.HistoryRestoredMsg{ID: r.ID, Text: r.Raw, Path: path}
+	}
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-go-golems/prescribe/internal/tui/events"
+)
+
+func TestHistoryBrowser(t *testing.T) {
+	m := update(t, newTestModel(t), tea.WindowSizeMsg{Width: 100, Height: 30})
+
+	m = update(t, m, events.HistoryLoadedMsg{})
+	if m.mode != ModeMain {
+		t.Fatalf("expected an empty history to stay on the main screen, got %v", m.mode)
+	}
+
+	m = update(t, m, events.HistoryLoadedMsg{Entries: []events.HistoryEntry{
+		{ID: "b", CreatedAt: time.Unix(60, 0), Kind: "refine", Title: "Newer", Preview: "title: Newer\n"},
+		{ID: "a", CreatedAt: time.Unix(0, 0), Kind: "generate", Title: "Older", Preview: "title: Older\n"},
+	}})
+	if m.mode != ModeHistory {
+		t.Fatalf("expected ModeHistory, got %v", m.mode)
+	}
+
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyDown})
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyDown})
+	if m.historyCursor != 1 || !strings.Contains(m.view(), "title: Older") {
+		t.Fatalf("expected the cursor on the older record with its preview, got %d", m.historyCursor)
+	}
+
+	m = update(t, m, events.HistoryRestoredMsg{ID: "a", Text: "title: Older\n", Path: ".pr-builder/last-generated-pr.yaml"})
+	if m.mode != ModeResult || m.generatedDesc != "title: Older\n" {
+		t.Fatalf("expected the restored draft on the result screen, got mode %v", m.mode)
+	}
+
+	m = update(t, m, events.HistoryLoadedMsg{Entries: []events.HistoryEntry{{ID: "a"}}})
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.mode != ModeMain {
+		t.Fatalf("expected esc to leave the history browser, got %v", m.mode)
+	}
+}
@@ -0,0 +1,28 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/go-go-golems/prescribe/internal/controller"
+)
+
+// maxLintLines caps the findings shown above the result so the description keeps most of the screen.
+const maxLintLines = 4
+
+// lintLines are the lint findings of the current generation, as shown on the result screen.
+func lintLines(ctrl *controller.Controller) []string {
+	meta := ctrl.GetData().GenerationMetadata
+	if meta == nil || len(meta.LintFindings) == 0 {
+		return nil
+	}
+	findings := meta.LintFindings
+	lines := []string{fmt.Sprintf("Lint: %d finding(s)", len(findings))}
+	for i, f := range findings {
+		if i == maxLintLines-1 && len(findings) > maxLintLines {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(findings)-i))
+			break
+		}
+		lines = append(lines, "  "+f.String())
+	}
+	return lines
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/go-go-golems/prescribe/internal/tui/events"
+)
+
+func TestResult_ShowsLintFindings(t *testing.T) {
+	m := newTestModel(t)
+	findings := []domain.LintFinding{}
+	for _, ref := range []string{"A.One", "A.Two", "A.Three", "A.Four", "A.Five"} {
+		findings = append(findings, domain.LintFinding{Rule: domain.LintRuleUnknownReference, Section: domain.PRDataSectionBody, Message: "`" + ref + "` does not appear in the included files"})
+	}
+	m.ctrl.GetData().GenerationMetadata = &domain.GenerationMetadata{Model: "mock", LintFindings: findings}
+
+	m = update(t, m, tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = update(t, m, events.DescriptionGeneratedMsg{Text: "title: T"})
+	view := m.view()
+	if !strings.Contains(view, "Lint: 5 finding(s)") || !strings.Contains(view, "A.Three") || !strings.Contains(view, "... and 2 more") {
+		t.Fatalf("expected the lint findings on the result screen, got:\n%s", view)
+	}
+	if strings.Contains(view, "A.Four") {
+		t.Fatalf("expected the findings to be capped")
+	}
+	if got := m.headerHeight(); got != 3+2+len(lintLines(m.ctrl))+1 {
+		t.Fatalf("unexpected header height %d", got)
+	}
+}
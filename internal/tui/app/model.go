@@ -7,11 +7,14 @@ import (
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/go-go-golems/prescribe/internal/controller"
 	"github.com/go-go-golems/prescribe/internal/domain"
 	pexport "github.com/go-go-golems/prescribe/internal/export"
+	"github.com/go-go-golems/prescribe/internal/pricing"
 	"github.com/go-go-golems/prescribe/internal/tui/components/filelist"
 	"github.com/go-go-golems/prescribe/internal/tui/components/filterpane"
 	"github.com/go-go-golems/prescribe/internal/tui/components/result"
@@ -33,17 +36,23 @@ func New(ctrl *controller.Controller, deps Deps) Model {
 	rm := result.New()
 	fl := filelist.New(km, st)
 	fp := filterpane.New(km, st)
+	ri := textinput.New()
+	ri.Prompt = "Refine: "
+	ri.Placeholder = "e.g. make it shorter, mention the migration"
+	sp := spinner.New(spinner.WithSpinner(spinner.Dot))
 
 	return Model{
-		ctrl:       ctrl,
-		deps:       deps,
-		mode:       ModeMain,
-		keymap:     km,
-		styles:     st,
-		status:     sm,
-		result:     rm,
-		filelist:   fl,
-		filterpane: fp,
+		ctrl:        ctrl,
+		deps:        deps,
+		mode:        ModeMain,
+		keymap:      km,
+		styles:      st,
+		status:      sm,
+		result:      rm,
+		filelist:    fl,
+		filterpane:  fp,
+		refineInput: ri,
+		spinner:     sp,
 	}
 }
 
@@ -106,7 +115,13 @@ func (m *Model) footerHeight() int {
 		// - blank line (1)
 		const presetsBlockH = 5
 		return presetsBlockH + base
-	case ModeMain, ModeGenerating, ModeResult:
+	case ModeResult:
+		if m.refining {
+			// The refine prompt is rendered as one line above the status footer.
+			return 1 + base
+		}
+		return base
+	case ModeMain, ModeGenerating, ModeCompare, ModeHistory:
 		return base
 	}
 	return base
@@ -136,8 +151,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.recomputeLayout()
 
 	case tea.KeyMsg:
+		if m.refining {
+			// The prompt owns the keyboard while typing (so "q" or "esc" do not quit/leave).
+			m, cmd = m.updateRefineInput(msg)
+			return m, cmd
+		}
+
 		switch {
+		case m.mode == ModeGenerating && (key.Matches(msg, m.keymap.Back) || msg.Type == tea.KeyCtrlC):
+			m, cmd = m.cancelGeneration()
+			return m, cmd
+
 		case key.Matches(msg, m.keymap.Quit):
+			if m.gen != nil {
+				m.gen.cancel()
+			}
 			return m, tea.Quit
 
 		case key.Matches(msg, m.keymap.Help):
@@ -148,7 +176,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, m.keymap.Back):
 			// Global "back" semantics.
 			switch m.mode {
-			case ModeFilters, ModeResult:
+			case ModeFilters, ModeResult, ModeCompare, ModeHistory:
 				m.mode = ModeMain
 				m.recomputeLayout()
 			case ModeMain, ModeGenerating:
@@ -180,13 +208,81 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.syncFilelist()
 
 		case m.mode == ModeMain && key.Matches(msg, m.keymap.Generate):
-			m.mode = ModeGenerating
+			m, cmd = m.startStreamingGeneration()
+			cmds = append(cmds, cmd)
+
+		case m.mode == ModeMain && key.Matches(msg, m.keymap.GenerateCandidates):
+			m, cmd = m.startGeneration("Generating candidates...", generateCandidatesCmd(m.ctrl))
+			cmds = append(cmds, cmd)
+
+		case (m.mode == ModeMain || m.mode == ModeResult) && key.Matches(msg, m.keymap.OpenHistory):
+			cmds = append(cmds, loadHistoryCmd(m.ctrl))
+		case m.mode == ModeHistory && key.Matches(msg, m.keymap.Up):
+			m.historyCursor = maxInt(0, m.historyCursor-1)
+		case m.mode == ModeHistory && key.Matches(msg, m.keymap.Down):
+			m.historyCursor = maxInt(0, minInt(len(m.historyEntries)-1, m.historyCursor+1))
+		case m.mode == ModeHistory && key.Matches(msg, m.keymap.RestoreHistory):
+			if m.historyCursor < len(m.historyEntries) {
+				cmds = append(cmds, restoreHistoryCmd(m.ctrl, m.historyEntries[m.historyCursor].ID))
+			}
+
+		case m.mode == ModeCompare && key.Matches(msg, m.keymap.Up):
+			m.compareSection = maxInt(0, m.compareSection-1)
+			m.compareCursor = m.comparePicks[domain.PRDataSections[m.compareSection]]
+		case m.mode == ModeCompare && key.Matches(msg, m.keymap.Down):
+			m.compareSection = minInt(len(domain.PRDataSections)-1, m.compareSection+1)
+			m.compareCursor = m.comparePicks[domain.PRDataSections[m.compareSection]]
+		case m.mode == ModeCompare && key.Matches(msg, m.keymap.PrevCandidate):
+			m.compareCursor = maxInt(0, m.compareCursor-1)
+		case m.mode == ModeCompare && key.Matches(msg, m.keymap.NextCandidate):
+			m.compareCursor = minInt(len(m.ctrl.Candidates())-1, m.compareCursor+1)
+		case m.mode == ModeCompare && key.Matches(msg, m.keymap.PickSection):
+			m = m.pickCandidate(domain.PRDataSections[m.compareSection])
+		case m.mode == ModeCompare && key.Matches(msg, m.keymap.PickCandidate):
+			m = m.pickCandidate(domain.PRDataSections...)
+		case m.mode == ModeCompare && key.Matches(msg, m.keymap.AcceptSelection):
+			cmds = append(cmds, selectCandidatesCmd(m.ctrl, m.comparePicks))
+
+		case m.mode == ModeResult && key.Matches(msg, m.keymap.Refine):
+			m.refining = true
 			m.recomputeLayout()
-			cmds = append(cmds, generateCmd(m.ctrl))
+			cmds = append(cmds, m.refineInput.Focus())
+
+		case m.mode == ModeResult && key.Matches(msg, m.keymap.RegenerateTitle):
+			m, cmd = m.regenerateSection(domain.PRDataSectionTitle)
+			cmds = append(cmds, cmd)
+		case m.mode == ModeResult && key.Matches(msg, m.keymap.RegenerateBody):
+			m, cmd = m.regenerateSection(domain.PRDataSectionBody)
+			cmds = append(cmds, cmd)
+		case m.mode == ModeResult && key.Matches(msg, m.keymap.RegenerateChangelog):
+			m, cmd = m.regenerateSection(domain.PRDataSectionChangelog)
+			cmds = append(cmds, cmd)
+		case m.mode == ModeResult && key.Matches(msg, m.keymap.RegenerateReleaseNotes):
+			m, cmd = m.regenerateSection(domain.PRDataSectionReleaseNotes)
+			cmds = append(cmds, cmd)
 
 		case (m.mode == ModeMain || m.mode == ModeResult) && key.Matches(msg, m.keymap.CopyContext):
 			cmds = append(cmds, copyContextCmd(m.ctrl, m.deps))
 
+		case m.mode == ModeMain && key.Matches(msg, m.keymap.ToggleConcise):
+			m.ctrl.GetData().PromptVars.Concise = !m.ctrl.GetData().PromptVars.Concise
+			cmds = append(cmds, saveSessionCmd(m.ctrl))
+		case m.mode == ModeMain && key.Matches(msg, m.keymap.ToggleBullets):
+			m.ctrl.GetData().PromptVars.UseBullets = !m.ctrl.GetData().PromptVars.UseBullets
+			cmds = append(cmds, saveSessionCmd(m.ctrl))
+		case m.mode == ModeMain && key.Matches(msg, m.keymap.ToggleKeywords):
+			m.ctrl.GetData().PromptVars.UseKeywords = !m.ctrl.GetData().PromptVars.UseKeywords
+			cmds = append(cmds, saveSessionCmd(m.ctrl))
+		case m.mode == ModeMain && key.Matches(msg, m.keymap.ToggleMentionFiles):
+			withoutFiles := !m.ctrl.GetData().PromptVars.WithoutFilesOrDefault()
+			m.ctrl.GetData().PromptVars.WithoutFiles = &withoutFiles
+			cmds = append(cmds, saveSessionCmd(m.ctrl))
+
+		case m.mode == ModeMain && key.Matches(msg, m.keymap.FitBudget):
+			m = m.fitTokenBudget()
+			m.syncFilelist()
+			cmds = append(cmds, saveSessionCmd(m.ctrl))
+
 		case m.mode == ModeMain:
 			m.filelist, cmd = m.filelist.Update(msg)
 			if cmd != nil {
@@ -200,6 +296,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case generationDoneMsg:
+		return m.finishGeneration(msg)
+
+	case generationStreamMsg:
+		return m.applyStreamUpdate(msg)
+
+	case spinner.TickMsg:
+		// The spinner keeps ticking only while a generation runs.
+		if m.mode == ModeGenerating {
+			m.spinner, cmd = m.spinner.Update(msg)
+		}
+		return m, cmd
+
 	case events.SessionLoadedMsg:
 		m.status, cmd = m.status.Update(events.ShowToastMsg{
 			Text:     "Session loaded",
@@ -212,6 +321,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if cmd != nil {
 			cmds = append(cmds, cmd)
 		}
+		m, cmd = m.scheduleCostEstimate()
+		cmds = append(cmds, cmd)
 	case events.SessionLoadFailedMsg:
 		m.status, cmd = m.status.Update(events.ShowToastMsg{
 			Text:     "Failed to load session: " + msg.Err.Error(),
@@ -233,6 +344,26 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if cmd != nil {
 			cmds = append(cmds, cmd)
 		}
+		// Every session change is saved, so this keeps the footer estimate current.
+		m, cmd = m.scheduleCostEstimate()
+		cmds = append(cmds, cmd)
+
+	case costEstimateDueMsg:
+		if msg.seq != m.costSeq {
+			return m, nil
+		}
+		if m.gen != nil || m.stopping != 0 {
+			// A run is changing the controller; estimate once it is done.
+			return m, costEstimateTick(msg.seq)
+		}
+		return m.Update(events.CostEstimatedMsg{Text: costEstimateText(m.ctrl)})
+
+	case events.CostEstimatedMsg:
+		m.costInfo = msg.Text
+		if m.gen == nil || m.gen.stream == nil {
+			m.status.SetInfo(msg.Text)
+			m.recomputeLayout()
+		}
 
 	case events.SessionSaveFailedMsg:
 		m.status, cmd = m.status.Update(events.ShowToastMsg{
@@ -250,6 +381,133 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.err = nil
 		m.mode = ModeResult
 		m.recomputeLayout()
+		if text := actualCostText(m.ctrl); text != "" {
+			m.status, cmd = m.status.Update(events.ShowToastMsg{
+				Text:     text,
+				Level:    events.ToastInfo,
+				Duration: 8 * time.Second,
+			})
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+
+	case events.CandidatesGeneratedMsg:
+		m.comparePicks = controller.DefaultCandidatePicks(m.ctrl.Candidates())
+		m.compareSection = 0
+		m.compareCursor = m.comparePicks[domain.PRDataSectionTitle]
+		m.err = nil
+		m.mode = ModeCompare
+		m.recomputeLayout()
+		if msg.Failed > 0 {
+			m.status, cmd = m.status.Update(events.ShowToastMsg{
+				Text:     fmt.Sprintf("%d of %d candidates failed", msg.Failed, msg.Count),
+				Level:    events.ToastWarning,
+				Duration: 5 * time.Second,
+			})
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+
+	case events.CandidatesGenerationFailedMsg:
+		m.generatedDesc = ""
+		m.result.SetContent("")
+		m.err = msg.Err
+		m.mode = ModeResult
+		m.recomputeLayout()
+
+	case events.CandidatesSelectedMsg:
+		m.generatedDesc = msg.Text
+		m.result.SetContent(m.generatedDesc)
+		m.err = nil
+		m.mode = ModeResult
+		m.recomputeLayout()
+		m.status, cmd = m.status.Update(events.ShowToastMsg{
+			Text:     "Selection written to " + msg.Path,
+			Level:    events.ToastSuccess,
+			Duration: 3 * time.Second,
+		})
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case events.CandidatesSelectionFailedMsg:
+		m.status, cmd = m.status.Update(events.ShowToastMsg{
+			Text:     "Selection failed: " + msg.Err.Error(),
+			Level:    events.ToastError,
+			Duration: 5 * time.Second,
+		})
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case events.DescriptionRefinedMsg:
+		m.generatedDesc = msg.Text
+		m.result.SetContent(m.generatedDesc)
+		m.err = nil
+		m.mode = ModeResult
+		m.recomputeLayout()
+		toast := events.ShowToastMsg{
+			Text:     fmt.Sprintf("Refinement %d applied", msg.Count),
+			Level:    events.ToastSuccess,
+			Duration: 5 * time.Second,
+		}
+		if msg.ParseError != "" {
+			toast.Text = "Refined output did not parse (" + msg.ParseError + "); keeping the previous PR data"
+			toast.Level = events.ToastWarning
+		} else if text := actualCostText(m.ctrl); text != "" {
+			toast.Text += " | " + text
+		}
+		m.status, cmd = m.status.Update(toast)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case events.DescriptionRefineFailedMsg:
+		// Keep showing the previous description; the refinement can be retried.
+		m.mode = ModeResult
+		m.recomputeLayout()
+		m.status, cmd = m.status.Update(events.ShowToastMsg{
+			Text:     "Refinement failed: " + msg.Err.Error(),
+			Level:    events.ToastError,
+			Duration: 5 * time.Second,
+		})
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case events.SectionRegeneratedMsg:
+		m.generatedDesc = msg.Text
+		m.result.SetContent(m.generatedDesc)
+		m.err = nil
+		m.mode = ModeResult
+		m.recomputeLayout()
+		text := "Regenerated " + msg.Section
+		if cost := actualCostText(m.ctrl); cost != "" {
+			text += " | " + cost
+		}
+		m.status, cmd = m.status.Update(events.ShowToastMsg{
+			Text:     text,
+			Level:    events.ToastSuccess,
+			Duration: 5 * time.Second,
+		})
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case events.SectionRegenerationFailedMsg:
+		// Keep showing the previous description; the other sections are untouched anyway.
+		m.mode = ModeResult
+		m.recomputeLayout()
+		m.status, cmd = m.status.Update(events.ShowToastMsg{
+			Text:     "Failed to regenerate " + msg.Section + ": " + msg.Err.Error(),
+			Level:    events.ToastError,
+			Duration: 5 * time.Second,
+		})
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 
 	case events.DescriptionGenerationFailedMsg:
 		m.generatedDesc = ""
@@ -258,6 +516,58 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.mode = ModeResult
 		m.recomputeLayout()
 
+	case events.HistoryLoadedMsg:
+		if len(msg.Entries) == 0 {
+			m.status, cmd = m.status.Update(events.ShowToastMsg{
+				Text:     "No generation history yet",
+				Level:    events.ToastInfo,
+				Duration: 2 * time.Second,
+			})
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			break
+		}
+		m.historyEntries = msg.Entries
+		m.historyCursor = 0
+		m.mode = ModeHistory
+		m.recomputeLayout()
+
+	case events.HistoryLoadFailedMsg:
+		m.status, cmd = m.status.Update(events.ShowToastMsg{
+			Text:     "Failed to load history: " + msg.Err.Error(),
+			Level:    events.ToastError,
+			Duration: 5 * time.Second,
+		})
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case events.HistoryRestoredMsg:
+		m.generatedDesc = msg.Text
+		m.result.SetContent(m.generatedDesc)
+		m.err = nil
+		m.mode = ModeResult
+		m.recomputeLayout()
+		m.status, cmd = m.status.Update(events.ShowToastMsg{
+			Text:     "Restored " + msg.ID + " to " + msg.Path,
+			Level:    events.ToastSuccess,
+			Duration: 3 * time.Second,
+		})
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case events.HistoryRestoreFailedMsg:
+		m.status, cmd = m.status.Update(events.ShowToastMsg{
+			Text:     "Restore failed: " + msg.Err.Error(),
+			Level:    events.ToastError,
+			Duration: 5 * time.Second,
+		})
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
 	case events.ToggleFileIncludedRequested:
 		if m.showFiltered {
 			m.status, cmd = m.status.Update(events.ShowToastMsg{
@@ -418,6 +728,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.recomputeLayout()
 	}
 
+	// Cursor blink and other non-key messages of the refine prompt.
+	if _, isKey := msg.(tea.KeyMsg); m.refining && !isKey {
+		m.refineInput, cmd = m.refineInput.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+
 	// Let result model consume messages too (viewport scrolling / internal state),
 	// but only while in result mode to avoid stealing navigation keys.
 	if m.mode == ModeResult {
@@ -464,6 +782,83 @@ func (m Model) applyQuickPresetByIndex(i int) Model {
 	return m
 }
 
+// fitTokenBudget degrades included files until they fit the resolved token budget
+// (session budget.max_tokens or the configured model's context window).
+func (m Model) fitTokenBudget() Model {
+	report, err := m.ctrl.FitResolvedTokenBudget(0)
+	if err != nil {
+		m.status, _ = m.status.Update(events.ShowToastMsg{
+			Text:     "Failed to fit budget: " + err.Error(),
+			Level:    events.ToastError,
+			Duration: 5 * time.Second,
+		})
+		return m
+	}
+
+	level := events.ToastSuccess
+	text := fmt.Sprintf("Fitted to %d/%d tokens: %d file(s) changed", report.TokensAfter, report.MaxTokens, len(report.Changes))
+	if !report.Fits {
+		level = events.ToastWarning
+		text = fmt.Sprintf("Still over budget: %d/%d tokens (%d file(s) changed)", report.TokensAfter, report.MaxTokens, len(report.Changes))
+	}
+	m.status, _ = m.status.Update(events.ShowToastMsg{
+		Text:     text,
+		Level:    level,
+		Duration: 3 * time.Second,
+	})
+	return m
+}
+
+// pickCandidate takes the given sections from the candidate under the cursor.
+func (m Model) pickCandidate(sections ...domain.PRDataSection) Model {
+	cands := m.ctrl.Candidates()
+	if m.compareCursor < 0 || m.compareCursor >= len(cands) || cands[m.compareCursor].Parsed() == nil {
+		m.status, _ = m.status.Update(events.ShowToastMsg{
+			Text:     "Candidate has no parsed PR data",
+			Level:    events.ToastWarning,
+			Duration: 2 * time.Second,
+		})
+		return m
+	}
+	picks := controller.CandidatePicks{}
+	for s, i := range m.comparePicks {
+		picks[s] = i
+	}
+	for _, s := range sections {
+		picks[s] = m.compareCursor
+	}
+	m.comparePicks = picks
+	return m
+}
+
+// updateRefineInput handles keys while the refine prompt is focused:
+// enter submits the instruction, esc cancels, everything else edits the input.
+func (m Model) updateRefineInput(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		instruction := strings.TrimSpace(m.refineInput.Value())
+		if instruction == "" {
+			return m, nil
+		}
+		m.refining = false
+		m.refineInput.Blur()
+		m.refineInput.Reset()
+		return m.startGeneration("Refining PR description...", refineCmd(m.ctrl, instruction))
+	case tea.KeyEsc:
+		m.refining = false
+		m.refineInput.Blur()
+		m.refineInput.Reset()
+		m.recomputeLayout()
+		return m, nil
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	default:
+		var cmd tea.Cmd
+		m.refineInput, cmd = m.refineInput.Update(msg)
+		return m, cmd
+	}
+}
+
 func saveSessionCmd(ctrl *controller.Controller) tea.Cmd {
 	return func() tea.Msg {
 		path := ctrl.GetDefaultSessionPath()
@@ -474,16 +869,176 @@ func saveSessionCmd(ctrl *controller.Controller) tea.Cmd {
 	}
 }
 
-func generateCmd(ctrl *controller.Controller) tea.Cmd {
+// regenerateSection starts regenerating one section of the result.
+func (m Model) regenerateSection(section domain.PRDataSection) (Model, tea.Cmd) {
+	label := "Regenerating " + strings.ReplaceAll(string(section), "_", " ") + "..."
+	return m.startGeneration(label, regenerateSectionCmd(m.ctrl, section))
+}
+
+// refineCmd continues the last generation with instruction and persists the result
+// (last-generated-pr.yaml and its conversation), like `prescribe refine`.
+func refineCmd(ctrl *controller.Controller, instruction string) func(context.Context) tea.Msg {
+	return func(ctx context.Context) tea.Msg {
+		desc, err := ctrl.Refine(ctx, instruction)
+		if err != nil {
+			return events.DescriptionRefineFailedMsg{Err: err}
+		}
+		if _, err := ctrl.WriteLastGeneratedPRData(); err != nil {
+			return events.DescriptionRefineFailedMsg{Err: err}
+		}
+		data := ctrl.GetData()
+		count := 0
+		if data.GenerationMetadata != nil {
+			count = len(data.GenerationMetadata.Refinements)
+		}
+		return events.DescriptionRefinedMsg{Text: desc, Count: count, ParseError: data.GeneratedPRDataParseError}
+	}
+}
+
+// regenerateSectionCmd regenerates one section and persists the result, like `generate --section`.
+func regenerateSectionCmd(ctrl *controller.Controller, section domain.PRDataSection) func(context.Context) tea.Msg {
+	return func(ctx context.Context) tea.Msg {
+		desc, err := ctrl.RegenerateSection(ctx, section)
+		if err != nil {
+			return events.SectionRegenerationFailedMsg{Section: string(section), Err: err}
+		}
+		if _, err := ctrl.WriteLastGeneratedPRData(); err != nil {
+			return events.SectionRegenerationFailedMsg{Section: string(section), Err: err}
+		}
+		return events.SectionRegeneratedMsg{Section: string(section), Text: desc}
+	}
+}
+
+// generateCandidatesCmd generates the candidates configured in the session (`candidates:` in session.yaml).
+func generateCandidatesCmd(ctrl *controller.Controller) func(context.Context) tea.Msg {
+	return func(ctx context.Context) tea.Msg {
+		cfg := ctrl.CandidatesConfig()
+		cands, err := ctrl.GenerateCandidates(ctx, controller.CandidateSpecs(cfg))
+		if err != nil {
+			return events.CandidatesGenerationFailedMsg{Err: err}
+		}
+		failed := 0
+		for _, c := range cands {
+			if c.Err != nil {
+				failed++
+			}
+		}
+		return events.CandidatesGeneratedMsg{Count: len(cands), Failed: failed}
+	}
+}
+
+// selectCandidatesCmd applies the picks and writes the result to last-generated-pr.yaml.
+func selectCandidatesCmd(ctrl *controller.Controller, picks controller.CandidatePicks) tea.Cmd {
 	return func() tea.Msg {
-		desc, err := ctrl.GenerateDescription(context.Background())
+		if err := ctrl.SelectCandidates(ctrl.Candidates(), picks); err != nil {
+			return events.CandidatesSelectionFailedMsg{Err: err}
+		}
+		path, err := ctrl.WriteLastGeneratedPRData()
 		if err != nil {
-			return events.DescriptionGenerationFailedMsg{Err: err}
+			return events.CandidatesSelectionFailedMsg{Err: err}
 		}
-		return events.DescriptionGeneratedMsg{Text: desc}
+		return events.CandidatesSelectedMsg{Text: ctrl.GetData().GeneratedDescription, Path: path}
 	}
 }
 
+// costEstimateDelay is how long the footer estimate waits for the session to settle.
+const costEstimateDelay = 300 * time.Millisecond
+
+// costEstimateDueMsg asks for the footer estimate of session change seq.
+type costEstimateDueMsg struct {
+	seq int
+}
+
+func costEstimateTick(seq int) tea.Cmd {
+	return tea.Tick(costEstimateDelay, func(time.Time) tea.Msg {
+		return costEstimateDueMsg{seq: seq}
+	})
+}
+
+// scheduleCostEstimate debounces the footer estimate. The estimate reads the controller, so it runs
+// in Update (see costEstimateDueMsg) rather than in a command goroutine.
+func (m Model) scheduleCostEstimate() (Model, tea.Cmd) {
+	m.costSeq++
+	return m, costEstimateTick(m.costSeq)
+}
+
+// costEstimateText is the footer cost estimate, or "" if there is nothing to estimate.
+func costEstimateText(ctrl *controller.Controller) string {
+	est, err := ctrl.EstimateCost()
+	if err != nil {
+		// e.g. no files included; hide the estimate rather than toasting on every change.
+		return ""
+	}
+	if !est.Priced {
+		return fmt.Sprintf("Prompt: %d tokens | cost: no pricing for %s", est.InputTokens, est.Model)
+	}
+	return fmt.Sprintf("Prompt: %d tokens | est. cost (%s): %s in + up to %s out (%d tokens)",
+		est.InputTokens, est.Model,
+		pricing.FormatUSD(est.Cost.Input), pricing.FormatUSD(est.Cost.Output), est.OutputTokens)
+}
+
+// actualCostText summarizes the provider-reported usage of the last generation, or "" if unavailable.
+func actualCostText(ctrl *controller.Controller) string {
+	last := ctrl.LastGeneration()
+	if last != nil && last.Cached {
+		return "Served from cache: no inference ran (generate --no-cache to regenerate)"
+	}
+	if last == nil || last.Usage == nil {
+		return ""
+	}
+	est, err := ctrl.ActualCost(last.Model, *last.Usage)
+	if err != nil {
+		return ""
+	}
+	text := fmt.Sprintf("Usage: %d in (%s) / %d out tokens", est.InputTokens, est.Usage().CacheText(), est.OutputTokens)
+	if est.Priced {
+		text += " | cost " + pricing.FormatUSD(est.Cost.Total())
+	}
+	return text
+}
+
+// generationInfoText summarizes the metadata of the last generation for the result view,
+// or "" if nothing was generated yet.
+func generationInfoText(ctrl *controller.Controller) string {
+	meta := ctrl.GetData().GenerationMetadata
+	if meta == nil {
+		return ""
+	}
+	parts := []string{}
+	if meta.Model != "" {
+		parts = append(parts, meta.Model)
+	}
+	if meta.Cached {
+		parts = append(parts, "cached")
+	}
+	if meta.Usage != nil {
+		parts = append(parts, fmt.Sprintf("%d in (%s) / %d out tokens", meta.Usage.InputTokens, meta.Usage.CacheText(), meta.Usage.OutputTokens))
+	}
+	if meta.StopReason != "" {
+		parts = append(parts, "stop: "+meta.StopReason)
+	}
+	parts = append(parts, "latency: "+(time.Duration(meta.LatencyMs)*time.Millisecond).Round(100*time.Millisecond).String())
+	parts = append(parts, fmt.Sprintf("attempts: %d", meta.Attempts))
+	if n := len(meta.AttemptLog); n > 0 && meta.AttemptLog[n-1].Fallback {
+		parts = append(parts, "fallback")
+	}
+	if meta.OutputMode != "" {
+		parts = append(parts, "output: "+string(meta.OutputMode))
+	}
+	if n := len(meta.Refinements); n > 0 {
+		parts = append(parts, fmt.Sprintf("refinements: %d", n))
+	}
+	if meta.LintRepaired {
+		parts = append(parts, "lint repaired")
+	}
+	if last := ctrl.LastGeneration(); last != nil && last.Usage != nil {
+		if est, err := ctrl.ActualCost(last.Model, *last.Usage); err == nil && est.Priced {
+			parts = append(parts, "cost: "+pricing.FormatUSD(est.Cost.Total()))
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
 func copyContextCmd(ctrl *controller.Controller, deps Deps) tea.Cmd {
 	return func() tea.Msg {
 		req, err := ctrl.BuildGenerateDescriptionRequest()
@@ -513,17 +1068,47 @@ func (m Model) headerHeight() int {
 	switch m.mode {
 	case ModeMain:
 		// renderMain writes a fixed header before the file list:
-		// title + blank + branch + blank + stats + blank + section header + separator.
-		return 8
+		// title + blank + branch + blank + stats + blank + section header + separator,
+		// plus the prompt variables line when any is set and the denied files + blank when
+		// .prescribeignore dropped any.
+		h := 8
+		if m.ctrl.GetData().PromptVars.Summary() != "" {
+			h++
+		}
+		if lines := deniedLines(m.ctrl.GetData()); len(lines) > 0 {
+			h += len(lines) + 1
+		}
+		return h
 	case ModeFilters:
 		// renderFilters writes: title + blank + stats + blank + header + separator.
 		return 6
 	case ModeResult:
-		// renderResult writes: title line + "\n\n" (=> 3 lines total before the viewport)
-		return 3
+		// renderResult writes: title line + "\n\n" (=> 3 lines total before the viewport),
+		// plus the generation metadata line + blank, the lint findings + blank and the redaction
+		// report + blank when available.
+		if m.err != nil {
+			return 3
+		}
+		h := 3
+		if generationInfoText(m.ctrl) != "" {
+			h += 2
+		}
+		for _, lines := range [][]string{lintLines(m.ctrl), redactionLines(m.ctrl)} {
+			if len(lines) > 0 {
+				h += len(lines) + 1
+			}
+		}
+		return h
 	case ModeGenerating:
-		// renderGenerating uses the same overall structure as main for now.
-		return 8
+		// renderGenerating writes: title + blank + spinner line + cancel hint + blank,
+		// followed by the streamed output.
+		return 5
+	case ModeCompare:
+		// renderCompare writes: title + blank + picks + blank + section header + separator.
+		return 6
+	case ModeHistory:
+		// renderHistory writes: title + blank + count line + blank.
+		return 4
 	default:
 		return 0
 	}
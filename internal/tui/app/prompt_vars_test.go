@@ -0,0 +1,30 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestMain_TogglesPromptVars(t *testing.T) {
+	m := newTestModel(t)
+	m = update(t, m, tea.WindowSizeMsg{Width: 120, Height: 40})
+	if strings.Contains(m.view(), "Prompt:") {
+		t.Fatalf("expected no prompt variables line by default")
+	}
+
+	for _, k := range []string{"c", "b", "m"} {
+		m = update(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+	}
+	vars := m.ctrl.GetData().PromptVars
+	if !vars.Concise || !vars.UseBullets || vars.UseKeywords || vars.WithoutFilesOrDefault() {
+		t.Fatalf("unexpected prompt vars after toggling: %+v", vars)
+	}
+	if !strings.Contains(m.view(), "Prompt: mention files, concise, bullets") {
+		t.Fatalf("expected the prompt variables line, got:\n%s", m.view())
+	}
+	if got := m.headerHeight(); got != 9 {
+		t.Fatalf("unexpected header height %d", got)
+	}
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/go-go-golems/prescribe/internal/controller"
+	"github.com/go-go-golems/prescribe/internal/redact"
+)
+
+// maxRedactionLines caps the secrets listed above the result.
+const maxRedactionLines = 3
+
+// redactionLines report the secrets found in the last request sent to the model.
+func redactionLines(ctrl *controller.Controller) []string {
+	report := ctrl.LastRedaction()
+	findings := report.Findings
+	if len(findings) == 0 {
+		return nil
+	}
+	var lines []string
+	switch report.Mode {
+	case redact.ModeRedact:
+		lines = []string{fmt.Sprintf("Redacted %d possible secret(s) before sending", len(findings))}
+	case redact.ModeWarn:
+		lines = []string{fmt.Sprintf("Sent %d possible secret(s) unredacted (redaction mode warn)", len(findings))}
+	default:
+		return nil
+	}
+	for i, f := range findings {
+		if i == maxRedactionLines-1 && len(findings) > maxRedactionLines {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(findings)-i))
+			break
+		}
+		lines = append(lines, "  "+f.String())
+	}
+	return lines
+}
@@ -1,6 +1,10 @@
 package app
 
 import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/go-go-golems/prescribe/internal/controller"
 	"github.com/go-go-golems/prescribe/internal/tui/components/filelist"
 	"github.com/go-go-golems/prescribe/internal/tui/components/filterpane"
@@ -20,6 +24,8 @@ const (
 	ModeFilters
 	ModeGenerating
 	ModeResult
+	ModeCompare
+	ModeHistory
 )
 
 // Model is the root Bubbletea model for the modular TUI.
@@ -50,6 +56,34 @@ type Model struct {
 	height int
 	layout layout.Layout
 
+	// candidate comparison (picks index into ctrl.Candidates())
+	comparePicks   controller.CandidatePicks
+	compareSection int
+	compareCursor  int
+
+	// history browser (entries are newest first)
+	historyEntries []events.HistoryEntry
+	historyCursor  int
+
+	// refinement prompt on the result screen (active while refining is true)
+	refining    bool
+	refineInput textinput.Model
+
+	// generation in flight (nil outside ModeGenerating); genSeq numbers the runs so results of
+	// cancelled ones can be told apart.
+	gen        *generation
+	genSeq     int
+	genTimeout time.Duration
+	spinner    spinner.Model
+	// stopping is the ID of a cancelled run whose result has not arrived yet. The run may still be
+	// changing the controller, so no new run starts until it is done.
+	stopping int
+
+	// costInfo is the footer cost estimate, shown again once a streaming generation ends; costSeq
+	// numbers the session changes so only the last of a burst is estimated.
+	costInfo string
+	costSeq  int
+
 	// generation/result
 	generatedDesc string
 	result        result.Model
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-go-golems/prescribe/internal/api"
+	"github.com/go-go-golems/prescribe/internal/controller"
+	"github.com/go-go-golems/prescribe/internal/tui/events"
+)
+
+// streamUpdate is the text streamed so far by the generation in flight.
+type streamUpdate struct {
+	text   string
+	tokens int
+}
+
+// generationStreamMsg carries a streamUpdate of the generation with the given id.
+type generationStreamMsg struct {
+	id int
+	streamUpdate
+}
+
+// streamMailbox hands stream updates from the event router to the UI. It only keeps the latest
+// update (each one carries the full text), so a slow UI never blocks inference.
+type streamMailbox struct {
+	mu     sync.Mutex
+	closed bool
+	ch     chan streamUpdate
+}
+
+func newStreamMailbox() *streamMailbox {
+	return &streamMailbox{ch: make(chan streamUpdate, 1)}
+}
+
+func (b *streamMailbox) offer(u streamUpdate) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	select {
+	case <-b.ch:
+	default:
+	}
+	b.ch <- u
+}
+
+func (b *streamMailbox) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		b.closed = true
+		close(b.ch)
+	}
+}
+
+// waitForStreamCmd delivers the next update of box; it returns nil once the stream is closed.
+func waitForStreamCmd(id int, box *streamMailbox) tea.Cmd {
+	return func() tea.Msg {
+		u, ok := <-box.ch
+		if !ok {
+			return nil
+		}
+		return generationStreamMsg{id: id, streamUpdate: u}
+	}
+}
+
+// startStreamingGeneration generates the PR description while the result pane follows the
+// streamed output.
+func (m Model) startStreamingGeneration() (Model, tea.Cmd) {
+	box := newStreamMailbox()
+	m, cmd := m.startGeneration("Generating PR description...", generateStreamingCmd(m.ctrl, box))
+	if m.gen == nil {
+		return m, cmd
+	}
+	m.gen.stream = box
+	return m, tea.Batch(cmd, waitForStreamCmd(m.gen.id, box))
+}
+
+// generateStreamingCmd is generateCmd with partial completions forwarded to box. The YAML is
+// parsed once the stream completes, as for non-streaming generation.
+func generateStreamingCmd(ctrl *controller.Controller, box *streamMailbox) func(context.Context) tea.Msg {
+	return func(ctx context.Context) tea.Msg {
+		defer box.close()
+
+		counter := ctrl.TokenCounter()
+		var last streamUpdate
+		handler := api.PartialTextHandler(func(delta, completion string) {
+			if strings.HasPrefix(completion, last.text) {
+				last.tokens += counter.Count(delta)
+			} else {
+				// A retry or fallback started over.
+				last.tokens = counter.Count(completion)
+			}
+			last.text = completion
+			box.offer(last)
+		})
+
+		desc, err := ctrl.GenerateDescriptionWithEventHandler(ctx, handler)
+		if err != nil {
+			return events.DescriptionGenerationFailedMsg{Err: err}
+		}
+		return events.DescriptionGeneratedMsg{Text: desc}
+	}
+}
+
+// applyStreamUpdate shows the streamed text in the result pane and the token rate in the footer.
+func (m Model) applyStreamUpdate(msg generationStreamMsg) (Model, tea.Cmd) {
+	if m.gen == nil || m.gen.id != msg.id {
+		return m, nil
+	}
+	m.gen.streamed = msg.streamUpdate
+	m.result.SetContent(msg.text)
+	m.result.GotoBottom()
+	m.status.SetInfo(m.streamInfoText())
+	m.recomputeLayout()
+	return m, waitForStreamCmd(msg.id, m.gen.stream)
+}
+
+// streamInfoText is the footer line while streaming, e.g. "Streaming: 345 tokens | 41.2 tok/s".
+func (m Model) streamInfoText() string {
+	if m.gen == nil {
+		return ""
+	}
+	text := fmt.Sprintf("Streaming: %d tokens", m.gen.streamed.tokens)
+	if elapsed := m.deps.Now().Sub(m.gen.started); elapsed > 0 {
+		text += fmt.Sprintf(" | %.1f tok/s", float64(m.gen.streamed.tokens)/elapsed.Seconds())
+	}
+	return text
+}
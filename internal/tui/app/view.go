@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/go-go-golems/prescribe/internal/domain"
 )
 
 func (m Model) view() string {
@@ -17,6 +18,10 @@ func (m Model) view() string {
 		return m.renderGenerating()
 	case ModeResult:
 		return m.renderResult()
+	case ModeCompare:
+		return m.renderCompare()
+	case ModeHistory:
+		return m.renderHistory()
 	default:
 		return m.renderMain()
 	}
@@ -39,14 +44,26 @@ func (m Model) renderMain() string {
 	b.WriteString(m.styles.Base.Render(branchInfo))
 	b.WriteString("\n\n")
 
-	stats := fmt.Sprintf("Files: %d visible, %d filtered | Tokens: %d | Filters: %d",
+	stats := fmt.Sprintf("Files: %d visible, %d filtered, %d denied | Tokens: %d (%s) | Filters: %d",
 		len(data.GetVisibleFiles()),
 		len(data.GetFilteredFiles()),
+		len(data.DeniedFiles),
 		data.GetTotalTokens(),
+		m.ctrl.TokenCounter().Label(),
 		len(data.ActiveFilters),
 	)
 	b.WriteString(m.styles.Base.Render(stats))
-	b.WriteString("\n\n")
+	b.WriteString("\n")
+	if summary := data.PromptVars.Summary(); summary != "" {
+		b.WriteString(m.styles.MutedText.Render("Prompt: " + summary))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if lines := deniedLines(data); len(lines) > 0 {
+		b.WriteString(m.styles.ErrorText.Render(strings.Join(lines, "\n")))
+		b.WriteString("\n\n")
+	}
 
 	if m.showFiltered {
 		b.WriteString(m.styles.Header.Render("FILTERED FILES"))
@@ -151,8 +168,14 @@ func (m Model) renderGenerating() string {
 	title := m.styles.Title.Render("GENERATING")
 	b.WriteString(lipgloss.PlaceHorizontal(maxInt(0, m.layout.Width), lipgloss.Center, title))
 	b.WriteString("\n\n")
-	b.WriteString(m.styles.Base.Render("Generating PR description..."))
+	b.WriteString(m.styles.Base.Render(m.generationStatusText()))
+	b.WriteString("\n")
+	b.WriteString(m.styles.MutedText.Render("esc/ctrl+c: cancel"))
 	b.WriteString("\n\n")
+	if m.gen != nil && m.gen.streamed.text != "" {
+		b.WriteString(m.result.View())
+		b.WriteString("\n")
+	}
 	b.WriteString(m.status.View())
 	boxW, boxH := m.boxWH()
 	return strings.TrimRight(
@@ -174,10 +197,111 @@ func (m Model) renderResult() string {
 		b.WriteString(m.styles.ErrorText.Render("Error: " + m.err.Error()))
 		b.WriteString("\n\n")
 	} else {
+		if info := generationInfoText(m.ctrl); info != "" {
+			b.WriteString(m.styles.MutedText.Render(info))
+			b.WriteString("\n\n")
+		}
+		if lines := lintLines(m.ctrl); len(lines) > 0 {
+			b.WriteString(m.styles.WarningText.Render(strings.Join(lines, "\n")))
+			b.WriteString("\n\n")
+		}
+		if lines := redactionLines(m.ctrl); len(lines) > 0 {
+			b.WriteString(m.styles.WarningText.Render(strings.Join(lines, "\n")))
+			b.WriteString("\n\n")
+		}
 		b.WriteString(m.result.View())
 		b.WriteString("\n")
 	}
+	if m.refining {
+		b.WriteString(m.refineInput.View())
+		b.WriteString("\n")
+	}
+
+	b.WriteString(m.status.View())
+
+	boxW, boxH := m.boxWH()
+	return strings.TrimRight(
+		m.styles.BorderBox.
+			Width(boxW).Height(boxH).
+			Render(b.String()),
+		"\n",
+	)
+}
+
+// renderCompare shows one section of all candidates side by side; the picks line shows
+// which candidate each section is currently taken from.
+func (m Model) renderCompare() string {
+	cands := m.ctrl.Candidates()
+	section := domain.PRDataSections[m.compareSection]
+
+	var b strings.Builder
+
+	title := m.styles.Title.Render("COMPARE CANDIDATES")
+	b.WriteString(lipgloss.PlaceHorizontal(maxInt(0, m.layout.Width), lipgloss.Center, title))
+	b.WriteString("\n\n")
+
+	picks := make([]string, 0, len(domain.PRDataSections))
+	for i, s := range domain.PRDataSections {
+		p := fmt.Sprintf("%s: #%d", s, m.comparePicks[s]+1)
+		if i == m.compareSection {
+			p = m.styles.SelectedItem.Render(p)
+		}
+		picks = append(picks, p)
+	}
+	b.WriteString(m.styles.Base.Render("Picks  " + strings.Join(picks, "  ")))
+	b.WriteString("\n\n")
+
+	b.WriteString(m.styles.Header.Render(strings.ToUpper(strings.ReplaceAll(string(section), "_", " "))))
+	b.WriteString("\n")
+	b.WriteString(strings.Repeat("─", maxInt(0, m.layout.Width)))
+	b.WriteString("\n")
+
+	if len(cands) == 0 {
+		b.WriteString(m.styles.MutedText.Render("No candidates"))
+		b.WriteString("\n")
+	} else {
+		const gap = 2
+		colW := maxInt(10, (m.layout.BodyW-gap*(len(cands)-1))/len(cands))
+		cols := make([]string, 0, len(cands)*2)
+		for i, cand := range cands {
+			label := fmt.Sprintf("#%d %s", i+1, cand.Spec.Label())
+			if m.comparePicks[section] == i {
+				label = "✓ " + label
+			}
+			labelStyle := m.styles.UnselectedItem
+			if i == m.compareCursor {
+				labelStyle = m.styles.SelectedItem
+			}
+
+			var text string
+			switch {
+			case cand.Err != nil:
+				text = m.styles.ErrorText.Render("failed: " + cand.Err.Error())
+			case cand.Parsed() == nil:
+				text = m.styles.WarningText.Render("parse error: " + cand.Response.ParseError)
+			default:
+				text = cand.Parsed().SectionText(section)
+				if strings.TrimSpace(text) == "" {
+					text = m.styles.MutedText.Render("(empty)")
+				}
+			}
+
+			col := labelStyle.MaxWidth(colW).Render(label) + "\n" +
+				lipgloss.NewStyle().Width(colW).Render(text)
+			lines := strings.Split(col, "\n")
+			if len(lines) > m.layout.BodyH {
+				lines = lines[:maxInt(0, m.layout.BodyH)]
+			}
+			if i > 0 {
+				cols = append(cols, strings.Repeat(" ", gap))
+			}
+			cols = append(cols, lipgloss.NewStyle().Width(colW).Render(strings.Join(lines, "\n")))
+		}
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, cols...))
+		b.WriteString("\n")
+	}
 
+	b.WriteString("\n")
 	b.WriteString(m.status.View())
 
 	boxW, boxH := m.boxWH()
@@ -189,9 +313,83 @@ func (m Model) renderResult() string {
 	)
 }
 
+// renderHistory lists the recorded generations (newest first) above a preview of the one under
+// the cursor.
+func (m Model) renderHistory() string {
+	var b strings.Builder
+
+	title := m.styles.Title.Render("HISTORY")
+	b.WriteString(lipgloss.PlaceHorizontal(maxInt(0, m.layout.Width), lipgloss.Center, title))
+	b.WriteString("\n\n")
+	b.WriteString(m.styles.Base.Render(fmt.Sprintf("%d generation(s) in .pr-builder/history/ | enter: restore | esc: back", len(m.historyEntries))))
+	b.WriteString("\n\n")
+
+	// The list gets up to half of the body; the preview gets the rest.
+	listH := maxInt(1, minInt(len(m.historyEntries), m.layout.BodyH/2))
+	start := maxInt(0, minInt(m.historyCursor-listH/2, len(m.historyEntries)-listH))
+	for i := start; i < minInt(len(m.historyEntries), start+listH); i++ {
+		e := m.historyEntries[i]
+		label := e.Title
+		if e.ParseError != "" {
+			label = "(parse error) " + e.ParseError
+		}
+		line := fmt.Sprintf("%s  %-9s %-20s %s", e.CreatedAt.Local().Format("2006-01-02 15:04"), e.Kind, e.Model, label)
+		style := m.styles.UnselectedItem
+		if i == m.historyCursor {
+			style = m.styles.SelectedItem
+		}
+		b.WriteString(style.MaxWidth(m.layout.BodyW).Render(line))
+		b.WriteString("\n")
+	}
+	b.WriteString(strings.Repeat("─", maxInt(0, m.layout.Width)))
+	b.WriteString("\n")
+
+	if m.historyCursor < len(m.historyEntries) {
+		previewH := maxInt(0, m.layout.BodyH-listH-1)
+		lines := strings.Split(strings.TrimRight(m.historyEntries[m.historyCursor].Preview, "\n"), "\n")
+		if len(lines) > previewH {
+			lines = lines[:previewH]
+		}
+		b.WriteString(m.styles.MutedText.MaxWidth(m.layout.BodyW).Render(strings.Join(lines, "\n")))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.status.View())
+
+	boxW, boxH := m.boxWH()
+	return strings.TrimRight(
+		m.styles.BorderBox.
+			Width(boxW).Height(boxH).
+			Render(b.String()),
+		"\n",
+	)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
 func maxInt(a, b int) int {
 	if a > b {
 		return a
 	}
 	return b
 }
+
+// generationStatusText is the spinner line of the generating screen, e.g.
+// "⣾ Generating PR description... 12s (timeout 5m0s)".
+func (m Model) generationStatusText() string {
+	label := "Generating PR description..."
+	if m.gen != nil {
+		label = m.gen.label
+	}
+	text := fmt.Sprintf("%s %s %s", m.spinner.View(), label, m.generationElapsed())
+	if m.genTimeout > 0 {
+		text += fmt.Sprintf(" (timeout %s)", m.genTimeout)
+	}
+	return text
+}
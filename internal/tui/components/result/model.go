@@ -38,3 +38,8 @@ func (m *Model) SetSize(w, h int) {
 func (m *Model) SetContent(s string) {
 	m.vp.SetContent(s)
 }
+
+// GotoBottom scrolls to the end of the content (e.g. to follow streamed text).
+func (m *Model) GotoBottom() {
+	m.vp.GotoBottom()
+}
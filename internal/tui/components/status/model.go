@@ -24,6 +24,9 @@ type Model struct {
 	width        int
 
 	toast ToastState
+
+	// info is a persistent line above the help (e.g. the cost estimate).
+	info string
 }
 
 func New(km keys.KeyMap, st styles.Styles) Model {
@@ -40,6 +43,11 @@ func (m *Model) SetSize(width int) {
 	m.width = width
 }
 
+// SetInfo sets the persistent info line; empty hides it.
+func (m *Model) SetInfo(text string) {
+	m.info = text
+}
+
 func (m *Model) SetShowFullHelp(v bool) {
 	m.showFullHelp = v
 }
@@ -74,6 +82,9 @@ func (m Model) View() string {
 	h.Width = m.width
 	h.ShowAll = m.showFullHelp
 	helpView := h.View(m.keymap)
+	if m.info != "" {
+		helpView = m.styles.MutedText.Render(m.info) + "\n" + helpView
+	}
 
 	if toastLine == "" {
 		return helpView
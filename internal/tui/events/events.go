@@ -55,7 +55,7 @@ type FilterPresetSummary struct {
 	ID          string
 	Name        string
 	Description string
-	Location    string // "project" or "global"
+	Location    string // "project", "global" or "builtin"
 }
 
 // FilterPresetsLoadedMsg indicates filter presets were discovered from preset dirs.
@@ -78,6 +78,68 @@ type CopyContextRequested struct{}
 type DescriptionGeneratedMsg struct{ Text string }
 type DescriptionGenerationFailedMsg struct{ Err error }
 
+// CandidatesGeneratedMsg indicates a multi-candidate generation finished; the candidates are
+// kept by the controller. Failed counts candidates that errored.
+type CandidatesGeneratedMsg struct {
+	Count  int
+	Failed int
+}
+type CandidatesGenerationFailedMsg struct{ Err error }
+
+// CandidatesSelectedMsg indicates the picked candidate sections were applied and written to Path.
+type CandidatesSelectedMsg struct {
+	Text string
+	Path string
+}
+type CandidatesSelectionFailedMsg struct{ Err error }
+
+// DescriptionRefinedMsg indicates a refinement finished; Count is the number of refinements so far.
+// ParseError is set if the refined output did not parse (the previous PR data is kept).
+type DescriptionRefinedMsg struct {
+	Text       string
+	Count      int
+	ParseError string
+}
+type DescriptionRefineFailedMsg struct{ Err error }
+
+// SectionRegeneratedMsg indicates one section (title, body, changelog, release_notes) was regenerated.
+type SectionRegeneratedMsg struct {
+	Section string
+	Text    string
+}
+type SectionRegenerationFailedMsg struct {
+	Section string
+	Err     error
+}
+
+// HistoryEntry summarizes a recorded generation for the history browser.
+// This is intentionally dependency-light (no imports from internal/history).
+type HistoryEntry struct {
+	ID        string
+	CreatedAt time.Time
+	Kind      string
+	Model     string
+	Title     string
+	// Preview is the parsed PR data as YAML (the raw text if it did not parse).
+	Preview    string
+	ParseError string
+}
+
+// HistoryLoadedMsg carries the generation history, newest first.
+type HistoryLoadedMsg struct{ Entries []HistoryEntry }
+type HistoryLoadFailedMsg struct{ Err error }
+
+// HistoryRestoredMsg indicates a history record became the current generation (written to Path).
+type HistoryRestoredMsg struct {
+	ID   string
+	Text string
+	Path string
+}
+type HistoryRestoreFailedMsg struct{ Err error }
+
+// CostEstimatedMsg carries the footer line with the pre-generation cost estimate.
+type CostEstimatedMsg struct{ Text string }
+
 type ClipboardCopiedMsg struct {
 	What  string
 	Bytes int
@@ -26,6 +26,32 @@ type KeyMap struct {
 	SelectAllVisible   key.Binding
 	UnselectAllVisible key.Binding
 	CopyContext        key.Binding
+	FitBudget          key.Binding
+	GenerateCandidates key.Binding
+
+	// Prompt variable toggles (main screen)
+	ToggleConcise      key.Binding
+	ToggleBullets      key.Binding
+	ToggleKeywords     key.Binding
+	ToggleMentionFiles key.Binding
+
+	// Candidate comparison actions
+	PrevCandidate   key.Binding
+	NextCandidate   key.Binding
+	PickSection     key.Binding
+	PickCandidate   key.Binding
+	AcceptSelection key.Binding
+
+	// Result screen actions
+	Refine                 key.Binding
+	RegenerateTitle        key.Binding
+	RegenerateBody         key.Binding
+	RegenerateChangelog    key.Binding
+	RegenerateReleaseNotes key.Binding
+
+	// History browser actions
+	OpenHistory    key.Binding
+	RestoreHistory key.Binding
 
 	// Filter screen actions
 	DeleteFilter key.Binding
@@ -85,6 +111,78 @@ func Default() KeyMap {
 			key.WithKeys("y"),
 			key.WithHelp("y", "copy context"),
 		),
+		FitBudget: key.NewBinding(
+			key.WithKeys("B"),
+			key.WithHelp("B", "fit budget"),
+		),
+		GenerateCandidates: key.NewBinding(
+			key.WithKeys("G"),
+			key.WithHelp("G", "generate candidates"),
+		),
+		ToggleConcise: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "toggle concise"),
+		),
+		ToggleBullets: key.NewBinding(
+			key.WithKeys("b"),
+			key.WithHelp("b", "toggle bullets"),
+		),
+		ToggleKeywords: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "toggle keywords"),
+		),
+		ToggleMentionFiles: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "toggle file mentions"),
+		),
+		PrevCandidate: key.NewBinding(
+			key.WithKeys("left", "h"),
+			key.WithHelp("←/h", "prev candidate"),
+		),
+		NextCandidate: key.NewBinding(
+			key.WithKeys("right", "l"),
+			key.WithHelp("→/l", "next candidate"),
+		),
+		PickSection: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "pick section"),
+		),
+		PickCandidate: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "pick all sections"),
+		),
+		AcceptSelection: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "accept"),
+		),
+		Refine: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "refine"),
+		),
+		RegenerateTitle: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "regenerate title"),
+		),
+		RegenerateBody: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "regenerate body"),
+		),
+		RegenerateChangelog: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "regenerate changelog"),
+		),
+		RegenerateReleaseNotes: key.NewBinding(
+			key.WithKeys("N"),
+			key.WithHelp("N", "regenerate release notes"),
+		),
+		OpenHistory: key.NewBinding(
+			key.WithKeys("H"),
+			key.WithHelp("H", "history"),
+		),
+		RestoreHistory: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "restore"),
+		),
 		DeleteFilter: key.NewBinding(
 			key.WithKeys("d", "x"),
 			key.WithHelp("d", "delete filter"),
@@ -123,8 +221,12 @@ func (k KeyMap) ShortHelp() []key.Binding {
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.ToggleIncluded, k.ToggleFilteredView},
-		{k.OpenFilters, k.Back, k.Generate, k.CopyContext},
-		{k.SelectAllVisible, k.UnselectAllVisible},
+		{k.OpenFilters, k.Back, k.Generate, k.CopyContext, k.Refine},
+		{k.SelectAllVisible, k.UnselectAllVisible, k.FitBudget, k.GenerateCandidates},
+		{k.ToggleConcise, k.ToggleBullets, k.ToggleKeywords, k.ToggleMentionFiles},
+		{k.PrevCandidate, k.NextCandidate, k.PickSection, k.PickCandidate, k.AcceptSelection},
+		{k.RegenerateTitle, k.RegenerateBody, k.RegenerateChangelog, k.RegenerateReleaseNotes},
+		{k.OpenHistory, k.RestoreHistory},
 		{k.DeleteFilter, k.ClearFilters, k.Preset1, k.Preset2, k.Preset3},
 		{k.Help, k.Quit},
 	}
@@ -16,6 +16,7 @@ type GenerationSettings struct {
 	OutputFile  string `glazed.parameter:"output-file"`
 	Title       string `glazed.parameter:"title"`
 	Description string `glazed.parameter:"description"`
+	OutputMode  string `glazed.parameter:"output-mode"`
 }
 
 func NewGenerationLayer() (schema.Section, error) {
@@ -62,6 +63,12 @@ func NewGenerationLayer() (schema.Section, error) {
 				fields.WithDefault(""),
 				fields.WithHelp("PR description/notes (overrides session description)"),
 			),
+			fields.New(
+				"output-mode",
+				fields.TypeString,
+				fields.WithDefault(""),
+				fields.WithHelp("How structured PR data is requested: yaml or tool (schema-constrained tool call, YAML fallback); default: the preset's output_mode, else yaml"),
+			),
 		),
 	)
 }
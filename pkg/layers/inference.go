@@ -0,0 +1,70 @@
+package layers
+
+import (
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/pkg/errors"
+)
+
+const InferenceSlug = "inference"
+
+// InferenceSettings control how inference failures are handled. Durations are Go durations ("1s", "2m").
+type InferenceSettings struct {
+	Retries         int      `glazed.parameter:"retries"`
+	RetryBackoff    string   `glazed.parameter:"retry-backoff"`
+	RetryMaxBackoff string   `glazed.parameter:"retry-max-backoff"`
+	AttemptTimeout  string   `glazed.parameter:"attempt-timeout"`
+	Fallbacks       []string `glazed.parameter:"fallback"`
+}
+
+func NewInferenceLayer() (schema.Section, error) {
+	return schema.NewSection(
+		InferenceSlug,
+		"Inference Retries and Fallbacks",
+		schema.WithFields(
+			fields.New(
+				"retries",
+				fields.TypeInteger,
+				fields.WithDefault(2),
+				fields.WithHelp("Retries per model after a transient error (rate limit, 5xx, timeout); 0 disables retries"),
+			),
+			fields.New(
+				"retry-backoff",
+				fields.TypeString,
+				fields.WithDefault("1s"),
+				fields.WithHelp("Delay before the first retry; doubles with every retry (with jitter)"),
+			),
+			fields.New(
+				"retry-max-backoff",
+				fields.TypeString,
+				fields.WithDefault("30s"),
+				fields.WithHelp("Maximum delay between retries"),
+			),
+			fields.New(
+				"attempt-timeout",
+				fields.TypeString,
+				fields.WithDefault(""),
+				fields.WithHelp("Timeout of a single inference attempt, e.g. 2m (default: none); timed-out attempts are retried"),
+			),
+			fields.New(
+				"fallback",
+				fields.TypeStringList,
+				fields.WithHelp("Profiles or models to try, in order, when the configured model keeps failing (e.g. --fallback cheap-profile,gpt-4o-mini)"),
+			),
+		),
+	)
+}
+
+func GetInferenceSettings(parsedLayers *glazed_layers.ParsedLayers) (*InferenceSettings, error) {
+	if parsedLayers == nil {
+		return nil, errors.New("parsedLayers is nil")
+	}
+
+	settings := &InferenceSettings{}
+	if err := parsedLayers.InitializeStruct(InferenceSlug, settings); err != nil {
+		return nil, errors.Wrap(err, "failed to initialize inference settings")
+	}
+
+	return settings, nil
+}
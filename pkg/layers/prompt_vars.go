@@ -0,0 +1,199 @@
+package layers
+
+import (
+	"strings"
+
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	glazed_layers "github.com/go-go-golems/glazed/pkg/cmds/layers"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/prescribe/internal/domain"
+	"github.com/pkg/errors"
+)
+
+const PromptVarsSlug = "prompt-vars"
+
+type PromptVarsSettings struct {
+	Issue            string   `glazed.parameter:"issue"`
+	AdditionalSystem string   `glazed.parameter:"additional-system"`
+	Additional       []string `glazed.parameter:"additional"`
+	WithoutFiles     bool     `glazed.parameter:"without-files"`
+	Concise          bool     `glazed.parameter:"concise"`
+	UseBullets       bool     `glazed.parameter:"use-bullets"`
+	UseKeywords      bool     `glazed.parameter:"use-keywords"`
+	Language         string   `glazed.parameter:"language"`
+	Tone             string   `glazed.parameter:"tone"`
+	Vars             []string `glazed.parameter:"var"`
+	PresetVars       []string `glazed.parameter:"preset-var"`
+}
+
+// NewPromptVarsLayer defines the flags for the prompt template variables. Only the flags given on
+// the command line override the session.
+func NewPromptVarsLayer() (schema.Section, error) {
+	return schema.NewSection(
+		PromptVarsSlug,
+		"Prompt Template Variables",
+		schema.WithFields(
+			fields.New(
+				"issue",
+				fields.TypeString,
+				fields.WithDefault(""),
+				fields.WithHelp("Issue this PR addresses (template variable issue; overrides session)"),
+			),
+			fields.New(
+				"additional-system",
+				fields.TypeString,
+				fields.WithDefault(""),
+				fields.WithHelp("Text appended to the system prompt (additional_system; overrides session)"),
+			),
+			fields.New(
+				"additional",
+				fields.TypeStringList,
+				fields.WithDefault([]string{}),
+				fields.WithHelp("Additional instruction, repeatable (additional; replaces the session's list)"),
+			),
+			fields.New(
+				"without-files",
+				fields.TypeBool,
+				fields.WithDefault(true),
+				fields.WithHelp("Ask the model not to mention file names (without_files; default true)"),
+			),
+			fields.New(
+				"concise",
+				fields.TypeBool,
+				fields.WithDefault(false),
+				fields.WithHelp("Ask for a concise answer (concise)"),
+			),
+			fields.New(
+				"use-bullets",
+				fields.TypeBool,
+				fields.WithDefault(false),
+				fields.WithHelp("Ask for bullet points (use_bullets)"),
+			),
+			fields.New(
+				"use-keywords",
+				fields.TypeBool,
+				fields.WithDefault(false),
+				fields.WithHelp("Ask for keywords instead of full sentences (use_keywords)"),
+			),
+			fields.New(
+				"language",
+				fields.TypeString,
+				fields.WithDefault(""),
+				fields.WithHelp("Output language, a name or ISO code such as German or de (language; overrides session)"),
+			),
+			fields.New(
+				"tone",
+				fields.TypeString,
+				fields.WithDefault(""),
+				fields.WithHelp("Tone/audience preset: "+strings.Join(domain.ToneIDs(), ", ")+" (tone; overrides session)"),
+			),
+			fields.New(
+				"var",
+				fields.TypeStringList,
+				fields.WithDefault([]string{}),
+				fields.WithHelp("Custom template variable as key=value, repeatable (merged into the session's vars)"),
+			),
+			fields.New(
+				"preset-var",
+				fields.TypeStringList,
+				fields.WithDefault([]string{}),
+				fields.WithHelp("Value of a parameter declared by the prompt preset as key=value, repeatable (validated against the preset)"),
+			),
+		),
+	)
+}
+
+// ApplyPromptVars overrides vars with the prompt variable flags that were given explicitly, and
+// reports whether any was.
+func ApplyPromptVars(parsedLayers *glazed_layers.ParsedLayers, vars *domain.PromptVars) (bool, error) {
+	if parsedLayers == nil {
+		return false, errors.New("parsedLayers is nil")
+	}
+	settings := &PromptVarsSettings{}
+	if err := parsedLayers.InitializeStruct(PromptVarsSlug, settings); err != nil {
+		return false, errors.Wrap(err, "failed to initialize prompt variable settings")
+	}
+
+	changed := false
+	set := func(key string) bool {
+		ok := parameterWasSet(parsedLayers, PromptVarsSlug, key)
+		changed = changed || ok
+		return ok
+	}
+	if set("issue") {
+		vars.Issue = settings.Issue
+	}
+	if set("additional-system") {
+		vars.AdditionalSystem = settings.AdditionalSystem
+	}
+	if set("additional") {
+		vars.Additional = append([]string(nil), settings.Additional...)
+	}
+	if set("without-files") {
+		withoutFiles := settings.WithoutFiles
+		vars.WithoutFiles = &withoutFiles
+	}
+	if set("concise") {
+		vars.Concise = settings.Concise
+	}
+	if set("use-bullets") {
+		vars.UseBullets = settings.UseBullets
+	}
+	if set("use-keywords") {
+		vars.UseKeywords = settings.UseKeywords
+	}
+	if set("language") {
+		vars.Language = strings.TrimSpace(settings.Language)
+	}
+	if set("tone") {
+		tone := strings.ToLower(strings.TrimSpace(settings.Tone))
+		if err := domain.ValidateTone(tone); err != nil {
+			return false, errors.Wrap(err, "--tone")
+		}
+		vars.Tone = tone
+	}
+	if set("var") {
+		for _, kv := range settings.Vars {
+			k, v, ok := strings.Cut(kv, "=")
+			k = strings.TrimSpace(k)
+			if !ok || k == "" {
+				return false, errors.Errorf("invalid --var %q (expected key=value)", kv)
+			}
+			if domain.IsBuiltinPromptVar(k) {
+				return false, errors.Errorf("--var %s: %q is a built-in template variable", kv, k)
+			}
+			if vars.Vars == nil {
+				vars.Vars = map[string]any{}
+			}
+			vars.Vars[k] = v
+		}
+	}
+	return changed, nil
+}
+
+// PresetVarPairs returns the --preset-var key=value pairs.
+func PresetVarPairs(parsedLayers *glazed_layers.ParsedLayers) ([]string, error) {
+	if parsedLayers == nil {
+		return nil, errors.New("parsedLayers is nil")
+	}
+	settings := &PromptVarsSettings{}
+	if err := parsedLayers.InitializeStruct(PromptVarsSlug, settings); err != nil {
+		return nil, errors.Wrap(err, "failed to initialize prompt variable settings")
+	}
+	return settings.PresetVars, nil
+}
+
+// parameterWasSet reports whether key was given by a source other than its default (flags, env,
+// config).
+func parameterWasSet(parsedLayers *glazed_layers.ParsedLayers, slug, key string) bool {
+	p, ok := parsedLayers.GetParameter(slug, key)
+	if !ok {
+		return false
+	}
+	for _, step := range p.Log {
+		if step.Source != "default" && step.Source != "defaults" {
+			return true
+		}
+	}
+	return false
+}
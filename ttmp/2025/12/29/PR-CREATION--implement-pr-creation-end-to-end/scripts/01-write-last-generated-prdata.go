@@ -19,7 +19,7 @@ func main() {
 	err := prdata.WriteGeneratedPRDataToYAMLFile(p, &domain.GeneratedPRData{
 		Title: *title,
 		Body:  *body,
-	})
+	}, nil)
 	if err != nil {
 		panic(errors.Wrap(err, "failed to write last-generated-pr.yaml"))
 	}